@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gobot/internal/config"
+	"gobot/internal/database"
+	"gobot/internal/logging"
+	"gobot/internal/services"
+)
+
+// runExport writes exports to a directory, meant to be run by cron:
+//
+//	bot export -period month -previous -dir /data/exports
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	types := fs.String("type", "bookings,clients,revenue", "comma separated exports: bookings, clients, revenue, audit")
+	formats := fs.String("format", "csv,xlsx", "comma separated file formats: csv, xlsx")
+	periodKind := fs.String("period", services.ReportPeriodMonth, "period: day, week or month")
+	previous := fs.Bool("previous", false, "export the previous period instead of the current one")
+	fromStr := fs.String("from", "", "first day of a custom period, YYYY-MM-DD")
+	toStr := fs.String("to", "", "last day of a custom period, YYYY-MM-DD")
+	dir := fs.String("dir", ".", "directory to write files to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	period, err := exportPeriod(*periodKind, *previous, *fromStr, *toStr)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+
+	if err := database.Initialize(cfg.DBPath); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer database.Close()
+
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	exportService := services.NewExportService()
+	ctx := context.Background()
+
+	for _, kind := range strings.Split(*types, ",") {
+		for _, format := range strings.Split(*formats, ",") {
+			file, err := exportService.Export(ctx, strings.TrimSpace(kind), strings.TrimSpace(format), period)
+			if err != nil {
+				return err
+			}
+
+			path := filepath.Join(*dir, file.Name)
+			if err := os.WriteFile(path, file.Data, 0o644); err != nil {
+				return fmt.Errorf("failed to write export: %w", err)
+			}
+			slog.Info("Exported", "path", path)
+		}
+	}
+
+	return nil
+}
+
+// exportPeriod returns the custom period when dates are given, otherwise the current or previous period of the kind
+func exportPeriod(kind string, previous bool, fromStr, toStr string) (services.ReportPeriod, error) {
+	if fromStr == "" && toStr == "" {
+		switch kind {
+		case services.ReportPeriodDay, services.ReportPeriodWeek, services.ReportPeriodMonth:
+		default:
+			return services.ReportPeriod{}, fmt.Errorf("unknown period: %s", kind)
+		}
+
+		period := services.NewReportPeriod(kind, time.Now())
+		if previous {
+			period = period.Previous()
+		}
+		return period, nil
+	}
+
+	if fromStr == "" || toStr == "" {
+		return services.ReportPeriod{}, fmt.Errorf("both -from and -to are required for a custom period")
+	}
+	from, err := time.ParseInLocation("2006-01-02", fromStr, time.Local)
+	if err != nil {
+		return services.ReportPeriod{}, fmt.Errorf("invalid -from date: %w", err)
+	}
+	to, err := time.ParseInLocation("2006-01-02", toStr, time.Local)
+	if err != nil {
+		return services.ReportPeriod{}, fmt.Errorf("invalid -to date: %w", err)
+	}
+	if to.Before(from) {
+		return services.ReportPeriod{}, fmt.Errorf("-to is before -from")
+	}
+
+	return services.ReportPeriod{Kind: services.ReportPeriodCustom, From: from, To: to.AddDate(0, 0, 1)}, nil
+}
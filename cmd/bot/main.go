@@ -2,40 +2,61 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"gobot/internal/bot"
 	"gobot/internal/config"
 	"gobot/internal/database"
+	"gobot/internal/logging"
+	"gobot/internal/services"
+	"gobot/internal/web"
 )
 
 func main() {
+	// Scheduled exports run without starting the bot
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := runExport(os.Args[2:]); err != nil {
+			fatal("Export failed", err)
+		}
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		fatal("Failed to load configuration", err)
 	}
 
-	log.Println("Configuration loaded successfully")
+	logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	slog.Info("Configuration loaded", "log_level", cfg.LogLevel.String(), "log_format", cfg.LogFormat)
 
 	// Initialize database
-	if err := database.Initialize(cfg.DBPath, cfg.Debug); err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	if err := database.Initialize(cfg.DBPath); err != nil {
+		fatal("Failed to initialize database", err)
 	}
 	defer database.Close()
 
 	// Create initial data if needed
 	if err := seedDatabase(); err != nil {
-		log.Printf("Warning: Failed to seed database: %v", err)
+		slog.Warn("Failed to seed database", "error", err)
 	}
 
 	// Create and start bot
 	telegramBot, err := bot.New(cfg)
 	if err != nil {
-		log.Fatalf("Failed to create bot: %v", err)
+		fatal("Failed to create bot", err)
+	}
+
+	// Start HTTP server for the calendar feed, the admin API, the dashboard and the Mini App if configured
+	var server *web.Server
+	if cfg.HTTPAddr != "" {
+		server = web.New(cfg, services.NewCalendarService(), telegramBot.Notifications(), telegramBot.BookingLimiter(), telegramBot.Username())
+		server.Start()
 	}
 
 	// Handle graceful shutdown
@@ -43,27 +64,40 @@ func main() {
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 		<-sigChan
-		log.Println("Shutting down bot...")
+		slog.Info("Shutting down bot")
+		if server != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := server.Stop(ctx); err != nil {
+				slog.Error("Failed to stop HTTP server", "error", err)
+			}
+			cancel()
+		}
 		telegramBot.Stop()
 		os.Exit(0)
 	}()
 
 	// Start bot
-	log.Println("Starting bot...")
+	slog.Info("Starting bot")
 	telegramBot.Start()
 }
 
+// fatal logs the error and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
 // seedDatabase creates initial services if they don't exist
 func seedDatabase() error {
 	var count int64
 	database.DB.Model(&database.Service{}).Count(&count)
 
 	if count > 0 {
-		log.Println("Database already seeded")
+		slog.Debug("Database already seeded")
 		return nil
 	}
 
-	log.Println("Seeding database with initial services...")
+	slog.Info("Seeding database with initial services")
 
 	services := []database.Service{
 		// Массаж
@@ -181,6 +215,6 @@ func seedDatabase() error {
 		}
 	}
 
-	log.Println("Database seeded successfully")
+	slog.Info("Database seeded")
 	return nil
 }
@@ -0,0 +1,121 @@
+// Package bot contains admin handlers of HTTP API keys
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+
+	"gobot/internal/callback"
+	"gobot/internal/web"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// apiKeyNameMaxLength limits key names typed by admins
+const apiKeyNameMaxLength = 64
+
+// handleAdminAPIKeys shows active API keys
+func (b *Bot) handleAdminAPIKeys(ctx context.Context, c tele.Context) error {
+	keys, err := b.apiKeyService.ListKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load API keys: %w", err)
+	}
+
+	msg := "🔑 <b>HTTP API</b>\n\n"
+	if b.config.HTTPAddr == "" {
+		msg += "⚠️ HTTP-сервер выключен, задайте HTTP_ADDR.\n\n"
+	} else if b.config.PublicURL != "" {
+		msg += fmt.Sprintf("Адрес: <code>%s</code>\n", html.EscapeString(b.config.PublicURL+web.APIPrefix))
+		msg += fmt.Sprintf("Описание: %s\n\n", html.EscapeString(b.config.PublicURL+web.APIPrefix+"/openapi.yaml"))
+	}
+
+	markup := &tele.ReplyMarkup{}
+	var rows []tele.Row
+
+	if len(keys) == 0 {
+		msg += "Ключей пока нет."
+	}
+	for _, key := range keys {
+		lastUsed := "не использовался"
+		if key.LastUsedAt != nil {
+			lastUsed = key.LastUsedAt.Format("02.01.2006 15:04")
+		}
+		msg += fmt.Sprintf("• <b>%s</b> — <code>%s…</code>\n  создан %s, последний запрос: %s\n",
+			html.EscapeString(key.Name), key.Prefix, key.CreatedAt.Format("02.01.2006"), lastUsed)
+
+		rows = append(rows, markup.Row(callback.Button("🗑 Отозвать «"+key.Name+"»", "admin_api_key", "revoke", fmt.Sprintf("%d", key.ID))))
+	}
+
+	rows = append(rows,
+		markup.Row(callback.Button("➕ Создать ключ", "admin_api_key", "create")),
+		markup.Row(callback.Button("⬅️ Назад", "admin", "main")),
+	)
+	markup.Inline(rows...)
+
+	return c.Edit(msg, &tele.SendOptions{ParseMode: tele.ModeHTML, ReplyMarkup: markup})
+}
+
+// handleAdminAPIKeyAction handles API key buttons
+// args: "create", or "revoke" and the key ID
+func (b *Bot) handleAdminAPIKeyAction(ctx context.Context, c tele.Context, data callback.Data) error {
+	switch action := data.Arg(0); action {
+	case "create":
+		state := b.getUserState(c.Sender().ID)
+		state.EditMode = "api_key_name"
+
+		markup := &tele.ReplyMarkup{}
+		markup.Inline(markup.Row(callback.Button("⬅️ Назад", "admin", "api_keys")))
+
+		return c.Edit("✏️ Введите название ключа, например «CRM» или «Сайт»:", markup)
+
+	case "revoke":
+		keyID, err := data.Uint(1)
+		if err != nil {
+			return invalidArg(err)
+		}
+		if err := b.apiKeyService.RevokeKey(ctx, keyID); err != nil {
+			slog.ErrorContext(ctx, "Failed to revoke API key", "api_key_id", keyID, "error", err)
+			return c.Respond(&tele.CallbackResponse{Text: "❌ Не удалось отозвать ключ"})
+		}
+		slog.InfoContext(ctx, "API key revoked", "api_key_id", keyID)
+
+		_ = c.Respond(&tele.CallbackResponse{Text: "✅ Ключ отозван"})
+		return b.handleAdminAPIKeys(ctx, c)
+
+	default:
+		return b.staleAction(c, data.Route, action)
+	}
+}
+
+// handleAdminAPIKeyNameInput creates a key with the typed name and shows it once
+func (b *Bot) handleAdminAPIKeyNameInput(c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+
+	name := strings.TrimSpace(c.Text())
+	if name == "" || len([]rune(name)) > apiKeyNameMaxLength {
+		return c.Send(fmt.Sprintf("❌ Название должно быть от 1 до %d символов", apiKeyNameMaxLength))
+	}
+	state.EditMode = ""
+
+	ctx := b.requestContext(c)
+	key, apiKey, err := b.apiKeyService.CreateKey(ctx, name, c.Sender().ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create API key", "error", err)
+		return c.Send("❌ Не удалось создать ключ")
+	}
+	slog.InfoContext(ctx, "API key created", "api_key_id", apiKey.ID, "name", apiKey.Name)
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(callback.Button("🔑 К ключам", "admin", "api_keys")))
+
+	return c.Send(fmt.Sprintf("✅ Ключ «%s» создан:\n\n<code>%s</code>\n\n"+
+		"Сохраните его сейчас — бот хранит только хеш и больше его не покажет.\n"+
+		"Передавайте ключ в заголовке <code>Authorization: Bearer …</code>",
+		html.EscapeString(apiKey.Name), key), &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
@@ -0,0 +1,133 @@
+// Package bot contains the owner's audit log screen
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"gobot/internal/callback"
+	"gobot/internal/services"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// auditPageSize is the number of audit records per page
+const auditPageSize = 8
+
+// auditChangeShown limits the text shown per changed field
+const auditChangeShown = 100
+
+// auditFilters are entity filters of the audit screen in button order, "all" shows everything
+var auditFilters = []struct {
+	entity string
+	title  string
+}{
+	{"all", "Все"},
+	{services.AuditEntityService, "Услуги"},
+	{services.AuditEntityDiscount, "Акции"},
+	{services.AuditEntityBooking, "Записи"},
+	{services.AuditEntitySchedule, "Расписание"},
+}
+
+// handleAdminAudit shows the first page of the audit log
+func (b *Bot) handleAdminAudit(ctx context.Context, c tele.Context) error {
+	return b.showAdminAudit(ctx, c, "all", 0)
+}
+
+// handleAdminAuditAction handles audit log buttons
+// args: the entity, "all" or an audited entity type, and the page
+func (b *Bot) handleAdminAuditAction(ctx context.Context, c tele.Context, data callback.Data) error {
+	page, err := data.Int(1)
+	if err != nil {
+		return invalidArg(err)
+	}
+	if page < 0 {
+		return fmt.Errorf("%w: invalid audit page %d", services.ErrValidation, page)
+	}
+	return b.showAdminAudit(ctx, c, data.Arg(0), page)
+}
+
+// showAdminAudit shows a page of the audit log filtered by entity type
+func (b *Bot) showAdminAudit(ctx context.Context, c tele.Context, entity string, page int) error {
+	// The "audit" admin action reaches here without the owner check of the admin_audit route
+	if !b.isOwner(c.Sender().ID) {
+		return services.ErrForbidden
+	}
+
+	filter := services.AuditFilter{}
+	if entity != "all" {
+		filter.EntityType = entity
+	}
+
+	records, total, err := b.auditService.List(ctx, filter, auditPageSize, page*auditPageSize)
+	if err != nil {
+		return fmt.Errorf("failed to load audit log: %w", err)
+	}
+	pages := int((total + auditPageSize - 1) / auditPageSize)
+
+	var sb strings.Builder
+	sb.WriteString("🧾 <b>Журнал действий</b>\n\n")
+	if total == 0 {
+		sb.WriteString("Изменений пока нет")
+	} else {
+		sb.WriteString(fmt.Sprintf("Всего: %d · Стр. %d/%d\n", total, page+1, pages))
+	}
+
+	for i := range records {
+		record := &records[i]
+		action := services.AuditActionTitles[record.Action]
+		if action == "" {
+			action = record.Action
+		}
+
+		sb.WriteString(fmt.Sprintf("\n<b>%s</b> — %s\n%s #%d\n",
+			record.CreatedAt.Format("02.01.2006 15:04"),
+			html.EscapeString(services.ActorText(services.AuditActor(record))),
+			action,
+			record.EntityID,
+		))
+		for _, change := range services.AuditChanges(record) {
+			if runes := []rune(change); len(runes) > auditChangeShown {
+				change = string(runes[:auditChangeShown]) + "…"
+			}
+			sb.WriteString("• " + html.EscapeString(change) + "\n")
+		}
+	}
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+
+	filterRow := tele.Row{}
+	for _, f := range auditFilters {
+		title := f.title
+		if f.entity == entity {
+			title = "✅ " + title
+		}
+		filterRow = append(filterRow, callback.Button(title, "admin_audit", f.entity, "0"))
+	}
+	rows = append(rows, filterRow[:3], filterRow[3:])
+
+	pager := tele.Row{}
+	if page > 0 {
+		pager = append(pager, callback.Button("◀️", "admin_audit", entity, fmt.Sprintf("%d", page-1)))
+	}
+	if page+1 < pages {
+		pager = append(pager, callback.Button("▶️", "admin_audit", entity, fmt.Sprintf("%d", page+1)))
+	}
+	if len(pager) > 0 {
+		rows = append(rows, pager)
+	}
+
+	rows = append(rows,
+		markup.Row(callback.Button("📤 Выгрузить", "admin_export", services.ExportAudit)),
+		markup.Row(callback.Button("⬅️ Назад", "admin", "main")),
+	)
+	markup.Inline(rows...)
+
+	return c.Edit(sb.String(), &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
@@ -0,0 +1,690 @@
+// Package bot contains admin bookings browser handlers
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gobot/internal/callback"
+	"gobot/internal/database"
+	"gobot/internal/i18n"
+	"gobot/internal/services"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// bookingsPageSize is the number of bookings shown per page
+const bookingsPageSize = 8
+
+// rescheduleDays is how many days ahead a booking can be moved
+const rescheduleDays = 14
+
+// handleAdminBookingsPage handles the bookings list buttons
+// args: the page offset, none to return to the page the admin left
+func (b *Bot) handleAdminBookingsPage(ctx context.Context, c tele.Context, data callback.Data) error {
+	if data.Arg(0) == "" {
+		return b.handleAdminBookingsDetailed(ctx, c, b.getUserState(c.Sender().ID).BookingsOffset)
+	}
+	offset, err := data.Int(0)
+	if err != nil {
+		return invalidArg(err)
+	}
+	return b.handleAdminBookingsDetailed(ctx, c, offset)
+}
+
+// handleAdminBookingsDetailed shows a page of bookings matching the admin's filter
+func (b *Bot) handleAdminBookingsDetailed(ctx context.Context, c tele.Context, offset int) error {
+	state := b.getUserState(c.Sender().ID)
+
+	if offset < 0 {
+		offset = 0
+	}
+	state.BookingsOffset = offset
+
+	bookings, total, err := b.adminService.ListBookings(ctx, state.BookingFilter, bookingsPageSize, offset)
+	if err != nil {
+		return fmt.Errorf("failed to load bookings: %w", err)
+	}
+
+	msg := "📋 <b>Записи</b>\n"
+	if filterText := b.describeBookingFilter(ctx, state.BookingFilter); filterText != "" {
+		msg += "🔎 " + filterText + "\n"
+	}
+	msg += "\n"
+
+	if total == 0 {
+		msg += "Записей не найдено"
+	} else {
+		msg += fmt.Sprintf("Показаны %d–%d из %d\n\n", offset+1, offset+len(bookings), total)
+		for _, booking := range bookings {
+			msg += fmt.Sprintf(
+				"%s <b>%s %s</b> — %s\n"+
+					"   👤 %s %s\n",
+				getStatusEmoji(booking.Status),
+				booking.Date.Format("02.01"),
+				booking.Time,
+				booking.Service.Name,
+				booking.User.FirstName,
+				booking.User.LastName,
+			)
+		}
+	}
+
+	return c.EditOrSend(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: getAdminBookingsKeyboard(bookings, offset, total),
+	})
+}
+
+// getAdminBookingsKeyboard returns keyboard with bookings, pagination and filters
+func getAdminBookingsKeyboard(bookings []database.Booking, offset int, total int64) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+
+	for _, booking := range bookings {
+		btn := callback.Button(
+			fmt.Sprintf("%s %s %s — %s", getStatusEmoji(booking.Status), booking.Date.Format("02.01"), booking.Time, booking.User.FirstName),
+			"admin_booking",
+			fmt.Sprintf("%d", booking.ID),
+		)
+		rows = append(rows, markup.Row(btn))
+	}
+
+	nav := tele.Row{}
+	if offset > 0 {
+		prev := offset - bookingsPageSize
+		if prev < 0 {
+			prev = 0
+		}
+		nav = append(nav, callback.Button("◀️", "admin_bookings", fmt.Sprintf("%d", prev)))
+	}
+	if int64(offset+bookingsPageSize) < total {
+		nav = append(nav, callback.Button("▶️", "admin_bookings", fmt.Sprintf("%d", offset+bookingsPageSize)))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	btnFilter := callback.Button("🔎 Фильтры", "admin_bookings_filter", "menu")
+	btnBack := callback.Button("⬅️ Назад", "admin", "main")
+	rows = append(rows, markup.Row(btnFilter), markup.Row(btnBack))
+
+	markup.Inline(rows...)
+	return markup
+}
+
+// describeBookingFilter returns human readable description of active filters
+func (b *Bot) describeBookingFilter(ctx context.Context, filter services.BookingFilter) string {
+	parts := make([]string, 0)
+
+	switch filter.Period {
+	case services.BookingPeriodToday:
+		parts = append(parts, "сегодня")
+	case services.BookingPeriodTomorrow:
+		parts = append(parts, "завтра")
+	case services.BookingPeriodWeek:
+		parts = append(parts, "7 дней")
+	case services.BookingPeriodCustom:
+		if filter.DateTo.Sub(filter.DateFrom) <= 24*time.Hour {
+			parts = append(parts, filter.DateFrom.Format("02.01.2006"))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s–%s",
+				filter.DateFrom.Format("02.01.2006"),
+				filter.DateTo.AddDate(0, 0, -1).Format("02.01.2006")))
+		}
+	}
+
+	if filter.Status != "" {
+		parts = append(parts, strings.ToLower(getStatusText(filter.Status)))
+	}
+
+	if filter.ServiceID != 0 {
+		if service, err := b.adminService.GetServiceByID(ctx, filter.ServiceID); err == nil {
+			parts = append(parts, service.Name)
+		}
+	}
+
+	if filter.Client != "" {
+		parts = append(parts, "клиент «"+filter.Client+"»")
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+// handleAdminBookingsFilter shows filter menus
+func (b *Bot) handleAdminBookingsFilter(ctx context.Context, c tele.Context, kind string) error {
+	state := b.getUserState(c.Sender().ID)
+	markup := &tele.ReplyMarkup{}
+	btnBack := callback.Button("⬅️ Назад", "admin_bookings_filter", "menu")
+
+	switch kind {
+	case "menu":
+		btnDate := callback.Button("📆 Дата", "admin_bookings_filter", "date")
+		btnStatus := callback.Button("📌 Статус", "admin_bookings_filter", "status")
+		btnService := callback.Button("📋 Услуга", "admin_bookings_filter", "service")
+		btnClient := callback.Button("👤 Клиент", "admin_bookings_filter", "client")
+		btnReset := callback.Button("♻️ Сбросить фильтры", "admin_bookings_filter", "reset")
+		btnList := callback.Button("⬅️ К записям", "admin_bookings", "0")
+
+		rows := []tele.Row{
+			markup.Row(btnDate, btnStatus),
+			markup.Row(btnService, btnClient),
+		}
+		if !state.BookingFilter.IsEmpty() {
+			rows = append(rows, markup.Row(btnReset))
+		}
+		rows = append(rows, markup.Row(btnList))
+		markup.Inline(rows...)
+
+		msg := "🔎 <b>Фильтры записей</b>\n\n"
+		if filterText := b.describeBookingFilter(ctx, state.BookingFilter); filterText != "" {
+			msg += "Сейчас: " + filterText
+		} else {
+			msg += "Фильтры не заданы, показываются все записи"
+		}
+
+		return c.Edit(msg, &tele.SendOptions{
+			ParseMode:   tele.ModeHTML,
+			ReplyMarkup: markup,
+		})
+
+	case "date":
+		markup.Inline(
+			markup.Row(
+				callback.Button("Сегодня", "admin_bookings_set", "date", services.BookingPeriodToday),
+				callback.Button("Завтра", "admin_bookings_set", "date", services.BookingPeriodTomorrow),
+			),
+			markup.Row(
+				callback.Button("7 дней", "admin_bookings_set", "date", services.BookingPeriodWeek),
+				callback.Button("Любая", "admin_bookings_set", "date", ""),
+			),
+			markup.Row(callback.Button("✏️ Указать дату", "admin_bookings_filter", "custom_date")),
+			markup.Row(btnBack),
+		)
+		return c.Edit("📆 Выберите период:", markup)
+
+	case "custom_date":
+		state.EditMode = "bookings_date_filter"
+		markup.Inline(markup.Row(btnBack))
+		return c.Edit(
+			"📆 Введите дату в формате <b>ДД.ММ.ГГГГ</b>\n"+
+				"или период: <b>ДД.ММ.ГГГГ-ДД.ММ.ГГГГ</b>",
+			&tele.SendOptions{ParseMode: tele.ModeHTML, ReplyMarkup: markup},
+		)
+
+	case "status":
+		rows := make([]tele.Row, 0)
+		for _, status := range []database.BookingStatus{
+			database.BookingStatusPending,
+			database.BookingStatusConfirmed,
+			database.BookingStatusCompleted,
+			database.BookingStatusNoShow,
+			database.BookingStatusCancelled,
+			database.BookingStatusRejected,
+		} {
+			rows = append(rows, markup.Row(callback.Button(
+				fmt.Sprintf("%s %s", getStatusEmoji(status), getStatusText(status)),
+				"admin_bookings_set",
+				"status", string(status),
+			)))
+		}
+		rows = append(rows, markup.Row(callback.Button("Любой", "admin_bookings_set", "status", "")))
+		rows = append(rows, markup.Row(btnBack))
+		markup.Inline(rows...)
+		return c.Edit("📌 Выберите статус:", markup)
+
+	case "service":
+		allServices, err := b.adminService.GetAllServices(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load services: %w", err)
+		}
+		rows := make([]tele.Row, 0)
+		for _, service := range allServices {
+			rows = append(rows, markup.Row(callback.Button(service.Name, "admin_bookings_set", "service", fmt.Sprintf("%d", service.ID))))
+		}
+		rows = append(rows, markup.Row(callback.Button("Любая", "admin_bookings_set", "service", "0")))
+		rows = append(rows, markup.Row(btnBack))
+		markup.Inline(rows...)
+		return c.Edit("📋 Выберите услугу:", markup)
+
+	case "client":
+		state.EditMode = "bookings_client_filter"
+		rows := []tele.Row{}
+		if state.BookingFilter.Client != "" {
+			rows = append(rows, markup.Row(callback.Button("Любой клиент", "admin_bookings_set", "client", "")))
+		}
+		rows = append(rows, markup.Row(btnBack))
+		markup.Inline(rows...)
+		return c.Edit("👤 Введите имя, @username или Telegram ID клиента:", markup)
+
+	case "reset":
+		state.BookingFilter = services.BookingFilter{}
+		state.BookingsOffset = 0
+		return b.handleAdminBookingsDetailed(ctx, c, 0)
+
+	default:
+		return b.staleAction(c, "admin_bookings_filter", kind)
+	}
+}
+
+// handleAdminBookingsSet applies a filter value chosen with a button
+// args: the filter and its value, an empty value clears the filter
+func (b *Bot) handleAdminBookingsSet(ctx context.Context, c tele.Context, data callback.Data) error {
+	state := b.getUserState(c.Sender().ID)
+
+	switch key, value := data.Arg(0), data.Arg(1); key {
+	case "date":
+		state.BookingFilter.Period = value
+	case "status":
+		state.BookingFilter.Status = database.BookingStatus(value)
+	case "service":
+		serviceID, err := data.Uint(1)
+		if err != nil {
+			return invalidArg(err)
+		}
+		state.BookingFilter.ServiceID = serviceID
+	case "client":
+		state.BookingFilter.Client = value
+	default:
+		return b.staleAction(c, data.Route, key)
+	}
+	state.EditMode = ""
+
+	return b.handleAdminBookingsDetailed(ctx, c, 0)
+}
+
+// handleAdminBookingsFilterInput handles typed date or client filter
+func (b *Bot) handleAdminBookingsFilterInput(c tele.Context) error {
+	ctx := b.requestContext(c)
+	state := b.getUserState(c.Sender().ID)
+	text := strings.TrimSpace(c.Text())
+
+	switch state.EditMode {
+	case "bookings_date_filter":
+		from, to, err := parseDateRange(text)
+		if err != nil {
+			return c.Send("❌ Неверный формат. Введите дату как ДД.ММ.ГГГГ или период ДД.ММ.ГГГГ-ДД.ММ.ГГГГ")
+		}
+		state.BookingFilter.Period = services.BookingPeriodCustom
+		state.BookingFilter.DateFrom = from
+		state.BookingFilter.DateTo = to
+
+	case "bookings_client_filter":
+		if text == "" {
+			return c.Send("❌ Введите имя, @username или Telegram ID клиента")
+		}
+		state.BookingFilter.Client = text
+	}
+
+	state.EditMode = ""
+	return b.handleAdminBookingsDetailed(ctx, c, 0)
+}
+
+// parseDateRange parses "DD.MM.YYYY" or "DD.MM.YYYY-DD.MM.YYYY" into [from, to)
+func parseDateRange(text string) (time.Time, time.Time, error) {
+	fromStr, toStr, isRange := strings.Cut(text, "-")
+
+	from, err := time.ParseInLocation("02.01.2006", strings.TrimSpace(fromStr), time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	to := from
+	if isRange {
+		to, err = time.ParseInLocation("02.01.2006", strings.TrimSpace(toStr), time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if to.Before(from) {
+			from, to = to, from
+		}
+	}
+
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// handleAdminBookingCard shows a single booking with actions
+func (b *Bot) handleAdminBookingCard(ctx context.Context, c tele.Context, bookingID uint) error {
+	booking, err := b.adminService.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		return fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	msg := fmt.Sprintf(
+		"📋 <b>Запись #%d</b>\n\n"+
+			"👤 %s %s (@%s)\n"+
+			"🆔 <code>%d</code>\n"+
+			"📋 %s\n"+
+			"📆 %s (%s) в %s\n"+
+			"⏱ %d мин\n"+
+			"💰 %d руб.\n"+
+			"Статус: %s %s\n"+
+			"🕐 Создана: %s\n",
+		booking.ID,
+		booking.User.FirstName,
+		booking.User.LastName,
+		booking.User.Username,
+		booking.UserID,
+		booking.Service.Name,
+		booking.Date.Format("02.01.2006"),
+		i18n.Weekday(i18n.Default, booking.Date),
+		booking.Time,
+		booking.Service.Duration,
+		booking.Price/100,
+		getStatusEmoji(booking.Status),
+		getStatusText(booking.Status),
+		booking.CreatedAt.Format("02.01.2006 15:04"),
+	)
+
+	if booking.User.Phone != "" {
+		msg += fmt.Sprintf("📞 %s\n", booking.User.Phone)
+	}
+
+	if booking.User.Allergies != "" {
+		msg += fmt.Sprintf("⚠️ Аллергии: %s\n", booking.User.Allergies)
+	}
+
+	if booking.User.Specialist != "" {
+		msg += fmt.Sprintf("💆 Мастер: %s\n", booking.User.Specialist)
+	}
+
+	if booking.User.IsOffline {
+		msg += fmt.Sprintf("📵 Клиент без Telegram, ссылка-приглашение:\n%s\n", b.inviteDeepLink(&booking.User))
+	}
+
+	if booking.CertificateAmount > 0 {
+		msg += fmt.Sprintf("🎁 Оплачено сертификатом: %d руб.\n", booking.CertificateAmount/100)
+	}
+
+	if booking.Notes != "" {
+		msg += fmt.Sprintf("\n📝 <b>Заметка:</b>\n%s\n", booking.Notes)
+	}
+
+	if history, err := b.adminService.GetBookingHistory(ctx, booking.ID); err == nil && len(history) > 0 {
+		msg += "\n🗂 <b>История:</b>\n"
+		for _, h := range history {
+			msg += fmt.Sprintf("%s %s → %s %s, %s\n",
+				h.CreatedAt.Format("02.01 15:04"),
+				getStatusEmoji(h.FromStatus),
+				getStatusEmoji(h.ToStatus),
+				getStatusText(h.ToStatus),
+				bookingActorText(&h),
+			)
+			if h.Reason != "" {
+				msg += fmt.Sprintf("   <i>%s</i>\n", html.EscapeString(h.Reason))
+			}
+		}
+	}
+
+	if messages, err := b.outboxService.ListByBooking(ctx, booking.ID); err == nil && len(messages) > 0 {
+		counts := make(map[database.OutboxStatus]int)
+		for _, m := range messages {
+			counts[m.Status]++
+		}
+		msg += fmt.Sprintf(
+			"\n📤 Уведомления: ✅ %d · ⏳ %d · ❌ %d\n",
+			counts[database.OutboxStatusSent],
+			counts[database.OutboxStatusPending],
+			counts[database.OutboxStatusFailed]+counts[database.OutboxStatusBlocked],
+		)
+	}
+
+	return c.EditOrSend(msg, &tele.SendOptions{
+		ParseMode:             tele.ModeHTML,
+		ReplyMarkup:           getAdminBookingCardKeyboard(booking),
+		DisableWebPagePreview: true,
+	})
+}
+
+// bookingActorText describes who changed a booking status
+func bookingActorText(h *database.BookingStatusHistory) string {
+	switch h.Actor {
+	case database.ActorClient:
+		return "клиент"
+	case database.ActorAdmin:
+		return fmt.Sprintf("админ <code>%d</code>", h.ActorID)
+	case database.ActorAPI:
+		return "API «" + html.EscapeString(h.ActorName) + "»"
+	default:
+		return string(h.Actor)
+	}
+}
+
+// getAdminBookingCardKeyboard returns actions available for the booking status
+func getAdminBookingCardKeyboard(booking *database.Booking) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+	id := fmt.Sprintf("%d", booking.ID)
+
+	switch booking.Status {
+	case database.BookingStatusPending:
+		rows = append(rows, markup.Row(
+			callback.Button("✅ Подтвердить", "admin_booking_status", id, string(database.BookingStatusConfirmed)),
+			callback.Button("🚫 Отклонить", "admin_booking_status", id, string(database.BookingStatusRejected)),
+		))
+		rows = append(rows, markup.Row(callback.Button("🔄 Перенести", "admin_booking_reschedule", id)))
+	case database.BookingStatusConfirmed:
+		rows = append(rows, markup.Row(
+			callback.Button("✔️ Завершена", "admin_booking_status", id, string(database.BookingStatusCompleted)),
+			callback.Button("🚷 Не пришел", "admin_booking_status", id, string(database.BookingStatusNoShow)),
+		))
+		rows = append(rows, markup.Row(
+			callback.Button("🔄 Перенести", "admin_booking_reschedule", id),
+			callback.Button("❌ Отменить", "admin_booking_status", id, string(database.BookingStatusCancelled)),
+		))
+	}
+
+	rows = append(rows, markup.Row(
+		callback.Button("📝 Заметка", "admin_booking_note", id),
+		callback.Button("👤 Клиент", "admin_client", fmt.Sprintf("%d", booking.UserID)),
+	))
+	rows = append(rows, markup.Row(callback.Button("⬅️ К записям", "admin_bookings")))
+
+	markup.Inline(rows...)
+	return markup
+}
+
+// handleAdminBookingStatus changes booking status from the booking card
+// args: booking ID and the new status
+func (b *Bot) handleAdminBookingStatus(ctx context.Context, c tele.Context, data callback.Data) error {
+	bookingID, err := data.Uint(0)
+	if err != nil {
+		return invalidArg(err)
+	}
+	status := database.BookingStatus(data.Arg(1))
+
+	booking, err := b.adminService.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		return fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	if !services.CanTransitionBooking(booking.Status, status, database.ActorAdmin) {
+		return fmt.Errorf("%w: from %s to %s", services.ErrBookingTransition, booking.Status, status)
+	}
+
+	if err := b.adminService.UpdateBookingStatus(ctx, booking.ID, status, services.ByAdmin(c.Sender().ID)); err != nil {
+		return fmt.Errorf("failed to update booking status: %w", err)
+	}
+	booking.Status = status
+
+	// Let the client know about decisions that affect the visit
+	switch status {
+	case database.BookingStatusConfirmed:
+		err = b.notificationService.SendBookingConfirmation(ctx, booking)
+	case database.BookingStatusCancelled:
+		err = b.notificationService.SendBookingCancellation(ctx, booking)
+	case database.BookingStatusRejected:
+		err = b.notificationService.SendBookingRejection(ctx, booking)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to notify user about booking", "booking_id", booking.ID, "error", err)
+	}
+
+	c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("%s %s", getStatusEmoji(status), getStatusText(status))})
+	return b.handleAdminBookingCard(ctx, c, bookingID)
+}
+
+// handleAdminBookingNoteStart asks admin to type an internal note
+func (b *Bot) handleAdminBookingNoteStart(ctx context.Context, c tele.Context, bookingID uint) error {
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = "booking_note"
+	state.BookingID = bookingID
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(
+		markup.Row(callback.Button("🗑 Удалить заметку", "admin_booking_note_clear", fmt.Sprintf("%d", bookingID))),
+		markup.Row(callback.Button("⬅️ Назад", "admin_booking", fmt.Sprintf("%d", bookingID))),
+	)
+
+	return c.Edit("📝 Введите заметку к записи.\nЕе видят только администраторы.", markup)
+}
+
+// handleAdminBookingNoteInput saves the typed note
+func (b *Bot) handleAdminBookingNoteInput(c tele.Context) error {
+	ctx := b.requestContext(c)
+	state := b.getUserState(c.Sender().ID)
+	bookingID := state.BookingID
+
+	state.EditMode = ""
+	state.BookingID = 0
+
+	if err := b.adminService.UpdateBookingNotes(ctx, bookingID, strings.TrimSpace(c.Text())); err != nil {
+		return c.Send("❌ Не удалось сохранить заметку")
+	}
+
+	return b.handleAdminBookingCard(ctx, c, bookingID)
+}
+
+// handleAdminBookingNoteClear removes the internal note
+func (b *Bot) handleAdminBookingNoteClear(ctx context.Context, c tele.Context, bookingID uint) error {
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = ""
+	state.BookingID = 0
+
+	if err := b.adminService.UpdateBookingNotes(ctx, bookingID, ""); err != nil {
+		return fmt.Errorf("failed to clear booking note: %w", err)
+	}
+
+	return b.handleAdminBookingCard(ctx, c, bookingID)
+}
+
+// handleAdminBookingReschedule shows dates to move the booking to
+func (b *Bot) handleAdminBookingReschedule(ctx context.Context, c tele.Context, bookingID uint) error {
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+
+	for i := 0; i < rescheduleDays; i += 2 {
+		row := tele.Row{}
+		for j := i; j < i+2 && j < rescheduleDays; j++ {
+			date := getNextAvailableDate(j)
+			row = append(row, callback.Button(
+				fmt.Sprintf("%s (%s)", date.Format("02.01"), i18n.Weekday(i18n.Default, date)),
+				"admin_booking_resched_date",
+				fmt.Sprintf("%d", bookingID),
+				date.Format("2006-01-02"),
+			))
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, markup.Row(callback.Button("⬅️ Назад", "admin_booking", fmt.Sprintf("%d", bookingID))))
+	markup.Inline(rows...)
+
+	return c.Edit("🔄 Выберите новую дату:", markup)
+}
+
+// handleAdminBookingRescheduleDate handles the date buttons of moving a booking
+// args: booking ID and the date as YYYY-MM-DD
+func (b *Bot) handleAdminBookingRescheduleDate(ctx context.Context, c tele.Context, data callback.Data) error {
+	bookingID, err := data.Uint(0)
+	if err != nil {
+		return invalidArg(err)
+	}
+	date, err := time.ParseInLocation("2006-01-02", data.Arg(1), time.Local)
+	if err != nil {
+		return invalidArg(err)
+	}
+	return b.showAdminRescheduleSlots(ctx, c, bookingID, date)
+}
+
+// showAdminRescheduleSlots shows free time slots to move the booking to on the date
+func (b *Bot) showAdminRescheduleSlots(ctx context.Context, c tele.Context, bookingID uint, date time.Time) error {
+	booking, err := b.adminService.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		return fmt.Errorf("failed to get booking: %w", err)
+	}
+	if !services.CanMoveBooking(booking.Status) {
+		return fmt.Errorf("%w: booking %d is %s", services.ErrBookingNotMovable, booking.ID, booking.Status)
+	}
+	id := fmt.Sprintf("%d", booking.ID)
+	dateStr := date.Format("2006-01-02")
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+
+	slots := services.AvailableTimeSlots(ctx, date, booking.Service.Duration, booking.ID)
+	for i := 0; i < len(slots); i += 3 {
+		row := tele.Row{}
+		for j := i; j < i+3 && j < len(slots); j++ {
+			row = append(row, callback.Button(
+				slots[j],
+				"admin_booking_resched_time",
+				id, dateStr, slots[j],
+			))
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, markup.Row(callback.Button("⬅️ Назад", "admin_booking_reschedule", id)))
+	markup.Inline(rows...)
+
+	msg := fmt.Sprintf("🔄 Свободное время на %s:", date.Format("02.01.2006"))
+	if len(slots) == 0 {
+		msg = fmt.Sprintf("❌ На %s нет свободного времени", date.Format("02.01.2006"))
+	}
+
+	return c.Edit(msg, markup)
+}
+
+// handleAdminBookingRescheduleTime moves the booking and notifies the client
+// args: booking ID, the date as YYYY-MM-DD and the time as HH:MM
+func (b *Bot) handleAdminBookingRescheduleTime(ctx context.Context, c tele.Context, data callback.Data) error {
+	bookingID, err := data.Uint(0)
+	if err != nil {
+		return invalidArg(err)
+	}
+	date, err := time.ParseInLocation("2006-01-02", data.Arg(1), time.Local)
+	if err != nil {
+		return invalidArg(err)
+	}
+	timeSlot := data.Arg(2)
+	if _, err := time.Parse("15:04", timeSlot); err != nil {
+		return invalidArg(err)
+	}
+
+	booking, err := b.adminService.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		return fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	// The slot might have been taken while admin was choosing
+	if services.IsTimeSlotTaken(ctx, timeSlot, date, booking.Service.Duration, booking.ID) {
+		c.Respond(&tele.CallbackResponse{Text: "❌ Это время уже занято"})
+		return b.showAdminRescheduleSlots(ctx, c, booking.ID, date)
+	}
+
+	if err := b.adminService.RescheduleBooking(ctx, booking.ID, date, timeSlot); err != nil {
+		return fmt.Errorf("failed to reschedule booking: %w", err)
+	}
+	booking.Date = date
+	booking.Time = timeSlot
+
+	if err := b.notificationService.SendBookingRescheduled(ctx, booking); err != nil {
+		slog.ErrorContext(ctx, "Failed to notify user about rescheduled booking", "booking_id", booking.ID, "error", err)
+	}
+
+	c.Respond(&tele.CallbackResponse{Text: "✅ Запись перенесена"})
+	return b.handleAdminBookingCard(ctx, c, bookingID)
+}
@@ -0,0 +1,308 @@
+// Package bot contains broadcast messaging handlers
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gobot/internal/callback"
+	"gobot/internal/database"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleAdminBroadcastStart starts composing a broadcast
+func (b *Bot) handleAdminBroadcastStart(ctx context.Context, c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = "broadcast_text"
+	state.TempServiceData = make(map[string]interface{})
+
+	msg := "📣 <b>Рассылка</b>\n\n" +
+		"Шаг 1/3: Отправьте текст сообщения.\n" +
+		"💡 Можно отправить фото с подписью. Поддерживается HTML-разметка: <b>жирный</b>, <i>курсив</i>."
+
+	return c.Edit(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: getBroadcastCancelKeyboard(),
+	})
+}
+
+// handleAdminBroadcastMessage handles broadcast text or photo from admin
+func (b *Bot) handleAdminBroadcastMessage(c tele.Context) error {
+	if !b.isAdmin(c.Sender().ID) {
+		return nil
+	}
+
+	state := b.getUserState(c.Sender().ID)
+	if state.EditMode != "broadcast_text" || state.TempServiceData == nil {
+		return nil
+	}
+
+	if photo := c.Message().Photo; photo != nil {
+		state.TempServiceData["photo"] = photo.FileID
+		state.TempServiceData["text"] = c.Message().Caption
+	} else {
+		state.TempServiceData["text"] = c.Text()
+	}
+	state.EditMode = "broadcast_button"
+
+	services, err := b.bookingService.GetAvailableServices(b.requestContext(c))
+	if err != nil {
+		return fmt.Errorf("failed to load services: %w", err)
+	}
+
+	return c.Send(
+		"✅ Сообщение сохранено!\n\nШаг 2/3: Добавить кнопку записи на услугу?",
+		getBroadcastButtonKeyboard(services),
+	)
+}
+
+// getBroadcastButtonKeyboard returns keyboard to pick a service for the book button
+func getBroadcastButtonKeyboard(services []database.Service) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+
+	rows = append(rows, markup.Row(callback.Button("Без кнопки", "admin_broadcast_button", "0")))
+	for _, service := range services {
+		btn := callback.Button(
+			fmt.Sprintf("📋 %s", service.Name),
+			"admin_broadcast_button",
+			fmt.Sprintf("%d", service.ID),
+		)
+		rows = append(rows, markup.Row(btn))
+	}
+	rows = append(rows, markup.Row(callback.Button("❌ Отмена", "admin_broadcast_cancel")))
+
+	markup.Inline(rows...)
+	return markup
+}
+
+// handleAdminBroadcastButton handles the service button choice
+func (b *Bot) handleAdminBroadcastButton(ctx context.Context, c tele.Context, serviceID uint) error {
+	state := b.getUserState(c.Sender().ID)
+	if state.TempServiceData == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+	}
+
+	if serviceID != 0 {
+		state.TempServiceData["service_id"] = serviceID
+	}
+	state.EditMode = "broadcast_segment"
+
+	return c.Edit("Шаг 3/3: Выберите получателей:", getBroadcastSegmentKeyboard())
+}
+
+// getBroadcastSegmentKeyboard returns keyboard with user segments
+func getBroadcastSegmentKeyboard() *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+
+	btnAll := callback.Button("👥 Все пользователи", "admin_broadcast_segment", string(database.BroadcastSegmentAll))
+	btnClients := callback.Button("✔️ Клиенты с завершенной записью", "admin_broadcast_segment", string(database.BroadcastSegmentClients))
+	btnInactive := callback.Button("💤 Давно не были", "admin_broadcast_segment", string(database.BroadcastSegmentInactive))
+	btnService := callback.Button("📋 Записывались на услугу", "admin_broadcast_segment", string(database.BroadcastSegmentService))
+	btnBirthday := callback.Button("🎂 День рождения в этом месяце", "admin_broadcast_segment", string(database.BroadcastSegmentBirthday))
+	btnCancel := callback.Button("❌ Отмена", "admin_broadcast_cancel")
+
+	markup.Inline(
+		markup.Row(btnAll),
+		markup.Row(btnClients),
+		markup.Row(btnInactive),
+		markup.Row(btnService),
+		markup.Row(btnBirthday),
+		markup.Row(btnCancel),
+	)
+
+	return markup
+}
+
+// handleAdminBroadcastSegment handles segment choice
+func (b *Bot) handleAdminBroadcastSegment(ctx context.Context, c tele.Context, segmentStr string) error {
+	state := b.getUserState(c.Sender().ID)
+	if state.TempServiceData == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+	}
+
+	segment := database.BroadcastSegment(segmentStr)
+	state.TempServiceData["segment"] = segment
+
+	switch segment {
+	case database.BroadcastSegmentInactive:
+		markup := &tele.ReplyMarkup{}
+		row := tele.Row{}
+		for _, days := range []int{30, 60, 90, 180} {
+			row = append(row, callback.Button(fmt.Sprintf("%d дн.", days), "admin_broadcast_param", fmt.Sprintf("%d", days)))
+		}
+		markup.Inline(row, markup.Row(callback.Button("❌ Отмена", "admin_broadcast_cancel")))
+		return c.Edit("💤 Сколько дней клиент не записывался?", markup)
+
+	case database.BroadcastSegmentService:
+		services, err := b.adminService.GetAllServices(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load services: %w", err)
+		}
+		markup := &tele.ReplyMarkup{}
+		rows := make([]tele.Row, 0)
+		for _, service := range services {
+			rows = append(rows, markup.Row(callback.Button(service.Name, "admin_broadcast_param", fmt.Sprintf("%d", service.ID))))
+		}
+		rows = append(rows, markup.Row(callback.Button("❌ Отмена", "admin_broadcast_cancel")))
+		markup.Inline(rows...)
+		return c.Edit("📋 Выберите услугу:", markup)
+	}
+
+	return b.showBroadcastPreview(ctx, c)
+}
+
+// handleAdminBroadcastParam handles segment parameter (days or service)
+func (b *Bot) handleAdminBroadcastParam(ctx context.Context, c tele.Context, param int) error {
+	state := b.getUserState(c.Sender().ID)
+	if state.TempServiceData == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+	}
+
+	state.TempServiceData["segment_param"] = param
+
+	return b.showBroadcastPreview(ctx, c)
+}
+
+// buildBroadcastFromState creates a broadcast from admin's composing state
+func buildBroadcastFromState(adminID int64, state *UserState) *database.Broadcast {
+	broadcast := &database.Broadcast{CreatedBy: adminID}
+	broadcast.Text, _ = state.TempServiceData["text"].(string)
+	broadcast.PhotoFileID, _ = state.TempServiceData["photo"].(string)
+	broadcast.Segment, _ = state.TempServiceData["segment"].(database.BroadcastSegment)
+	broadcast.SegmentParam, _ = state.TempServiceData["segment_param"].(int)
+	if serviceID, ok := state.TempServiceData["service_id"].(uint); ok {
+		broadcast.ServiceID = &serviceID
+	}
+	return broadcast
+}
+
+// showBroadcastPreview sends the message as recipients will see it and asks to confirm
+func (b *Bot) showBroadcastPreview(ctx context.Context, c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = "broadcast_confirm"
+	broadcast := buildBroadcastFromState(c.Sender().ID, state)
+
+	recipients, err := b.broadcastService.ResolveRecipients(ctx, broadcast.Segment, broadcast.SegmentParam)
+	if err != nil {
+		return c.Edit("❌ Ошибка выбора получателей: " + err.Error())
+	}
+
+	c.Edit("👀 <b>Предпросмотр рассылки:</b>", &tele.SendOptions{ParseMode: tele.ModeHTML})
+	if err := b.broadcastService.SendPreview(ctx, broadcast, c.Sender().ID); err != nil {
+		return c.Send("❌ Не удалось отправить предпросмотр: " + err.Error() + "\n\nПроверьте разметку и начните заново.")
+	}
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+	if len(recipients) > 0 {
+		rows = append(rows, markup.Row(callback.Button("🚀 Отправить", "admin_broadcast_send")))
+	}
+	rows = append(rows, markup.Row(callback.Button("❌ Отмена", "admin_broadcast_cancel")))
+	markup.Inline(rows...)
+
+	msg := fmt.Sprintf(
+		"📣 <b>Рассылка готова</b>\n\n"+
+			"👥 Сегмент: %s\n"+
+			"📬 Получателей: <b>%d</b>\n"+
+			"⏱ Примерное время отправки: %s",
+		getBroadcastSegmentText(broadcast),
+		len(recipients),
+		estimateBroadcastDuration(len(recipients)),
+	)
+
+	return c.Send(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleAdminBroadcastSend starts delivering the broadcast
+func (b *Bot) handleAdminBroadcastSend(ctx context.Context, c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+	if state.EditMode != "broadcast_confirm" || state.TempServiceData == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+	}
+
+	broadcast := buildBroadcastFromState(c.Sender().ID, state)
+	state.EditMode = ""
+	state.TempServiceData = nil
+
+	adminID := c.Sender().ID
+	err := b.broadcastService.Start(ctx, broadcast, func(result *database.Broadcast) {
+		report := fmt.Sprintf(
+			"📣 <b>Рассылка #%d завершена</b>\n\n"+
+				"📬 Всего: %d\n"+
+				"✅ Доставлено: %d\n"+
+				"🚫 Заблокировали бота: %d\n"+
+				"❌ Ошибок: %d",
+			result.ID,
+			result.Total,
+			result.Delivered,
+			result.Blocked,
+			result.Failed,
+		)
+		if err := b.notificationService.NotifyAdmin(context.Background(), adminID, report); err != nil {
+			slog.Error("Failed to send broadcast report", "broadcast_id", result.ID, "admin_id", adminID, "error", err)
+		}
+	})
+	if err != nil {
+		return c.Edit("❌ Ошибка запуска рассылки: " + err.Error())
+	}
+
+	return c.Edit(fmt.Sprintf(
+		"🚀 Рассылка #%d запущена для %d получателей.\nОтчет придет по завершении.",
+		broadcast.ID,
+		broadcast.Total,
+	), getAdminKeyboard(b.isOwner(c.Sender().ID)))
+}
+
+// handleAdminBroadcastCancel cancels broadcast composing
+func (b *Bot) handleAdminBroadcastCancel(ctx context.Context, c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = ""
+	state.TempServiceData = nil
+
+	return c.Edit("❌ Рассылка отменена", getAdminKeyboard(b.isOwner(c.Sender().ID)))
+}
+
+// getBroadcastCancelKeyboard returns keyboard with cancel button
+func getBroadcastCancelKeyboard() *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(callback.Button("❌ Отмена", "admin_broadcast_cancel")))
+	return markup
+}
+
+// getBroadcastSegmentText returns description of broadcast segment
+func getBroadcastSegmentText(broadcast *database.Broadcast) string {
+	switch broadcast.Segment {
+	case database.BroadcastSegmentAll:
+		return "все пользователи"
+	case database.BroadcastSegmentClients:
+		return "клиенты с завершенной записью"
+	case database.BroadcastSegmentInactive:
+		return fmt.Sprintf("не записывались %d дн.", broadcast.SegmentParam)
+	case database.BroadcastSegmentService:
+		var service database.Service
+		if err := database.DB.First(&service, broadcast.SegmentParam).Error; err == nil {
+			return "записывались на «" + service.Name + "»"
+		}
+		return "записывались на услугу"
+	case database.BroadcastSegmentBirthday:
+		return "день рождения в этом месяце"
+	default:
+		return "неизвестно"
+	}
+}
+
+// estimateBroadcastDuration returns human readable estimate of sending time
+func estimateBroadcastDuration(recipients int) string {
+	d := time.Duration(recipients) * time.Second / 25
+	if d < time.Minute {
+		return "меньше минуты"
+	}
+	return fmt.Sprintf("~%d мин", int(d.Minutes())+1)
+}
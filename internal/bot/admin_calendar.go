@@ -0,0 +1,81 @@
+// Package bot contains admin calendar handlers
+package bot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"gobot/internal/callback"
+	"gobot/internal/web"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleAdminCalendar shows the subscription link of the bookings calendar and CalDAV sync status
+func (b *Bot) handleAdminCalendar(c tele.Context) error {
+	var sb strings.Builder
+	sb.WriteString("📅 <b>Календарь записей</b>\n\n")
+
+	if feedURL := web.CalendarFeedURL(b.config); feedURL != "" {
+		sb.WriteString("Подпишитесь на ссылку в Google Календаре, Apple Календаре или Outlook " +
+			"(«Добавить календарь» → «По URL»):\n\n" +
+			"<code>" + html.EscapeString(feedURL) + "</code>\n\n" +
+			"В календаре будут подтвержденные записи с данными клиента. " +
+			"Переносы и отмены обновляются автоматически при следующей синхронизации.\n\n" +
+			"⚠️ Не пересылайте ссылку посторонним: по ней видны телефоны клиентов. " +
+			"Чтобы отозвать доступ, смените <code>CALENDAR_FEED_TOKEN</code>.")
+	} else {
+		sb.WriteString("Подписка на календарь не настроена. Укажите в .env:\n" +
+			"• <code>HTTP_ADDR</code> — адрес HTTP-сервера, например <code>:8080</code>\n" +
+			"• <code>PUBLIC_URL</code> — внешний адрес сервера\n" +
+			"• <code>CALENDAR_FEED_TOKEN</code> — секретный токен не короче 16 символов\n\n" +
+			"После перезапуска здесь появится ссылка для подписки.")
+	}
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+
+	sb.WriteString("\n\n🔄 <b>Синхронизация CalDAV</b>\n")
+	if b.caldavService == nil {
+		sb.WriteString("Не настроена. Укажите <code>CALDAV_URL</code>, <code>CALDAV_USERNAME</code> и <code>CALDAV_PASSWORD</code>, " +
+			"чтобы записи попадали в календарь сотрудников, а занятое там время не предлагалось клиентам.")
+	} else {
+		status := b.caldavService.Status()
+		switch {
+		case status.LastSync.IsZero():
+			sb.WriteString("Еще не выполнялась")
+		case status.LastError != nil:
+			sb.WriteString(fmt.Sprintf("❌ %s: <code>%s</code>",
+				status.LastSync.Format("02.01.2006 15:04"),
+				html.EscapeString(status.LastError.Error()),
+			))
+		default:
+			sb.WriteString(fmt.Sprintf("✅ %s\nОтправлено: %d, удалено: %d\nЗанятых интервалов в календаре: %d",
+				status.LastSync.Format("02.01.2006 15:04"),
+				status.Pushed,
+				status.Deleted,
+				status.BusyPeriods,
+			))
+		}
+		rows = append(rows, markup.Row(callback.Button("🔄 Синхронизировать", "admin", "calendar_sync")))
+	}
+
+	rows = append(rows, markup.Row(callback.Button("⬅️ Назад", "admin", "main")))
+	markup.Inline(rows...)
+
+	return c.Edit(sb.String(), &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleAdminCalendarSync starts CalDAV sync right away
+func (b *Bot) handleAdminCalendarSync(c tele.Context) error {
+	if b.caldavService == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "CalDAV не настроен"})
+	}
+
+	b.caldavService.SyncNow()
+	return c.Respond(&tele.CallbackResponse{Text: "🔄 Синхронизация запущена, обновите экран через несколько секунд"})
+}
@@ -0,0 +1,353 @@
+// Package bot contains gift certificate management handlers
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gobot/internal/callback"
+	"gobot/internal/database"
+	"gobot/internal/i18n"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleAdminCertificates shows gift certificates management interface
+func (b *Bot) handleAdminCertificates(ctx context.Context, c tele.Context) error {
+	certificates, err := b.certificateService.ListCertificates(ctx, 20)
+	if err != nil {
+		return fmt.Errorf("failed to load certificates: %w", err)
+	}
+
+	msg := "🎁 <b>Подарочные сертификаты</b>\n\n"
+	if len(certificates) == 0 {
+		msg += "Сертификатов пока нет\n"
+	} else {
+		msg += "Последние выпущенные сертификаты:\n"
+	}
+
+	return c.EditOrSend(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: getCertificatesManagementKeyboard(certificates),
+	})
+}
+
+// getCertificatesManagementKeyboard returns keyboard for certificates management
+func getCertificatesManagementKeyboard(certificates []database.GiftCertificate) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+
+	for _, certificate := range certificates {
+		label := fmt.Sprintf("%d/%d руб.", certificate.Balance/100, certificate.Amount/100)
+		if certificate.Service != nil {
+			label = certificate.Service.Name
+		}
+
+		btn := callback.Button(
+			fmt.Sprintf("%s %s · %s", getCertificateStatusEmoji(&certificate), certificate.Code, label),
+			"admin_view_certificate",
+			fmt.Sprintf("%d", certificate.ID),
+		)
+		rows = append(rows, markup.Row(btn))
+	}
+
+	btnAdd := callback.Button("➕ Выпустить сертификат", "admin_add_certificate", "new")
+	btnLookup := callback.Button("🔎 Найти по коду", "admin_lookup_certificate")
+	btnBack := callback.Button("⬅️ Назад", "admin", "main")
+	btnMenu := callback.Button("🏠 Главное меню", "back_to_menu")
+
+	rows = append(rows, markup.Row(btnAdd))
+	rows = append(rows, markup.Row(btnLookup))
+	rows = append(rows, markup.Row(btnBack, btnMenu))
+
+	markup.Inline(rows...)
+	return markup
+}
+
+// handleAdminAddCertificateStart starts certificate issuing
+func (b *Bot) handleAdminAddCertificateStart(ctx context.Context, c tele.Context) error {
+	services, err := b.adminService.GetAllServices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load services: %w", err)
+	}
+
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = "add_certificate_amount"
+	state.TempServiceData = make(map[string]interface{})
+
+	msg := "➕ <b>Выпуск сертификата</b>\n\n" +
+		"Шаг 1/2: Выберите номинал или услугу\n" +
+		"💡 Или введите свой номинал в рублях:"
+
+	return c.Edit(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: getCertificateValueKeyboard(services),
+	})
+}
+
+// getCertificateValueKeyboard returns keyboard with nominal values and services
+func getCertificateValueKeyboard(services []database.Service) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+
+	amounts := []int{1000, 2000, 3000, 5000, 10000}
+	row := tele.Row{}
+	for _, amount := range amounts {
+		row = append(row, callback.Button(fmt.Sprintf("%d ₽", amount), "admin_certificate_amount", fmt.Sprintf("%d", amount)))
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = tele.Row{}
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	for _, service := range services {
+		if !service.IsActive {
+			continue
+		}
+		btn := callback.Button(
+			fmt.Sprintf("📋 %s", service.Name),
+			"admin_certificate_service",
+			fmt.Sprintf("%d", service.ID),
+		)
+		rows = append(rows, markup.Row(btn))
+	}
+
+	btnCancel := callback.Button("❌ Отмена", "admin_cancel_add_certificate")
+	rows = append(rows, markup.Row(btnCancel))
+
+	markup.Inline(rows...)
+	return markup
+}
+
+// getCertificateValidityKeyboard returns keyboard with validity options
+func getCertificateValidityKeyboard() *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+
+	btn3 := callback.Button("3 месяца", "admin_certificate_validity", "3")
+	btn6 := callback.Button("6 месяцев", "admin_certificate_validity", "6")
+	btn12 := callback.Button("12 месяцев", "admin_certificate_validity", "12")
+	btnCancel := callback.Button("❌ Отмена", "admin_cancel_add_certificate")
+
+	markup.Inline(
+		markup.Row(btn3, btn6, btn12),
+		markup.Row(btnCancel),
+	)
+
+	return markup
+}
+
+// handleAdminCertificateAmount handles nominal value selection
+func (b *Bot) handleAdminCertificateAmount(ctx context.Context, c tele.Context, amount int) error {
+	if amount <= 0 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный номинал"})
+	}
+
+	state := b.getUserState(c.Sender().ID)
+	if state.TempServiceData == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+	}
+	state.TempServiceData["amount"] = amount * 100
+	state.EditMode = "add_certificate_validity"
+
+	return c.Edit(
+		fmt.Sprintf("✅ Номинал: <b>%d руб.</b>\n\nШаг 2/2: Выберите срок действия:", amount),
+		&tele.SendOptions{
+			ParseMode:   tele.ModeHTML,
+			ReplyMarkup: getCertificateValidityKeyboard(),
+		},
+	)
+}
+
+// handleAdminCertificateService handles service selection for a service certificate
+func (b *Bot) handleAdminCertificateService(ctx context.Context, c tele.Context, serviceID uint) error {
+	service, err := b.adminService.GetServiceByID(ctx, serviceID)
+	if err != nil {
+		return c.Edit("Услуга не найдена")
+	}
+
+	state := b.getUserState(c.Sender().ID)
+	if state.TempServiceData == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+	}
+	state.TempServiceData["service_id"] = service.ID
+	state.EditMode = "add_certificate_validity"
+
+	return c.Edit(
+		fmt.Sprintf("✅ Услуга: <b>%s</b>\n\nШаг 2/2: Выберите срок действия:", service.Name),
+		&tele.SendOptions{
+			ParseMode:   tele.ModeHTML,
+			ReplyMarkup: getCertificateValidityKeyboard(),
+		},
+	)
+}
+
+// handleAdminCertificateValidity handles validity selection and issues the certificate
+func (b *Bot) handleAdminCertificateValidity(ctx context.Context, c tele.Context, months int) error {
+	if months <= 0 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный срок"})
+	}
+
+	state := b.getUserState(c.Sender().ID)
+	if state.TempServiceData == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+	}
+
+	amount, _ := state.TempServiceData["amount"].(int)
+	var serviceID *uint
+	if id, ok := state.TempServiceData["service_id"].(uint); ok {
+		serviceID = &id
+	}
+
+	// Clear state
+	state.EditMode = ""
+	state.TempServiceData = nil
+
+	now := time.Now()
+	expiresAt := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location()).AddDate(0, months, 0)
+
+	certificate, err := b.certificateService.IssueCertificate(ctx, c.Sender().ID, amount, serviceID, expiresAt, "")
+	if err != nil {
+		return c.Edit("❌ Ошибка выпуска сертификата: " + err.Error())
+	}
+
+	c.Respond(&tele.CallbackResponse{Text: "✅ Сертификат выпущен"})
+
+	msg, markup := b.certificateShareMessage(i18n.Default, certificate)
+	return c.Edit("✅ <b>Сертификат выпущен!</b>\n\n"+msg+"\n\n💡 Перешлите это сообщение получателю или нажмите кнопку ниже.", &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleAdminAddCertificateMessage handles text input for certificate issuing and lookup
+func (b *Bot) handleAdminAddCertificateMessage(c tele.Context) error {
+	if !b.isAdmin(c.Sender().ID) {
+		return nil
+	}
+
+	state := b.getUserState(c.Sender().ID)
+	ctx := b.requestContext(c)
+	text := strings.TrimSpace(c.Text())
+
+	switch state.EditMode {
+	case "add_certificate_amount":
+		amount, err := strconv.Atoi(text)
+		if err != nil || amount <= 0 {
+			return c.Send("❌ Неверный формат. Введите число (например: 2500)")
+		}
+		state.TempServiceData["amount"] = amount * 100
+		state.EditMode = "add_certificate_validity"
+
+		return c.Send(
+			fmt.Sprintf("✅ Номинал: <b>%d руб.</b>\n\nШаг 2/2: Выберите срок действия:", amount),
+			&tele.SendOptions{
+				ParseMode:   tele.ModeHTML,
+				ReplyMarkup: getCertificateValidityKeyboard(),
+			},
+		)
+
+	case "certificate_lookup":
+		state.EditMode = ""
+		certificate, err := b.certificateService.GetCertificateByCode(ctx, text)
+		if err != nil {
+			return c.Send("❌ "+certificateErrorText(i18n.Default, err), getCertificateBackKeyboard())
+		}
+		return b.showAdminCertificate(ctx, c, certificate.ID)
+	}
+
+	return nil
+}
+
+// handleAdminLookupCertificateStart asks admin for a certificate code
+func (b *Bot) handleAdminLookupCertificateStart(ctx context.Context, c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = "certificate_lookup"
+
+	return c.Edit("🔎 Отправьте код сертификата:", getCertificateBackKeyboard())
+}
+
+// handleAdminViewCertificate shows certificate details
+func (b *Bot) handleAdminViewCertificate(ctx context.Context, c tele.Context, certificateID uint) error {
+	return b.showAdminCertificate(ctx, c, certificateID)
+}
+
+// showAdminCertificate renders certificate details with redemption history
+func (b *Bot) showAdminCertificate(ctx context.Context, c tele.Context, certificateID uint) error {
+	certificate, err := b.certificateService.GetCertificateByID(ctx, certificateID)
+	if err != nil {
+		return c.EditOrSend("Сертификат не найден", getCertificateBackKeyboard())
+	}
+
+	msg := formatCertificateInfo(i18n.Default, certificate)
+	if certificate.PurchasedBy != nil {
+		msg += fmt.Sprintf("\n💳 Куплен клиентом: <code>%d</code>", *certificate.PurchasedBy)
+	} else {
+		msg += fmt.Sprintf("\n👤 Выпустил: <code>%d</code>", certificate.IssuedBy)
+	}
+	msg += fmt.Sprintf("\n🕐 Выпущен: %s", certificate.CreatedAt.Format("02.01.2006 15:04"))
+
+	if len(certificate.Redemptions) > 0 {
+		msg += "\n\n<b>История списаний:</b>\n"
+		for _, redemption := range certificate.Redemptions {
+			msg += fmt.Sprintf(
+				"• %s — %d руб. (запись #%d на %s)\n",
+				redemption.CreatedAt.Format("02.01.2006"),
+				redemption.Amount/100,
+				redemption.BookingID,
+				redemption.Booking.Date.Format("02.01.2006"),
+			)
+		}
+	}
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+	if certificate.Status == database.GiftCertificateStatusActive {
+		btnVoid := callback.Button("🚫 Аннулировать", "admin_void_certificate", fmt.Sprintf("%d", certificate.ID))
+		rows = append(rows, markup.Row(btnVoid))
+	}
+	btnBack := callback.Button("⬅️ К сертификатам", "admin_certificates")
+	rows = append(rows, markup.Row(btnBack))
+	markup.Inline(rows...)
+
+	return c.EditOrSend(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleAdminVoidCertificate voids a certificate
+func (b *Bot) handleAdminVoidCertificate(ctx context.Context, c tele.Context, certificateID uint) error {
+	if err := b.certificateService.VoidCertificate(ctx, certificateID); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Сертификат уже неактивен"})
+	}
+
+	c.Respond(&tele.CallbackResponse{Text: "🚫 Сертификат аннулирован"})
+	return b.showAdminCertificate(ctx, c, certificateID)
+}
+
+// handleAdminCancelAddCertificate cancels certificate issuing
+func (b *Bot) handleAdminCancelAddCertificate(ctx context.Context, c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = ""
+	state.TempServiceData = nil
+
+	return c.Edit("❌ Выпуск сертификата отменен", getCertificateBackKeyboard())
+}
+
+// getCertificateBackKeyboard returns keyboard to go back to certificates
+func getCertificateBackKeyboard() *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	btnBack := callback.Button("⬅️ К сертификатам", "admin_certificates")
+	btnMenu := callback.Button("🏠 Главное меню", "back_to_menu")
+	markup.Inline(
+		markup.Row(btnBack),
+		markup.Row(btnMenu),
+	)
+	return markup
+}
@@ -0,0 +1,363 @@
+// Package bot contains admin client card handlers
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"gobot/internal/callback"
+	"gobot/internal/database"
+	"gobot/internal/services"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// clientCardBookingsLimit is how many recent bookings are listed in the client card
+const clientCardBookingsLimit = 10
+
+// handleAdminClients asks admin for a client search query
+func (b *Bot) handleAdminClients(ctx context.Context, c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = "client_search"
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(
+		markup.Row(callback.Button("🚫 Черный список", "admin", "blocked")),
+		markup.Row(callback.Button("⬅️ Назад", "admin", "main")),
+	)
+
+	return c.Edit("👥 <b>Клиенты</b>\n\n🔎 Введите имя, @username, телефон или Telegram ID клиента:", &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleAdminClientSearchInput shows clients found by the typed query
+func (b *Bot) handleAdminClientSearchInput(c tele.Context) error {
+	ctx := b.requestContext(c)
+
+	users, err := b.userService.SearchUsers(ctx, strings.TrimSpace(c.Text()), clientSearchLimit)
+	if err != nil {
+		return fmt.Errorf("failed to search clients: %w", err)
+	}
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+	for _, user := range users {
+		label := formatClientButton(&user)
+		if user.IsBlocked {
+			label = "🚫 " + label
+		}
+		rows = append(rows, markup.Row(callback.Button(label, "admin_client", fmt.Sprintf("%d", user.ID))))
+	}
+	rows = append(rows, markup.Row(callback.Button("⬅️ Админ-панель", "admin", "main")))
+	markup.Inline(rows...)
+
+	msg := fmt.Sprintf("Найдено клиентов: %d\nВыберите клиента или введите другой запрос:", len(users))
+	if len(users) == 0 {
+		msg = "Никого не нашли. Введите другой запрос:"
+	}
+	return c.Send(msg, markup)
+}
+
+// handleAdminBlockedClients shows the block list
+func (b *Bot) handleAdminBlockedClients(ctx context.Context, c tele.Context) error {
+	b.getUserState(c.Sender().ID).EditMode = ""
+
+	users, err := b.userService.GetBlockedUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load blocked users: %w", err)
+	}
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+	for _, user := range users {
+		rows = append(rows, markup.Row(callback.Button(formatClientButton(&user), "admin_client", fmt.Sprintf("%d", user.ID))))
+	}
+	rows = append(rows, markup.Row(callback.Button("⬅️ Назад", "admin", "clients")))
+	markup.Inline(rows...)
+
+	msg := fmt.Sprintf("🚫 <b>Черный список</b>\n\nЗаблокировано клиентов: %d", len(users))
+	if len(users) == 0 {
+		msg = "🚫 <b>Черный список</b>\n\nЗаблокированных клиентов нет."
+	}
+
+	return c.Edit(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleAdminClientCard shows client details, visit history and actions
+func (b *Bot) handleAdminClientCard(ctx context.Context, c tele.Context, userID int64) error {
+	user, err := b.userService.GetUser(ctx, userID)
+	if err != nil {
+		return c.EditOrSend("❌ Клиент не найден")
+	}
+
+	stats, err := b.userService.GetClientStats(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get stats of client %d: %w", userID, err)
+	}
+
+	bookings, _, err := b.adminService.ListBookings(ctx, services.BookingFilter{Client: strconv.FormatInt(userID, 10)}, clientCardBookingsLimit, 0)
+	if err != nil {
+		return fmt.Errorf("failed to get bookings of client %d: %w", userID, err)
+	}
+
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = ""
+	state.ClientID = 0
+
+	return c.EditOrSend(b.formatClientCard(user, stats, bookings), &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: getAdminClientCardKeyboard(user),
+	})
+}
+
+// formatClientCard returns client card text for admins
+func (b *Bot) formatClientCard(user *database.User, stats *services.ClientStats, bookings []database.Booking) string {
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	msg := fmt.Sprintf("👤 <b>%s</b>\n", name)
+
+	if user.Username != "" {
+		msg += fmt.Sprintf("@%s\n", user.Username)
+	}
+	if user.IsOffline {
+		msg += fmt.Sprintf("📵 Без Telegram, ссылка-приглашение:\n%s\n", b.inviteDeepLink(user))
+	} else {
+		msg += fmt.Sprintf("🆔 %d\n", user.ID)
+	}
+	if user.Phone != "" {
+		msg += fmt.Sprintf("📞 %s\n", user.Phone)
+	}
+	if user.Birthday != nil {
+		msg += fmt.Sprintf("🎂 %s\n", user.Birthday.Format("02.01.2006"))
+	}
+	if user.Specialist != "" {
+		msg += fmt.Sprintf("💆 Мастер: %s\n", user.Specialist)
+	}
+	msg += fmt.Sprintf("📅 Клиент с %s\n", user.CreatedAt.Format("02.01.2006"))
+
+	if user.IsBlocked {
+		msg += "\n🚫 <b>Заблокирован — не может записываться</b>\n"
+		if user.BlockedAt != nil {
+			msg += fmt.Sprintf("С %s\n", user.BlockedAt.Format("02.01.2006 15:04"))
+		}
+		if user.BlockReason != "" {
+			visibility := "видна клиенту"
+			if user.BlockReasonHidden {
+				visibility = "скрыта от клиента"
+			}
+			msg += fmt.Sprintf("Причина (%s): %s\n", visibility, user.BlockReason)
+		}
+	}
+	if user.BlockedBot {
+		msg += "\n🔕 Заблокировал бота — сообщения не доставляются\n"
+	}
+
+	if user.Allergies != "" {
+		msg += fmt.Sprintf("\n⚠️ <b>Аллергии и противопоказания:</b>\n%s\n", user.Allergies)
+	}
+	if user.AdminNotes != "" {
+		msg += fmt.Sprintf("\n📝 <b>Заметка:</b>\n%s\n", user.AdminNotes)
+	}
+
+	msg += fmt.Sprintf(
+		"\n📊 <b>История:</b>\n"+
+			"📋 Всего записей: %d\n"+
+			"✔️ Визитов: %d\n"+
+			"💰 Потрачено: %d руб.\n"+
+			"❌ Отмен: %d (поздних: %d)\n"+
+			"🚷 Не пришел: %d\n",
+		stats.TotalBookings,
+		stats.Completed,
+		stats.TotalSpent/100,
+		stats.Cancelled,
+		stats.LateCancels,
+		stats.NoShows,
+	)
+	if stats.LastVisit != nil {
+		msg += fmt.Sprintf("🕐 Последний визит: %s\n", stats.LastVisit.Format("02.01.2006"))
+	}
+	if stats.CertificateBalance > 0 {
+		msg += fmt.Sprintf("🎁 Баланс сертификатов: %d руб.\n", stats.CertificateBalance/100)
+	}
+
+	if len(bookings) > 0 {
+		msg += "\n📅 <b>Последние записи:</b>\n"
+		for _, booking := range bookings {
+			msg += fmt.Sprintf(
+				"%s %s %s — %s\n",
+				getStatusEmoji(booking.Status),
+				booking.Date.Format("02.01.06"),
+				booking.Time,
+				booking.Service.Name,
+			)
+		}
+	}
+
+	return msg
+}
+
+// getAdminClientCardKeyboard returns keyboard with client actions
+func getAdminClientCardKeyboard(user *database.User) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	id := fmt.Sprintf("%d", user.ID)
+
+	btnBookings := callback.Button("📅 Все записи", "admin_client_action", "bookings", id)
+	btnNote := callback.Button("📝 Заметка", "admin_client_action", "note", id)
+
+	btnBlock := callback.Button("🚫 Заблокировать", "admin_client_action", "block", id)
+	if user.IsBlocked {
+		btnBlock = callback.Button("✅ Разблокировать", "admin_client_action", "unblock", id)
+	}
+
+	rows := []tele.Row{markup.Row(btnBookings, btnNote)}
+	if user.IsOffline {
+		rows = append(rows, markup.Row(btnBlock))
+	} else {
+		btnMessage := callback.Button("✉️ Написать", "admin_client_action", "message", id)
+		rows = append(rows, markup.Row(btnMessage, btnBlock))
+	}
+	rows = append(rows, markup.Row(
+		callback.Button("🔎 Поиск", "admin", "clients"),
+		callback.Button("⬅️ Админ-панель", "admin", "main"),
+	))
+
+	markup.Inline(rows...)
+	return markup
+}
+
+// handleAdminClientAction handles client card buttons
+// args: the action and the client's Telegram ID
+func (b *Bot) handleAdminClientAction(ctx context.Context, c tele.Context, data callback.Data) error {
+	action := data.Arg(0)
+	userID, err := data.Int64(1)
+	if err != nil {
+		return invalidArg(err)
+	}
+	idStr := strconv.FormatInt(userID, 10)
+
+	state := b.getUserState(c.Sender().ID)
+
+	markup := &tele.ReplyMarkup{}
+	btnBack := callback.Button("⬅️ Назад", "admin_client", idStr)
+
+	switch action {
+	case "bookings":
+		state.BookingFilter = services.BookingFilter{Client: idStr}
+		return b.handleAdminBookingsDetailed(ctx, c, 0)
+
+	case "note":
+		state.EditMode = "client_note"
+		state.ClientID = userID
+		markup.Inline(
+			markup.Row(callback.Button("🗑 Удалить заметку", "admin_client_action", "clear_note", idStr)),
+			markup.Row(btnBack),
+		)
+		return c.Edit("📝 Введите заметку о клиенте.\nЕе видят только администраторы.", markup)
+
+	case "clear_note":
+		if err := b.userService.UpdateAdminNotes(ctx, userID, ""); err != nil {
+			return c.Respond(&tele.CallbackResponse{Text: "❌ Не удалось удалить заметку"})
+		}
+		return b.handleAdminClientCard(ctx, c, userID)
+
+	case "message":
+		if services.IsOfflineUserID(userID) {
+			return c.Respond(&tele.CallbackResponse{Text: "У клиента нет Telegram"})
+		}
+		state.EditMode = "client_message"
+		state.ClientID = userID
+		markup.Inline(markup.Row(btnBack))
+		return c.Edit("✉️ Введите сообщение для клиента.\nОно придет от имени бота.", markup)
+
+	case "block":
+		state.EditMode = "client_block_reason"
+		state.ClientID = userID
+		markup.Inline(
+			markup.Row(callback.Button("🚫 Без причины", "admin_client_action", "block_now", idStr)),
+			markup.Row(btnBack),
+		)
+		return c.Edit("🚫 Введите причину блокировки.\nПосле этого выберите, показывать ли ее клиенту.", markup)
+
+	case "block_now", "block_shown", "block_hidden":
+		reason := ""
+		if action != "block_now" {
+			reason, _ = state.TempServiceData["block_reason"].(string)
+			if reason == "" || state.ClientID != userID {
+				return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+			}
+		}
+		state.EditMode = ""
+		state.TempServiceData = nil
+
+		if err := b.userService.BlockUser(ctx, userID, reason, action == "block_hidden"); err != nil {
+			slog.ErrorContext(ctx, "Failed to block client", "client_id", userID, "error", err)
+			return c.Respond(&tele.CallbackResponse{Text: "❌ Ошибка"})
+		}
+		return b.handleAdminClientCard(ctx, c, userID)
+
+	case "unblock":
+		if err := b.userService.UnblockUser(ctx, userID); err != nil {
+			slog.ErrorContext(ctx, "Failed to unblock client", "client_id", userID, "error", err)
+			return c.Respond(&tele.CallbackResponse{Text: "❌ Ошибка"})
+		}
+		return b.handleAdminClientCard(ctx, c, userID)
+
+	default:
+		return b.staleAction(c, data.Route, action)
+	}
+}
+
+// handleAdminClientInput handles typed client note, message or block reason
+func (b *Bot) handleAdminClientInput(c tele.Context) error {
+	ctx := b.requestContext(c)
+	state := b.getUserState(c.Sender().ID)
+	userID := state.ClientID
+	text := strings.TrimSpace(c.Text())
+
+	// Block reason is kept until admin chooses its visibility
+	if state.EditMode == "client_block_reason" {
+		if text == "" {
+			return c.Send("❌ Причина не может быть пустой")
+		}
+		state.TempServiceData = map[string]interface{}{"block_reason": text}
+
+		idStr := fmt.Sprintf("%d", userID)
+		markup := &tele.ReplyMarkup{}
+		markup.Inline(
+			markup.Row(
+				callback.Button("👁 Показать клиенту", "admin_client_action", "block_shown", idStr),
+				callback.Button("🙈 Скрыть", "admin_client_action", "block_hidden", idStr),
+			),
+			markup.Row(callback.Button("⬅️ Назад", "admin_client", idStr)),
+		)
+		return c.Send(fmt.Sprintf("Причина: %s\n\nПоказывать причину клиенту при попытке записаться?", text), markup)
+	}
+
+	mode := state.EditMode
+	state.EditMode = ""
+	state.ClientID = 0
+
+	switch mode {
+	case "client_note":
+		if err := b.userService.UpdateAdminNotes(ctx, userID, text); err != nil {
+			return c.Send("❌ Не удалось сохранить заметку")
+		}
+
+	case "client_message":
+		msg := "💬 <b>Сообщение от администратора</b>\n\n" + text
+		if _, err := b.outboxService.Enqueue(ctx, &tele.User{ID: userID}, msg, nil, 0); err != nil {
+			slog.ErrorContext(ctx, "Failed to send message to client", "client_id", userID, "error", err)
+			return c.Send("❌ Не удалось отправить сообщение")
+		}
+		c.Send("✅ Сообщение поставлено в очередь отправки")
+	}
+
+	return b.handleAdminClientCard(ctx, c, userID)
+}
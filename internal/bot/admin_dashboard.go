@@ -0,0 +1,41 @@
+// Package bot contains the admin handler of web dashboard links
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"gobot/internal/services"
+	"gobot/internal/web"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleAdminDashboard sends a one-time link to the web dashboard
+func (b *Bot) handleAdminDashboard(ctx context.Context, c tele.Context) error {
+	if b.config.HTTPAddr == "" || b.config.PublicURL == "" {
+		_ = c.Respond()
+		return c.Send("⚠️ Веб-панель выключена: задайте HTTP_ADDR и PUBLIC_URL.")
+	}
+
+	token, err := b.dashboardAuthService.CreateLoginToken(ctx, c.Sender().ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create dashboard login link", "error", err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Не удалось создать ссылку"})
+	}
+	link := b.config.PublicURL + web.DashboardPath + "/login?token=" + url.QueryEscape(token)
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(markup.URL("🖥 Открыть веб-панель", link)))
+
+	_ = c.Respond()
+	return c.Send(fmt.Sprintf("🖥 <b>Веб-панель</b>\n\n"+
+		"Ссылка одноразовая и действует %d минут. Не пересылайте ее — она открывает доступ к панели.",
+		int(services.LoginLinkTTL.Minutes())), &tele.SendOptions{
+		ParseMode:             tele.ModeHTML,
+		ReplyMarkup:           markup,
+		DisableWebPagePreview: true,
+	})
+}
@@ -4,11 +4,12 @@ package bot
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"strconv"
 	"strings"
 	"time"
 
+	"gobot/internal/callback"
 	"gobot/internal/database"
 	"gobot/internal/services"
 
@@ -17,14 +18,10 @@ import (
 
 // handleAdminDiscounts shows discounts management interface
 func (b *Bot) handleAdminDiscounts(ctx context.Context, c tele.Context) error {
-	if !b.isAdmin(c.Sender().ID) {
-		return c.Respond(&tele.CallbackResponse{Text: "❌ Нет доступа"})
-	}
-
 	discountService := services.NewDiscountService()
 	discounts, err := discountService.GetAllDiscounts(ctx)
 	if err != nil {
-		return c.Edit("Ошибка при загрузке акций")
+		return fmt.Errorf("failed to load discounts: %w", err)
 	}
 
 	msg := "🎉 <b>Управление акциями</b>\n\n"
@@ -77,7 +74,7 @@ func getDiscountsManagementKeyboard(discounts []database.Discount) *tele.ReplyMa
 			statusBtn = "❌"
 		}
 
-		btn := markup.Data(
+		btn := callback.Button(
 			fmt.Sprintf("%s %s (%d%%)", statusBtn, discount.Name, discount.Percentage),
 			"admin_edit_discount",
 			fmt.Sprintf("%d", discount.ID),
@@ -86,9 +83,9 @@ func getDiscountsManagementKeyboard(discounts []database.Discount) *tele.ReplyMa
 	}
 
 	// Add discount button
-	btnAdd := markup.Data("➕ Создать акцию", "admin_add_discount", "new")
-	btnBack := markup.Data("⬅️ Назад", "admin", "main")
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnAdd := callback.Button("➕ Создать акцию", "admin_add_discount", "new")
+	btnBack := callback.Button("⬅️ Назад", "admin", "main")
+	btnMenu := callback.Button("🏠 Главное меню", "back_to_menu")
 
 	rows = append(rows, markup.Row(btnAdd))
 	rows = append(rows, markup.Row(btnBack, btnMenu))
@@ -101,7 +98,7 @@ func getDiscountsManagementKeyboard(discounts []database.Discount) *tele.ReplyMa
 func (b *Bot) handleAdminAddDiscountStart(ctx context.Context, c tele.Context) error {
 	services, err := b.adminService.GetAllServices(ctx)
 	if err != nil {
-		return c.Edit("Ошибка загрузки услуг")
+		return fmt.Errorf("failed to load services: %w", err)
 	}
 
 	if len(services) == 0 {
@@ -130,7 +127,7 @@ func getServicesForDiscountKeyboard(services []database.Service) *tele.ReplyMark
 		if !service.IsActive {
 			continue
 		}
-		btn := markup.Data(
+		btn := callback.Button(
 			fmt.Sprintf("%s (%d руб.)", service.Name, service.Price/100),
 			"admin_discount_select_service",
 			fmt.Sprintf("%d", service.ID),
@@ -138,8 +135,8 @@ func getServicesForDiscountKeyboard(services []database.Service) *tele.ReplyMark
 		rows = append(rows, markup.Row(btn))
 	}
 
-	btnCancel := markup.Data("❌ Отмена", "admin_cancel_add_discount", "")
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnCancel := callback.Button("❌ Отмена", "admin_cancel_add_discount")
+	btnMenu := callback.Button("🏠 Главное меню", "back_to_menu")
 	rows = append(rows, markup.Row(btnCancel))
 	rows = append(rows, markup.Row(btnMenu))
 
@@ -148,25 +145,20 @@ func getServicesForDiscountKeyboard(services []database.Service) *tele.ReplyMark
 }
 
 // handleAdminDiscountSelectService handles service selection for discount
-func (b *Bot) handleAdminDiscountSelectService(ctx context.Context, c tele.Context, serviceIDStr string) error {
-	serviceID, err := strconv.ParseUint(serviceIDStr, 10, 32)
-	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка"})
-	}
-
-	service, err := b.adminService.GetServiceByID(ctx, uint(serviceID))
+func (b *Bot) handleAdminDiscountSelectService(ctx context.Context, c tele.Context, serviceID uint) error {
+	service, err := b.adminService.GetServiceByID(ctx, serviceID)
 	if err != nil {
 		return c.Edit("Услуга не найдена")
 	}
 
 	state := b.getUserState(c.Sender().ID)
-	state.TempServiceData["service_id"] = uint(serviceID)
+	state.TempServiceData["service_id"] = serviceID
 	state.TempServiceData["service_name"] = service.Name
 	state.EditMode = "add_discount_name"
 
 	markup := &tele.ReplyMarkup{}
-	btnCancel := markup.Data("❌ Отмена", "admin_cancel_add_discount", "")
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnCancel := callback.Button("❌ Отмена", "admin_cancel_add_discount")
+	btnMenu := callback.Button("🏠 Главное меню", "back_to_menu")
 	markup.Inline(
 		markup.Row(btnCancel),
 		markup.Row(btnMenu),
@@ -197,7 +189,7 @@ func (b *Bot) handleAdminAddDiscountMessage(c tele.Context) error {
 	}
 
 	text := c.Text()
-	ctx := context.Background()
+	ctx := b.requestContext(c)
 
 	switch state.EditMode {
 	case "add_discount_name":
@@ -205,7 +197,7 @@ func (b *Bot) handleAdminAddDiscountMessage(c tele.Context) error {
 		state.EditMode = "add_discount_percentage"
 
 		markup := &tele.ReplyMarkup{}
-		btnCancel := markup.Data("❌ Отмена", "admin_cancel_add_discount", "")
+		btnCancel := callback.Button("❌ Отмена", "admin_cancel_add_discount")
 		markup.Inline(markup.Row(btnCancel))
 
 		return c.Send(
@@ -227,8 +219,8 @@ func (b *Bot) handleAdminAddDiscountMessage(c tele.Context) error {
 		state.EditMode = "add_discount_dates"
 
 		markup := &tele.ReplyMarkup{}
-		btnCancel := markup.Data("❌ Отмена", "admin_cancel_add_discount", "")
-		btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+		btnCancel := callback.Button("❌ Отмена", "admin_cancel_add_discount")
+		btnMenu := callback.Button("🏠 Главное меню", "back_to_menu")
 		markup.Inline(
 			markup.Row(btnCancel),
 			markup.Row(btnMenu),
@@ -298,8 +290,8 @@ func (b *Bot) handleAdminAddDiscountMessage(c tele.Context) error {
 		}
 
 		markup := &tele.ReplyMarkup{}
-		btnBack := markup.Data("⬅️ К акциям", "admin_discounts", "main")
-		btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+		btnBack := callback.Button("⬅️ К акциям", "admin_discounts", "main")
+		btnMenu := callback.Button("🏠 Главное меню", "back_to_menu")
 		markup.Inline(
 			markup.Row(btnBack),
 			markup.Row(btnMenu),
@@ -329,14 +321,9 @@ func (b *Bot) handleAdminAddDiscountMessage(c tele.Context) error {
 }
 
 // handleAdminEditDiscount shows discount editing menu
-func (b *Bot) handleAdminEditDiscount(ctx context.Context, c tele.Context, discountIDStr string) error {
-	discountID, err := strconv.ParseUint(discountIDStr, 10, 32)
-	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка"})
-	}
-
+func (b *Bot) handleAdminEditDiscount(ctx context.Context, c tele.Context, discountID uint) error {
 	discountService := services.NewDiscountService()
-	discount, err := discountService.GetDiscountByID(ctx, uint(discountID))
+	discount, err := discountService.GetDiscountByID(ctx, discountID)
 	if err != nil {
 		return c.Edit("Акция не найдена")
 	}
@@ -361,10 +348,10 @@ func (b *Bot) handleAdminEditDiscount(ctx context.Context, c tele.Context, disco
 	)
 
 	markup := &tele.ReplyMarkup{}
-	btnToggle := markup.Data("🔄 Вкл/Выкл", "admin_toggle_discount", fmt.Sprintf("%d", discount.ID))
-	btnDelete := markup.Data("🗑 Удалить", "admin_delete_discount", fmt.Sprintf("%d", discount.ID))
-	btnBack := markup.Data("⬅️ Назад", "admin_discounts", "main")
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnToggle := callback.Button("🔄 Вкл/Выкл", "admin_toggle_discount", fmt.Sprintf("%d", discount.ID))
+	btnDelete := callback.Button("🗑 Удалить", "admin_delete_discount", fmt.Sprintf("%d", discount.ID))
+	btnBack := callback.Button("⬅️ Назад", "admin_discounts", "main")
+	btnMenu := callback.Button("🏠 Главное меню", "back_to_menu")
 
 	markup.Inline(
 		markup.Row(btnToggle),
@@ -379,30 +366,20 @@ func (b *Bot) handleAdminEditDiscount(ctx context.Context, c tele.Context, disco
 }
 
 // handleAdminToggleDiscount toggles discount status
-func (b *Bot) handleAdminToggleDiscount(ctx context.Context, c tele.Context, discountIDStr string) error {
-	discountID, err := strconv.ParseUint(discountIDStr, 10, 32)
-	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка"})
-	}
-
+func (b *Bot) handleAdminToggleDiscount(ctx context.Context, c tele.Context, discountID uint) error {
 	discountService := services.NewDiscountService()
-	if err := discountService.ToggleDiscountStatus(ctx, uint(discountID)); err != nil {
+	if err := discountService.ToggleDiscountStatus(ctx, discountID); err != nil {
 		return c.Respond(&tele.CallbackResponse{Text: "Ошибка изменения статуса"})
 	}
 
-	return b.handleAdminEditDiscount(ctx, c, discountIDStr)
+	return b.handleAdminEditDiscount(ctx, c, discountID)
 }
 
 // handleAdminDeleteDiscount deletes a discount
-func (b *Bot) handleAdminDeleteDiscount(ctx context.Context, c tele.Context, discountIDStr string) error {
-	discountID, err := strconv.ParseUint(discountIDStr, 10, 32)
-	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка"})
-	}
-
+func (b *Bot) handleAdminDeleteDiscount(ctx context.Context, c tele.Context, discountID uint) error {
 	discountService := services.NewDiscountService()
-	if err := discountService.DeleteDiscount(ctx, uint(discountID)); err != nil {
-		return c.Edit("❌ Ошибка удаления акции")
+	if err := discountService.DeleteDiscount(ctx, discountID); err != nil {
+		return fmt.Errorf("failed to delete discount: %w", err)
 	}
 
 	c.Respond(&tele.CallbackResponse{Text: "✅ Акция удалена"})
@@ -416,8 +393,8 @@ func (b *Bot) handleAdminCancelAddDiscount(ctx context.Context, c tele.Context)
 	state.TempServiceData = nil
 
 	markup := &tele.ReplyMarkup{}
-	btnBack := markup.Data("⬅️ К акциям", "admin_discounts", "main")
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnBack := callback.Button("⬅️ К акциям", "admin_discounts", "main")
+	btnMenu := callback.Button("🏠 Главное меню", "back_to_menu")
 	markup.Inline(
 		markup.Row(btnBack),
 		markup.Row(btnMenu),
@@ -427,9 +404,8 @@ func (b *Bot) handleAdminCancelAddDiscount(ctx context.Context, c tele.Context)
 }
 
 // handleAdminDiscountSetPercentage handles percentage selection from keyboard
-func (b *Bot) handleAdminDiscountSetPercentage(ctx context.Context, c tele.Context, percentageStr string) error {
-	percentage, err := strconv.Atoi(percentageStr)
-	if err != nil || percentage < 1 || percentage > 99 {
+func (b *Bot) handleAdminDiscountSetPercentage(ctx context.Context, c tele.Context, percentage int) error {
+	if percentage < 1 || percentage > 99 {
 		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный процент"})
 	}
 
@@ -515,13 +491,13 @@ func (b *Bot) handleAdminDiscountSetEndDate(ctx context.Context, c tele.Context,
 
 	// Send promotion to channel if configured
 	if err := b.notificationService.SendPromotionToChannel(ctx, discount); err != nil {
-		log.Printf("Error sending promotion to channel: %v", err)
+		slog.ErrorContext(ctx, "Failed to send promotion to channel", "discount_id", discount.ID, "error", err)
 		// Don't fail the whole operation if channel send fails
 	}
 
 	markup := &tele.ReplyMarkup{}
-	btnBack := markup.Data("⬅️ К акциям", "admin_discounts", "main")
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnBack := callback.Button("⬅️ К акциям", "admin_discounts", "main")
+	btnMenu := callback.Button("🏠 Главное меню", "back_to_menu")
 	markup.Inline(
 		markup.Row(btnBack),
 		markup.Row(btnMenu),
@@ -0,0 +1,192 @@
+// Package bot contains admin export handlers
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gobot/internal/callback"
+	"gobot/internal/services"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// exportTitles names export kinds
+var exportTitles = map[string]string{
+	services.ExportBookings: "📋 Записи",
+	services.ExportClients:  "👥 Клиенты",
+	services.ExportRevenue:  "💰 Выручка",
+	services.ExportAudit:    "🧾 Журнал",
+}
+
+// exportRangeLayout is the date format of periods in callback data
+const exportRangeLayout = "20060102"
+
+// exportAllTime is the period of exports that aren't limited by dates, e.g. the client list
+const exportAllTime = "all"
+
+// handleAdminExport shows export kinds
+func (b *Bot) handleAdminExport(c tele.Context) error {
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(
+		markup.Row(callback.Button(exportTitles[services.ExportBookings], "admin_export", services.ExportBookings)),
+		markup.Row(callback.Button(exportTitles[services.ExportClients], "admin_export", services.ExportClients, exportAllTime)),
+		markup.Row(callback.Button(exportTitles[services.ExportRevenue], "admin_export", services.ExportRevenue)),
+		markup.Row(callback.Button("⬅️ Назад", "admin", "main")),
+	)
+
+	return c.Edit("📤 <b>Экспорт</b>\n\n"+
+		"Выберите, что выгрузить. Файл придет сюда в формате CSV или Excel.", &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleAdminExportAction handles export steps
+// args: the kind to choose a period, the kind and "custom" to enter dates,
+// the kind and "from-to" to choose a format, and the kind, "from-to" and format to send the file
+// The client list uses "all" instead of a period
+func (b *Bot) handleAdminExportAction(ctx context.Context, c tele.Context, data callback.Data) error {
+	kind, dates, format := data.Arg(0), data.Arg(1), data.Arg(2)
+	if _, ok := exportTitles[kind]; !ok {
+		return fmt.Errorf("%w: unknown export %q", services.ErrValidation, kind)
+	}
+	if kind == services.ExportAudit && !b.isOwner(c.Sender().ID) {
+		return services.ErrForbidden
+	}
+
+	if dates == "" {
+		return showAdminExportPeriods(c, kind)
+	}
+
+	if dates == services.ReportPeriodCustom {
+		state := b.getUserState(c.Sender().ID)
+		state.EditMode = "export_period"
+		state.ExportKind = kind
+
+		markup := &tele.ReplyMarkup{}
+		markup.Inline(markup.Row(callback.Button("⬅️ Назад", "admin_export", kind)))
+
+		return c.Edit("📅 Введите период как ДД.ММ.ГГГГ-ДД.ММ.ГГГГ или одну дату ДД.ММ.ГГГГ", markup)
+	}
+
+	var period services.ReportPeriod
+	if kind != services.ExportClients || dates != exportAllTime {
+		from, to, err := parseExportRange(dates)
+		if err != nil {
+			return invalidArg(err)
+		}
+		period = services.ReportPeriod{Kind: services.ReportPeriodCustom, From: from, To: to}
+	}
+
+	if format == "" {
+		return c.Edit(exportTitles[kind]+"\n"+formatExportRange(dates)+"\n\nВыберите формат файла:",
+			getAdminExportFormatKeyboard(kind, dates))
+	}
+
+	_ = c.Respond(&tele.CallbackResponse{Text: "⏳ Готовим файл..."})
+
+	file, err := b.exportService.Export(ctx, kind, format, period)
+	if err != nil {
+		return fmt.Errorf("failed to export %s: %w", kind, err)
+	}
+
+	return c.Send(&tele.Document{
+		File:     tele.FromReader(bytes.NewReader(file.Data)),
+		FileName: file.Name,
+		MIME:     file.MIME,
+		Caption:  exportTitles[kind] + "\n" + formatExportRange(dates),
+	})
+}
+
+// handleAdminExportPeriodInput handles typed custom export period
+func (b *Bot) handleAdminExportPeriodInput(c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+
+	from, to, err := parseDateRange(strings.TrimSpace(c.Text()))
+	if err != nil {
+		return c.Send("❌ Неверный формат. Введите дату как ДД.ММ.ГГГГ или период ДД.ММ.ГГГГ-ДД.ММ.ГГГГ")
+	}
+
+	kind := state.ExportKind
+	state.EditMode = ""
+	state.ExportKind = ""
+
+	dates := from.Format(exportRangeLayout) + "-" + to.Format(exportRangeLayout)
+	return c.Send(exportTitles[kind]+"\n"+formatExportRange(dates)+"\n\nВыберите формат файла:",
+		getAdminExportFormatKeyboard(kind, dates))
+}
+
+// showAdminExportPeriods shows period buttons of the export
+func showAdminExportPeriods(c tele.Context, kind string) error {
+	now := time.Now()
+	month := services.NewReportPeriod(services.ReportPeriodMonth, now)
+	week := services.NewReportPeriod(services.ReportPeriodWeek, now)
+
+	markup := &tele.ReplyMarkup{}
+	button := func(title string, period services.ReportPeriod) tele.Btn {
+		dates := period.From.Format(exportRangeLayout) + "-" + period.To.Format(exportRangeLayout)
+		return callback.Button(title, "admin_export", kind, dates)
+	}
+
+	markup.Inline(
+		markup.Row(button("Эта неделя", week), button("Прошлая неделя", week.Previous())),
+		markup.Row(button("Этот месяц", month), button("Прошлый месяц", month.Previous())),
+		markup.Row(callback.Button("📅 Другой период", "admin_export", kind, services.ReportPeriodCustom)),
+		markup.Row(exportBackButton(markup, kind)),
+	)
+
+	return c.Edit(exportTitles[kind]+"\n\nВыберите период:", markup)
+}
+
+// getAdminExportFormatKeyboard returns file format buttons
+func getAdminExportFormatKeyboard(kind, dates string) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(
+		markup.Row(
+			callback.Button("📄 CSV", "admin_export", kind, dates, services.ExportFormatCSV),
+			callback.Button("📊 Excel", "admin_export", kind, dates, services.ExportFormatXLSX),
+		),
+		markup.Row(exportBackButton(markup, kind)),
+	)
+	return markup
+}
+
+// exportBackButton returns to the export menu, or to the audit screen the audit export is started from
+func exportBackButton(markup *tele.ReplyMarkup, kind string) tele.Btn {
+	if kind == services.ExportAudit {
+		return callback.Button("⬅️ Назад", "admin", "audit")
+	}
+	return callback.Button("⬅️ Назад", "admin", "export")
+}
+
+// parseExportRange parses "20261001-20261101" of callback data, the end is exclusive
+func parseExportRange(dates string) (time.Time, time.Time, error) {
+	fromStr, toStr, _ := strings.Cut(dates, "-")
+
+	from, err := time.ParseInLocation(exportRangeLayout, fromStr, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := time.ParseInLocation(exportRangeLayout, toStr, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("empty period: %s", dates)
+	}
+
+	return from, to, nil
+}
+
+// formatExportRange formats the period of callback data for messages
+func formatExportRange(dates string) string {
+	from, to, err := parseExportRange(dates)
+	if err != nil {
+		return "За все время"
+	}
+	return formatReportRange(services.ReportPeriod{From: from, To: to})
+}
@@ -4,8 +4,8 @@ package bot
 import (
 	"context"
 	"fmt"
-	"strconv"
 
+	"gobot/internal/callback"
 	"gobot/internal/database"
 
 	tele "gopkg.in/telebot.v3"
@@ -13,13 +13,9 @@ import (
 
 // handleAdminServicesManagement shows services management interface
 func (b *Bot) handleAdminServicesManagement(ctx context.Context, c tele.Context) error {
-	if !b.isAdmin(c.Sender().ID) {
-		return c.Respond(&tele.CallbackResponse{Text: "❌ Нет доступа"})
-	}
-
 	services, err := b.adminService.GetAllServices(ctx)
 	if err != nil {
-		return c.Edit("Ошибка при загрузке услуг")
+		return fmt.Errorf("failed to load services: %w", err)
 	}
 
 	if len(services) == 0 {
@@ -61,7 +57,7 @@ func getServicesManagementKeyboard(services []database.Service) *tele.ReplyMarku
 			statusBtn = "❌"
 		}
 
-		btnEdit := markup.Data(
+		btnEdit := callback.Button(
 			fmt.Sprintf("%s %s", statusBtn, service.Name),
 			"admin_edit_service_menu",
 			fmt.Sprintf("%d", service.ID),
@@ -70,8 +66,8 @@ func getServicesManagementKeyboard(services []database.Service) *tele.ReplyMarku
 	}
 
 	// Add service button
-	btnAdd := markup.Data("➕ Добавить услугу", "admin_add_service", "new")
-	btnBack := markup.Data("⬅️ Назад", "admin", "main")
+	btnAdd := callback.Button("➕ Добавить услугу", "admin_add_service", "new")
+	btnBack := callback.Button("⬅️ Назад", "admin", "main")
 
 	rows = append(rows, markup.Row(btnAdd))
 	rows = append(rows, markup.Row(btnBack))
@@ -84,8 +80,8 @@ func getServicesManagementKeyboard(services []database.Service) *tele.ReplyMarku
 func getAddServiceKeyboard() *tele.ReplyMarkup {
 	markup := &tele.ReplyMarkup{}
 
-	btnAdd := markup.Data("➕ Добавить услугу", "admin_add_service", "new")
-	btnBack := markup.Data("⬅️ Назад", "admin", "main")
+	btnAdd := callback.Button("➕ Добавить услугу", "admin_add_service", "new")
+	btnBack := callback.Button("⬅️ Назад", "admin", "main")
 
 	markup.Inline(
 		markup.Row(btnAdd),
@@ -99,9 +95,9 @@ func getAddServiceKeyboard() *tele.ReplyMarkup {
 func getServiceEditKeyboard(serviceID uint) *tele.ReplyMarkup {
 	markup := &tele.ReplyMarkup{}
 
-	btnToggle := markup.Data("🔄 Вкл/Выкл", "admin_toggle_service", fmt.Sprintf("%d", serviceID))
-	btnDelete := markup.Data("🗑 Удалить", "admin_delete_service", fmt.Sprintf("%d", serviceID))
-	btnBack := markup.Data("⬅️ Назад", "admin", "services")
+	btnToggle := callback.Button("🔄 Вкл/Выкл", "admin_toggle_service", fmt.Sprintf("%d", serviceID))
+	btnDelete := callback.Button("🗑 Удалить", "admin_delete_service", fmt.Sprintf("%d", serviceID))
+	btnBack := callback.Button("⬅️ Назад", "admin", "services")
 
 	markup.Inline(
 		markup.Row(btnToggle),
@@ -113,13 +109,8 @@ func getServiceEditKeyboard(serviceID uint) *tele.ReplyMarkup {
 }
 
 // handleAdminEditService shows service edit options
-func (b *Bot) handleAdminEditService(ctx context.Context, c tele.Context, serviceIDStr string) error {
-	serviceID, err := strconv.ParseUint(serviceIDStr, 10, 32)
-	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка"})
-	}
-
-	service, err := b.adminService.GetServiceByID(ctx, uint(serviceID))
+func (b *Bot) handleAdminEditService(ctx context.Context, c tele.Context, serviceID uint) error {
+	service, err := b.adminService.GetServiceByID(ctx, serviceID)
 	if err != nil {
 		return c.Edit("Услуга не найдена")
 	}
@@ -149,115 +140,20 @@ func (b *Bot) handleAdminEditService(ctx context.Context, c tele.Context, servic
 }
 
 // handleAdminToggleService toggles service active status
-func (b *Bot) handleAdminToggleService(ctx context.Context, c tele.Context, serviceIDStr string) error {
-	serviceID, err := strconv.ParseUint(serviceIDStr, 10, 32)
-	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка"})
-	}
-
-	if err := b.adminService.ToggleServiceStatus(ctx, uint(serviceID)); err != nil {
+func (b *Bot) handleAdminToggleService(ctx context.Context, c tele.Context, serviceID uint) error {
+	if err := b.adminService.ToggleServiceStatus(ctx, serviceID); err != nil {
 		return c.Respond(&tele.CallbackResponse{Text: "Ошибка изменения статуса"})
 	}
 
-	return b.handleAdminEditService(ctx, c, serviceIDStr)
+	return b.handleAdminEditService(ctx, c, serviceID)
 }
 
 // handleAdminDeleteService deletes a service
-func (b *Bot) handleAdminDeleteService(ctx context.Context, c tele.Context, serviceIDStr string) error {
-	serviceID, err := strconv.ParseUint(serviceIDStr, 10, 32)
-	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка"})
-	}
-
-	if err := b.adminService.DeleteService(ctx, uint(serviceID)); err != nil {
-		return c.Edit("❌ Ошибка удаления услуги")
+func (b *Bot) handleAdminDeleteService(ctx context.Context, c tele.Context, serviceID uint) error {
+	if err := b.adminService.DeleteService(ctx, serviceID); err != nil {
+		return fmt.Errorf("failed to delete service: %w", err)
 	}
 
 	c.Respond(&tele.CallbackResponse{Text: "✅ Услуга удалена"})
 	return b.handleAdminServicesManagement(ctx, c)
 }
-
-// handleAdminBookingsDetailed shows detailed bookings list
-func (b *Bot) handleAdminBookingsDetailed(ctx context.Context, c tele.Context) error {
-	if !b.isAdmin(c.Sender().ID) {
-		return c.Respond(&tele.CallbackResponse{Text: "❌ Нет доступа"})
-	}
-
-	bookings, err := b.adminService.GetAllBookings(ctx, 50, 0)
-	if err != nil {
-		return c.Edit("Ошибка при загрузке записей")
-	}
-
-	if len(bookings) == 0 {
-		return c.Edit("📋 Записей пока нет")
-	}
-
-	msg := "📋 <b>Все записи:</b>\n\n"
-	for i, booking := range bookings {
-		if i >= 15 { // Limit for message size
-			msg += fmt.Sprintf("\n... и еще %d записей", len(bookings)-15)
-			break
-		}
-
-		statusEmoji := getStatusEmoji(booking.Status)
-		msg += fmt.Sprintf(
-			"%d. %s <b>%s</b>\n"+
-				"   👤 %s %s (@%s)\n"+
-				"   📆 %s в %s\n"+
-				"   💰 %d руб.\n\n",
-			i+1,
-			statusEmoji,
-			booking.Service.Name,
-			booking.User.FirstName,
-			booking.User.LastName,
-			booking.User.Username,
-			booking.Date.Format("02.01.2006"),
-			booking.Time,
-			booking.Service.Price/100,
-		)
-	}
-
-	markup := &tele.ReplyMarkup{}
-	btnBack := markup.Data("⬅️ Назад", "admin", "main")
-	markup.Inline(markup.Row(btnBack))
-
-	return c.Edit(msg, &tele.SendOptions{
-		ParseMode:   tele.ModeHTML,
-		ReplyMarkup: markup,
-	})
-}
-
-// handleAdminStatsDetailed shows detailed statistics
-func (b *Bot) handleAdminStatsDetailed(ctx context.Context, c tele.Context) error {
-	if !b.isAdmin(c.Sender().ID) {
-		return c.Respond(&tele.CallbackResponse{Text: "❌ Нет доступа"})
-	}
-
-	stats, err := b.adminService.GetStats(ctx)
-	if err != nil {
-		return c.Edit("Ошибка загрузки статистики")
-	}
-
-	msg := fmt.Sprintf(
-		"📊 <b>Статистика системы</b>\n\n"+
-			"👥 Всего пользователей: <b>%d</b>\n"+
-			"📋 Всего записей: <b>%d</b>\n"+
-			"✅ Активных записей: <b>%d</b>\n"+
-			"✔️ Завершенных записей: <b>%d</b>\n"+
-			"🛠 Активных услуг: <b>%d</b>\n",
-		stats["total_users"],
-		stats["total_bookings"],
-		stats["active_bookings"],
-		stats["completed_bookings"],
-		stats["active_services"],
-	)
-
-	markup := &tele.ReplyMarkup{}
-	btnBack := markup.Data("⬅️ Назад", "admin", "main")
-	markup.Inline(markup.Row(btnBack))
-
-	return c.Edit(msg, &tele.SendOptions{
-		ParseMode:   tele.ModeHTML,
-		ReplyMarkup: markup,
-	})
-}
@@ -0,0 +1,419 @@
+// Package bot contains handlers for bookings created by admin
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gobot/internal/callback"
+	"gobot/internal/database"
+	"gobot/internal/i18n"
+	"gobot/internal/services"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	// claimStartPrefix is the deep link prefix for offline client invites
+	claimStartPrefix = "claim_"
+	// newBookingDays is how many days ahead admin can book
+	newBookingDays = 14
+	// clientSearchLimit is the maximum number of users shown in search results
+	clientSearchLimit = 10
+)
+
+// handleAdminNewBookingStart starts creating a booking on behalf of a client
+func (b *Bot) handleAdminNewBookingStart(ctx context.Context, c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = "new_booking"
+	state.TempServiceData = make(map[string]interface{})
+
+	services, err := b.bookingService.GetAvailableServices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load services: %w", err)
+	}
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+	for _, service := range services {
+		rows = append(rows, markup.Row(callback.Button(
+			fmt.Sprintf("%s — %d руб.", service.Name, service.Price/100),
+			"admin_nb_service",
+			fmt.Sprintf("%d", service.ID),
+		)))
+	}
+	rows = append(rows, markup.Row(callback.Button("❌ Отмена", "admin_nb_cancel")))
+	markup.Inline(rows...)
+
+	return c.Edit("➕ <b>Новая запись</b>\n\nШаг 1/4: Выберите услугу:", &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleAdminNewBookingService saves the service and asks for a date
+func (b *Bot) handleAdminNewBookingService(ctx context.Context, c tele.Context, serviceID uint) error {
+	state := b.getUserState(c.Sender().ID)
+	if state.EditMode != "new_booking" || state.TempServiceData == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+	}
+
+	state.TempServiceData["service_id"] = serviceID
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+	for i := 0; i < newBookingDays; i += 2 {
+		row := tele.Row{}
+		for j := i; j < i+2 && j < newBookingDays; j++ {
+			date := getNextAvailableDate(j)
+			row = append(row, callback.Button(
+				fmt.Sprintf("%s (%s)", date.Format("02.01"), i18n.Weekday(i18n.Default, date)),
+				"admin_nb_date",
+				date.Format("2006-01-02"),
+			))
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, markup.Row(
+		callback.Button("⬅️ Назад", "admin", "new_booking"),
+		callback.Button("❌ Отмена", "admin_nb_cancel"),
+	))
+	markup.Inline(rows...)
+
+	return c.Edit("Шаг 2/4: Выберите дату:", markup)
+}
+
+// handleAdminNewBookingDate saves the date and shows free time slots
+func (b *Bot) handleAdminNewBookingDate(ctx context.Context, c tele.Context, dateStr string) error {
+	state := b.getUserState(c.Sender().ID)
+	if state.EditMode != "new_booking" || state.TempServiceData == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Ошибка"})
+	}
+	state.TempServiceData["date"] = date
+
+	serviceID, _ := state.TempServiceData["service_id"].(uint)
+	service, err := b.adminService.GetServiceByID(ctx, serviceID)
+	if err != nil {
+		return c.Edit("Услуга не найдена")
+	}
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+	slots := services.AvailableTimeSlots(ctx, date, service.Duration, 0)
+	for i := 0; i < len(slots); i += 3 {
+		row := tele.Row{}
+		for j := i; j < i+3 && j < len(slots); j++ {
+			row = append(row, callback.Button(slots[j], "admin_nb_time", slots[j]))
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, markup.Row(
+		callback.Button("⬅️ Назад", "admin_nb_service", fmt.Sprintf("%d", serviceID)),
+		callback.Button("❌ Отмена", "admin_nb_cancel"),
+	))
+	markup.Inline(rows...)
+
+	msg := fmt.Sprintf("Шаг 3/4: Выберите время на %s:", date.Format("02.01.2006"))
+	if len(slots) == 0 {
+		msg = fmt.Sprintf("❌ На %s нет свободного времени, выберите другую дату", date.Format("02.01.2006"))
+	}
+
+	return c.Edit(msg, markup)
+}
+
+// handleAdminNewBookingTime saves the time and asks who the client is
+func (b *Bot) handleAdminNewBookingTime(ctx context.Context, c tele.Context, timeSlot string) error {
+	state := b.getUserState(c.Sender().ID)
+	if state.EditMode != "new_booking" || state.TempServiceData == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+	}
+	state.TempServiceData["time"] = timeSlot
+
+	return c.Edit("Шаг 4/4: Кто клиент?", getNewBookingClientKeyboard())
+}
+
+// getNewBookingClientKeyboard returns keyboard to choose how to find the client
+func getNewBookingClientKeyboard() *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+
+	btnSearch := callback.Button("🔎 Найти клиента", "admin_nb_client", "search")
+	btnNew := callback.Button("➕ Новый клиент без Telegram", "admin_nb_client", "new")
+	btnCancel := callback.Button("❌ Отмена", "admin_nb_cancel")
+
+	markup.Inline(
+		markup.Row(btnSearch),
+		markup.Row(btnNew),
+		markup.Row(btnCancel),
+	)
+
+	return markup
+}
+
+// handleAdminNewBookingClient asks for client search query or new client name
+func (b *Bot) handleAdminNewBookingClient(ctx context.Context, c tele.Context, mode string) error {
+	state := b.getUserState(c.Sender().ID)
+	if state.TempServiceData == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+	}
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(callback.Button("❌ Отмена", "admin_nb_cancel")))
+
+	switch mode {
+	case "search":
+		state.EditMode = "nb_client_search"
+		return c.Edit("🔎 Введите имя, @username, телефон или Telegram ID клиента:", markup)
+	case "new":
+		state.EditMode = "nb_client_name"
+		return c.Edit("👤 Введите имя клиента:", markup)
+	default:
+		return b.staleAction(c, "admin_nb_client", mode)
+	}
+}
+
+// handleAdminNewBookingMessage handles text steps of client selection
+func (b *Bot) handleAdminNewBookingMessage(c tele.Context) error {
+	ctx := b.requestContext(c)
+	state := b.getUserState(c.Sender().ID)
+	text := strings.TrimSpace(c.Text())
+
+	switch state.EditMode {
+	case "nb_client_search":
+		users, err := b.userService.SearchUsers(ctx, text, clientSearchLimit)
+		if err != nil {
+			return fmt.Errorf("failed to search clients: %w", err)
+		}
+
+		markup := &tele.ReplyMarkup{}
+		rows := make([]tele.Row, 0)
+		for _, user := range users {
+			rows = append(rows, markup.Row(callback.Button(
+				formatClientButton(&user),
+				"admin_nb_user",
+				fmt.Sprintf("%d", user.ID),
+			)))
+		}
+		rows = append(rows, markup.Row(callback.Button("➕ Новый клиент без Telegram", "admin_nb_client", "new")))
+		rows = append(rows, markup.Row(callback.Button("❌ Отмена", "admin_nb_cancel")))
+		markup.Inline(rows...)
+
+		msg := fmt.Sprintf("Найдено клиентов: %d\nВыберите клиента или введите другой запрос:", len(users))
+		if len(users) == 0 {
+			msg = "Никого не нашли. Введите другой запрос или создайте нового клиента:"
+		}
+		return c.Send(msg, markup)
+
+	case "nb_client_name":
+		if text == "" {
+			return c.Send("❌ Имя не может быть пустым")
+		}
+		state.TempServiceData["client_name"] = text
+		state.EditMode = "nb_client_phone"
+		return c.Send("📞 Введите телефон клиента (например: +7 900 123-45-67):")
+
+	case "nb_client_phone":
+		digits := strings.TrimPrefix(services.NormalizePhone(text), "+")
+		if len(digits) < 10 || len(digits) > 15 {
+			return c.Send("❌ Неверный номер. Введите телефон в формате +7 900 123-45-67")
+		}
+
+		name, _ := state.TempServiceData["client_name"].(string)
+		user, err := b.userService.CreateOfflineClient(ctx, name, text)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to create offline client", "error", err)
+			return c.Send("❌ Не удалось создать клиента")
+		}
+		state.TempServiceData["user_id"] = user.ID
+		state.EditMode = "new_booking"
+
+		return b.showNewBookingSummary(ctx, c)
+	}
+
+	return nil
+}
+
+// handleAdminNewBookingUser saves the chosen existing client
+func (b *Bot) handleAdminNewBookingUser(ctx context.Context, c tele.Context, userID int64) error {
+	state := b.getUserState(c.Sender().ID)
+	if state.TempServiceData == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+	}
+
+	state.TempServiceData["user_id"] = userID
+	state.EditMode = "new_booking"
+
+	return b.showNewBookingSummary(ctx, c)
+}
+
+// showNewBookingSummary shows booking details before creating it
+func (b *Bot) showNewBookingSummary(ctx context.Context, c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+
+	serviceID, _ := state.TempServiceData["service_id"].(uint)
+	date, _ := state.TempServiceData["date"].(time.Time)
+	timeSlot, _ := state.TempServiceData["time"].(string)
+	userID, _ := state.TempServiceData["user_id"].(int64)
+
+	service, price, err := services.NewDiscountService().GetServiceWithDiscount(ctx, serviceID)
+	if err != nil {
+		return c.EditOrSend("Услуга не найдена")
+	}
+
+	var user database.User
+	if err := database.DB.WithContext(ctx).First(&user, userID).Error; err != nil {
+		return c.EditOrSend("Клиент не найден")
+	}
+
+	msg := fmt.Sprintf(
+		"📝 <b>Проверьте запись</b>\n\n"+
+			"👤 %s\n"+
+			"📋 %s\n"+
+			"📆 %s (%s) в %s\n"+
+			"💰 %d руб.\n",
+		formatClientButton(&user),
+		service.Name,
+		date.Format("02.01.2006"),
+		i18n.Weekday(i18n.Default, date),
+		timeSlot,
+		price/100,
+	)
+
+	if user.IsOffline {
+		msg += "\n📵 Клиент без Telegram — уведомления не отправляются, вы получите ссылку-приглашение."
+	} else {
+		msg += "\n🔔 Клиент получит подтверждение и напоминания в боте."
+	}
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(
+		markup.Row(callback.Button("✅ Создать запись", "admin_nb_confirm")),
+		markup.Row(callback.Button("❌ Отмена", "admin_nb_cancel")),
+	)
+
+	return c.EditOrSend(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleAdminNewBookingConfirm creates the confirmed booking
+func (b *Bot) handleAdminNewBookingConfirm(ctx context.Context, c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+	if state.EditMode != "new_booking" || state.TempServiceData == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+	}
+
+	serviceID, _ := state.TempServiceData["service_id"].(uint)
+	date, _ := state.TempServiceData["date"].(time.Time)
+	timeSlot, _ := state.TempServiceData["time"].(string)
+	userID, okUser := state.TempServiceData["user_id"].(int64)
+	if serviceID == 0 || timeSlot == "" || !okUser {
+		return c.Respond(&tele.CallbackResponse{Text: "Сессия истекла, начните заново"})
+	}
+
+	service, err := b.adminService.GetServiceByID(ctx, serviceID)
+	if err != nil {
+		return c.Edit("Услуга не найдена")
+	}
+
+	// The slot might have been taken by a client while admin was filling the form
+	if services.IsTimeSlotTaken(ctx, timeSlot, date, service.Duration, 0) {
+		c.Respond(&tele.CallbackResponse{Text: "❌ Это время уже занято"})
+		return b.handleAdminNewBookingDate(ctx, c, date.Format("2006-01-02"))
+	}
+
+	booking, err := b.bookingService.CreateBooking(ctx, userID, serviceID, date, timeSlot)
+	if err != nil {
+		return fmt.Errorf("failed to create booking: %w", err)
+	}
+
+	// Admin agreed the time with the client already
+	if err := b.adminService.UpdateBookingStatus(ctx, booking.ID, database.BookingStatusConfirmed, services.ByAdmin(c.Sender().ID)); err != nil {
+		slog.ErrorContext(ctx, "Failed to confirm booking", "booking_id", booking.ID, "error", err)
+	}
+	booking.Status = database.BookingStatusConfirmed
+
+	state.EditMode = ""
+	state.TempServiceData = nil
+
+	msg := fmt.Sprintf("✅ <b>Запись #%d создана</b>\n\n", booking.ID)
+	if booking.User.IsOffline {
+		msg += fmt.Sprintf(
+			"Отправьте клиенту ссылку (например, по SMS), чтобы он увидел запись в боте и получал напоминания:\n\n%s",
+			b.inviteDeepLink(&booking.User),
+		)
+	} else {
+		if err := b.notificationService.SendBookingConfirmation(ctx, booking); err != nil {
+			slog.ErrorContext(ctx, "Failed to send booking confirmation", "booking_id", booking.ID, "error", err)
+		}
+		msg += "Клиент получил подтверждение в боте."
+	}
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(
+		markup.Row(callback.Button("📋 Открыть запись", "admin_booking", fmt.Sprintf("%d", booking.ID))),
+		markup.Row(callback.Button("⬅️ В админ-панель", "admin", "main")),
+	)
+
+	return c.Edit(msg, &tele.SendOptions{
+		ParseMode:             tele.ModeHTML,
+		ReplyMarkup:           markup,
+		DisableWebPagePreview: true,
+	})
+}
+
+// handleAdminNewBookingCancel cancels the new booking flow
+func (b *Bot) handleAdminNewBookingCancel(ctx context.Context, c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = ""
+	state.TempServiceData = nil
+
+	return c.Edit("❌ Создание записи отменено", getAdminKeyboard(b.isOwner(c.Sender().ID)))
+}
+
+// handleClaimInvite links offline bookings to the Telegram user who opened the invite
+func (b *Bot) handleClaimInvite(ctx context.Context, c tele.Context, token string) error {
+	lang := b.lang(c)
+	moved, err := b.userService.ClaimOfflineClient(ctx, token, c.Sender().ID)
+	if err != nil {
+		if errors.Is(err, services.ErrInviteNotFound) {
+			return c.Send(i18n.T(lang, "invite.invalid"), b.mainMenu(c))
+		}
+		slog.WarnContext(ctx, "Failed to claim invite", "error", err)
+		return c.Send(i18n.T(lang, "error.generic"))
+	}
+
+	msg := i18n.T(lang, "invite.claimed", c.Sender().FirstName, i18n.N(lang, "bookings_found", moved))
+
+	return c.Send(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: b.mainMenu(c),
+	})
+}
+
+// inviteDeepLink returns the link an offline client opens to claim bookings
+func (b *Bot) inviteDeepLink(user *database.User) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s%s", b.tg.Me.Username, claimStartPrefix, user.InviteToken)
+}
+
+// formatClientButton returns short client description for lists
+func formatClientButton(user *database.User) string {
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	switch {
+	case user.IsOffline:
+		return fmt.Sprintf("📞 %s %s", name, user.Phone)
+	case user.Username != "":
+		return fmt.Sprintf("👤 %s (@%s)", name, user.Username)
+	default:
+		return fmt.Sprintf("👤 %s (%d)", name, user.ID)
+	}
+}
@@ -0,0 +1,213 @@
+// Package bot contains admin report handlers
+package bot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gobot/internal/callback"
+	"gobot/internal/i18n"
+	"gobot/internal/services"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// reportTopServices is how many services the report lists
+const reportTopServices = 5
+
+// reportPeriodTitles names report periods
+var reportPeriodTitles = map[string]string{
+	services.ReportPeriodDay:    "Сегодня",
+	services.ReportPeriodWeek:   "Неделя",
+	services.ReportPeriodMonth:  "Месяц",
+	services.ReportPeriodCustom: "Период",
+}
+
+// handleAdminReport handles report period buttons
+// data format: "day", "week", "month" or "custom" to enter dates
+func (b *Bot) handleAdminReport(ctx context.Context, c tele.Context, data string) error {
+	if data == services.ReportPeriodCustom {
+		b.getUserState(c.Sender().ID).EditMode = "report_period"
+
+		markup := &tele.ReplyMarkup{}
+		markup.Inline(markup.Row(callback.Button("⬅️ Назад", "admin_report", services.ReportPeriodMonth)))
+
+		return c.Edit("📅 Введите период как ДД.ММ.ГГГГ-ДД.ММ.ГГГГ или одну дату ДД.ММ.ГГГГ", markup)
+	}
+
+	return b.showAdminReport(ctx, c, services.NewReportPeriod(data, time.Now()))
+}
+
+// handleAdminReportPeriodInput handles typed custom report period
+func (b *Bot) handleAdminReportPeriodInput(c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+
+	from, to, err := parseDateRange(strings.TrimSpace(c.Text()))
+	if err != nil {
+		return c.Send("❌ Неверный формат. Введите дату как ДД.ММ.ГГГГ или период ДД.ММ.ГГГГ-ДД.ММ.ГГГГ")
+	}
+
+	state.EditMode = ""
+	return b.showAdminReport(context.Background(), c, services.ReportPeriod{
+		Kind: services.ReportPeriodCustom,
+		From: from,
+		To:   to,
+	})
+}
+
+// showAdminReport shows the report for the period compared with the previous one
+func (b *Bot) showAdminReport(ctx context.Context, c tele.Context, period services.ReportPeriod) error {
+	report, err := b.reportService.Build(ctx, period)
+	if err != nil {
+		return fmt.Errorf("failed to build report: %w", err)
+	}
+	previous, err := b.reportService.Build(ctx, period.Previous())
+	if err != nil {
+		return fmt.Errorf("failed to build previous report: %w", err)
+	}
+
+	msg := formatReport(report, previous)
+
+	if outboxStats, err := b.outboxService.GetStats(ctx); err == nil {
+		msg += fmt.Sprintf(
+			"\n📤 <b>Доставка сообщений</b>\n"+
+				"✅ Доставлено: <b>%d</b>\n"+
+				"⏳ В очереди: <b>%d</b>\n"+
+				"🚫 Заблокировали бота: <b>%d</b>\n"+
+				"❌ Не доставлено: <b>%d</b>\n",
+			outboxStats.Sent,
+			outboxStats.Pending,
+			outboxStats.Blocked,
+			outboxStats.Failed,
+		)
+	}
+
+	return c.EditOrSend(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: getAdminReportKeyboard(period.Kind),
+	})
+}
+
+// formatReport returns the report text
+func formatReport(report, previous *services.Report) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("📊 <b>Статистика: %s</b>\n%s\n<i>Сравнение с %s</i>\n\n",
+		reportPeriodTitles[report.Period.Kind],
+		formatReportRange(report.Period),
+		formatReportRange(previous.Period),
+	))
+
+	sb.WriteString(fmt.Sprintf("💰 Выручка: <b>%s</b>%s\n",
+		i18n.Price(i18n.Default, report.Revenue),
+		formatChange(float64(report.Revenue), float64(previous.Revenue)),
+	))
+	sb.WriteString(fmt.Sprintf("🧾 Средний чек: <b>%s</b>%s\n",
+		i18n.Price(i18n.Default, report.AverageCheck),
+		formatChange(float64(report.AverageCheck), float64(previous.AverageCheck)),
+	))
+	sb.WriteString(fmt.Sprintf("📋 Записей: <b>%d</b>%s\n",
+		report.Bookings,
+		formatChange(float64(report.Bookings), float64(previous.Bookings)),
+	))
+	sb.WriteString(fmt.Sprintf("   ✔️ Завершено: %d · ⏳ Предстоит: %d\n", report.Completed, report.Upcoming))
+	sb.WriteString(fmt.Sprintf("❌ Отмены: %d (%.1f%%)%s\n",
+		report.Cancelled,
+		report.CancellationRate,
+		formatPointsChange(report.CancellationRate, previous.CancellationRate),
+	))
+	sb.WriteString(fmt.Sprintf("🚷 Неявки: %d (%.1f%%)%s\n",
+		report.NoShow,
+		report.NoShowRate,
+		formatPointsChange(report.NoShowRate, previous.NoShowRate),
+	))
+	sb.WriteString(fmt.Sprintf("📈 Загрузка: <b>%.0f%%</b> (%d из %d ч)%s\n",
+		report.Utilization,
+		report.BookedMinutes/60,
+		report.WorkingMinutes/60,
+		formatPointsChange(report.Utilization, previous.Utilization),
+	))
+	sb.WriteString(fmt.Sprintf("👥 Клиенты: %d новых, %d постоянных\n",
+		report.NewClients,
+		report.ReturningClients,
+	))
+
+	if len(report.Services) > 0 {
+		sb.WriteString("\n🏆 <b>Популярные услуги</b>\n")
+		for i, service := range report.Services {
+			if i == reportTopServices {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("%d. %s — %d зап., %s\n",
+				i+1,
+				service.Name,
+				service.Bookings,
+				i18n.Price(i18n.Default, service.Revenue),
+			))
+		}
+	}
+
+	return sb.String()
+}
+
+// formatReportRange formats period bounds, To is exclusive
+func formatReportRange(period services.ReportPeriod) string {
+	last := period.To.AddDate(0, 0, -1)
+	if last.Equal(period.From) {
+		return period.From.Format("02.01.2006")
+	}
+	return period.From.Format("02.01.2006") + " – " + last.Format("02.01.2006")
+}
+
+// formatChange returns relative change to the previous value
+func formatChange(current, previous float64) string {
+	if previous == 0 {
+		return ""
+	}
+	change := (current - previous) * 100 / previous
+	switch {
+	case math.Abs(change) < 0.5:
+		return " (=)"
+	case change > 0:
+		return fmt.Sprintf(" (▲ %.0f%%)", change)
+	default:
+		return fmt.Sprintf(" (▼ %.0f%%)", -change)
+	}
+}
+
+// formatPointsChange returns change of a percentage in percentage points
+func formatPointsChange(current, previous float64) string {
+	change := current - previous
+	if math.Abs(change) < 0.05 {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.1f п.п.)", change)
+}
+
+// getAdminReportKeyboard returns report period buttons
+func getAdminReportKeyboard(selected string) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+
+	button := func(kind string) tele.Btn {
+		title := reportPeriodTitles[kind]
+		if kind == selected {
+			title = "• " + title
+		}
+		return callback.Button(title, "admin_report", kind)
+	}
+
+	markup.Inline(
+		markup.Row(
+			button(services.ReportPeriodDay),
+			button(services.ReportPeriodWeek),
+			button(services.ReportPeriodMonth),
+		),
+		markup.Row(button(services.ReportPeriodCustom)),
+		markup.Row(callback.Button("⬅️ Назад", "admin", "main")),
+	)
+
+	return markup
+}
@@ -0,0 +1,221 @@
+// Package bot contains review moderation handlers
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+
+	"gobot/internal/callback"
+	"gobot/internal/database"
+	"gobot/internal/services"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// reviewsPageSize is the number of reviews shown per page
+const reviewsPageSize = 10
+
+// handleAdminReviews shows a page of reviews for moderation
+func (b *Bot) handleAdminReviews(ctx context.Context, c tele.Context, offset int) error {
+	reviews, total, err := b.reviewService.ListReviews(ctx, "", reviewsPageSize, offset)
+	if err != nil {
+		return fmt.Errorf("failed to load reviews: %w", err)
+	}
+
+	msg := "⭐ <b>Отзывы клиентов</b>\n\n"
+	if total == 0 {
+		msg += "Отзывов пока нет"
+	} else {
+		msg += fmt.Sprintf("Показаны %d–%d из %d\n\n", offset+1, offset+len(reviews), total)
+		for _, review := range reviews {
+			// Truncated before escaping, so an entity is never cut in half
+			comment := review.Comment
+			if len([]rune(comment)) > 60 {
+				comment = string([]rune(comment)[:60]) + "…"
+			}
+			comment = html.EscapeString(comment)
+			msg += fmt.Sprintf(
+				"%s %s <b>%s</b>\n   👤 %s · %s\n",
+				getReviewStatusEmoji(review.Status),
+				services.FormatStars(review.Rating),
+				html.EscapeString(review.Service.Name),
+				html.EscapeString(review.User.FirstName),
+				review.CreatedAt.Format("02.01.2006"),
+			)
+			if comment != "" {
+				msg += fmt.Sprintf("   💬 %s\n", comment)
+			}
+			msg += "\n"
+		}
+	}
+
+	return c.Edit(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: getReviewsKeyboard(reviews, offset, total),
+	})
+}
+
+// getReviewsKeyboard returns keyboard with reviews and pagination
+func getReviewsKeyboard(reviews []database.Review, offset int, total int64) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+
+	for _, review := range reviews {
+		btn := callback.Button(
+			fmt.Sprintf("%s %d⭐ %s — %s", getReviewStatusEmoji(review.Status), review.Rating, review.User.FirstName, review.Service.Name),
+			"admin_view_review",
+			fmt.Sprintf("%d", review.ID),
+		)
+		rows = append(rows, markup.Row(btn))
+	}
+
+	nav := tele.Row{}
+	if offset > 0 {
+		prev := offset - reviewsPageSize
+		if prev < 0 {
+			prev = 0
+		}
+		nav = append(nav, callback.Button("◀️", "admin_reviews", fmt.Sprintf("%d", prev)))
+	}
+	if int64(offset+reviewsPageSize) < total {
+		nav = append(nav, callback.Button("▶️", "admin_reviews", fmt.Sprintf("%d", offset+reviewsPageSize)))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	btnBack := callback.Button("⬅️ Назад", "admin", "main")
+	rows = append(rows, markup.Row(btnBack))
+
+	markup.Inline(rows...)
+	return markup
+}
+
+// handleAdminViewReview shows a single review with moderation actions
+func (b *Bot) handleAdminViewReview(ctx context.Context, c tele.Context, reviewID uint) error {
+	review, err := b.reviewService.GetReviewByID(ctx, reviewID)
+	if err != nil {
+		return c.Edit("Отзыв не найден")
+	}
+
+	msg := fmt.Sprintf(
+		"⭐ <b>Отзыв #%d</b>\n\n"+
+			"%s\n"+
+			"👤 %s %s (@%s)\n"+
+			"📋 %s\n"+
+			"📆 Визит: %s в %s\n"+
+			"🕐 Оставлен: %s\n"+
+			"Статус: %s %s\n",
+		review.ID,
+		services.FormatStars(review.Rating),
+		html.EscapeString(review.User.FirstName),
+		html.EscapeString(review.User.LastName),
+		html.EscapeString(review.User.Username),
+		html.EscapeString(review.Service.Name),
+		review.Booking.Date.Format("02.01.2006"),
+		review.Booking.Time,
+		review.CreatedAt.Format("02.01.2006 15:04"),
+		getReviewStatusEmoji(review.Status),
+		getReviewStatusText(review.Status),
+	)
+
+	if review.Comment != "" {
+		msg += fmt.Sprintf("\n💬 %s\n", html.EscapeString(review.Comment))
+	}
+
+	if review.PublishedAt != nil {
+		msg += fmt.Sprintf("\n📢 Опубликован в канале %s", review.PublishedAt.Format("02.01.2006"))
+	}
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+
+	id := fmt.Sprintf("%d", review.ID)
+	if review.Status != database.ReviewStatusApproved {
+		rows = append(rows, markup.Row(callback.Button("✅ Одобрить", "admin_moderate_review", id, string(database.ReviewStatusApproved))))
+	}
+	if review.Status != database.ReviewStatusHidden {
+		rows = append(rows, markup.Row(callback.Button("🙈 Скрыть", "admin_moderate_review", id, string(database.ReviewStatusHidden))))
+	}
+	if b.config.ChannelID != "" && review.PublishedAt == nil && review.Status != database.ReviewStatusHidden {
+		rows = append(rows, markup.Row(callback.Button("📢 Опубликовать в канал", "admin_publish_review", id)))
+	}
+	rows = append(rows, markup.Row(callback.Button("⬅️ К отзывам", "admin_reviews", "0")))
+
+	markup.Inline(rows...)
+
+	return c.Edit(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleAdminModerateReview changes review moderation status
+// args: review ID and the new status
+func (b *Bot) handleAdminModerateReview(ctx context.Context, c tele.Context, data callback.Data) error {
+	reviewID, err := data.Uint(0)
+	if err != nil {
+		return invalidArg(err)
+	}
+
+	reviewStatus := database.ReviewStatus(data.Arg(1))
+	if reviewStatus != database.ReviewStatusApproved && reviewStatus != database.ReviewStatusHidden {
+		return b.staleAction(c, data.Route, data.Arg(1))
+	}
+
+	if err := b.reviewService.SetReviewStatus(ctx, reviewID, reviewStatus); err != nil {
+		return fmt.Errorf("failed to set review status: %w", err)
+	}
+
+	return b.handleAdminViewReview(ctx, c, reviewID)
+}
+
+// handleAdminPublishReview posts a review to the configured channel
+func (b *Bot) handleAdminPublishReview(ctx context.Context, c tele.Context, reviewID uint) error {
+	review, err := b.reviewService.GetReviewByID(ctx, reviewID)
+	if err != nil {
+		return c.Edit("Отзыв не найден")
+	}
+
+	if err := b.notificationService.SendReviewToChannel(ctx, review); err != nil {
+		slog.ErrorContext(ctx, "Failed to publish review", "review_id", review.ID, "error", err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Не удалось опубликовать"})
+	}
+
+	if err := b.reviewService.MarkPublished(ctx, review.ID); err != nil {
+		slog.ErrorContext(ctx, "Failed to mark review as published", "review_id", review.ID, "error", err)
+	}
+
+	c.Respond(&tele.CallbackResponse{Text: "📢 Отзыв опубликован"})
+	return b.handleAdminViewReview(ctx, c, reviewID)
+}
+
+// getReviewStatusEmoji returns emoji for review status
+func getReviewStatusEmoji(status database.ReviewStatus) string {
+	switch status {
+	case database.ReviewStatusNew:
+		return "🆕"
+	case database.ReviewStatusApproved:
+		return "✅"
+	case database.ReviewStatusHidden:
+		return "🙈"
+	default:
+		return "❓"
+	}
+}
+
+// getReviewStatusText returns text for review status
+func getReviewStatusText(status database.ReviewStatus) string {
+	switch status {
+	case database.ReviewStatusNew:
+		return "Новый"
+	case database.ReviewStatusApproved:
+		return "Одобрен"
+	case database.ReviewStatusHidden:
+		return "Скрыт"
+	default:
+		return "Неизвестно"
+	}
+}
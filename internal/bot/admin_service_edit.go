@@ -6,17 +6,14 @@ import (
 	"fmt"
 	"strconv"
 
+	"gobot/internal/callback"
+
 	tele "gopkg.in/telebot.v3"
 )
 
 // handleAdminEditServiceMenu shows editing menu for a service
-func (b *Bot) handleAdminEditServiceMenu(ctx context.Context, c tele.Context, serviceIDStr string) error {
-	serviceID, err := strconv.ParseUint(serviceIDStr, 10, 32)
-	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка"})
-	}
-
-	service, err := b.adminService.GetServiceByID(ctx, uint(serviceID))
+func (b *Bot) handleAdminEditServiceMenu(ctx context.Context, c tele.Context, serviceID uint) error {
+	service, err := b.adminService.GetServiceByID(ctx, serviceID)
 	if err != nil {
 		return c.Edit("Услуга не найдена")
 	}
@@ -57,15 +54,16 @@ func (b *Bot) handleAdminEditServiceMenu(ctx context.Context, c tele.Context, se
 // getServiceEditMenuKeyboard returns keyboard for service editing menu
 func getServiceEditMenuKeyboard(serviceID uint) *tele.ReplyMarkup {
 	markup := &tele.ReplyMarkup{}
+	id := fmt.Sprintf("%d", serviceID)
 
-	btnName := markup.Data("📝 Изменить название", "admin_edit_field", fmt.Sprintf("name:%d", serviceID))
-	btnPrice := markup.Data("💰 Изменить цену", "admin_edit_field", fmt.Sprintf("price:%d", serviceID))
-	btnDuration := markup.Data("⏱ Изменить длительность", "admin_edit_field", fmt.Sprintf("duration:%d", serviceID))
-	btnDesc := markup.Data("📝 Изменить описание", "admin_edit_field", fmt.Sprintf("description:%d", serviceID))
-	btnDetailedDesc := markup.Data("📖 Изменить подробное описание", "admin_edit_field", fmt.Sprintf("detailed_description:%d", serviceID))
-	btnToggle := markup.Data("🔄 Вкл/Выкл", "admin_toggle_service", fmt.Sprintf("%d", serviceID))
-	btnDelete := markup.Data("🗑 Удалить", "admin_delete_service", fmt.Sprintf("%d", serviceID))
-	btnBack := markup.Data("⬅️ Назад", "admin", "services")
+	btnName := callback.Button("📝 Изменить название", "admin_edit_field", id, "name")
+	btnPrice := callback.Button("💰 Изменить цену", "admin_edit_field", id, "price")
+	btnDuration := callback.Button("⏱ Изменить длительность", "admin_edit_field", id, "duration")
+	btnDesc := callback.Button("📝 Изменить описание", "admin_edit_field", id, "description")
+	btnDetailedDesc := callback.Button("📖 Изменить подробное описание", "admin_edit_field", id, "detailed_description")
+	btnToggle := callback.Button("🔄 Вкл/Выкл", "admin_toggle_service", id)
+	btnDelete := callback.Button("🗑 Удалить", "admin_delete_service", id)
+	btnBack := callback.Button("⬅️ Назад", "admin", "services")
 
 	markup.Inline(
 		markup.Row(btnName),
@@ -80,25 +78,19 @@ func getServiceEditMenuKeyboard(serviceID uint) *tele.ReplyMarkup {
 }
 
 // handleAdminEditField starts editing a specific field
-func (b *Bot) handleAdminEditField(ctx context.Context, c tele.Context, data string) error {
-	// Parse data: "field:serviceID"
-	var field string
-	var serviceID uint64
-	_, err := fmt.Sscanf(data, "%[^:]:%d", &field, &serviceID)
+// args: service ID and the field
+func (b *Bot) handleAdminEditField(ctx context.Context, c tele.Context, data callback.Data) error {
+	serviceID, err := data.Uint(0)
 	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка"})
+		return invalidArg(err)
 	}
+	field := data.Arg(1)
 
-	service, err := b.adminService.GetServiceByID(ctx, uint(serviceID))
+	service, err := b.adminService.GetServiceByID(ctx, serviceID)
 	if err != nil {
-		return c.Edit("Услуга не найдена")
+		return fmt.Errorf("failed to get service: %w", err)
 	}
 
-	// Set edit mode in user state
-	state := b.getUserState(c.Sender().ID)
-	state.EditMode = field
-	state.EditServiceID = uint(serviceID)
-
 	var msg string
 	switch field {
 	case "name":
@@ -141,11 +133,16 @@ func (b *Bot) handleAdminEditField(ctx context.Context, c tele.Context, data str
 			currentDesc,
 		)
 	default:
-		return c.Respond(&tele.CallbackResponse{Text: "Неизвестное поле"})
+		return b.staleAction(c, data.Route, field)
 	}
 
+	// Set edit mode in user state
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = field
+	state.EditServiceID = service.ID
+
 	markup := &tele.ReplyMarkup{}
-	btnCancel := markup.Data("❌ Отмена", "admin_cancel_edit", "")
+	btnCancel := callback.Button("❌ Отмена", "admin_cancel_edit")
 	markup.Inline(markup.Row(btnCancel))
 
 	return c.Edit(msg, &tele.SendOptions{
@@ -161,7 +158,7 @@ func (b *Bot) handleAdminCancelEdit(ctx context.Context, c tele.Context) error {
 	state.EditMode = ""
 	state.EditServiceID = 0
 
-	return b.handleAdminEditServiceMenu(ctx, c, fmt.Sprintf("%d", serviceID))
+	return b.handleAdminEditServiceMenu(ctx, c, serviceID)
 }
 
 // handleAdminTextMessage handles text messages during editing
@@ -175,7 +172,7 @@ func (b *Bot) handleAdminTextMessage(c tele.Context) error {
 		return nil // Not in edit mode
 	}
 
-	ctx := context.Background()
+	ctx := b.requestContext(c)
 	serviceID := state.EditServiceID
 	text := c.Text()
 
@@ -240,7 +237,7 @@ func (b *Bot) handleAdminAddServiceStart(ctx context.Context, c tele.Context) er
 	state.TempServiceData = make(map[string]interface{})
 
 	markup := &tele.ReplyMarkup{}
-	btnCancel := markup.Data("❌ Отмена", "admin_cancel_add_service", "")
+	btnCancel := callback.Button("❌ Отмена", "admin_cancel_add_service")
 	markup.Inline(markup.Row(btnCancel))
 
 	msg := "➕ <b>Добавление новой услуги</b>\n\n" +
@@ -264,7 +261,7 @@ func (b *Bot) handleAdminAddServiceMessage(c tele.Context) error {
 	}
 
 	text := c.Text()
-	ctx := context.Background()
+	ctx := b.requestContext(c)
 
 	switch state.EditMode {
 	case "add_service_name":
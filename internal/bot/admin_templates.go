@@ -0,0 +1,261 @@
+// Package bot contains notification template editor handlers
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+
+	"gobot/internal/callback"
+	"gobot/internal/i18n"
+	"gobot/internal/services"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleAdminTemplates shows the list of editable notification templates
+func (b *Bot) handleAdminTemplates(ctx context.Context, c tele.Context) error {
+	b.clearTemplateEdit(c.Sender().ID)
+
+	custom, err := b.templateService.ListCustom(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list templates: %w", err)
+	}
+	customized := make(map[string]bool)
+	for _, tmpl := range custom {
+		customized[tmpl.Key] = true
+	}
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+	for _, info := range services.Templates {
+		label := "📝 " + info.Title
+		if customized[info.Key] {
+			label = "✏️ " + info.Title
+		}
+		rows = append(rows, markup.Row(callback.Button(label, "admin_template", info.Key, i18n.Default)))
+	}
+	rows = append(rows, markup.Row(callback.Button("⬅️ Назад", "admin", "main")))
+	markup.Inline(rows...)
+
+	msg := "📝 <b>Шаблоны уведомлений</b>\n\n" +
+		"Тексты сообщений, которые бот отправляет клиентам, админам и в канал.\n" +
+		"✏️ — шаблон изменен, 📝 — используется стандартный текст."
+
+	return c.Edit(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleAdminTemplateCard shows a template with its source and actions
+// args: template key and language
+func (b *Bot) handleAdminTemplateCard(ctx context.Context, c tele.Context, data callback.Data) error {
+	return b.showAdminTemplate(ctx, c, data.Arg(0), data.Arg(1))
+}
+
+// showAdminTemplate renders the template card
+func (b *Bot) showAdminTemplate(ctx context.Context, c tele.Context, key, lang string) error {
+	info, err := services.GetTemplateInfo(key)
+	if err != nil {
+		return invalidArg(err)
+	}
+	if !i18n.IsSupported(lang) {
+		lang = i18n.Default
+	}
+
+	custom, err := b.templateService.GetCustom(ctx, key, lang)
+	if err != nil {
+		return fmt.Errorf("failed to load template: %w", err)
+	}
+
+	source := services.DefaultTemplate(key, lang)
+	status := "стандартный"
+	if custom != nil {
+		source = custom.Body
+		status = "изменен " + custom.UpdatedAt.Format("02.01.2006 15:04")
+	}
+
+	fields := make([]string, 0, len(info.Fields))
+	for _, field := range info.Fields {
+		fields = append(fields, "<code>{{."+field+"}}</code>")
+	}
+
+	msg := fmt.Sprintf(
+		"📝 <b>%s</b>\n\n"+
+			"🌐 Язык: %s\n"+
+			"Статус: %s\n\n"+
+			"Доступные поля: %s\n\n"+
+			"<pre>%s</pre>",
+		info.Title,
+		i18n.T(lang, "language."+lang),
+		status,
+		strings.Join(fields, ", "),
+		html.EscapeString(source),
+	)
+
+	return c.EditOrSend(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: getAdminTemplateKeyboard(info, lang, custom != nil),
+	})
+}
+
+// getAdminTemplateKeyboard returns template card actions
+func getAdminTemplateKeyboard(info *services.TemplateInfo, lang string, customized bool) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+
+	// Language switch for client templates
+	if languages := services.TemplateLanguages(info); len(languages) > 1 {
+		langRow := tele.Row{}
+		for _, code := range languages {
+			label := i18n.T(code, "language."+code)
+			if code == lang {
+				label = "• " + label
+			}
+			langRow = append(langRow, callback.Button(label, "admin_template", info.Key, code))
+		}
+		rows = append(rows, langRow)
+	}
+
+	rows = append(rows, markup.Row(
+		callback.Button("✏️ Изменить", "admin_template_action", "edit", info.Key, lang),
+		callback.Button("👁 Предпросмотр", "admin_template_action", "preview", info.Key, lang),
+	))
+	if customized {
+		rows = append(rows, markup.Row(callback.Button("↩️ Вернуть стандартный", "admin_template_action", "reset", info.Key, lang)))
+	}
+	rows = append(rows, markup.Row(callback.Button("⬅️ К шаблонам", "admin", "templates")))
+
+	markup.Inline(rows...)
+	return markup
+}
+
+// handleAdminTemplateAction handles template card buttons
+// args: the action, template key and language
+func (b *Bot) handleAdminTemplateAction(ctx context.Context, c tele.Context, data callback.Data) error {
+	action, key, lang := data.Arg(0), data.Arg(1), data.Arg(2)
+	if _, err := services.GetTemplateInfo(key); err != nil {
+		return invalidArg(err)
+	}
+	if !i18n.IsSupported(lang) {
+		return fmt.Errorf("%w: unsupported template language %q", services.ErrValidation, lang)
+	}
+
+	state := b.getUserState(c.Sender().ID)
+
+	switch action {
+	case "edit":
+		state.EditMode = "template_body"
+		state.TempServiceData = map[string]interface{}{
+			"template_key":  key,
+			"template_lang": lang,
+		}
+
+		markup := &tele.ReplyMarkup{}
+		markup.Inline(markup.Row(callback.Button("❌ Отмена", "admin_template_action", "cancel", key, lang)))
+
+		return c.Edit(
+			"✏️ Отправьте новый текст шаблона.\n\n"+
+				"Поля подставляются так: <code>{{.Service}}</code>, условия — <code>{{if .Phone}}…{{else}}…{{end}}</code>. "+
+				"Для оформления используйте HTML-теги <code>&lt;b&gt;</code>, <code>&lt;i&gt;</code>, <code>&lt;s&gt;</code>.\n\n"+
+				"Текущий текст можно скопировать из карточки шаблона.",
+			&tele.SendOptions{ParseMode: tele.ModeHTML, ReplyMarkup: markup},
+		)
+
+	case "preview":
+		source, _ := b.templateService.GetSource(ctx, key, lang)
+		return b.sendTemplatePreview(c, key, lang, source, false)
+
+	case "save":
+		draft, ok := state.TempServiceData["template_draft"].(string)
+		if !ok || state.TempServiceData["template_key"] != key || state.TempServiceData["template_lang"] != lang {
+			return c.Respond(&tele.CallbackResponse{Text: "Черновик не найден, отправьте текст заново"})
+		}
+		if err := b.templateService.Save(ctx, key, lang, draft, c.Sender().ID); err != nil {
+			return c.Edit("❌ Шаблон не сохранен: "+html.EscapeString(err.Error()), &tele.SendOptions{ParseMode: tele.ModeHTML})
+		}
+		b.clearTemplateEdit(c.Sender().ID)
+		c.Respond(&tele.CallbackResponse{Text: "✅ Шаблон сохранен"})
+		return b.showAdminTemplate(ctx, c, key, lang)
+
+	case "reset":
+		if err := b.templateService.Reset(ctx, key, lang); err != nil {
+			slog.ErrorContext(ctx, "Failed to reset template", "key", key, "lang", lang, "error", err)
+			return c.Respond(&tele.CallbackResponse{Text: "Ошибка при сбросе шаблона"})
+		}
+		c.Respond(&tele.CallbackResponse{Text: "↩️ Стандартный текст восстановлен"})
+		return b.showAdminTemplate(ctx, c, key, lang)
+
+	case "cancel":
+		b.clearTemplateEdit(c.Sender().ID)
+		return b.showAdminTemplate(ctx, c, key, lang)
+
+	default:
+		return b.staleAction(c, data.Route, action)
+	}
+}
+
+// handleAdminTemplateInput validates a template typed by admin and shows its preview
+func (b *Bot) handleAdminTemplateInput(c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+	key, _ := state.TempServiceData["template_key"].(string)
+	lang, _ := state.TempServiceData["template_lang"].(string)
+
+	source := strings.TrimSpace(c.Text())
+	state.TempServiceData["template_draft"] = source
+
+	return b.sendTemplatePreview(c, key, lang, source, true)
+}
+
+// sendTemplatePreview renders the template against the sample booking and sends the result
+// Drafts get save and cancel buttons, invalid drafts are rejected
+func (b *Bot) sendTemplatePreview(c tele.Context, key, lang, source string, draft bool) error {
+	rendered, err := b.templateService.Preview(key, lang, source)
+	if err != nil {
+		msg := "❌ Шаблон не принят:\n<code>" + html.EscapeString(err.Error()) + "</code>"
+		if draft {
+			msg += "\n\nИсправьте текст и отправьте его снова."
+		}
+		return c.Send(msg, &tele.SendOptions{ParseMode: tele.ModeHTML})
+	}
+
+	markup := &tele.ReplyMarkup{}
+	if draft {
+		markup.Inline(
+			markup.Row(callback.Button("💾 Сохранить", "admin_template_action", "save", key, lang)),
+			markup.Row(callback.Button("❌ Отмена", "admin_template_action", "cancel", key, lang)),
+		)
+	} else {
+		markup.Inline(markup.Row(callback.Button("⬅️ К шаблону", "admin_template", key, lang)))
+	}
+
+	header := "👁 <b>Предпросмотр на примере записи</b>\n➖➖➖➖➖➖➖➖\n\n"
+	if _, err := b.tg.Send(c.Recipient(), header+rendered, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	}); err != nil {
+		// Telegram rejects broken HTML markup, don't let such a template be saved
+		if draft {
+			delete(b.getUserState(c.Sender().ID).TempServiceData, "template_draft")
+		}
+		return c.Send(
+			"❌ Telegram не смог отобразить сообщение:\n<code>"+html.EscapeString(err.Error())+"</code>\n\n"+
+				"Проверьте, что HTML-теги закрыты и символы &lt; и &gt; записаны как <code>&amp;lt;</code> и <code>&amp;gt;</code>.",
+			&tele.SendOptions{ParseMode: tele.ModeHTML},
+		)
+	}
+
+	return nil
+}
+
+// clearTemplateEdit drops template editing state
+func (b *Bot) clearTemplateEdit(userID int64) {
+	state := b.getUserState(userID)
+	if state.EditMode == "template_body" {
+		state.EditMode = ""
+		state.TempServiceData = nil
+	}
+}
@@ -0,0 +1,123 @@
+// Package bot contains admin handlers of outgoing webhooks
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+
+	"gobot/internal/callback"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// webhookFailuresShown limits failed deliveries listed on the webhooks screen
+const webhookFailuresShown = 10
+
+// webhookErrorShown limits the error text shown per delivery
+const webhookErrorShown = 120
+
+// handleAdminWebhooks shows webhook delivery stats and recent failures
+func (b *Bot) handleAdminWebhooks(ctx context.Context, c tele.Context) error {
+	var sb strings.Builder
+	sb.WriteString("🪝 <b>Вебхуки</b>\n\n")
+
+	if !b.webhookService.Enabled() {
+		sb.WriteString("Не настроены. Укажите в .env:\n" +
+			"• <code>WEBHOOK_URLS</code> — адреса через запятую\n" +
+			"• <code>WEBHOOK_SECRET</code> — секрет подписи не короче 16 символов\n\n" +
+			"После перезапуска события о записях, акциях и новых клиентах будут отправляться на эти адреса.\n\n")
+	} else {
+		sb.WriteString("Адреса:\n")
+		for _, url := range b.webhookService.URLs() {
+			sb.WriteString("• <code>" + html.EscapeString(url) + "</code>\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	stats, err := b.webhookService.GetStats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load webhook stats: %w", err)
+	}
+	failed, err := b.webhookService.ListFailed(ctx, webhookFailuresShown)
+	if err != nil {
+		return fmt.Errorf("failed to load failed webhooks: %w", err)
+	}
+
+	sb.WriteString(fmt.Sprintf("✅ Доставлено: %d\n⏳ В очереди: %d\n❌ Не доставлено: %d\n",
+		stats.Delivered, stats.Pending, stats.Failed))
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+
+	if len(failed) > 0 {
+		sb.WriteString("\n<b>Последние ошибки:</b>\n")
+	}
+	for _, delivery := range failed {
+		errText := delivery.LastError
+		if runes := []rune(errText); len(runes) > webhookErrorShown {
+			errText = string(runes[:webhookErrorShown]) + "…"
+		}
+		sb.WriteString(fmt.Sprintf("\n#%d <b>%s</b> — %s\n%s\n<code>%s</code>\n",
+			delivery.ID,
+			delivery.EventType,
+			delivery.UpdatedAt.Format("02.01.2006 15:04"),
+			html.EscapeString(delivery.URL),
+			html.EscapeString(errText),
+		))
+		if !b.webhookService.Enabled() {
+			continue
+		}
+		rows = append(rows, markup.Row(callback.Button(fmt.Sprintf("🔁 Повторить #%d", delivery.ID), "admin_webhook", "redeliver", fmt.Sprintf("%d", delivery.ID))))
+	}
+
+	if stats.Failed > 0 && b.webhookService.Enabled() {
+		rows = append(rows, markup.Row(callback.Button(fmt.Sprintf("🔁 Повторить все (%d)", stats.Failed), "admin_webhook", "redeliver_all")))
+	}
+	rows = append(rows,
+		markup.Row(callback.Button("🔄 Обновить", "admin", "webhooks")),
+		markup.Row(callback.Button("⬅️ Назад", "admin", "main")),
+	)
+	markup.Inline(rows...)
+
+	return c.Edit(sb.String(), &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleAdminWebhookAction handles webhook buttons
+// args: "redeliver" and the delivery ID, or "redeliver_all"
+func (b *Bot) handleAdminWebhookAction(ctx context.Context, c tele.Context, data callback.Data) error {
+	switch action := data.Arg(0); action {
+	case "redeliver":
+		deliveryID, err := data.Uint(1)
+		if err != nil {
+			return invalidArg(err)
+		}
+		if err := b.webhookService.Redeliver(ctx, deliveryID); err != nil {
+			slog.ErrorContext(ctx, "Failed to redeliver webhook", "delivery_id", deliveryID, "error", err)
+			return c.Respond(&tele.CallbackResponse{Text: "❌ Не удалось повторить отправку"})
+		}
+		slog.InfoContext(ctx, "Webhook queued again", "delivery_id", deliveryID)
+
+		_ = c.Respond(&tele.CallbackResponse{Text: "🔁 Отправка поставлена в очередь"})
+		return b.handleAdminWebhooks(ctx, c)
+
+	case "redeliver_all":
+		count, err := b.webhookService.RedeliverFailed(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to redeliver webhooks", "error", err)
+			return c.Respond(&tele.CallbackResponse{Text: "❌ Не удалось повторить отправку"})
+		}
+		slog.InfoContext(ctx, "Webhooks queued again", "count", count)
+
+		_ = c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("🔁 В очереди: %d", count)})
+		return b.handleAdminWebhooks(ctx, c)
+
+	default:
+		return b.staleAction(c, data.Route, action)
+	}
+}
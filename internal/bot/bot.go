@@ -4,25 +4,43 @@ package bot
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 	"time"
 
+	"gobot/internal/callback"
 	"gobot/internal/config"
 	"gobot/internal/database"
+	"gobot/internal/i18n"
 	"gobot/internal/services"
+	"gobot/internal/web"
 
 	tele "gopkg.in/telebot.v3"
 )
 
 // Bot represents the Telegram bot instance
 type Bot struct {
-	tg                  *tele.Bot
-	config              *config.Config
-	bookingService      *services.BookingService
-	userService         *services.UserService
-	adminService        *services.AdminService
-	notificationService *services.NotificationService
-	userStates          map[int64]*UserState
+	tg                   *tele.Bot
+	config               *config.Config
+	bookingService       *services.BookingService
+	userService          *services.UserService
+	adminService         *services.AdminService
+	notificationService  *services.NotificationService
+	certificateService   *services.CertificateService
+	reviewService        *services.ReviewService
+	broadcastService     *services.BroadcastService
+	outboxService        *services.OutboxService
+	templateService      *services.TemplateService
+	reportService        *services.ReportService
+	exportService        *services.ExportService
+	auditService         *services.AuditService
+	apiKeyService        *services.APIKeyService
+	dashboardAuthService *services.DashboardAuthService
+	caldavService        *services.CalDAVService // nil when CalDAV sync is disabled
+	webhookService       *services.WebhookService
+	bookingLimiter       *services.BookingLimiter
+	callbacks            *callbackRouter
+	userStates           map[int64]*UserState
 }
 
 // UserState holds the current state of user interaction
@@ -33,6 +51,22 @@ type UserState struct {
 	Time        string
 	BookingID   uint
 
+	// Gift certificate code applied to the current booking
+	CertificateCode string
+
+	// Review awaiting an optional comment
+	ReviewID uint
+
+	// Admin bookings browser
+	BookingFilter  services.BookingFilter
+	BookingsOffset int
+
+	// Client card being edited or messaged by admin
+	ClientID int64
+
+	// Export waiting for a custom period
+	ExportKind string
+
 	// Admin editing states
 	EditMode        string // "service_name", "service_price", etc.
 	EditServiceID   uint
@@ -44,33 +78,87 @@ func New(cfg *config.Config) (*Bot, error) {
 	pref := tele.Settings{
 		Token:  cfg.BotToken,
 		Poller: &tele.LongPoller{Timeout: 10 * time.Second},
+		OnError: func(err error, c tele.Context) {
+			slog.Error("Telegram error", "error", err)
+		},
 	}
 
+	// Buttons are signed before any message is built, notifications included
+	callback.SetSecret(cfg.CallbackSecret)
+
 	tg, err := tele.NewBot(pref)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bot: %w", err)
 	}
 
+	outbox := services.NewOutboxService(tg)
+	templates := services.NewTemplateService()
+
 	bot := &Bot{
-		tg:                  tg,
-		config:              cfg,
-		bookingService:      services.NewBookingService(),
-		userService:         services.NewUserService(),
-		adminService:        services.NewAdminService(),
-		notificationService: services.NewNotificationService(tg, cfg.AdminUserIDs, cfg.ChannelID),
-		userStates:          make(map[int64]*UserState),
+		tg:                   tg,
+		config:               cfg,
+		bookingService:       services.NewBookingService(),
+		userService:          services.NewUserService(),
+		adminService:         services.NewAdminService(),
+		notificationService:  services.NewNotificationService(outbox, templates, services.NewCalendarService(), cfg.AdminUserIDs, cfg.ChannelID, time.Duration(cfg.ReviewRequestDelayHours)*time.Hour),
+		certificateService:   services.NewCertificateService(),
+		reviewService:        services.NewReviewService(),
+		broadcastService:     services.NewBroadcastService(tg, outbox),
+		outboxService:        outbox,
+		templateService:      templates,
+		reportService:        services.NewReportService(),
+		exportService:        services.NewExportService(),
+		auditService:         services.NewAuditService(),
+		apiKeyService:        services.NewAPIKeyService(),
+		dashboardAuthService: services.NewDashboardAuthService(),
+		webhookService:       services.NewWebhookService(cfg.WebhookURLs, cfg.WebhookSecret),
+		bookingLimiter: services.NewBookingLimiter(services.BookingLimits{
+			MaxActive:   cfg.MaxActiveBookings,
+			MaxPerDay:   cfg.MaxBookingsPerDay,
+			MinInterval: time.Duration(cfg.MinBookingIntervalSeconds) * time.Second,
+		}),
+		userStates: make(map[int64]*UserState),
 	}
 
 	bot.setupHandlers()
 
-	// Start reminder worker in background
+	// Booking counters by service for the metrics endpoint
+	services.Events.Subscribe(services.CountBookingEvent)
+
+	// Start outgoing message and reminder workers in background
+	go bot.outboxService.StartWorker(context.Background())
 	go bot.notificationService.StartReminderWorker(context.Background())
 
+	// Two-way sync with the staff calendar
+	if cfg.CalDAVURL != "" {
+		client := services.NewCalDAVClient(cfg.CalDAVURL, cfg.CalDAVUsername, cfg.CalDAVPassword)
+		bot.caldavService = services.NewCalDAVService(client, services.NewCalendarService(), time.Duration(cfg.CalDAVSyncIntervalMin)*time.Minute)
+		go bot.caldavService.StartWorker(context.Background())
+	}
+
+	// Outgoing webhooks for domain events
+	if bot.webhookService.Enabled() {
+		services.Events.Subscribe(bot.webhookService.HandleEvent)
+		go bot.webhookService.StartWorker(context.Background())
+	}
+
 	return bot, nil
 }
 
 // setupHandlers registers all command and callback handlers
 func (b *Bot) setupHandlers() {
+	// Trace and log every update, throttled ones too
+	b.tg.Use(b.logMiddleware)
+	b.tg.Use(b.metricsMiddleware)
+
+	// Recover panics, answer callbacks once and reply to failed updates
+	b.tg.Use(b.errorMiddleware)
+
+	// Throttle users who spam commands and buttons
+	if b.config.RateLimitPerMinute > 0 {
+		b.tg.Use(b.rateLimitMiddleware(newRateLimiter(b.config.RateLimitPerMinute, b.config.RateLimitBurst)))
+	}
+
 	// Command handlers
 	b.tg.Handle("/start", b.handleStart)
 	b.tg.Handle("/help", b.handleHelp)
@@ -79,23 +167,58 @@ func (b *Bot) setupHandlers() {
 	b.tg.Handle("/cancel", b.handleCancelStart)
 	b.tg.Handle("/admin", b.handleAdmin)
 
-	// Callback handlers
+	// Callback handlers, routed by the signed payload of the button
+	b.callbacks = b.callbackRoutes()
 	b.tg.Handle(tele.OnCallback, b.handleCallback)
 
 	// Text message handler for admin edits
 	b.tg.Handle(tele.OnText, b.handleTextInput)
+
+	// Photo handler for broadcasts with images
+	b.tg.Handle(tele.OnPhoto, b.handleAdminBroadcastMessage)
+
+	// Contact handler for phone numbers shared from the profile or booking
+	b.tg.Handle(tele.OnContact, b.handleContact)
+
+	// Payment handlers for gift certificate purchases
+	b.tg.Handle(tele.OnCheckout, b.handleCheckout)
+	b.tg.Handle(tele.OnPayment, b.handlePayment)
 }
 
 // Start starts the bot
 func (b *Bot) Start() {
-	log.Println("Bot started successfully")
+	slog.Info("Bot started", "username", b.tg.Me.Username)
 	b.tg.Start()
 }
 
 // Stop stops the bot gracefully
 func (b *Bot) Stop() {
 	b.tg.Stop()
-	log.Println("Bot stopped")
+	slog.Info("Bot stopped")
+}
+
+// Notifications returns the notification service so other front ends notify clients the same way
+func (b *Bot) Notifications() *services.NotificationService {
+	return b.notificationService
+}
+
+// BookingLimiter returns the booking limiter so other front ends apply the same limits
+func (b *Bot) BookingLimiter() *services.BookingLimiter {
+	return b.bookingLimiter
+}
+
+// Username returns the bot username without "@"
+func (b *Bot) Username() string {
+	return b.tg.Me.Username
+}
+
+// miniAppURL returns the address of the booking Mini App, empty when the HTTP server is off
+// Telegram opens Mini Apps over HTTPS only
+func (b *Bot) miniAppURL() string {
+	if b.config.HTTPAddr == "" || !strings.HasPrefix(b.config.PublicURL, "https://") {
+		return ""
+	}
+	return b.config.PublicURL + web.MiniAppPath + "/"
 }
 
 // getUserState retrieves or creates user state
@@ -118,7 +241,50 @@ func (b *Bot) isAdmin(userID int64) bool {
 	return b.config.IsAdmin(userID)
 }
 
+// isOwner checks if user is the owner, who can see the audit log
+func (b *Bot) isOwner(userID int64) bool {
+	return b.config.IsOwner(userID)
+}
+
+// requestContext returns the context of an update, changes in it are audited as made by the sender
+// Logs written with it carry the trace ID of the update
+func (b *Bot) requestContext(c tele.Context) context.Context {
+	ctx, ok := c.Get(requestContextKey).(context.Context)
+	if !ok {
+		ctx = context.Background()
+	}
+	sender := c.Sender()
+	if sender == nil {
+		return ctx
+	}
+	actorType := database.ActorClient
+	if b.isAdmin(sender.ID) {
+		actorType = database.ActorAdmin
+	}
+	return services.WithActor(ctx, services.Actor{Type: actorType, ID: sender.ID})
+}
+
 // ensureUser ensures user exists in database
 func (b *Bot) ensureUser(ctx context.Context, tgUser *tele.User) (*database.User, error) {
 	return b.userService.GetOrCreateUser(ctx, tgUser)
 }
+
+// lang returns the language to talk to the sender in
+// It is resolved once per update and kept in tele.Context
+func (b *Bot) lang(c tele.Context) string {
+	sender := c.Sender()
+	if sender == nil {
+		return i18n.Default
+	}
+	if lang, ok := c.Get(languageKey).(string); ok {
+		return lang
+	}
+	lang := i18n.Resolve(b.userService.GetLanguage(b.requestContext(c), sender.ID), sender.LanguageCode)
+	c.Set(languageKey, lang)
+	return lang
+}
+
+// mainMenu returns the main menu keyboard for the sender
+func (b *Bot) mainMenu(c tele.Context) *tele.ReplyMarkup {
+	return getMainMenuInlineKeyboard(b.lang(c), b.isAdmin(c.Sender().ID), b.miniAppURL())
+}
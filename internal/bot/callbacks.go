@@ -3,186 +3,72 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strconv"
-	"strings"
+	"html"
+	"log/slog"
 	"time"
 
 	"gobot/internal/database"
+	"gobot/internal/i18n"
+	"gobot/internal/services"
 
 	tele "gopkg.in/telebot.v3"
 )
 
-// handleCallback handles all callback queries from inline keyboards
-func (b *Bot) handleCallback(c tele.Context) error {
-	callback := c.Callback()
-	if callback == nil {
-		return nil
-	}
-
-	// DEBUG: Log callback data
-	fmt.Printf("📲 Callback received: %s from user %d\n", callback.Data, c.Sender().ID)
-
-	// Parse callback data - telebot uses "|" as separator
-	// Clean the callback data from whitespace
-	cleanCallbackData := strings.TrimSpace(callback.Data)
-
-	parts := strings.Split(cleanCallbackData, "|")
-	if len(parts) < 1 {
-		fmt.Printf("❌ Error: empty callback parts\n")
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка обработки действия"})
-	}
-
-	action := strings.TrimSpace(parts[0])
-	data := ""
-	if len(parts) > 1 {
-		data = strings.TrimSpace(parts[1])
-	}
-
-	fmt.Printf("🔍 Action: '%s', Data: '%s'\n", action, data)
-
-	ctx := context.Background()
-
-	// Answer callback first to remove loading state
-	c.Respond()
-
-	switch action {
-	case "main_menu":
-		return b.handleMainMenuAction(ctx, c, data)
-	case "service":
-		return b.handleServiceSelection(ctx, c, data)
-	case "date":
-		return b.handleDateSelection(ctx, c, data)
-	case "time":
-		return b.handleTimeSelection(ctx, c, data)
-	case "confirm":
-		return b.handleBookingConfirmation(ctx, c)
-	case "cancel":
-		return b.handleCancel(ctx, c, data)
-	case "cancel_booking":
-		return b.handleBookingCancellation(ctx, c, data)
-	case "back":
-		return b.handleBack(ctx, c, data)
-	case "back_to_menu":
-		return b.handleBackToMainMenu(ctx, c)
-	case "admin":
-		return b.handleAdminAction(ctx, c, data)
-	case "admin_edit_service":
-		return b.handleAdminEditService(ctx, c, data)
-	case "admin_toggle_service":
-		return b.handleAdminToggleService(ctx, c, data)
-	case "admin_delete_service":
-		return b.handleAdminDeleteService(ctx, c, data)
-	case "admin_add_service":
-		return b.handleAdminAddServiceStart(ctx, c)
-	case "admin_edit_service_menu":
-		return b.handleAdminEditServiceMenu(ctx, c, data)
-	case "admin_edit_field":
-		return b.handleAdminEditField(ctx, c, data)
-	case "admin_cancel_edit":
-		return b.handleAdminCancelEdit(ctx, c)
-	case "admin_cancel_add_service":
-		return b.handleAdminCancelAddService(ctx, c)
-	case "admin_discounts":
-		return b.handleAdminDiscounts(ctx, c)
-	case "admin_add_discount":
-		return b.handleAdminAddDiscountStart(ctx, c)
-	case "admin_discount_select_service":
-		return b.handleAdminDiscountSelectService(ctx, c, data)
-	case "admin_edit_discount":
-		return b.handleAdminEditDiscount(ctx, c, data)
-	case "admin_toggle_discount":
-		return b.handleAdminToggleDiscount(ctx, c, data)
-	case "admin_delete_discount":
-		return b.handleAdminDeleteDiscount(ctx, c, data)
-	case "admin_cancel_add_discount":
-		return b.handleAdminCancelAddDiscount(ctx, c)
-	case "admin_discount_set_percentage":
-		return b.handleAdminDiscountSetPercentage(ctx, c, data)
-	case "admin_discount_set_start_date":
-		return b.handleAdminDiscountSetStartDate(ctx, c, data)
-	case "admin_discount_set_end_date":
-		return b.handleAdminDiscountSetEndDate(ctx, c, data)
-	case "admin_approve_booking":
-		return b.handleAdminApproveBooking(ctx, c, data)
-	case "admin_reject_booking":
-		return b.handleAdminRejectBooking(ctx, c, data)
-	case "catalog_service":
-		return b.handleCatalogService(ctx, c, data)
-	default:
-		return c.Respond(&tele.CallbackResponse{Text: "Неизвестное действие"})
-	}
-}
-
 // handleServiceSelection handles service selection
-func (b *Bot) handleServiceSelection(ctx context.Context, c tele.Context, serviceIDStr string) error {
-	serviceID, err := strconv.ParseUint(serviceIDStr, 10, 32)
-	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка выбора услуги"})
-	}
-
+func (b *Bot) handleServiceSelection(ctx context.Context, c tele.Context, serviceID uint) error {
+	lang := b.lang(c)
 	// Get service info
 	var service database.Service
 	if err := database.DB.First(&service, serviceID).Error; err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка загрузки услуги"})
+		return c.Respond(&tele.CallbackResponse{Text: i18n.T(lang, "error.load_service")})
 	}
 
-	// Debug: log detailed description
-	fmt.Printf("DEBUG: Service %d - DetailedDescription length: %d\n", service.ID, len(service.DetailedDescription))
-	if service.DetailedDescription != "" {
-		previewLen := 50
-		if len(service.DetailedDescription) < previewLen {
-			previewLen = len(service.DetailedDescription)
+	// Don't let blocked or over-limit clients go through the whole flow
+	if _, err := b.ensureUser(ctx, c.Sender()); err == nil {
+		if err := b.bookingLimiter.Check(ctx, c.Sender().ID); err != nil {
+			return c.Edit(services.LimitErrorText(lang, err))
 		}
-		fmt.Printf("DEBUG: DetailedDescription content: %s\n", service.DetailedDescription[:previewLen])
 	}
 
+	slog.DebugContext(ctx, "Service selected", "service_id", service.ID, "detailed_description_length", len(service.DetailedDescription))
+
 	// Save service selection to user state
 	state := b.getUserState(c.Sender().ID)
-	state.ServiceID = uint(serviceID)
+	state.ServiceID = serviceID
 
 	// Show service details with detailed description
-	serviceMsg := fmt.Sprintf(
-		"✅ <b>Выбрана услуга: %s</b>\n\n"+
-			"📝 <b>Описание:</b>\n%s\n\n",
-		service.Name,
-		service.Description,
-	)
+	serviceMsg := i18n.T(lang, "booking.service_selected", service.Name, service.Description)
 
 	// Add detailed description if available
 	if service.DetailedDescription != "" {
-		serviceMsg += fmt.Sprintf(
-			"📖 <b>Подробное описание:</b>\n%s\n\n",
-			service.DetailedDescription,
-		)
+		serviceMsg += i18n.T(lang, "service.detailed", service.DetailedDescription)
 	} else {
 		// Show message if no detailed description
-		serviceMsg += "ℹ️ <i>Подробное описание отсутствует</i>\n\n"
+		serviceMsg += i18n.T(lang, "service.no_detailed")
 	}
 
-	serviceMsg += fmt.Sprintf(
-		"⏱ Длительность: <b>%d минут</b>\n"+
-			"💰 Стоимость: <b>%d руб.</b>\n\n"+
-			"📅 <b>Выберите дату:</b>",
-		service.Duration,
-		service.Price/100,
-	)
+	serviceMsg += i18n.T(lang, "service.duration_price", service.Duration, i18n.Price(lang, service.Price)) +
+		"\n\n" + i18n.T(lang, "booking.choose_date")
 
 	state.CurrentStep = "date"
 
 	// Update message with service details and date selection
 	return c.Edit(serviceMsg, &tele.SendOptions{
 		ParseMode:   tele.ModeHTML,
-		ReplyMarkup: getDateKeyboard(),
+		ReplyMarkup: getDateKeyboard(lang),
 	})
 }
 
 // handleDateSelection handles date selection
 func (b *Bot) handleDateSelection(ctx context.Context, c tele.Context, dateStr string) error {
+	lang := b.lang(c)
+
 	// Parse date and normalize to local timezone
 	parsedDate, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка выбора даты"})
+		return c.Respond(&tele.CallbackResponse{Text: i18n.T(lang, "error.select_date")})
 	}
 
 	// Normalize to local timezone (same as time.Now())
@@ -199,15 +85,16 @@ func (b *Bot) handleDateSelection(ctx context.Context, c tele.Context, dateStr s
 
 	// Get service to know duration
 	var service database.Service
-	if err := database.DB.First(&service, state.ServiceID).Error; err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка загрузки услуги"})
+	if err := database.DB.WithContext(ctx).First(&service, state.ServiceID).Error; err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: i18n.T(lang, "error.load_service")})
 	}
 
 	// Update message with time selection
-	msg := fmt.Sprintf("⏰ <b>Выберите время:</b>\n\n📅 Дата: %s", date.Format("02.01.2006"))
+	slots := services.AvailableTimeSlots(ctx, date, service.Duration, 0)
+	msg := i18n.T(lang, "booking.choose_time", i18n.Date(lang, date), i18n.Weekday(lang, date))
 	return c.Edit(msg, &tele.SendOptions{
 		ParseMode:   tele.ModeHTML,
-		ReplyMarkup: getTimeKeyboard(date, service.Duration),
+		ReplyMarkup: getTimeKeyboard(lang, slots),
 	})
 }
 
@@ -217,49 +104,70 @@ func (b *Bot) handleTimeSelection(ctx context.Context, c tele.Context, timeStr s
 
 	// Validate time slot
 	if err := b.validateTimeSlot(ctx, state.Date, timeStr, state.ServiceID); err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: err.Error()})
+		return err
 	}
 
 	// Save time selection to user state
 	state.CurrentStep = "confirm"
 	state.Time = timeStr
 
-	// Get service info
-	var service database.Service
-	if err := database.DB.First(&service, state.ServiceID).Error; err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка загрузки услуги"})
+	return b.showBookingConfirmation(ctx, c, state)
+}
+
+// showBookingConfirmation shows booking summary with price and applied certificate
+func (b *Bot) showBookingConfirmation(ctx context.Context, c tele.Context, state *UserState) error {
+	lang := b.lang(c)
+
+	// Get service info with current discount
+	service, price, err := services.NewDiscountService().GetServiceWithDiscount(ctx, state.ServiceID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: i18n.T(lang, "error.load_service")})
 	}
 
 	// Show confirmation
-	confirmMsg := fmt.Sprintf(
-		"✅ <b>Подтверждение записи</b>\n\n"+
-			"📋 Услуга: <b>%s</b>\n"+
-			"📝 Описание: %s\n"+
-			"⏱ Длительность: %d минут\n"+
-			"💰 Стоимость: %d руб.\n\n"+
-			"📆 Дата: <b>%s</b>\n"+
-			"⏰ Время: <b>%s</b>\n\n"+
-			"Подтвердите запись:",
+	confirmMsg := i18n.T(lang, "booking.confirm",
 		service.Name,
 		service.Description,
 		service.Duration,
-		service.Price/100,
-		state.Date.Format("02.01.2006"),
+		i18n.Price(lang, price),
+		i18n.Date(lang, state.Date),
+		i18n.Weekday(lang, state.Date),
 		state.Time,
 	)
 
-	return c.Edit(confirmMsg, &tele.SendOptions{
+	// Apply gift certificate if the client has one
+	hasCertificate := false
+	if state.CertificateCode != "" {
+		certificate, err := b.certificateService.ValidateForService(ctx, state.CertificateCode, state.ServiceID)
+		if err != nil {
+			confirmMsg += i18n.T(lang, "booking.certificate_not_applied", state.CertificateCode, certificateErrorText(lang, err))
+			state.CertificateCode = ""
+		} else {
+			hasCertificate = true
+			deduction := services.CertificateDeduction(certificate, price)
+			confirmMsg += i18n.T(lang, "booking.certificate_applied",
+				certificate.Code,
+				i18n.Price(lang, deduction),
+				i18n.Price(lang, price-deduction),
+			)
+		}
+	}
+
+	confirmMsg += i18n.T(lang, "booking.confirm_prompt")
+
+	return c.EditOrSend(confirmMsg, &tele.SendOptions{
 		ParseMode:   tele.ModeHTML,
-		ReplyMarkup: getConfirmKeyboard(),
+		ReplyMarkup: getConfirmKeyboard(lang, hasCertificate),
 	})
 }
 
 // validateTimeSlot validates if a time slot is available
+// Taken slots return services.ErrSlotTaken, bad input a services.ValidationError
 func (b *Bot) validateTimeSlot(ctx context.Context, date time.Time, timeStr string, serviceID uint) error {
 	// Parse time
 	slotTime, err := time.Parse("15:04", timeStr)
 	if err != nil {
-		return fmt.Errorf("неверный формат времени")
+		return services.Invalid("slot.invalid_time")
 	}
 
 	// Check if time is in the past
@@ -281,49 +189,19 @@ func (b *Bot) validateTimeSlot(ctx context.Context, date time.Time, timeStr stri
 	if selectedDayNormalized.Equal(today) {
 		// Check if the slot time is before current time (with 1 minute buffer for safety)
 		if slotDateTime.Before(now.Add(1 * time.Minute)) {
-			return fmt.Errorf("❌ Нельзя записаться на прошедшее время")
+			return services.Invalid("slot.past")
 		}
 	}
 
 	// Get service duration
 	var service database.Service
-	if err := database.DB.First(&service, serviceID).Error; err != nil {
-		return fmt.Errorf("ошибка загрузки услуги")
+	if err := database.DB.WithContext(ctx).First(&service, serviceID).Error; err != nil {
+		return fmt.Errorf("failed to load service: %w", err)
 	}
 
-	// Check if slot is already booked
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
-
-	var bookings []database.Booking
-	database.DB.WithContext(ctx).
-		Preload("Service").
-		Where("date >= ? AND date < ?", startOfDay, endOfDay).
-		Where("status IN ?", []database.BookingStatus{
-			database.BookingStatusPending,
-			database.BookingStatusConfirmed,
-		}).
-		Find(&bookings)
-
-	slotStart := slotDateTime
-	slotEnd := slotDateTime.Add(time.Duration(service.Duration) * time.Minute)
-
-	for _, booking := range bookings {
-		bookedTime, err := time.Parse("15:04", booking.Time)
-		if err != nil {
-			continue
-		}
-
-		bookedDateTime := time.Date(
-			booking.Date.Year(), booking.Date.Month(), booking.Date.Day(),
-			bookedTime.Hour(), bookedTime.Minute(), 0, 0, booking.Date.Location(),
-		)
-		bookedEnd := bookedDateTime.Add(time.Duration(booking.Service.Duration) * time.Minute)
-
-		// Check for overlap
-		if slotStart.Before(bookedEnd) && slotEnd.After(bookedDateTime) {
-			return fmt.Errorf("❌ Это время уже занято. Выберите другое время.")
-		}
+	// Check working hours, other bookings and the staff calendar
+	if services.IsTimeSlotTaken(ctx, timeStr, date, service.Duration, 0) {
+		return services.ErrSlotTaken
 	}
 
 	return nil
@@ -332,10 +210,46 @@ func (b *Bot) validateTimeSlot(ctx context.Context, date time.Time, timeStr stri
 // handleBookingConfirmation handles booking confirmation
 func (b *Bot) handleBookingConfirmation(ctx context.Context, c tele.Context) error {
 	state := b.getUserState(c.Sender().ID)
+	lang := b.lang(c)
 
 	// Validate time slot again before creating booking (double check to prevent race conditions)
 	if err := b.validateTimeSlot(ctx, state.Date, state.Time, state.ServiceID); err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: err.Error()})
+		return err
+	}
+
+	// Check block list and anti-abuse limits
+	if err := b.bookingLimiter.Attempt(ctx, c.Sender().ID); err != nil {
+		if errors.Is(err, services.ErrBookingTooFrequent) {
+			return c.Respond(&tele.CallbackResponse{Text: services.LimitErrorText(lang, err)})
+		}
+		b.clearUserState(c.Sender().ID)
+		return c.Edit(services.LimitErrorText(lang, err))
+	}
+
+	// Ask for phone number on the first booking
+	user, err := b.ensureUser(ctx, c.Sender())
+	if err == nil && user.Phone == "" {
+		state.CurrentStep = "contact"
+		c.Delete()
+		return c.Send(i18n.T(lang, "contact.booking_prompt"), getContactRequestKeyboard(lang))
+	}
+
+	return b.completeBooking(ctx, c)
+}
+
+// completeBooking creates the booking from user state and notifies admins
+func (b *Bot) completeBooking(ctx context.Context, c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+	lang := b.lang(c)
+
+	// Slot may have been taken while the user was sharing the phone
+	if err := b.validateTimeSlot(ctx, state.Date, state.Time, state.ServiceID); err != nil {
+		text, ok := services.UserErrorText(lang, err)
+		if !ok {
+			return err
+		}
+		b.clearUserState(c.Sender().ID)
+		return c.EditOrSend(text + "\n" + i18n.T(lang, "booking.choose_another_time"))
 	}
 
 	// Create booking
@@ -347,141 +261,138 @@ func (b *Bot) handleBookingConfirmation(ctx context.Context, c tele.Context) err
 		state.Time,
 	)
 	if err != nil {
-		return c.Edit("❌ Ошибка при создании записи. Попробуйте позже.")
+		return c.EditOrSend(i18n.T(lang, "error.create_booking"))
 	}
 
-	// Notify admins about new booking with approve/reject buttons
-	for _, adminID := range b.config.AdminUserIDs {
-		adminMsg := fmt.Sprintf(
-			"🔔 <b>Новая запись!</b>\n\n"+
-				"👤 %s %s (@%s)\n"+
-				"📋 %s\n"+
-				"📆 %s в %s\n"+
-				"💰 %d руб.\n\n"+
-				"Подтвердите или отмените запись:",
-			booking.User.FirstName,
-			booking.User.LastName,
-			booking.User.Username,
-			booking.Service.Name,
-			booking.Date.Format("02.01.2006"),
-			booking.Time,
-			booking.Service.Price/100,
-		)
-		b.notificationService.NotifyAdminWithActions(ctx, adminID, adminMsg, booking.ID)
+	// Redeem gift certificate if one was applied
+	// Admins always get the certificate line in Russian
+	certificateLine, adminCertificateLine := "", ""
+	if state.CertificateCode != "" {
+		deducted, err := b.certificateService.RedeemForBooking(ctx, state.CertificateCode, booking)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to redeem certificate", "certificate", state.CertificateCode, "booking_id", booking.ID, "error", err)
+			certificateLine = i18n.T(lang, "booking.certificate_failed", certificateErrorText(lang, err))
+			adminCertificateLine = i18n.T(i18n.Default, "booking.certificate_failed", certificateErrorText(i18n.Default, err))
+		} else {
+			certificateLine = i18n.T(lang, "booking.certificate_paid",
+				i18n.Price(lang, deducted),
+				i18n.Price(lang, booking.Price-deducted),
+			)
+			adminCertificateLine = i18n.T(i18n.Default, "booking.certificate_paid",
+				i18n.Price(i18n.Default, deducted),
+				i18n.Price(i18n.Default, booking.Price-deducted),
+			)
+		}
 	}
 
+	// Notify admins about new booking with approve/reject buttons
+	b.notificationService.NotifyAdminsNewBooking(ctx, booking, adminCertificateLine)
+
 	// Clear user state
 	b.clearUserState(c.Sender().ID)
 
-	successMsg := fmt.Sprintf(
-		"⏳ <b>Запись создана и ожидает подтверждения</b>\n\n"+
-			"📋 Услуга: <b>%s</b>\n"+
-			"📆 Дата: <b>%s</b>\n"+
-			"⏰ Время: <b>%s</b>\n"+
-			"💰 Стоимость: %d руб.\n\n"+
-			"Администратор рассмотрит вашу заявку и подтвердит запись.\n"+
-			"Вы получите уведомление о решении.\n\n"+
-			"Для просмотра записей используйте /my_bookings",
+	successMsg := i18n.T(lang, "booking.created",
 		booking.Service.Name,
-		booking.Date.Format("02.01.2006"),
+		i18n.Date(lang, booking.Date),
+		i18n.Weekday(lang, booking.Date),
 		booking.Time,
-		booking.Service.Price/100,
+		i18n.Price(lang, booking.Price),
+		certificateLine,
 	)
 
-	return c.Edit(successMsg, &tele.SendOptions{ParseMode: tele.ModeHTML})
+	return c.EditOrSend(successMsg, &tele.SendOptions{ParseMode: tele.ModeHTML})
 }
 
 // handleCancel handles cancellation
 func (b *Bot) handleCancel(ctx context.Context, c tele.Context, cancelType string) error {
 	b.clearUserState(c.Sender().ID)
-	return c.Edit("❌ Действие отменено.\nИспользуйте каталог услуг для новой записи.")
+	return c.Edit(i18n.T(b.lang(c), "booking.cancelled_action"))
 }
 
 // handleBookingCancellation handles booking cancellation
-func (b *Bot) handleBookingCancellation(ctx context.Context, c tele.Context, bookingIDStr string) error {
-	bookingID, err := strconv.ParseUint(bookingIDStr, 10, 32)
-	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка отмены записи"})
-	}
-
+func (b *Bot) handleBookingCancellation(ctx context.Context, c tele.Context, bookingID uint) error {
+	lang := b.lang(c)
 	// Get booking info before cancellation
 	var booking database.Booking
 	if err := database.DB.WithContext(ctx).
 		Preload("Service").
 		Preload("User").
 		First(&booking, bookingID).Error; err != nil {
-		return c.Edit("❌ Запись не найдена")
+		return fmt.Errorf("failed to get booking: %w", err)
 	}
 
 	// Cancel booking
-	if err := b.bookingService.CancelBooking(ctx, uint(bookingID), c.Sender().ID); err != nil {
-		return c.Edit("❌ Ошибка при отмене записи: " + err.Error())
+	if err := b.bookingService.CancelBooking(ctx, bookingID, c.Sender().ID); err != nil {
+		return fmt.Errorf("failed to cancel booking: %w", err)
 	}
 
 	// Send cancellation notification
 	booking.Status = database.BookingStatusCancelled
 	if err := b.notificationService.SendBookingCancellation(ctx, &booking); err != nil {
-		fmt.Printf("Warning: failed to send cancellation notification: %v\n", err)
+		slog.ErrorContext(ctx, "Failed to send cancellation notification", "booking_id", bookingID, "error", err)
 	}
 
 	// Notify admins about cancellation
-	for _, adminID := range b.config.AdminUserIDs {
-		adminMsg := fmt.Sprintf(
-			"❌ <b>Отмена записи</b>\n\n"+
-				"👤 %s %s (@%s)\n"+
-				"📋 %s\n"+
-				"📆 %s в %s",
-			booking.User.FirstName,
-			booking.User.LastName,
-			booking.User.Username,
-			booking.Service.Name,
-			booking.Date.Format("02.01.2006"),
-			booking.Time,
-		)
-		b.notificationService.NotifyAdmin(ctx, adminID, adminMsg)
-	}
-
-	return c.Edit(
-		"✅ Запись успешно отменена!\n\n" +
-			"Для создания новой записи используйте /book",
+	adminMsg := fmt.Sprintf(
+		"❌ <b>Отмена записи</b>\n\n"+
+			"👤 %s %s (@%s)\n"+
+			"📋 %s\n"+
+			"📆 %s в %s",
+		html.EscapeString(booking.User.FirstName),
+		html.EscapeString(booking.User.LastName),
+		html.EscapeString(booking.User.Username),
+		html.EscapeString(booking.Service.Name),
+		booking.Date.Format("02.01.2006"),
+		booking.Time,
 	)
+	b.notificationService.NotifyAdmins(ctx, adminMsg)
+
+	return c.Edit(i18n.T(lang, "cancel.done"))
+}
+
+// handleNoTime explains a day without free time and returns to the dates
+func (b *Bot) handleNoTime(ctx context.Context, c tele.Context) error {
+	if err := c.Respond(&tele.CallbackResponse{Text: i18n.T(b.lang(c), "slot.none")}); err != nil {
+		slog.WarnContext(ctx, "Failed to answer callback", "error", err)
+	}
+	return b.handleBack(ctx, c, "date")
 }
 
 // handleBack handles back button
 func (b *Bot) handleBack(ctx context.Context, c tele.Context, backTo string) error {
 	state := b.getUserState(c.Sender().ID)
+	lang := b.lang(c)
 
 	switch backTo {
 	case "services":
 		services, err := b.bookingService.GetAvailableServices(ctx)
 		if err != nil {
-			return c.Edit("Ошибка при загрузке услуг")
+			return c.Edit(i18n.T(lang, "error.load_services"))
 		}
 		state.CurrentStep = "service"
-		return c.Edit("📋 Выберите услугу:", getServicesKeyboard(services))
+		return c.Edit(i18n.T(lang, "booking.choose_service"), getServicesKeyboard(lang, services))
 
 	case "date":
 		state.CurrentStep = "date"
-		return c.Edit("📅 Выберите дату:", getDateKeyboard())
+		return c.Edit(i18n.T(lang, "booking.choose_date"), &tele.SendOptions{
+			ParseMode:   tele.ModeHTML,
+			ReplyMarkup: getDateKeyboard(lang),
+		})
 
 	case "main":
 		b.clearUserState(c.Sender().ID)
-		return c.Edit("Возврат в главное меню")
+		return c.Edit(i18n.T(lang, "menu.returned"))
 
 	default:
-		return c.Respond(&tele.CallbackResponse{Text: "Неизвестное действие"})
+		return b.staleAction(c, "back", backTo)
 	}
 }
 
 // handleAdminAction handles admin panel actions
 func (b *Bot) handleAdminAction(ctx context.Context, c tele.Context, actionType string) error {
-	if !b.isAdmin(c.Sender().ID) {
-		return c.Respond(&tele.CallbackResponse{Text: "❌ Нет доступа"})
-	}
-
 	switch actionType {
 	case "bookings":
-		return b.handleAdminBookingsDetailed(ctx, c)
+		return b.handleAdminBookingsDetailed(ctx, c, 0)
 	case "services":
 		return b.handleAdminServicesManagement(ctx, c)
 	case "discounts":
@@ -489,11 +400,39 @@ func (b *Bot) handleAdminAction(ctx context.Context, c tele.Context, actionType
 	case "slots":
 		return c.Edit("⏰ Управление временными слотами\n\nФункция в разработке...")
 	case "stats":
-		return b.handleAdminStatsDetailed(ctx, c)
+		return b.handleAdminReport(ctx, c, services.ReportPeriodMonth)
+	case "certificates":
+		return b.handleAdminCertificates(ctx, c)
+	case "reviews":
+		return b.handleAdminReviews(ctx, c, 0)
+	case "broadcast":
+		return b.handleAdminBroadcastStart(ctx, c)
+	case "new_booking":
+		return b.handleAdminNewBookingStart(ctx, c)
+	case "clients":
+		return b.handleAdminClients(ctx, c)
+	case "blocked":
+		return b.handleAdminBlockedClients(ctx, c)
+	case "templates":
+		return b.handleAdminTemplates(ctx, c)
+	case "calendar":
+		return b.handleAdminCalendar(c)
+	case "calendar_sync":
+		return b.handleAdminCalendarSync(c)
+	case "export":
+		return b.handleAdminExport(c)
+	case "api_keys":
+		return b.handleAdminAPIKeys(ctx, c)
+	case "dashboard":
+		return b.handleAdminDashboard(ctx, c)
+	case "webhooks":
+		return b.handleAdminWebhooks(ctx, c)
+	case "audit":
+		return b.handleAdminAudit(ctx, c)
 	case "main":
 		return b.handleAdmin(c)
 	default:
-		return c.Respond(&tele.CallbackResponse{Text: "Неизвестное действие"})
+		return b.staleAction(c, "admin", actionType)
 	}
 }
 
@@ -508,7 +447,7 @@ func (b *Bot) handleAdminBookings(ctx context.Context, c tele.Context) error {
 		Find(&bookings).Error
 
 	if err != nil {
-		return c.Edit("Ошибка при загрузке записей")
+		return fmt.Errorf("failed to load bookings: %w", err)
 	}
 
 	if len(bookings) == 0 {
@@ -562,39 +501,25 @@ func (b *Bot) handleAdminStats(ctx context.Context, c tele.Context) error {
 }
 
 // handleAdminApproveBooking handles admin approval of a booking
-func (b *Bot) handleAdminApproveBooking(ctx context.Context, c tele.Context, bookingIDStr string) error {
-	if !b.isAdmin(c.Sender().ID) {
-		return c.Respond(&tele.CallbackResponse{Text: "❌ Нет доступа"})
-	}
-
-	bookingID, err := strconv.ParseUint(bookingIDStr, 10, 32)
-	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка обработки"})
-	}
-
+func (b *Bot) handleAdminApproveBooking(ctx context.Context, c tele.Context, bookingID uint) error {
 	// Get booking
 	var booking database.Booking
 	if err := database.DB.WithContext(ctx).
 		Preload("Service").
 		Preload("User").
 		First(&booking, bookingID).Error; err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Запись не найдена"})
+		return fmt.Errorf("failed to get booking: %w", err)
 	}
 
-	// Check if already processed
-	if booking.Status != database.BookingStatusPending {
-		return c.Respond(&tele.CallbackResponse{Text: "Запись уже обработана"})
+	// Update booking status, a booking processed in the meantime fails with ErrBookingTransition
+	if err := b.adminService.UpdateBookingStatus(ctx, booking.ID, database.BookingStatusConfirmed, services.ByAdmin(c.Sender().ID)); err != nil {
+		return fmt.Errorf("failed to confirm booking: %w", err)
 	}
-
-	// Update booking status
 	booking.Status = database.BookingStatusConfirmed
-	if err := database.DB.WithContext(ctx).Save(&booking).Error; err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка при обновлении записи"})
-	}
 
 	// Send confirmation to user
 	if err := b.notificationService.SendBookingConfirmation(ctx, &booking); err != nil {
-		fmt.Printf("Warning: failed to send confirmation to user: %v\n", err)
+		slog.ErrorContext(ctx, "Failed to send confirmation to user", "booking_id", bookingID, "error", err)
 	}
 
 	// Update admin message
@@ -603,14 +528,14 @@ func (b *Bot) handleAdminApproveBooking(ctx context.Context, c tele.Context, boo
 			"👤 %s %s (@%s)\n"+
 			"📋 %s\n"+
 			"📆 %s в %s\n"+
-			"💰 %d руб.",
-		booking.User.FirstName,
-		booking.User.LastName,
-		booking.User.Username,
-		booking.Service.Name,
+			"💰 %s",
+		html.EscapeString(booking.User.FirstName),
+		html.EscapeString(booking.User.LastName),
+		html.EscapeString(booking.User.Username),
+		html.EscapeString(booking.Service.Name),
 		booking.Date.Format("02.01.2006"),
 		booking.Time,
-		booking.Service.Price/100,
+		i18n.Price(i18n.Default, services.PaidPrice(&booking)),
 	)
 
 	c.Respond(&tele.CallbackResponse{Text: "✅ Запись подтверждена"})
@@ -618,123 +543,79 @@ func (b *Bot) handleAdminApproveBooking(ctx context.Context, c tele.Context, boo
 }
 
 // handleAdminRejectBooking handles admin rejection of a booking
-func (b *Bot) handleAdminRejectBooking(ctx context.Context, c tele.Context, bookingIDStr string) error {
-	if !b.isAdmin(c.Sender().ID) {
-		return c.Respond(&tele.CallbackResponse{Text: "❌ Нет доступа"})
-	}
-
-	bookingID, err := strconv.ParseUint(bookingIDStr, 10, 32)
-	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка обработки"})
-	}
-
+func (b *Bot) handleAdminRejectBooking(ctx context.Context, c tele.Context, bookingID uint) error {
 	// Get booking
 	var booking database.Booking
 	if err := database.DB.WithContext(ctx).
 		Preload("Service").
 		Preload("User").
 		First(&booking, bookingID).Error; err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Запись не найдена"})
+		return fmt.Errorf("failed to get booking: %w", err)
 	}
 
-	// Check if already processed
-	if booking.Status != database.BookingStatusPending {
-		return c.Respond(&tele.CallbackResponse{Text: "Запись уже обработана"})
-	}
-
-	// Update booking status
-	booking.Status = database.BookingStatusCancelled
-	if err := database.DB.WithContext(ctx).Save(&booking).Error; err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка при обновлении записи"})
+	// Update booking status, a booking processed in the meantime fails with ErrBookingTransition
+	if err := b.adminService.UpdateBookingStatus(ctx, booking.ID, database.BookingStatusRejected, services.ByAdmin(c.Sender().ID)); err != nil {
+		return fmt.Errorf("failed to reject booking: %w", err)
 	}
+	booking.Status = database.BookingStatusRejected
 
 	// Send rejection notification to user
-	userMsg := fmt.Sprintf(
-		"❌ <b>Ваша запись была отменена администратором</b>\n\n"+
-			"📋 Услуга: %s\n"+
-			"📆 Дата: %s в %s\n\n"+
-			"Вы можете создать новую запись через каталог услуг",
-		booking.Service.Name,
-		booking.Date.Format("02.01.2006"),
-		booking.Time,
-	)
-
-	recipient := &tele.User{ID: booking.UserID}
-	if _, err := b.tg.Send(recipient, userMsg, &tele.SendOptions{ParseMode: tele.ModeHTML}); err != nil {
-		fmt.Printf("Warning: failed to send rejection notification to user: %v\n", err)
+	if err := b.notificationService.SendBookingRejection(ctx, &booking); err != nil {
+		slog.ErrorContext(ctx, "Failed to send rejection notification to user", "booking_id", bookingID, "error", err)
 	}
 
 	// Update admin message
 	updatedMsg := fmt.Sprintf(
-		"❌ <b>Запись отменена</b>\n\n"+
+		"🚫 <b>Запись отклонена</b>\n\n"+
 			"👤 %s %s (@%s)\n"+
 			"📋 %s\n"+
 			"📆 %s в %s\n"+
-			"💰 %d руб.",
-		booking.User.FirstName,
-		booking.User.LastName,
-		booking.User.Username,
-		booking.Service.Name,
+			"💰 %s",
+		html.EscapeString(booking.User.FirstName),
+		html.EscapeString(booking.User.LastName),
+		html.EscapeString(booking.User.Username),
+		html.EscapeString(booking.Service.Name),
 		booking.Date.Format("02.01.2006"),
 		booking.Time,
-		booking.Service.Price/100,
+		i18n.Price(i18n.Default, services.PaidPrice(&booking)),
 	)
 
-	c.Respond(&tele.CallbackResponse{Text: "❌ Запись отменена"})
+	c.Respond(&tele.CallbackResponse{Text: "🚫 Запись отклонена"})
 	return c.Edit(updatedMsg, &tele.SendOptions{ParseMode: tele.ModeHTML})
 }
 
 // handleCatalogService shows service details from catalog
-func (b *Bot) handleCatalogService(ctx context.Context, c tele.Context, serviceIDStr string) error {
-	serviceID, err := strconv.ParseUint(serviceIDStr, 10, 32)
-	if err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка выбора услуги"})
-	}
-
+func (b *Bot) handleCatalogService(ctx context.Context, c tele.Context, serviceID uint) error {
+	lang := b.lang(c)
 	// Get service info
 	var service database.Service
 	if err := database.DB.First(&service, serviceID).Error; err != nil {
-		return c.Respond(&tele.CallbackResponse{Text: "Ошибка загрузки услуги"})
+		return c.Respond(&tele.CallbackResponse{Text: i18n.T(lang, "error.load_service")})
 	}
 
-	// Debug: log detailed description
-	fmt.Printf("DEBUG: Catalog Service %d - DetailedDescription length: %d\n", service.ID, len(service.DetailedDescription))
-	if service.DetailedDescription != "" {
-		previewLen := 50
-		if len(service.DetailedDescription) < previewLen {
-			previewLen = len(service.DetailedDescription)
-		}
-		fmt.Printf("DEBUG: DetailedDescription content: %s\n", service.DetailedDescription[:previewLen])
-	}
+	slog.DebugContext(ctx, "Catalog service opened", "service_id", service.ID, "detailed_description_length", len(service.DetailedDescription))
 
 	// Build service details message
-	serviceMsg := fmt.Sprintf(
-		"📋 <b>%s</b>\n\n"+
-			"📝 <b>Описание:</b>\n%s\n\n",
-		service.Name,
-		service.Description,
-	)
+	serviceMsg := i18n.T(lang, "service.header", service.Name, service.Description)
 
 	// Add detailed description if available
 	if service.DetailedDescription != "" {
-		serviceMsg += fmt.Sprintf(
-			"📖 <b>Подробное описание:</b>\n%s\n\n",
-			service.DetailedDescription,
-		)
+		serviceMsg += i18n.T(lang, "service.detailed", service.DetailedDescription)
 	} else {
 		// Show message if no detailed description
-		serviceMsg += "ℹ️ <i>Подробное описание отсутствует</i>\n\n"
+		serviceMsg += i18n.T(lang, "service.no_detailed")
 	}
 
-	serviceMsg += fmt.Sprintf(
-		"⏱ Длительность: <b>%d минут</b>\n"+
-			"💰 Стоимость: <b>%d руб.</b>",
-		service.Duration,
-		service.Price/100,
-	)
+	serviceMsg += i18n.T(lang, "service.duration_price", service.Duration, i18n.Price(lang, service.Price))
 
-	return c.Edit(serviceMsg, &tele.SendOptions{
+	// Show average rating if the service has reviews
+	if rating, err := b.reviewService.GetServiceRating(ctx, service.ID); err == nil && rating.Count > 0 {
+		serviceMsg += i18n.T(lang, "service.rating", rating.Average, i18n.N(lang, "reviews", rating.Count))
+	}
+
+	// EditOrSend allows opening the service from deep links as well
+	return c.EditOrSend(serviceMsg, &tele.SendOptions{
 		ParseMode:   tele.ModeHTML,
-		ReplyMarkup: getServiceDetailsKeyboard(uint(serviceID), true),
+		ReplyMarkup: getServiceDetailsKeyboard(lang, serviceID, true),
 	})
 }
@@ -0,0 +1,307 @@
+// Package bot contains gift certificate handlers for clients
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"gobot/internal/callback"
+	"gobot/internal/database"
+	"gobot/internal/i18n"
+	"gobot/internal/services"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// giftInvoicePrefix is the invoice payload prefix for certificate purchases
+const giftInvoicePrefix = "gift|"
+
+// purchasableCertificateAmounts lists nominal values (in rubles) clients can buy
+var purchasableCertificateAmounts = []int{2000, 3000, 5000, 10000}
+
+// purchasedCertificateValidity is how long a purchased certificate stays valid
+const purchasedCertificateValidity = 12 // months
+
+// handleGiftAction handles client gift certificate actions
+// args: the action, "buy" is followed by the amount in rubles
+func (b *Bot) handleGiftAction(ctx context.Context, c tele.Context, data callback.Data) error {
+	lang := b.lang(c)
+
+	switch action := data.Arg(0); action {
+	case "menu":
+		return b.handleGiftMenu(c)
+	case "enter_code":
+		state := b.getUserState(c.Sender().ID)
+		state.EditMode = "certificate_code"
+
+		markup := &tele.ReplyMarkup{}
+		btnCancel := callback.Button(i18n.T(lang, "button.cancel"), "gift", "cancel_code")
+		markup.Inline(markup.Row(btnCancel))
+
+		return c.Edit(i18n.T(lang, "gift.enter_code"), markup)
+	case "cancel_code":
+		state := b.getUserState(c.Sender().ID)
+		state.EditMode = ""
+		if state.CurrentStep == "confirm" {
+			return b.showBookingConfirmation(ctx, c, state)
+		}
+		return b.handleGiftMenu(c)
+	case "remove":
+		state := b.getUserState(c.Sender().ID)
+		state.CertificateCode = ""
+		return b.showBookingConfirmation(ctx, c, state)
+	case "buy":
+		amount, err := data.Int(1)
+		if err != nil {
+			return invalidArg(err)
+		}
+		return b.sendCertificateInvoice(c, amount)
+	default:
+		return b.staleAction(c, data.Route, action)
+	}
+}
+
+// handleGiftMenu shows gift certificate options to the client
+func (b *Bot) handleGiftMenu(c tele.Context) error {
+	lang := b.lang(c)
+	msg := i18n.T(lang, "gift.menu")
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0)
+
+	if b.config.PaymentProviderToken != "" {
+		msg += i18n.T(lang, "gift.choose_amount")
+		for _, amount := range purchasableCertificateAmounts {
+			btn := callback.Button("💳 "+i18n.Price(lang, amount*100), "gift", "buy", fmt.Sprintf("%d", amount))
+			rows = append(rows, markup.Row(btn))
+		}
+	} else {
+		msg += i18n.T(lang, "gift.ask_admin")
+	}
+
+	btnCode := callback.Button(i18n.T(lang, "gift.button.check_code"), "gift", "enter_code")
+	btnMenu := callback.Button(i18n.T(lang, "menu.main"), "back_to_menu")
+	rows = append(rows, markup.Row(btnCode))
+	rows = append(rows, markup.Row(btnMenu))
+	markup.Inline(rows...)
+
+	return c.EditOrSend(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleCertificateCodeInput handles a certificate code typed by the client
+func (b *Bot) handleCertificateCodeInput(c tele.Context) error {
+	ctx := b.requestContext(c)
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = ""
+	lang := b.lang(c)
+
+	code := services.NormalizeCertificateCode(c.Text())
+
+	// Outside of the booking flow just show the certificate info
+	if state.CurrentStep != "confirm" || state.ServiceID == 0 {
+		certificate, err := b.certificateService.GetCertificateByCode(ctx, code)
+		if err != nil {
+			return c.Send("❌ " + certificateErrorText(lang, err))
+		}
+		return c.Send(formatCertificateInfo(lang, certificate), &tele.SendOptions{
+			ParseMode:   tele.ModeHTML,
+			ReplyMarkup: b.mainMenu(c),
+		})
+	}
+
+	if _, err := b.certificateService.ValidateForService(ctx, code, state.ServiceID); err != nil {
+		return c.Send("❌ " + certificateErrorText(lang, err))
+	}
+
+	state.CertificateCode = code
+	return b.showBookingConfirmation(ctx, c, state)
+}
+
+// sendCertificateInvoice sends a Telegram invoice for a certificate purchase
+func (b *Bot) sendCertificateInvoice(c tele.Context, amount int) error {
+	lang := b.lang(c)
+	if b.config.PaymentProviderToken == "" {
+		return c.Respond(&tele.CallbackResponse{Text: i18n.T(lang, "gift.payment_unavailable")})
+	}
+
+	invoice := tele.Invoice{
+		Title:       i18n.T(lang, "gift.invoice_title"),
+		Description: i18n.T(lang, "gift.invoice_description", i18n.Price(lang, amount*100), purchasedCertificateValidity),
+		Payload:     fmt.Sprintf("%s%d", giftInvoicePrefix, amount),
+		Currency:    b.config.PaymentCurrency,
+		Token:       b.config.PaymentProviderToken,
+		Prices:      []tele.Price{{Label: i18n.T(lang, "gift.invoice_label"), Amount: amount * 100}},
+		Total:       amount * 100,
+	}
+
+	_, err := invoice.Send(b.tg, c.Sender(), nil)
+	if err != nil {
+		slog.ErrorContext(b.requestContext(c), "Failed to send certificate invoice", "error", err)
+		return c.Respond(&tele.CallbackResponse{Text: i18n.T(lang, "gift.invoice_failed")})
+	}
+
+	return nil
+}
+
+// handleCheckout confirms pre-checkout queries for certificate purchases
+func (b *Bot) handleCheckout(c tele.Context) error {
+	query := c.PreCheckoutQuery()
+	if query == nil || !strings.HasPrefix(query.Payload, giftInvoicePrefix) {
+		return c.Accept(i18n.T(b.lang(c), "gift.unknown_order"))
+	}
+	return c.Accept()
+}
+
+// handlePayment issues a certificate after a successful payment
+func (b *Bot) handlePayment(c tele.Context) error {
+	payment := c.Message().Payment
+	if payment == nil || !strings.HasPrefix(payment.Payload, giftInvoicePrefix) {
+		return nil
+	}
+
+	ctx := b.requestContext(c)
+	lang := b.lang(c)
+	expiresAt := time.Now().AddDate(0, purchasedCertificateValidity, 0)
+
+	certificate, err := b.certificateService.IssuePurchasedCertificate(
+		ctx,
+		c.Sender().ID,
+		payment.Total,
+		expiresAt,
+		payment.TelegramChargeID,
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to issue purchased certificate", "charge_id", payment.TelegramChargeID, "error", err)
+		return c.Send(i18n.T(lang, "gift.issue_failed"))
+	}
+
+	// Let admins know about the purchase
+	adminMsg := fmt.Sprintf(
+		"🎁 <b>Куплен сертификат</b>\n\n"+
+			"👤 %s %s (@%s)\n"+
+			"🔑 %s\n"+
+			"💰 %d руб.",
+		html.EscapeString(c.Sender().FirstName),
+		html.EscapeString(c.Sender().LastName),
+		html.EscapeString(c.Sender().Username),
+		certificate.Code,
+		certificate.Amount/100,
+	)
+	b.notificationService.NotifyAdmins(ctx, adminMsg)
+
+	msg, markup := b.certificateShareMessage(lang, certificate)
+	return c.Send(i18n.T(lang, "gift.thanks")+msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// certificateDeepLink returns a link that opens the bot with the certificate code
+func (b *Bot) certificateDeepLink(certificate *database.GiftCertificate) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s%s", b.tg.Me.Username, giftStartPrefix, certificate.Code)
+}
+
+// certificateShareMessage builds a shareable certificate message with a share button
+func (b *Bot) certificateShareMessage(lang string, certificate *database.GiftCertificate) (string, *tele.ReplyMarkup) {
+	link := b.certificateDeepLink(certificate)
+	msg := formatCertificateInfo(lang, certificate) + i18n.T(lang, "gift.link", link)
+
+	shareText := i18n.T(lang, "gift.share_text")
+	shareURL := fmt.Sprintf(
+		"https://t.me/share/url?url=%s&text=%s",
+		url.QueryEscape(link),
+		url.QueryEscape(shareText),
+	)
+
+	markup := &tele.ReplyMarkup{}
+	btnShare := markup.URL(i18n.T(lang, "gift.button.share"), shareURL)
+	btnMenu := callback.Button(i18n.T(lang, "menu.main"), "back_to_menu")
+	markup.Inline(
+		markup.Row(btnShare),
+		markup.Row(btnMenu),
+	)
+
+	return msg, markup
+}
+
+// formatCertificateInfo formats certificate details for display
+func formatCertificateInfo(lang string, certificate *database.GiftCertificate) string {
+	msg := i18n.T(lang, "certificate.header", certificate.Code)
+
+	if certificate.ServiceID != nil && certificate.Service != nil {
+		msg += i18n.T(lang, "certificate.service", certificate.Service.Name)
+	} else {
+		msg += i18n.T(lang, "certificate.amount",
+			i18n.Price(lang, certificate.Amount),
+			i18n.Price(lang, certificate.Balance),
+		)
+	}
+
+	msg += i18n.T(lang, "certificate.footer",
+		i18n.Date(lang, certificate.ExpiresAt),
+		getCertificateStatusText(lang, certificate),
+	)
+
+	return msg
+}
+
+// getCertificateStatusText returns text for certificate status
+func getCertificateStatusText(lang string, certificate *database.GiftCertificate) string {
+	switch certificate.Status {
+	case database.GiftCertificateStatusActive:
+		if time.Now().After(certificate.ExpiresAt) {
+			return i18n.T(lang, "certificate.status.expired")
+		}
+		return i18n.T(lang, "certificate.status.active")
+	case database.GiftCertificateStatusUsed:
+		return i18n.T(lang, "certificate.status.used")
+	case database.GiftCertificateStatusVoided:
+		return i18n.T(lang, "certificate.status.voided")
+	default:
+		return i18n.T(lang, "certificate.status.unknown")
+	}
+}
+
+// getCertificateStatusEmoji returns emoji for certificate status
+func getCertificateStatusEmoji(certificate *database.GiftCertificate) string {
+	switch certificate.Status {
+	case database.GiftCertificateStatusActive:
+		if time.Now().After(certificate.ExpiresAt) {
+			return "⌛"
+		}
+		return "✅"
+	case database.GiftCertificateStatusUsed:
+		return "✔️"
+	case database.GiftCertificateStatusVoided:
+		return "🚫"
+	default:
+		return "❓"
+	}
+}
+
+// certificateErrorText returns a user-friendly message for certificate errors
+func certificateErrorText(lang string, err error) string {
+	switch {
+	case errors.Is(err, services.ErrCertificateNotFound):
+		return i18n.T(lang, "certificate.error.not_found")
+	case errors.Is(err, services.ErrCertificateExpired):
+		return i18n.T(lang, "certificate.error.expired")
+	case errors.Is(err, services.ErrCertificateInactive):
+		return i18n.T(lang, "certificate.error.inactive")
+	case errors.Is(err, services.ErrCertificateWrongService):
+		return i18n.T(lang, "certificate.error.wrong_service")
+	case errors.Is(err, services.ErrCertificateEmpty):
+		return i18n.T(lang, "certificate.error.empty")
+	default:
+		return i18n.T(lang, "certificate.error.generic")
+	}
+}
@@ -0,0 +1,199 @@
+// Package bot contains centralized handling of handler errors
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"gobot/internal/i18n"
+	"gobot/internal/logging"
+	"gobot/internal/services"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	// errorReportInterval limits reports of the same error to the error chat
+	errorReportInterval = 10 * time.Minute
+	// errorReportTimeout bounds sending a report, it must not hold up anything
+	errorReportTimeout = 30 * time.Second
+	// errorReportLimit keeps reports below the Telegram message limit
+	errorReportLimit = 3500
+)
+
+// panicError is a recovered handler panic
+type panicError struct {
+	value interface{}
+	stack []byte
+}
+
+// Error implements the error interface
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// callbackResponder answers a callback query at most once
+// Telegram rejects a second answer, so later calls are dropped instead of failing
+type callbackResponder struct {
+	tele.Context
+	answered bool
+}
+
+// Respond answers the callback unless it was already answered
+func (c *callbackResponder) Respond(resp ...*tele.CallbackResponse) error {
+	if c.answered {
+		return nil
+	}
+	c.answered = true
+	return c.Context.Respond(resp...)
+}
+
+// RespondText answers the callback with a notification
+func (c *callbackResponder) RespondText(text string) error {
+	return c.Respond(&tele.CallbackResponse{Text: text})
+}
+
+// RespondAlert answers the callback with an alert
+func (c *callbackResponder) RespondAlert(text string) error {
+	return c.Respond(&tele.CallbackResponse{Text: text, ShowAlert: true})
+}
+
+// errorMiddleware recovers panics, answers every callback exactly once and replies to failed updates
+// Domain errors are explained to the user and count as handled,
+// unexpected ones get a generic reply, are reported to the error chat and passed on to be logged
+func (b *Bot) errorMiddleware(next tele.HandlerFunc) tele.HandlerFunc {
+	return func(c tele.Context) (err error) {
+		if c.Callback() != nil {
+			responder := &callbackResponder{Context: c}
+			c = responder
+			defer func() {
+				// Stops the loading indicator of buttons whose handler answered nothing
+				if !responder.answered {
+					_ = responder.Respond()
+				}
+			}()
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				err = &panicError{value: r, stack: debug.Stack()}
+			}
+			if err != nil {
+				err = b.handleError(c, err)
+			}
+		}()
+
+		return next(c)
+	}
+}
+
+// handleError replies to the user about a failed update
+// Returns nil for domain errors and the error itself for unexpected ones
+func (b *Bot) handleError(c tele.Context, err error) error {
+	ctx := b.requestContext(c)
+	lang := b.lang(c)
+
+	if text, ok := services.UserErrorText(lang, err); ok {
+		b.replyError(ctx, c, text)
+		return nil
+	}
+
+	b.replyError(ctx, c, i18n.T(lang, "error.generic"))
+	b.reportError(ctx, c, err)
+	return err
+}
+
+// replyError tells the user an update failed
+// Callbacks are answered with a notification unless the handler already answered them
+func (b *Bot) replyError(ctx context.Context, c tele.Context, text string) {
+	var err error
+	if responder, ok := c.(*callbackResponder); ok && !responder.answered {
+		err = responder.Respond(&tele.CallbackResponse{Text: text})
+	} else if c.Sender() != nil && c.PreCheckoutQuery() == nil {
+		err = c.Send(text)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to reply about error", "error", err)
+	}
+}
+
+// errorReports remembers when errors were last reported to the error chat
+var errorReports = struct {
+	sync.Mutex
+	sent map[string]time.Time
+}{sent: make(map[string]time.Time)}
+
+// shouldReport reports whether an error wasn't sent to the error chat recently
+func shouldReport(key string, now time.Time) bool {
+	errorReports.Lock()
+	defer errorReports.Unlock()
+
+	for k, sent := range errorReports.sent {
+		if now.Sub(sent) >= errorReportInterval {
+			delete(errorReports.sent, k)
+		}
+	}
+	if _, ok := errorReports.sent[key]; ok {
+		return false
+	}
+	errorReports.sent[key] = now
+	return true
+}
+
+// reportError sends an unexpected error with the update it broke to the error chat
+// The same error is reported once per errorReportInterval so a broken handler can't flood the chat
+func (b *Bot) reportError(ctx context.Context, c tele.Context, err error) {
+	var stack []byte
+	if p, ok := err.(*panicError); ok {
+		stack = p.stack
+		slog.ErrorContext(ctx, "Handler panicked", "panic", fmt.Sprint(p.value), "stack", string(stack))
+	}
+
+	chatID := b.config.ErrorChatID
+	if chatID == 0 || b.outboxService == nil {
+		return
+	}
+	action := updateAction(c)
+	if !shouldReport(action+"\n"+err.Error(), time.Now()) {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("⚠️ <b>Ошибка обработки обновления</b>\n\n")
+	sb.WriteString(fmt.Sprintf("Действие: <code>%s</code>\n", html.EscapeString(action)))
+	if sender := c.Sender(); sender != nil {
+		sb.WriteString(fmt.Sprintf("Пользователь: <code>%d</code>", sender.ID))
+		if sender.Username != "" {
+			sb.WriteString(" @" + html.EscapeString(sender.Username))
+		}
+		sb.WriteString("\n")
+	}
+	if traceID := logging.TraceID(ctx); traceID != "" {
+		sb.WriteString(fmt.Sprintf("Trace ID: <code>%s</code>\n", traceID))
+	}
+	details := err.Error()
+	if len(stack) > 0 {
+		details += "\n\n" + string(stack)
+	}
+	if len(details) > errorReportLimit {
+		details = strings.ToValidUTF8(details[:errorReportLimit], "") + "…"
+	}
+	sb.WriteString("\n<pre>" + html.EscapeString(details) + "</pre>")
+	msg := sb.String()
+
+	// Sent directly, the database may be what failed, and the user's reply must not wait
+	reportCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), errorReportTimeout)
+	go func() {
+		defer cancel()
+		recipient := &tele.Chat{ID: chatID}
+		if _, err := b.outboxService.Send(reportCtx, recipient, msg, &tele.SendOptions{ParseMode: tele.ModeHTML}); err != nil {
+			slog.WarnContext(reportCtx, "Failed to report error to the error chat", "chat_id", chatID, "error", err)
+		}
+	}()
+}
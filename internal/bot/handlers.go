@@ -2,61 +2,104 @@
 package bot
 
 import (
-	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"gobot/internal/database"
+	"gobot/internal/i18n"
+	"gobot/internal/services"
 
 	tele "gopkg.in/telebot.v3"
 )
 
+// Deep link payload prefixes for /start
+const (
+	giftStartPrefix    = "gift_"    // Shared gift certificate
+	serviceStartPrefix = "service_" // Service card, used in broadcasts
+)
+
 // handleStart handles the /start command
 func (b *Bot) handleStart(c tele.Context) error {
-	ctx := context.Background()
+	ctx := b.requestContext(c)
 
 	// Ensure user exists in database
 	_, err := b.ensureUser(ctx, c.Sender())
 	if err != nil {
-		return c.Send("Произошла ошибка при регистрации. Попробуйте позже.")
+		return c.Send(i18n.T(b.lang(c), "error.registration"))
 	}
 
-	welcomeMsg := fmt.Sprintf(
-		"👋 Привет, %s!\n\n"+
-			"Добро пожаловать в систему записи на услуги массажа и депиляции.\n\n"+
-			"Выберите действие:",
-		c.Sender().FirstName,
-	)
+	// Handle deep links such as shared gift certificates
+	if payload := c.Message().Payload; payload != "" {
+		if handled, err := b.handleStartPayload(c, payload); handled {
+			return err
+		}
+	}
+
+	welcomeMsg := i18n.T(b.lang(c), "start.welcome", c.Sender().FirstName)
 
 	return c.Send(welcomeMsg, &tele.SendOptions{
 		ParseMode:   tele.ModeHTML,
-		ReplyMarkup: getMainMenuInlineKeyboard(b.isAdmin(c.Sender().ID)),
+		ReplyMarkup: b.mainMenu(c),
+	})
+}
+
+// handleStartPayload handles deep link payloads passed to /start
+// Returns true if the payload was handled
+func (b *Bot) handleStartPayload(c tele.Context, payload string) (bool, error) {
+	ctx := b.requestContext(c)
+
+	// Invite links for clients booked by admin by phone
+	if strings.HasPrefix(payload, claimStartPrefix) {
+		return true, b.handleClaimInvite(ctx, c, strings.TrimPrefix(payload, claimStartPrefix))
+	}
+
+	// Service links from broadcasts open the service card
+	if strings.HasPrefix(payload, serviceStartPrefix) {
+		serviceID, err := strconv.ParseUint(strings.TrimPrefix(payload, serviceStartPrefix), 10, 32)
+		if err != nil {
+			return true, fmt.Errorf("%w: invalid service link: %w", services.ErrValidation, err)
+		}
+		return true, b.handleCatalogService(ctx, c, uint(serviceID))
+	}
+
+	if !strings.HasPrefix(payload, giftStartPrefix) {
+		return false, nil
+	}
+
+	lang := b.lang(c)
+	code := strings.TrimPrefix(payload, giftStartPrefix)
+	certificate, err := b.certificateService.GetCertificateByCode(ctx, code)
+	if err != nil {
+		return true, c.Send("❌ " + certificateErrorText(lang, err))
+	}
+
+	// Remember the code so it is applied automatically at booking confirmation
+	state := b.getUserState(c.Sender().ID)
+	state.CertificateCode = certificate.Code
+
+	msg := formatCertificateInfo(lang, certificate) + "\n\n" + i18n.T(lang, "gift.auto_apply")
+
+	return true, c.Send(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: b.mainMenu(c),
 	})
 }
 
 // handleHelp handles the /help command
 func (b *Bot) handleHelp(c tele.Context) error {
-	helpMsg := "📋 <b>Справка:</b>\n\n" +
-		"<b>📋 Каталог услуг</b>\n" +
-		"Просмотр всех услуг с описаниями и запись\n\n" +
-		"<b>📅 Мои записи</b>\n" +
-		"Просмотр всех ваших записей\n\n" +
-		"<b>🎉 Акции</b>\n" +
-		"Просмотр текущих акций и скидок\n\n"
+	lang := b.lang(c)
+	helpMsg := i18n.T(lang, "help.text")
 
 	if b.isAdmin(c.Sender().ID) {
-		helpMsg += "<b>🔧 Админ-панель</b>\n" +
-			"Управление записями, услугами и акциями\n\n"
+		helpMsg += i18n.T(lang, "help.admin")
 	}
 
-	helpMsg += "По всем вопросам обращайтесь к администратору."
-
-	markup := &tele.ReplyMarkup{}
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
-	markup.Inline(markup.Row(btnMenu))
+	helpMsg += i18n.T(lang, "help.contact")
 
 	return c.Send(helpMsg, &tele.SendOptions{
 		ParseMode:   tele.ModeHTML,
-		ReplyMarkup: markup,
+		ReplyMarkup: getMainMenuButtonKeyboard(lang),
 	})
 }
 
@@ -68,35 +111,31 @@ func (b *Bot) handleBook(c tele.Context) error {
 
 // handleMyBookings handles the /my_bookings command
 func (b *Bot) handleMyBookings(c tele.Context) error {
-	ctx := context.Background()
+	ctx := b.requestContext(c)
+	lang := b.lang(c)
 
 	bookings, err := b.bookingService.GetUserBookings(ctx, c.Sender().ID)
 	if err != nil {
-		return c.Send("Ошибка при загрузке записей. Попробуйте позже.")
+		return c.Send(i18n.T(lang, "error.load_bookings"))
 	}
 
 	if len(bookings) == 0 {
-		return c.Send("У вас пока нет записей.\nИспользуйте каталог услуг для создания записи.")
+		return c.Send(i18n.T(lang, "bookings.empty"))
 	}
 
-	msg := "📅 <b>Ваши записи:</b>\n\n"
+	msg := i18n.T(lang, "bookings.title")
 	for i, booking := range bookings {
 		statusEmoji := getStatusEmoji(booking.Status)
-		msg += fmt.Sprintf(
-			"%d. %s <b>%s</b>\n"+
-				"   📍 %s\n"+
-				"   📆 %s в %s\n"+
-				"   💰 %d руб.\n"+
-				"   %s %s\n\n",
+		msg += i18n.T(lang, "bookings.item",
 			i+1,
 			statusEmoji,
 			booking.Service.Name,
 			booking.Service.Description,
-			booking.Date.Format("02.01.2006"),
+			i18n.Date(lang, booking.Date),
 			booking.Time,
-			booking.Service.Price/100,
+			i18n.Price(lang, services.PaidPrice(&booking)),
 			statusEmoji,
-			getStatusText(booking.Status),
+			i18n.T(lang, "status."+string(booking.Status)),
 		)
 	}
 
@@ -107,11 +146,12 @@ func (b *Bot) handleMyBookings(c tele.Context) error {
 
 // handleCancelStart handles the /cancel command
 func (b *Bot) handleCancelStart(c tele.Context) error {
-	ctx := context.Background()
+	ctx := b.requestContext(c)
+	lang := b.lang(c)
 
 	bookings, err := b.bookingService.GetUserBookings(ctx, c.Sender().ID)
 	if err != nil {
-		return c.Send("Ошибка при загрузке записей. Попробуйте позже.")
+		return c.Send(i18n.T(lang, "error.load_bookings"))
 	}
 
 	// Filter only active bookings
@@ -123,30 +163,32 @@ func (b *Bot) handleCancelStart(c tele.Context) error {
 	}
 
 	if len(activeBookings) == 0 {
-		return c.Send("У вас нет активных записей для отмены.")
+		return c.Send(i18n.T(lang, "cancel.empty"))
 	}
 
 	return c.Send(
-		"❌ Выберите запись для отмены:",
-		getCancelBookingsKeyboard(activeBookings),
+		i18n.T(lang, "cancel.choose"),
+		getCancelBookingsKeyboard(lang, activeBookings),
 	)
 }
 
 // handleAdmin handles the /admin command
 func (b *Bot) handleAdmin(c tele.Context) error {
 	if !b.isAdmin(c.Sender().ID) {
-		return c.Send("❌ У вас нет доступа к админ-панели.")
+		return c.Send(i18n.T(b.lang(c), "error.no_admin_access"))
 	}
 
 	adminMsg := "🔧 <b>Админ-панель</b>\n\n" +
 		"Доступные функции:\n" +
 		"• Просмотр всех записей\n" +
 		"• Управление услугами\n" +
-		"• Управление временными слотами\n"
+		"• Управление временными слотами\n" +
+		"• Подарочные сертификаты\n" +
+		"• Модерация отзывов\n"
 
 	return c.Send(adminMsg, &tele.SendOptions{
 		ParseMode:   tele.ModeHTML,
-		ReplyMarkup: getAdminKeyboard(),
+		ReplyMarkup: getAdminKeyboard(b.isOwner(c.Sender().ID)),
 	})
 }
 
@@ -161,6 +203,10 @@ func getStatusEmoji(status database.BookingStatus) string {
 		return "❌"
 	case database.BookingStatusCompleted:
 		return "✔️"
+	case database.BookingStatusNoShow:
+		return "🚷"
+	case database.BookingStatusRejected:
+		return "🚫"
 	default:
 		return "❓"
 	}
@@ -177,6 +223,10 @@ func getStatusText(status database.BookingStatus) string {
 		return "Отменено"
 	case database.BookingStatusCompleted:
 		return "Завершено"
+	case database.BookingStatusNoShow:
+		return "Клиент не пришел"
+	case database.BookingStatusRejected:
+		return "Отклонено"
 	default:
 		return "Неизвестно"
 	}
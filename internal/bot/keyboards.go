@@ -5,64 +5,60 @@ import (
 	"fmt"
 	"time"
 
+	"gobot/internal/callback"
 	"gobot/internal/database"
+	"gobot/internal/i18n"
 
 	tele "gopkg.in/telebot.v3"
 )
 
-// getRussianWeekday returns Russian name of weekday
-func getRussianWeekday(t time.Time) string {
-	weekdays := map[time.Weekday]string{
-		time.Sunday:    "Вс",
-		time.Monday:    "Пн",
-		time.Tuesday:   "Вт",
-		time.Wednesday: "Ср",
-		time.Thursday:  "Чт",
-		time.Friday:    "Пт",
-		time.Saturday:  "Сб",
-	}
-	return weekdays[t.Weekday()]
-}
-
 // getMainMenuInlineKeyboard returns the main menu inline keyboard
-func getMainMenuInlineKeyboard(isAdmin bool) *tele.ReplyMarkup {
+// webAppURL adds the Mini App booking button when set
+func getMainMenuInlineKeyboard(lang string, isAdmin bool, webAppURL string) *tele.ReplyMarkup {
 	markup := &tele.ReplyMarkup{}
 
-	btnCatalog := markup.Data("📋 Каталог услуг", "main_menu", "catalog")
-	btnMyBookings := markup.Data("📅 Мои записи", "main_menu", "my_bookings")
-	btnDiscounts := markup.Data("🎉 Акции", "main_menu", "discounts")
-	btnHelp := markup.Data("❓ Помощь", "main_menu", "help")
+	btnCatalog := callback.Button(i18n.T(lang, "menu.catalog"), "main_menu", "catalog")
+	btnMyBookings := callback.Button(i18n.T(lang, "menu.my_bookings"), "main_menu", "my_bookings")
+	btnDiscounts := callback.Button(i18n.T(lang, "menu.discounts"), "main_menu", "discounts")
+	btnGift := callback.Button(i18n.T(lang, "menu.gift"), "gift", "menu")
+	btnHelp := callback.Button(i18n.T(lang, "menu.help"), "main_menu", "help")
+	btnProfile := callback.Button(i18n.T(lang, "menu.profile"), "profile", "view")
+
+	rows := []tele.Row{markup.Row(btnCatalog)}
+	if webAppURL != "" {
+		rows = append(rows, markup.Row(markup.WebApp(i18n.T(lang, "menu.webapp"), &tele.WebApp{URL: webAppURL})))
+	}
+	rows = append(rows,
+		markup.Row(btnMyBookings, btnProfile),
+		markup.Row(btnDiscounts, btnGift),
+	)
 
 	if isAdmin {
-		btnAdmin := markup.Data("🔧 Админ-панель", "main_menu", "admin")
-		btnAdminDiscounts := markup.Data("🎉 Управление акциями", "admin_discounts", "main")
-		markup.Inline(
-			markup.Row(btnCatalog),
-			markup.Row(btnMyBookings),
-			markup.Row(btnDiscounts),
-			markup.Row(btnAdmin, btnAdminDiscounts),
-			markup.Row(btnHelp),
-		)
-	} else {
-		markup.Inline(
-			markup.Row(btnCatalog),
-			markup.Row(btnMyBookings),
-			markup.Row(btnDiscounts),
-			markup.Row(btnHelp),
-		)
+		btnAdmin := callback.Button(i18n.T(lang, "menu.admin"), "main_menu", "admin")
+		btnAdminDiscounts := callback.Button(i18n.T(lang, "menu.admin_discounts"), "admin_discounts", "main")
+		rows = append(rows, markup.Row(btnAdmin, btnAdminDiscounts))
 	}
+	rows = append(rows, markup.Row(btnHelp))
 
+	markup.Inline(rows...)
+	return markup
+}
+
+// getMainMenuButtonKeyboard returns keyboard with a single main menu button
+func getMainMenuButtonKeyboard(lang string) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(callback.Button(i18n.T(lang, "menu.main"), "back_to_menu")))
 	return markup
 }
 
 // getServicesKeyboard returns keyboard with available services for booking
-func getServicesKeyboard(services []database.Service) *tele.ReplyMarkup {
+func getServicesKeyboard(lang string, services []database.Service) *tele.ReplyMarkup {
 	markup := &tele.ReplyMarkup{}
 	rows := make([]tele.Row, 0)
 
 	for _, service := range services {
-		btn := markup.Data(
-			fmt.Sprintf("%s - %d руб.", service.Name, service.Price/100),
+		btn := callback.Button(
+			fmt.Sprintf("%s - %s", service.Name, i18n.Price(lang, service.Price)),
 			"service",
 			fmt.Sprintf("%d", service.ID),
 		)
@@ -70,11 +66,11 @@ func getServicesKeyboard(services []database.Service) *tele.ReplyMarkup {
 	}
 
 	// Add cancel button
-	btnCancel := markup.Data("❌ Отмена", "cancel", "booking")
+	btnCancel := callback.Button(i18n.T(lang, "button.cancel"), "cancel", "booking")
 	rows = append(rows, markup.Row(btnCancel))
 
 	// Add main menu button
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnMenu := callback.Button(i18n.T(lang, "menu.main"), "back_to_menu")
 	rows = append(rows, markup.Row(btnMenu))
 
 	markup.Inline(rows...)
@@ -82,12 +78,12 @@ func getServicesKeyboard(services []database.Service) *tele.ReplyMarkup {
 }
 
 // getServicesCatalogKeyboard returns keyboard for services catalog
-func getServicesCatalogKeyboard(services []database.Service) *tele.ReplyMarkup {
+func getServicesCatalogKeyboard(lang string, services []database.Service) *tele.ReplyMarkup {
 	markup := &tele.ReplyMarkup{}
 	rows := make([]tele.Row, 0)
 
 	for _, service := range services {
-		btn := markup.Data(
+		btn := callback.Button(
 			fmt.Sprintf("📋 %s", service.Name),
 			"catalog_service",
 			fmt.Sprintf("%d", service.ID),
@@ -96,7 +92,7 @@ func getServicesCatalogKeyboard(services []database.Service) *tele.ReplyMarkup {
 	}
 
 	// Add main menu button
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnMenu := callback.Button(i18n.T(lang, "menu.main"), "back_to_menu")
 	rows = append(rows, markup.Row(btnMenu))
 
 	markup.Inline(rows...)
@@ -104,20 +100,20 @@ func getServicesCatalogKeyboard(services []database.Service) *tele.ReplyMarkup {
 }
 
 // getServiceDetailsKeyboard returns keyboard for service details view
-func getServiceDetailsKeyboard(serviceID uint, showBookButton bool) *tele.ReplyMarkup {
+func getServiceDetailsKeyboard(lang string, serviceID uint, showBookButton bool) *tele.ReplyMarkup {
 	markup := &tele.ReplyMarkup{}
 	rows := make([]tele.Row, 0)
 
 	if showBookButton {
-		btnBook := markup.Data("📝 Записаться на эту услугу", "service", fmt.Sprintf("%d", serviceID))
+		btnBook := callback.Button(i18n.T(lang, "button.book_service"), "service", fmt.Sprintf("%d", serviceID))
 		rows = append(rows, markup.Row(btnBook))
 	}
 
-	btnBack := markup.Data("⬅️ Назад к каталогу", "main_menu", "catalog")
+	btnBack := callback.Button(i18n.T(lang, "button.back_to_catalog"), "main_menu", "catalog")
 	rows = append(rows, markup.Row(btnBack))
 
 	// Add main menu button
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnMenu := callback.Button(i18n.T(lang, "menu.main"), "back_to_menu")
 	rows = append(rows, markup.Row(btnMenu))
 
 	markup.Inline(rows...)
@@ -125,16 +121,15 @@ func getServiceDetailsKeyboard(serviceID uint, showBookButton bool) *tele.ReplyM
 }
 
 // getDateKeyboard returns keyboard with available dates
-func getDateKeyboard() *tele.ReplyMarkup {
+func getDateKeyboard(lang string) *tele.ReplyMarkup {
 	markup := &tele.ReplyMarkup{}
 	rows := make([]tele.Row, 0)
 
 	// Generate next 7 days
 	for i := 0; i < 7; i++ {
 		date := getNextAvailableDate(i)
-		weekday := getRussianWeekday(date)
-		btn := markup.Data(
-			fmt.Sprintf("%s (%s)", date.Format("02.01.2006"), weekday),
+		btn := callback.Button(
+			fmt.Sprintf("%s (%s)", i18n.Date(lang, date), i18n.Weekday(lang, date)),
 			"date",
 			date.Format("2006-01-02"),
 		)
@@ -142,83 +137,26 @@ func getDateKeyboard() *tele.ReplyMarkup {
 	}
 
 	// Add back and cancel buttons
-	btnBack := markup.Data("⬅️ Назад", "back", "services")
-	btnCancel := markup.Data("❌ Отмена", "cancel", "booking")
+	btnBack := callback.Button(i18n.T(lang, "button.back"), "back", "services")
+	btnCancel := callback.Button(i18n.T(lang, "button.cancel"), "cancel", "booking")
 	rows = append(rows, markup.Row(btnBack, btnCancel))
 
 	// Add main menu button
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnMenu := callback.Button(i18n.T(lang, "menu.main"), "back_to_menu")
 	rows = append(rows, markup.Row(btnMenu))
 
 	markup.Inline(rows...)
 	return markup
 }
 
-// getTimeKeyboard returns keyboard with available time slots for a specific date
-// Filters out past times and already booked slots
-func getTimeKeyboard(selectedDate time.Time, serviceDuration int) *tele.ReplyMarkup {
+// getTimeKeyboard returns keyboard with the available time slots of a date
+func getTimeKeyboard(lang string, availableSlots []string) *tele.ReplyMarkup {
 	markup := &tele.ReplyMarkup{}
 	rows := make([]tele.Row, 0)
 
-	// Default time slots (можно расширить на основе TimeSlots из БД)
-	// Исключены: 17:00, 18:00, 20:00 - не работаем в это время
-	allTimeSlots := []string{
-		"09:00", "10:00", "11:00", "12:00",
-		"13:00", "14:00", "15:00", "16:00",
-		"19:00",
-	}
-
-	now := time.Now()
-	nowLocation := now.Location()
-
-	// Normalize selected date to same location and timezone
-	selectedDateNormalized := time.Date(
-		selectedDate.Year(), selectedDate.Month(), selectedDate.Day(),
-		0, 0, 0, 0, nowLocation,
-	)
-
-	// Get today's date normalized
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, nowLocation)
-
-	// Check if selected date is today
-	isToday := selectedDateNormalized.Equal(today)
-
-	// Filter available time slots
-	availableSlots := make([]string, 0)
-	for _, timeSlot := range allTimeSlots {
-		// Parse time
-		slotTime, err := time.Parse("15:04", timeSlot)
-		if err != nil {
-			continue
-		}
-
-		// Combine date and time using now's location
-		// Use normalized date to ensure correct timezone
-		slotDateTime := time.Date(
-			selectedDateNormalized.Year(), selectedDateNormalized.Month(), selectedDateNormalized.Day(),
-			slotTime.Hour(), slotTime.Minute(), 0, 0, nowLocation,
-		)
-
-		// Check if time is in the past (only for today)
-		if isToday {
-			// Check if the slot time is before current time (with 1 minute buffer for safety)
-			if slotDateTime.Before(now.Add(1 * time.Minute)) {
-				continue // Skip past times
-			}
-		}
-
-		// Check if slot is already booked
-		// Use normalized date for consistency
-		if isTimeSlotBooked(timeSlot, selectedDateNormalized, serviceDuration) {
-			continue // Skip booked slots
-		}
-
-		availableSlots = append(availableSlots, timeSlot)
-	}
-
 	if len(availableSlots) == 0 {
 		// No available slots
-		markup.Inline(markup.Row(markup.Data("❌ Нет доступного времени", "no_time", "")))
+		markup.Inline(markup.Row(callback.Button(i18n.T(lang, "button.no_time"), "no_time")))
 		return markup
 	}
 
@@ -227,107 +165,40 @@ func getTimeKeyboard(selectedDate time.Time, serviceDuration int) *tele.ReplyMar
 		row := tele.Row{}
 		for j := 0; j < 3 && i+j < len(availableSlots); j++ {
 			timeSlot := availableSlots[i+j]
-			btn := markup.Data(timeSlot, "time", timeSlot)
+			btn := callback.Button(timeSlot, "time", timeSlot)
 			row = append(row, btn)
 		}
 		rows = append(rows, row)
 	}
 
 	// Add back and cancel buttons
-	btnBack := markup.Data("⬅️ Назад", "back", "date")
-	btnCancel := markup.Data("❌ Отмена", "cancel", "booking")
+	btnBack := callback.Button(i18n.T(lang, "button.back"), "back", "date")
+	btnCancel := callback.Button(i18n.T(lang, "button.cancel"), "cancel", "booking")
 	rows = append(rows, markup.Row(btnBack, btnCancel))
 
 	// Add main menu button
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnMenu := callback.Button(i18n.T(lang, "menu.main"), "back_to_menu")
 	rows = append(rows, markup.Row(btnMenu))
 
 	markup.Inline(rows...)
 	return markup
 }
 
-// getBookedTimesForDate returns list of booked times with their durations for a specific date
-func getBookedTimesForDate(date time.Time) []struct {
-	Time     string
-	Duration int
-} {
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
-
-	var bookings []database.Booking
-	database.DB.
-		Preload("Service").
-		Where("date >= ? AND date < ?", startOfDay, endOfDay).
-		Where("status IN ?", []database.BookingStatus{
-			database.BookingStatusPending,
-			database.BookingStatusConfirmed,
-		}).
-		Find(&bookings)
-
-	bookedSlots := make([]struct {
-		Time     string
-		Duration int
-	}, 0, len(bookings))
-	for _, booking := range bookings {
-		bookedSlots = append(bookedSlots, struct {
-			Time     string
-			Duration int
-		}{
-			Time:     booking.Time,
-			Duration: booking.Service.Duration,
-		})
-	}
-
-	return bookedSlots
-}
-
-// isTimeSlotBooked checks if a time slot conflicts with existing bookings
-// Takes into account service duration to check for overlaps
-func isTimeSlotBooked(timeSlot string, date time.Time, serviceDuration int) bool {
-	slotTime, err := time.Parse("15:04", timeSlot)
-	if err != nil {
-		return true // If we can't parse, consider it booked to be safe
-	}
-
-	slotDateTime := time.Date(
-		date.Year(), date.Month(), date.Day(),
-		slotTime.Hour(), slotTime.Minute(), 0, 0, date.Location(),
-	)
-	slotStart := slotDateTime
-	slotEnd := slotDateTime.Add(time.Duration(serviceDuration) * time.Minute)
-
-	bookedSlots := getBookedTimesForDate(date)
-	for _, booked := range bookedSlots {
-		bookedTime, err := time.Parse("15:04", booked.Time)
-		if err != nil {
-			continue
-		}
-
-		bookedDateTime := time.Date(
-			date.Year(), date.Month(), date.Day(),
-			bookedTime.Hour(), bookedTime.Minute(), 0, 0, date.Location(),
-		)
-		bookedEnd := bookedDateTime.Add(time.Duration(booked.Duration) * time.Minute)
-
-		// Check for overlap
-		if slotStart.Before(bookedEnd) && slotEnd.After(bookedDateTime) {
-			return true // Time slot is booked
-		}
-	}
-
-	return false
-}
-
 // getConfirmKeyboard returns keyboard for booking confirmation
-func getConfirmKeyboard() *tele.ReplyMarkup {
+func getConfirmKeyboard(lang string, hasCertificate bool) *tele.ReplyMarkup {
 	markup := &tele.ReplyMarkup{}
 
-	btnConfirm := markup.Data("✅ Подтвердить", "confirm", "booking")
-	btnCancel := markup.Data("❌ Отмена", "cancel", "booking")
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnConfirm := callback.Button(i18n.T(lang, "button.confirm"), "confirm", "booking")
+	btnCertificate := callback.Button(i18n.T(lang, "button.apply_certificate"), "gift", "enter_code")
+	if hasCertificate {
+		btnCertificate = callback.Button(i18n.T(lang, "button.remove_certificate"), "gift", "remove")
+	}
+	btnCancel := callback.Button(i18n.T(lang, "button.cancel"), "cancel", "booking")
+	btnMenu := callback.Button(i18n.T(lang, "menu.main"), "back_to_menu")
 
 	markup.Inline(
 		markup.Row(btnConfirm),
+		markup.Row(btnCertificate),
 		markup.Row(btnCancel),
 		markup.Row(btnMenu),
 	)
@@ -336,13 +207,13 @@ func getConfirmKeyboard() *tele.ReplyMarkup {
 }
 
 // getCancelBookingsKeyboard returns keyboard with user's bookings for cancellation
-func getCancelBookingsKeyboard(bookings []database.Booking) *tele.ReplyMarkup {
+func getCancelBookingsKeyboard(lang string, bookings []database.Booking) *tele.ReplyMarkup {
 	markup := &tele.ReplyMarkup{}
 	rows := make([]tele.Row, 0)
 
 	for _, booking := range bookings {
-		btn := markup.Data(
-			fmt.Sprintf("%s - %s %s", booking.Service.Name, booking.Date.Format("02.01"), booking.Time),
+		btn := callback.Button(
+			fmt.Sprintf("%s - %s %s", booking.Service.Name, i18n.ShortDate(lang, booking.Date), booking.Time),
 			"cancel_booking",
 			fmt.Sprintf("%d", booking.ID),
 		)
@@ -350,29 +221,48 @@ func getCancelBookingsKeyboard(bookings []database.Booking) *tele.ReplyMarkup {
 	}
 
 	// Add main menu button
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnMenu := callback.Button(i18n.T(lang, "menu.main"), "back_to_menu")
 	rows = append(rows, markup.Row(btnMenu))
 
 	markup.Inline(rows...)
 	return markup
 }
 
-// getAdminKeyboard returns admin panel keyboard
-func getAdminKeyboard() *tele.ReplyMarkup {
+// getAdminKeyboard returns admin panel keyboard, the audit log is shown to the owner only
+func getAdminKeyboard(isOwner bool) *tele.ReplyMarkup {
 	markup := &tele.ReplyMarkup{}
 
-	btnBookings := markup.Data("📋 Все записи", "admin", "bookings")
-	btnServices := markup.Data("🛠 Услуги", "admin", "services")
-	btnDiscounts := markup.Data("🎉 Акции", "admin", "discounts")
-	btnSlots := markup.Data("⏰ Временные слоты", "admin", "slots")
-	btnStats := markup.Data("📊 Статистика", "admin", "stats")
-
-	markup.Inline(
-		markup.Row(btnBookings),
+	btnBookings := callback.Button("📋 Все записи", "admin", "bookings")
+	btnNewBooking := callback.Button("➕ Новая запись", "admin", "new_booking")
+	btnServices := callback.Button("🛠 Услуги", "admin", "services")
+	btnDiscounts := callback.Button("🎉 Акции", "admin", "discounts")
+	btnSlots := callback.Button("⏰ Временные слоты", "admin", "slots")
+	btnStats := callback.Button("📊 Статистика", "admin", "stats")
+	btnCertificates := callback.Button("🎁 Сертификаты", "admin", "certificates")
+	btnReviews := callback.Button("⭐ Отзывы", "admin", "reviews")
+	btnBroadcast := callback.Button("📣 Рассылка", "admin", "broadcast")
+	btnClients := callback.Button("👥 Клиенты", "admin", "clients")
+	btnTemplates := callback.Button("📝 Шаблоны", "admin", "templates")
+	btnCalendar := callback.Button("📅 Календарь", "admin", "calendar")
+	btnExport := callback.Button("📤 Экспорт", "admin", "export")
+	btnAPIKeys := callback.Button("🔑 API", "admin", "api_keys")
+	btnDashboard := callback.Button("🖥 Веб-панель", "admin", "dashboard")
+	btnWebhooks := callback.Button("🪝 Вебхуки", "admin", "webhooks")
+
+	rows := []tele.Row{
+		markup.Row(btnBookings, btnNewBooking),
 		markup.Row(btnServices, btnDiscounts),
-		markup.Row(btnSlots),
-		markup.Row(btnStats),
-	)
+		markup.Row(btnSlots, btnCertificates),
+		markup.Row(btnStats, btnReviews),
+		markup.Row(btnBroadcast, btnClients),
+		markup.Row(btnTemplates, btnCalendar),
+		markup.Row(btnExport, btnAPIKeys),
+		markup.Row(btnDashboard, btnWebhooks),
+	}
+	if isOwner {
+		rows = append(rows, markup.Row(callback.Button("🧾 Журнал", "admin", "audit")))
+	}
+	markup.Inline(rows...)
 
 	return markup
 }
@@ -393,14 +283,14 @@ func getDiscountPercentageKeyboard() *tele.ReplyMarkup {
 		row := tele.Row{}
 		for j := 0; j < 3 && i+j < len(percentages); j++ {
 			pct := percentages[i+j]
-			btn := markup.Data(fmt.Sprintf("%d%%", pct), "admin_discount_set_percentage", fmt.Sprintf("%d", pct))
+			btn := callback.Button(fmt.Sprintf("%d%%", pct), "admin_discount_set_percentage", fmt.Sprintf("%d", pct))
 			row = append(row, btn)
 		}
 		rows = append(rows, row)
 	}
 
-	btnCancel := markup.Data("❌ Отмена", "admin_cancel_add_discount", "")
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnCancel := callback.Button("❌ Отмена", "admin_cancel_add_discount")
+	btnMenu := callback.Button("🏠 Главное меню", "back_to_menu")
 	rows = append(rows, markup.Row(btnCancel))
 	rows = append(rows, markup.Row(btnMenu))
 
@@ -426,7 +316,7 @@ func getDiscountStartDateKeyboard() *tele.ReplyMarkup {
 	}
 
 	for _, d := range dates {
-		btn := markup.Data(
+		btn := callback.Button(
 			fmt.Sprintf("%s (%s)", d.label, d.date.Format("02.01.2006")),
 			"admin_discount_set_start_date",
 			d.date.Format("02.01.2006"),
@@ -434,8 +324,8 @@ func getDiscountStartDateKeyboard() *tele.ReplyMarkup {
 		rows = append(rows, markup.Row(btn))
 	}
 
-	btnCancel := markup.Data("❌ Отмена", "admin_cancel_add_discount", "")
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnCancel := callback.Button("❌ Отмена", "admin_cancel_add_discount")
+	btnMenu := callback.Button("🏠 Главное меню", "back_to_menu")
 	rows = append(rows, markup.Row(btnCancel))
 	rows = append(rows, markup.Row(btnMenu))
 
@@ -462,7 +352,7 @@ func getDiscountEndDateKeyboard(startDate time.Time) *tele.ReplyMarkup {
 	}
 
 	for _, d := range dates {
-		btn := markup.Data(
+		btn := callback.Button(
 			fmt.Sprintf("%s (%s)", d.label, d.date.Format("02.01.2006")),
 			"admin_discount_set_end_date",
 			d.date.Format("02.01.2006"),
@@ -470,8 +360,8 @@ func getDiscountEndDateKeyboard(startDate time.Time) *tele.ReplyMarkup {
 		rows = append(rows, markup.Row(btn))
 	}
 
-	btnCancel := markup.Data("❌ Отмена", "admin_cancel_add_discount", "")
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
+	btnCancel := callback.Button("❌ Отмена", "admin_cancel_add_discount")
+	btnMenu := callback.Button("🏠 Главное меню", "back_to_menu")
 	rows = append(rows, markup.Row(btnCancel))
 	rows = append(rows, markup.Row(btnMenu))
 
@@ -3,63 +3,58 @@ package bot
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
 	"time"
 
 	"gobot/internal/database"
+	"gobot/internal/i18n"
 
 	tele "gopkg.in/telebot.v3"
 )
 
 // handleMainMenuAction handles main menu button clicks
 func (b *Bot) handleMainMenuAction(ctx context.Context, c tele.Context, action string) error {
-	fmt.Printf("🎯 Main menu action: %s\n", action)
-
 	switch action {
 	case "my_bookings":
-		fmt.Println("➡️ Calling handleMyBookings...")
 		c.Delete()
 		return b.handleMyBookings(c)
 	case "help":
-		fmt.Println("➡️ Calling handleHelp...")
 		c.Delete()
 		return b.handleHelp(c)
 	case "discounts":
-		fmt.Println("➡️ Calling handleDiscounts...")
 		c.Delete()
 		return b.handleDiscounts(c)
 	case "catalog":
-		fmt.Println("➡️ Calling handleCatalog...")
 		c.Delete()
 		return b.handleCatalog(c)
 	case "admin":
 		if b.isAdmin(c.Sender().ID) {
-			fmt.Println("➡️ Calling handleAdmin...")
 			c.Delete()
 			return b.handleAdmin(c)
 		}
-		fmt.Println("❌ User is not admin")
-		c.Send("❌ Нет доступа")
+		c.Send(i18n.T(b.lang(c), "error.no_access"))
 		return nil
 	default:
-		fmt.Printf("❌ Unknown action: %s\n", action)
-		c.Send("❌ Неизвестное действие")
+		slog.WarnContext(ctx, "Unknown main menu action", "action", action)
+		c.Send("❌ " + i18n.T(b.lang(c), "error.unknown_action"))
 		return nil
 	}
 }
 
 // handleBackToMainMenu returns user to main menu
 func (b *Bot) handleBackToMainMenu(ctx context.Context, c tele.Context) error {
-	welcomeMsg := "🏠 Главное меню\n\nВыберите действие:"
+	welcomeMsg := i18n.T(b.lang(c), "menu.title")
 
 	return c.Edit(welcomeMsg, &tele.SendOptions{
-		ReplyMarkup: getMainMenuInlineKeyboard(b.isAdmin(c.Sender().ID)),
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: b.mainMenu(c),
 	})
 }
 
 // handleDiscounts shows active discounts to user
 func (b *Bot) handleDiscounts(c tele.Context) error {
-	ctx := context.Background()
+	ctx := b.requestContext(c)
+	lang := b.lang(c)
 
 	// Get active discounts
 	var discounts []database.Discount
@@ -70,83 +65,61 @@ func (b *Bot) handleDiscounts(c tele.Context) error {
 		Find(&discounts).Error
 
 	if err != nil {
-		return c.Send("❌ Ошибка при загрузке акций. Попробуйте позже.")
+		return c.Send(i18n.T(lang, "error.load_discounts"))
 	}
 
 	if len(discounts) == 0 {
-		msg := "🎉 <b>Акции</b>\n\n" +
-			"К сожалению, сейчас нет активных акций.\n\n" +
-			"Следите за обновлениями! Мы регулярно проводим специальные предложения."
-
-		markup := &tele.ReplyMarkup{}
-		btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
-		markup.Inline(markup.Row(btnMenu))
-
-		return c.Send(msg, &tele.SendOptions{
+		return c.Send(i18n.T(lang, "discounts.empty"), &tele.SendOptions{
 			ParseMode:   tele.ModeHTML,
-			ReplyMarkup: markup,
+			ReplyMarkup: getMainMenuButtonKeyboard(lang),
 		})
 	}
 
-	msg := "🎉 <b>Актуальные акции:</b>\n\n"
+	msg := i18n.T(lang, "discounts.title")
 	for i, discount := range discounts {
-		originalPrice := discount.Service.Price / 100
-		discountAmount := (discount.Service.Price * discount.Percentage) / 10000
-		newPrice := originalPrice - discountAmount
-
-		msg += fmt.Sprintf(
-			"%d. <b>%s</b>\n"+
-				"   📋 Услуга: %s\n"+
-				"   💰 Скидка: %d%%\n"+
-				"   💵 Цена: <s>%d руб.</s> <b>%d руб.</b>\n"+
-				"   📅 Действует до: %s\n\n",
+		discountAmount := (discount.Service.Price * discount.Percentage) / 100
+
+		msg += i18n.T(lang, "discounts.item",
 			i+1,
 			discount.Name,
 			discount.Service.Name,
 			discount.Percentage,
-			originalPrice,
-			newPrice,
-			discount.EndDate.Format("02.01.2006"),
+			i18n.Price(lang, discount.Service.Price),
+			i18n.Price(lang, discount.Service.Price-discountAmount),
+			i18n.Date(lang, discount.EndDate),
 		)
 	}
 
-	msg += "💡 <i>Чтобы записаться на услугу со скидкой, выберите услугу при бронировании.</i>"
-
-	markup := &tele.ReplyMarkup{}
-	btnMenu := markup.Data("🏠 Главное меню", "back_to_menu", "")
-	markup.Inline(markup.Row(btnMenu))
+	msg += i18n.T(lang, "discounts.hint")
 
 	return c.Send(msg, &tele.SendOptions{
 		ParseMode:   tele.ModeHTML,
-		ReplyMarkup: markup,
+		ReplyMarkup: getMainMenuButtonKeyboard(lang),
 	})
 }
 
 // handleCatalog shows services catalog
 func (b *Bot) handleCatalog(c tele.Context) error {
-	ctx := context.Background()
+	ctx := b.requestContext(c)
+	lang := b.lang(c)
 
 	// Get all active services
 	services, err := b.bookingService.GetAvailableServices(ctx)
 	if err != nil {
-		return c.Send("❌ Ошибка при загрузке услуг. Попробуйте позже.")
+		return c.Send(i18n.T(lang, "error.load_services_retry"))
 	}
 
 	if len(services) == 0 {
-		return c.Send("К сожалению, сейчас нет доступных услуг.")
+		return c.Send(i18n.T(lang, "catalog.empty"))
 	}
 
-	msg := "📋 <b>КАТАЛОГ УСЛУГ</b>\n\n" +
-		"👇 <i>Нажмите на услугу, чтобы увидеть полное описание и записаться</i>\n\n"
+	msg := i18n.T(lang, "catalog.title")
 
 	for i, service := range services {
-		msg += fmt.Sprintf(
-			"<b>%d. %s</b>\n"+
-				"💰 %d руб. | ⏱ %d мин\n"+
-				"📝 %s\n\n",
+		msg += i18n.T(lang, "catalog.item",
 			i+1,
 			service.Name,
-			service.Price/100,
+			i18n.Price(lang, service.Price),
 			service.Duration,
 			service.Description,
 		)
@@ -154,6 +127,6 @@ func (b *Bot) handleCatalog(c tele.Context) error {
 
 	return c.Send(msg, &tele.SendOptions{
 		ParseMode:   tele.ModeHTML,
-		ReplyMarkup: getServicesCatalogKeyboard(services),
+		ReplyMarkup: getServicesCatalogKeyboard(lang, services),
 	})
 }
@@ -0,0 +1,245 @@
+// Package bot contains telebot middleware
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"gobot/internal/callback"
+	"gobot/internal/i18n"
+	"gobot/internal/logging"
+	"gobot/internal/metrics"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// rateLimiter is a per-user token bucket limiter
+type rateLimiter struct {
+	rate  float64 // Tokens added per second
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[int64]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket holds tokens left for a single user
+type tokenBucket struct {
+	tokens   float64
+	updated  time.Time
+	notified bool // User was already told to slow down
+}
+
+// newRateLimiter creates a limiter allowing perMinute requests with the given burst
+func newRateLimiter(perMinute, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:      float64(perMinute) / 60,
+		burst:     float64(burst),
+		buckets:   make(map[int64]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// allow takes a token from the user's bucket
+// Returns false and whether the user should be warned when the bucket is empty
+func (l *rateLimiter) allow(userID int64) (bool, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[userID]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, updated: now}
+		l.buckets[userID] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.updated).Seconds() * l.rate
+	if bucket.tokens > l.burst {
+		bucket.tokens = l.burst
+	}
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		warn := !bucket.notified
+		bucket.notified = true
+		return false, warn
+	}
+
+	bucket.tokens--
+	bucket.notified = false
+	return true, false
+}
+
+// sweep drops buckets that are full again once a minute
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for userID, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.updated).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, userID)
+		}
+	}
+}
+
+// rateLimitMiddleware throttles users who send updates too fast
+// Admins are never throttled
+func (b *Bot) rateLimitMiddleware(limiter *rateLimiter) tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			sender := c.Sender()
+			if sender == nil || b.isAdmin(sender.ID) {
+				return next(c)
+			}
+
+			// Payment updates must always be processed
+			if c.PreCheckoutQuery() != nil || (c.Message() != nil && c.Message().Payment != nil) {
+				return next(c)
+			}
+
+			allowed, warn := limiter.allow(sender.ID)
+			if allowed {
+				return next(c)
+			}
+
+			// Use the Telegram language only, throttled users shouldn't cost a database query
+			lang := i18n.Detect(sender.LanguageCode)
+			if c.Callback() != nil {
+				return c.Respond(&tele.CallbackResponse{Text: i18n.T(lang, "rate_limit.short")})
+			}
+			if warn {
+				return c.Send(i18n.T(lang, "rate_limit.long"))
+			}
+			return nil
+		}
+	}
+}
+
+// requestContextKey stores the context of an update in tele.Context
+const requestContextKey = "request_context"
+
+// languageKey stores the resolved language of the sender in tele.Context
+const languageKey = "language"
+
+// logMiddleware gives every update a trace ID and logs its user, action and latency
+// Records logged with the context of the update carry the same trace ID
+// Handler errors are logged here and not passed on to the default telebot error log
+func (b *Bot) logMiddleware(next tele.HandlerFunc) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		start := time.Now()
+		action := updateAction(c)
+
+		ctx := logging.With(context.Background(), "trace_id", logging.NewTraceID(), "update_id", c.Update().ID)
+		if sender := c.Sender(); sender != nil {
+			ctx = logging.With(ctx, "user_id", sender.ID)
+		}
+		c.Set(requestContextKey, ctx)
+
+		err := next(c)
+
+		attrs := []any{"action", action, "latency_ms", time.Since(start).Milliseconds()}
+		if err != nil {
+			slog.ErrorContext(ctx, "Update failed", append(attrs, "error", err)...)
+			return nil
+		}
+		slog.InfoContext(ctx, "Update handled", attrs...)
+		return nil
+	}
+}
+
+// updateAction describes an update for logs: the command, the callback data or the kind of message
+func updateAction(c tele.Context) string {
+	if cb := c.Callback(); cb != nil {
+		if data, ok := callback.Default().Peek(cb.Data); ok {
+			return "callback:" + strings.Join(append([]string{data.Route}, data.Args...), "|")
+		}
+		return "callback:" + strings.TrimPrefix(cb.Data, "\f")
+	}
+	if c.PreCheckoutQuery() != nil {
+		return "checkout"
+	}
+
+	msg := c.Message()
+	switch {
+	case msg == nil:
+		return "other"
+	case msg.Payment != nil:
+		return "payment"
+	case msg.Contact != nil:
+		return "contact"
+	case msg.Photo != nil:
+		return "photo"
+	case strings.HasPrefix(msg.Text, "/"):
+		command, _, _ := strings.Cut(msg.Text, " ")
+		return "command:" + command
+	case msg.Text != "":
+		return "text"
+	default:
+		return "message"
+	}
+}
+
+var (
+	updatesTotal = metrics.NewCounter("gobot_updates_total",
+		"Telegram updates handled by type and action", "type", "action")
+	updateErrorsTotal = metrics.NewCounter("gobot_update_errors_total",
+		"Telegram updates whose handler returned an error by type and action", "type", "action")
+	updateDuration = metrics.NewHistogram("gobot_update_duration_seconds",
+		"Handler latency of Telegram updates by type", metrics.DefaultBuckets, "type")
+)
+
+// metricCommands are commands counted by name, others are counted as "unknown"
+var metricCommands = map[string]bool{
+	"/start": true, "/help": true, "/book": true, "/my_bookings": true, "/cancel": true, "/admin": true,
+}
+
+// metricsMiddleware counts updates, their handler latency and errors
+// Registered after logMiddleware, so it sees errors before they are logged and swallowed
+func (b *Bot) metricsMiddleware(next tele.HandlerFunc) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		start := time.Now()
+		updateType, action := updateLabels(c)
+
+		err := next(c)
+
+		updatesTotal.Inc(updateType, action)
+		updateDuration.Observe(time.Since(start).Seconds(), updateType)
+		if err != nil {
+			updateErrorsTotal.Inc(updateType, action)
+		}
+		return err
+	}
+}
+
+// updateLabels returns the type and action of an update for metrics
+// Unlike updateAction it drops callback payloads and unknown commands to keep the number of series small
+func updateLabels(c tele.Context) (string, string) {
+	if cb := c.Callback(); cb != nil {
+		// Only verified payloads name a route, anything else could be arbitrary text sent by a client
+		data, err := callback.Default().Decode(cb.Data)
+		if err != nil {
+			return "callback", "stale"
+		}
+		return "callback", data.Route
+	}
+
+	action := updateAction(c)
+	if command, ok := strings.CutPrefix(action, "command:"); ok {
+		command, _, _ = strings.Cut(command, "@")
+		if !metricCommands[command] {
+			command = "unknown"
+		}
+		return "command", command
+	}
+	return action, ""
+}
@@ -0,0 +1,325 @@
+// Package bot contains client profile handlers
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gobot/internal/callback"
+	"gobot/internal/database"
+	"gobot/internal/i18n"
+	"gobot/internal/services"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// isSkipContactText reports whether text is the skip button of any language
+func isSkipContactText(text string) bool {
+	for _, lang := range i18n.Supported() {
+		if text == i18n.T(lang, "contact.skip") {
+			return true
+		}
+	}
+	return false
+}
+
+// handleProfileAction routes profile callbacks
+// args: the action, "lang" is followed by the language code
+func (b *Bot) handleProfileAction(ctx context.Context, c tele.Context, data callback.Data) error {
+	action := data.Arg(0)
+	state := b.getUserState(c.Sender().ID)
+	lang := b.lang(c)
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(callback.Button(i18n.T(lang, "button.back"), "profile", "view")))
+
+	// Language selection, "auto" returns to detection by Telegram settings
+	if action == "lang" {
+		code := data.Arg(1)
+		if code == "auto" {
+			code = ""
+		} else if !i18n.IsSupported(code) {
+			return b.staleAction(c, data.Route, "lang "+code)
+		}
+		return b.updateProfileAndShow(ctx, c, services.ProfileFieldLanguage, code)
+	}
+
+	switch action {
+	case "view":
+		state.EditMode = ""
+		return b.showProfile(ctx, c)
+
+	case "phone":
+		state.EditMode = "profile_phone"
+		c.Delete()
+		return c.Send(i18n.T(lang, "profile.phone_prompt"), getContactRequestKeyboard(lang))
+
+	case "birthday":
+		state.EditMode = "profile_birthday"
+		return c.Edit(i18n.T(lang, "profile.birthday_prompt"), &tele.SendOptions{
+			ParseMode:   tele.ModeHTML,
+			ReplyMarkup: markup,
+		})
+
+	case "allergies":
+		state.EditMode = "profile_allergies"
+		return c.Edit(i18n.T(lang, "profile.allergies_prompt"), markup)
+
+	case "specialist":
+		state.EditMode = "profile_specialist"
+		return c.Edit(i18n.T(lang, "profile.specialist_prompt"), markup)
+
+	case "language":
+		return c.Edit(i18n.T(lang, "profile.language_prompt"), getLanguageKeyboard(lang))
+
+	case "clear_allergies":
+		return b.updateProfileAndShow(ctx, c, services.ProfileFieldAllergies, nil)
+
+	case "clear_specialist":
+		return b.updateProfileAndShow(ctx, c, services.ProfileFieldSpecialist, nil)
+
+	case "clear_birthday":
+		return b.updateProfileAndShow(ctx, c, services.ProfileFieldBirthday, nil)
+
+	default:
+		return b.staleAction(c, data.Route, action)
+	}
+}
+
+// showProfile shows the client's profile with edit buttons
+func (b *Bot) showProfile(ctx context.Context, c tele.Context) error {
+	user, err := b.ensureUser(ctx, c.Sender())
+	if err != nil {
+		return c.EditOrSend(i18n.T(b.lang(c), "error.generic"))
+	}
+
+	lang := services.UserLanguage(user)
+	return c.EditOrSend(formatProfile(lang, user), &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: getProfileKeyboard(lang, user),
+	})
+}
+
+// formatProfile returns profile description
+func formatProfile(lang string, user *database.User) string {
+	notSet := i18n.T(lang, "profile.not_set")
+
+	phone := notSet
+	if user.Phone != "" {
+		phone = user.Phone
+	}
+
+	birthday := notSet
+	if user.Birthday != nil {
+		birthday = i18n.Date(lang, *user.Birthday)
+	}
+
+	allergies := notSet
+	if user.Allergies != "" {
+		allergies = user.Allergies
+	}
+
+	specialist := notSet
+	if user.Specialist != "" {
+		specialist = user.Specialist
+	}
+
+	language := i18n.T(lang, "language."+lang)
+	if user.Language == "" {
+		language = i18n.T(lang, "profile.language_auto", language)
+	}
+
+	return i18n.T(lang, "profile.text",
+		user.FirstName,
+		user.LastName,
+		phone,
+		birthday,
+		allergies,
+		specialist,
+		language,
+	)
+}
+
+// getProfileKeyboard returns keyboard for editing profile fields
+func getProfileKeyboard(lang string, user *database.User) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+
+	btnPhone := callback.Button(i18n.T(lang, "profile.button.phone"), "profile", "phone")
+	btnBirthday := callback.Button(i18n.T(lang, "profile.button.birthday"), "profile", "birthday")
+	btnAllergies := callback.Button(i18n.T(lang, "profile.button.allergies"), "profile", "allergies")
+	btnSpecialist := callback.Button(i18n.T(lang, "profile.button.specialist"), "profile", "specialist")
+	btnLanguage := callback.Button(i18n.T(lang, "profile.button.language"), "profile", "language")
+	btnMenu := callback.Button(i18n.T(lang, "menu.main"), "back_to_menu")
+
+	rows := []tele.Row{
+		markup.Row(btnPhone, btnBirthday),
+		markup.Row(btnAllergies, btnSpecialist),
+		markup.Row(btnLanguage),
+	}
+
+	clearRow := tele.Row{}
+	if user.Birthday != nil {
+		clearRow = append(clearRow, callback.Button(i18n.T(lang, "profile.button.clear_birthday"), "profile", "clear_birthday"))
+	}
+	if user.Allergies != "" {
+		clearRow = append(clearRow, callback.Button(i18n.T(lang, "profile.button.clear_allergies"), "profile", "clear_allergies"))
+	}
+	if user.Specialist != "" {
+		clearRow = append(clearRow, callback.Button(i18n.T(lang, "profile.button.clear_specialist"), "profile", "clear_specialist"))
+	}
+	if len(clearRow) > 0 {
+		rows = append(rows, clearRow)
+	}
+
+	rows = append(rows, markup.Row(btnMenu))
+	markup.Inline(rows...)
+	return markup
+}
+
+// getLanguageKeyboard returns keyboard for choosing the interface language
+func getLanguageKeyboard(lang string) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+
+	var rows []tele.Row
+	for _, code := range i18n.Supported() {
+		// Each language is named in itself so users can find theirs
+		rows = append(rows, markup.Row(callback.Button(i18n.T(code, "language."+code), "profile", "lang", code)))
+	}
+	rows = append(rows,
+		markup.Row(callback.Button(i18n.T(lang, "profile.button.language_auto"), "profile", "lang", "auto")),
+		markup.Row(callback.Button(i18n.T(lang, "button.back"), "profile", "view")),
+	)
+
+	markup.Inline(rows...)
+	return markup
+}
+
+// getContactRequestKeyboard returns reply keyboard asking to share the phone number
+func getContactRequestKeyboard(lang string) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{ResizeKeyboard: true, OneTimeKeyboard: true}
+
+	btnContact := markup.Contact(i18n.T(lang, "contact.share"))
+	btnSkip := markup.Text(i18n.T(lang, "contact.skip"))
+
+	markup.Reply(
+		markup.Row(btnContact),
+		markup.Row(btnSkip),
+	)
+
+	return markup
+}
+
+// updateProfileAndShow saves a profile field and shows the profile
+func (b *Bot) updateProfileAndShow(ctx context.Context, c tele.Context, field string, value interface{}) error {
+	if err := b.userService.UpdateProfileField(ctx, c.Sender().ID, field, value); err != nil {
+		slog.ErrorContext(ctx, "Failed to update profile", "field", field, "error", err)
+		return c.EditOrSend(i18n.T(b.lang(c), "error.save"))
+	}
+
+	if field == services.ProfileFieldLanguage {
+		// The language resolved earlier in this update is outdated now
+		c.Set(languageKey, nil)
+	}
+
+	b.getUserState(c.Sender().ID).EditMode = ""
+	return b.showProfile(ctx, c)
+}
+
+// handleProfileInput handles typed profile values
+func (b *Bot) handleProfileInput(c tele.Context) error {
+	ctx := b.requestContext(c)
+	state := b.getUserState(c.Sender().ID)
+	lang := b.lang(c)
+	text := strings.TrimSpace(c.Text())
+
+	switch state.EditMode {
+	case "profile_phone":
+		if isSkipContactText(text) {
+			state.EditMode = ""
+			c.Send(i18n.T(lang, "profile.phone_later"), &tele.ReplyMarkup{RemoveKeyboard: true})
+			return b.showProfile(ctx, c)
+		}
+		phone := services.NormalizePhone(text)
+		if digits := strings.TrimPrefix(phone, "+"); len(digits) < 10 || len(digits) > 15 {
+			return c.Send(i18n.T(lang, "profile.phone_invalid"))
+		}
+		c.Send(i18n.T(lang, "contact.saved"), &tele.ReplyMarkup{RemoveKeyboard: true})
+		return b.updateProfileAndShow(ctx, c, services.ProfileFieldPhone, phone)
+
+	case "profile_birthday":
+		birthday, err := time.ParseInLocation("02.01.2006", text, time.Local)
+		if err != nil || birthday.After(time.Now()) || birthday.Year() < 1900 {
+			return c.Send(i18n.T(lang, "profile.birthday_invalid"))
+		}
+		return b.updateProfileAndShow(ctx, c, services.ProfileFieldBirthday, birthday)
+
+	case "profile_allergies":
+		return b.updateProfileAndShow(ctx, c, services.ProfileFieldAllergies, text)
+
+	case "profile_specialist":
+		return b.updateProfileAndShow(ctx, c, services.ProfileFieldSpecialist, text)
+	}
+
+	return nil
+}
+
+// handleContact saves the phone number shared with the request contact button
+func (b *Bot) handleContact(c tele.Context) error {
+	ctx := b.requestContext(c)
+	lang := b.lang(c)
+	contact := c.Message().Contact
+	if contact == nil {
+		return nil
+	}
+
+	// Only accept the sender's own number
+	if contact.UserID != c.Sender().ID {
+		return c.Send(i18n.T(lang, "contact.not_own"))
+	}
+
+	phone := services.NormalizePhone(contact.PhoneNumber)
+	if !strings.HasPrefix(phone, "+") {
+		phone = "+" + phone
+	}
+
+	if err := b.userService.UpdateProfileField(ctx, c.Sender().ID, services.ProfileFieldPhone, phone); err != nil {
+		slog.ErrorContext(ctx, "Failed to save phone", "error", err)
+		return c.Send(i18n.T(lang, "contact.save_failed"))
+	}
+
+	c.Send(i18n.T(lang, "contact.saved"), &tele.ReplyMarkup{RemoveKeyboard: true})
+
+	state := b.getUserState(c.Sender().ID)
+
+	// Phone was requested during booking, finish it now
+	if state.CurrentStep == "contact" {
+		return b.completeBooking(ctx, c)
+	}
+
+	state.EditMode = ""
+	return b.showProfile(ctx, c)
+}
+
+// handleBookingContactInput handles typed phone or skip while booking
+func (b *Bot) handleBookingContactInput(c tele.Context) error {
+	ctx := b.requestContext(c)
+	lang := b.lang(c)
+	text := strings.TrimSpace(c.Text())
+
+	reply := i18n.T(lang, "contact.skipped")
+	if !isSkipContactText(text) {
+		phone := services.NormalizePhone(text)
+		if digits := strings.TrimPrefix(phone, "+"); len(digits) < 10 || len(digits) > 15 {
+			return c.Send(i18n.T(lang, "contact.invalid"))
+		}
+		if err := b.userService.UpdateProfileField(ctx, c.Sender().ID, services.ProfileFieldPhone, phone); err != nil {
+			slog.ErrorContext(ctx, "Failed to save phone", "error", err)
+		}
+		reply = i18n.T(lang, "contact.saved")
+	}
+
+	c.Send(reply, &tele.ReplyMarkup{RemoveKeyboard: true})
+	return b.completeBooking(ctx, c)
+}
@@ -0,0 +1,97 @@
+// Package bot contains post-visit review handlers
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gobot/internal/callback"
+	"gobot/internal/i18n"
+	"gobot/internal/services"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleReviewRate handles a star rating chosen by the client
+// args: booking ID and the rating
+func (b *Bot) handleReviewRate(ctx context.Context, c tele.Context, data callback.Data) error {
+	lang := b.lang(c)
+
+	bookingID, err := data.Uint(0)
+	if err != nil {
+		return invalidArg(err)
+	}
+	rating, err := data.Int(1)
+	if err != nil {
+		return invalidArg(err)
+	}
+
+	review, err := b.reviewService.RateBooking(ctx, bookingID, c.Sender().ID, rating)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: i18n.T(lang, "review.save_failed")})
+	}
+
+	if review.Rating <= services.LowRatingThreshold {
+		b.notificationService.NotifyAdminsLowRating(ctx, review)
+	}
+
+	// Wait for an optional comment
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = "review_comment"
+	state.ReviewID = review.ID
+
+	markup := &tele.ReplyMarkup{}
+	btnSkip := callback.Button(i18n.T(lang, "button.skip"), "review_skip", fmt.Sprintf("%d", review.ID))
+	markup.Inline(markup.Row(btnSkip))
+
+	msg := i18n.T(lang, "review.thanks_rating",
+		services.FormatStars(review.Rating),
+		review.Service.Name,
+	)
+
+	return c.Edit(msg, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleReviewSkip finishes the review without a comment
+func (b *Bot) handleReviewSkip(ctx context.Context, c tele.Context) error {
+	state := b.getUserState(c.Sender().ID)
+	state.EditMode = ""
+	state.ReviewID = 0
+
+	return c.Edit(i18n.T(b.lang(c), "review.thanks"), &tele.SendOptions{
+		ReplyMarkup: b.mainMenu(c),
+	})
+}
+
+// handleReviewCommentInput saves the comment typed by the client
+func (b *Bot) handleReviewCommentInput(c tele.Context) error {
+	ctx := b.requestContext(c)
+	state := b.getUserState(c.Sender().ID)
+	reviewID := state.ReviewID
+
+	state.EditMode = ""
+	state.ReviewID = 0
+
+	comment := strings.TrimSpace(c.Text())
+	if comment == "" {
+		return nil
+	}
+
+	review, err := b.reviewService.SetComment(ctx, reviewID, c.Sender().ID, comment)
+	if err != nil {
+		return c.Send(i18n.T(b.lang(c), "review.comment_failed"))
+	}
+
+	// Send the comment to admins as well, the rating alone was reported already
+	if review.Rating <= services.LowRatingThreshold {
+		b.notificationService.NotifyAdminsLowRating(ctx, review)
+	}
+
+	return c.Send(i18n.T(b.lang(c), "review.thanks_comment"), &tele.SendOptions{
+		ReplyMarkup: b.mainMenu(c),
+	})
+}
@@ -0,0 +1,295 @@
+// Package bot contains the router of inline button callbacks
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"gobot/internal/callback"
+	"gobot/internal/i18n"
+	"gobot/internal/services"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// callbackHandler handles a button press with its decoded payload
+type callbackHandler func(ctx context.Context, c tele.Context, data callback.Data) error
+
+// callbackMiddleware wraps the handlers of a route group
+type callbackMiddleware func(next callbackHandler) callbackHandler
+
+// callbackRouter dispatches button presses to handlers by route
+type callbackRouter struct {
+	routes     map[string]callbackHandler
+	middleware []callbackMiddleware
+}
+
+// newCallbackRouter creates an empty router
+func newCallbackRouter() *callbackRouter {
+	return &callbackRouter{routes: make(map[string]callbackHandler)}
+}
+
+// group returns a router adding routes to the same table, wrapped in the middleware
+func (r *callbackRouter) group(middleware ...callbackMiddleware) *callbackRouter {
+	return &callbackRouter{
+		routes:     r.routes,
+		middleware: append(slices.Clone(r.middleware), middleware...),
+	}
+}
+
+// handle registers the handler of a route, the first middleware runs first
+func (r *callbackRouter) handle(route string, h callbackHandler) {
+	if _, ok := r.routes[route]; ok {
+		panic(fmt.Sprintf("callback route %q registered twice", route))
+	}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		h = r.middleware[i](h)
+	}
+	r.routes[route] = h
+}
+
+// lookup returns the handler of a route
+func (r *callbackRouter) lookup(route string) (callbackHandler, bool) {
+	h, ok := r.routes[route]
+	return h, ok
+}
+
+// invalidArg wraps an argument that doesn't parse, so the user is told the button is invalid
+func invalidArg(err error) error {
+	return fmt.Errorf("%w: %w", services.ErrValidation, err)
+}
+
+// staleAction answers a button with an action the handler doesn't know
+// Payloads are signed, so such a button was sent by an older version of the bot
+func (b *Bot) staleAction(c tele.Context, route, action string) error {
+	return b.handleStaleButton(c, fmt.Errorf("unknown %s action %q", route, action))
+}
+
+// noArgs adapts handlers of buttons without arguments
+func noArgs(h func(ctx context.Context, c tele.Context) error) callbackHandler {
+	return func(ctx context.Context, c tele.Context, _ callback.Data) error {
+		return h(ctx, c)
+	}
+}
+
+// withArg adapts handlers of buttons carrying a single word, e.g. a menu action
+func withArg(h func(ctx context.Context, c tele.Context, arg string) error) callbackHandler {
+	return func(ctx context.Context, c tele.Context, data callback.Data) error {
+		return h(ctx, c, data.Arg(0))
+	}
+}
+
+// withID adapts handlers of buttons carrying a record ID
+func withID(h func(ctx context.Context, c tele.Context, id uint) error) callbackHandler {
+	return func(ctx context.Context, c tele.Context, data callback.Data) error {
+		id, err := data.Uint(0)
+		if err != nil {
+			return invalidArg(err)
+		}
+		return h(ctx, c, id)
+	}
+}
+
+// withUserID adapts handlers of buttons carrying a Telegram user ID
+func withUserID(h func(ctx context.Context, c tele.Context, userID int64) error) callbackHandler {
+	return func(ctx context.Context, c tele.Context, data callback.Data) error {
+		userID, err := data.Int64(0)
+		if err != nil {
+			return invalidArg(err)
+		}
+		return h(ctx, c, userID)
+	}
+}
+
+// withNumber adapts handlers of buttons carrying a number, e.g. an amount
+func withNumber(h func(ctx context.Context, c tele.Context, n int) error) callbackHandler {
+	return func(ctx context.Context, c tele.Context, data callback.Data) error {
+		n, err := data.Int(0)
+		if err != nil {
+			return invalidArg(err)
+		}
+		return h(ctx, c, n)
+	}
+}
+
+// withOffset adapts handlers of paged lists, a missing offset is the first page
+func withOffset(h func(ctx context.Context, c tele.Context, offset int) error) callbackHandler {
+	return func(ctx context.Context, c tele.Context, data callback.Data) error {
+		if data.Arg(0) == "" {
+			return h(ctx, c, 0)
+		}
+		offset, err := data.Int(0)
+		if err != nil || offset < 0 {
+			return fmt.Errorf("%w: invalid offset %q", services.ErrValidation, data.Arg(0))
+		}
+		return h(ctx, c, offset)
+	}
+}
+
+// adminOnly rejects button presses of users who aren't admins
+// Payloads are signed, so this only stops admin messages forwarded to or left with former admins
+func (b *Bot) adminOnly(next callbackHandler) callbackHandler {
+	return func(ctx context.Context, c tele.Context, data callback.Data) error {
+		if !b.isAdmin(c.Sender().ID) {
+			slog.WarnContext(ctx, "Admin button pressed by non-admin", "route", data.Route)
+			return services.ErrForbidden
+		}
+		return next(ctx, c, data)
+	}
+}
+
+// ownerOnly rejects button presses of admins who aren't the owner
+func (b *Bot) ownerOnly(next callbackHandler) callbackHandler {
+	return func(ctx context.Context, c tele.Context, data callback.Data) error {
+		if !b.isOwner(c.Sender().ID) {
+			slog.WarnContext(ctx, "Owner button pressed by another user", "route", data.Route)
+			return services.ErrForbidden
+		}
+		return next(ctx, c, data)
+	}
+}
+
+// handleCallback dispatches button presses through the callback router
+func (b *Bot) handleCallback(c tele.Context) error {
+	data, err := callback.Default().Decode(strings.TrimSpace(c.Callback().Data))
+	if err != nil {
+		return b.handleStaleButton(c, err)
+	}
+	handler, ok := b.callbacks.lookup(data.Route)
+	if !ok {
+		return b.handleStaleButton(c, fmt.Errorf("unknown callback route %q", data.Route))
+	}
+	return handler(b.requestContext(c), c, data)
+}
+
+// handleStaleButton answers presses of buttons the bot can't handle anymore
+// Such buttons come from messages sent before a deploy changed the payloads or removed the route,
+// so the keyboard is removed from the message and the user is asked to open the menu again
+func (b *Bot) handleStaleButton(c tele.Context, reason error) error {
+	ctx := b.requestContext(c)
+	if errors.Is(reason, callback.ErrSignature) {
+		slog.WarnContext(ctx, "Callback with invalid signature", "error", reason)
+	} else {
+		slog.InfoContext(ctx, "Stale button pressed", "error", reason)
+	}
+
+	if msg := c.Callback().Message; msg != nil {
+		if _, err := c.Bot().EditReplyMarkup(msg, nil); err != nil {
+			slog.DebugContext(ctx, "Failed to remove stale keyboard", "error", err)
+		}
+	}
+	return c.Respond(&tele.CallbackResponse{Text: i18n.T(b.lang(c), "error.stale_button"), ShowAlert: true})
+}
+
+// callbackRoutes registers the handlers of all inline buttons
+func (b *Bot) callbackRoutes() *callbackRouter {
+	r := newCallbackRouter()
+
+	// Client menus and booking
+	r.handle("main_menu", withArg(b.handleMainMenuAction))
+	r.handle("service", withID(b.handleServiceSelection))
+	r.handle("date", withArg(b.handleDateSelection))
+	r.handle("time", withArg(b.handleTimeSelection))
+	r.handle("no_time", noArgs(b.handleNoTime))
+	r.handle("confirm", noArgs(b.handleBookingConfirmation))
+	r.handle("cancel", withArg(b.handleCancel))
+	r.handle("cancel_booking", withID(b.handleBookingCancellation))
+	r.handle("back", withArg(b.handleBack))
+	r.handle("back_to_menu", noArgs(b.handleBackToMainMenu))
+	r.handle("profile", b.handleProfileAction)
+	r.handle("catalog_service", withID(b.handleCatalogService))
+	r.handle("gift", b.handleGiftAction)
+	r.handle("review_rate", b.handleReviewRate)
+	r.handle("review_skip", noArgs(b.handleReviewSkip))
+
+	admin := r.group(b.adminOnly)
+	admin.handle("admin", withArg(b.handleAdminAction))
+
+	// Services
+	admin.handle("admin_edit_service", withID(b.handleAdminEditService))
+	admin.handle("admin_toggle_service", withID(b.handleAdminToggleService))
+	admin.handle("admin_delete_service", withID(b.handleAdminDeleteService))
+	admin.handle("admin_add_service", noArgs(b.handleAdminAddServiceStart))
+	admin.handle("admin_edit_service_menu", withID(b.handleAdminEditServiceMenu))
+	admin.handle("admin_edit_field", b.handleAdminEditField)
+	admin.handle("admin_cancel_edit", noArgs(b.handleAdminCancelEdit))
+	admin.handle("admin_cancel_add_service", noArgs(b.handleAdminCancelAddService))
+
+	// Discounts
+	admin.handle("admin_discounts", noArgs(b.handleAdminDiscounts))
+	admin.handle("admin_add_discount", noArgs(b.handleAdminAddDiscountStart))
+	admin.handle("admin_discount_select_service", withID(b.handleAdminDiscountSelectService))
+	admin.handle("admin_edit_discount", withID(b.handleAdminEditDiscount))
+	admin.handle("admin_toggle_discount", withID(b.handleAdminToggleDiscount))
+	admin.handle("admin_delete_discount", withID(b.handleAdminDeleteDiscount))
+	admin.handle("admin_cancel_add_discount", noArgs(b.handleAdminCancelAddDiscount))
+	admin.handle("admin_discount_set_percentage", withNumber(b.handleAdminDiscountSetPercentage))
+	admin.handle("admin_discount_set_start_date", withArg(b.handleAdminDiscountSetStartDate))
+	admin.handle("admin_discount_set_end_date", withArg(b.handleAdminDiscountSetEndDate))
+
+	// Bookings
+	admin.handle("admin_approve_booking", withID(b.handleAdminApproveBooking))
+	admin.handle("admin_reject_booking", withID(b.handleAdminRejectBooking))
+	admin.handle("admin_bookings", b.handleAdminBookingsPage)
+	admin.handle("admin_bookings_filter", withArg(b.handleAdminBookingsFilter))
+	admin.handle("admin_bookings_set", b.handleAdminBookingsSet)
+	admin.handle("admin_booking", withID(b.handleAdminBookingCard))
+	admin.handle("admin_booking_status", b.handleAdminBookingStatus)
+	admin.handle("admin_booking_note", withID(b.handleAdminBookingNoteStart))
+	admin.handle("admin_booking_note_clear", withID(b.handleAdminBookingNoteClear))
+	admin.handle("admin_booking_reschedule", withID(b.handleAdminBookingReschedule))
+	admin.handle("admin_booking_resched_date", b.handleAdminBookingRescheduleDate)
+	admin.handle("admin_booking_resched_time", b.handleAdminBookingRescheduleTime)
+	admin.handle("admin_nb_service", withID(b.handleAdminNewBookingService))
+	admin.handle("admin_nb_date", withArg(b.handleAdminNewBookingDate))
+	admin.handle("admin_nb_time", withArg(b.handleAdminNewBookingTime))
+	admin.handle("admin_nb_client", withArg(b.handleAdminNewBookingClient))
+	admin.handle("admin_nb_user", withUserID(b.handleAdminNewBookingUser))
+	admin.handle("admin_nb_confirm", noArgs(b.handleAdminNewBookingConfirm))
+	admin.handle("admin_nb_cancel", noArgs(b.handleAdminNewBookingCancel))
+
+	// Clients
+	admin.handle("admin_client", withUserID(b.handleAdminClientCard))
+	admin.handle("admin_client_action", b.handleAdminClientAction)
+
+	// Certificates
+	admin.handle("admin_certificates", noArgs(b.handleAdminCertificates))
+	admin.handle("admin_add_certificate", noArgs(b.handleAdminAddCertificateStart))
+	admin.handle("admin_certificate_amount", withNumber(b.handleAdminCertificateAmount))
+	admin.handle("admin_certificate_service", withID(b.handleAdminCertificateService))
+	admin.handle("admin_certificate_validity", withNumber(b.handleAdminCertificateValidity))
+	admin.handle("admin_view_certificate", withID(b.handleAdminViewCertificate))
+	admin.handle("admin_void_certificate", withID(b.handleAdminVoidCertificate))
+	admin.handle("admin_lookup_certificate", noArgs(b.handleAdminLookupCertificateStart))
+	admin.handle("admin_cancel_add_certificate", noArgs(b.handleAdminCancelAddCertificate))
+
+	// Reviews
+	admin.handle("admin_reviews", withOffset(b.handleAdminReviews))
+	admin.handle("admin_view_review", withID(b.handleAdminViewReview))
+	admin.handle("admin_moderate_review", b.handleAdminModerateReview)
+	admin.handle("admin_publish_review", withID(b.handleAdminPublishReview))
+
+	// Broadcasts
+	admin.handle("admin_broadcast_button", withID(b.handleAdminBroadcastButton))
+	admin.handle("admin_broadcast_segment", withArg(b.handleAdminBroadcastSegment))
+	admin.handle("admin_broadcast_param", withNumber(b.handleAdminBroadcastParam))
+	admin.handle("admin_broadcast_send", noArgs(b.handleAdminBroadcastSend))
+	admin.handle("admin_broadcast_cancel", noArgs(b.handleAdminBroadcastCancel))
+
+	// Reports, integrations and settings
+	admin.handle("admin_report", withArg(b.handleAdminReport))
+	admin.handle("admin_export", b.handleAdminExportAction)
+	admin.handle("admin_api_key", b.handleAdminAPIKeyAction)
+	admin.handle("admin_webhook", b.handleAdminWebhookAction)
+	admin.handle("admin_template", b.handleAdminTemplateCard)
+	admin.handle("admin_template_action", b.handleAdminTemplateAction)
+
+	owner := admin.group(b.ownerOnly)
+	owner.handle("admin_audit", b.handleAdminAuditAction)
+
+	return r
+}
@@ -2,8 +2,9 @@
 package bot
 
 import (
-	"context"
-	"fmt"
+	"strings"
+
+	"gobot/internal/i18n"
 
 	tele "gopkg.in/telebot.v3"
 )
@@ -13,26 +14,91 @@ func (b *Bot) handleTextInput(c tele.Context) error {
 	state := b.getUserState(c.Sender().ID)
 
 	// Handle "Главное меню" button
-	if c.Text() == "🏠 Главное меню" || c.Text() == "/start" {
-		ctx := context.Background()
+	if isMainMenuText(c.Text()) || c.Text() == "/start" {
+		ctx := b.requestContext(c)
 		_, err := b.ensureUser(ctx, c.Sender())
 		if err != nil {
-			return c.Send("Произошла ошибка. Попробуйте позже.")
+			return c.Send(i18n.T(b.lang(c), "error.generic"))
 		}
 
-		welcomeMsg := fmt.Sprintf(
-			"🏠 <b>Главное меню</b>\n\n"+
-				"Выберите действие:",
-		)
-
-		return c.Send(welcomeMsg, &tele.SendOptions{
+		return c.Send(i18n.T(b.lang(c), "menu.title"), &tele.SendOptions{
 			ParseMode:   tele.ModeHTML,
-			ReplyMarkup: getMainMenuInlineKeyboard(b.isAdmin(c.Sender().ID)),
+			ReplyMarkup: b.mainMenu(c),
 		})
 	}
 
+	// Gift certificate code entered by a client
+	if state.EditMode == "certificate_code" {
+		return b.handleCertificateCodeInput(c)
+	}
+
+	// Review comment entered by a client
+	if state.EditMode == "review_comment" {
+		return b.handleReviewCommentInput(c)
+	}
+
+	// Phone number requested during booking
+	if state.CurrentStep == "contact" {
+		return b.handleBookingContactInput(c)
+	}
+
+	// Profile fields entered by a client
+	if strings.HasPrefix(state.EditMode, "profile_") {
+		return b.handleProfileInput(c)
+	}
+
 	// Check if admin is editing
 	if b.isAdmin(c.Sender().ID) {
+		// Certificate lookup
+		if state.EditMode == "certificate_lookup" {
+			return b.handleAdminAddCertificateMessage(c)
+		}
+
+		// Bookings browser filters and notes
+		if state.EditMode == "bookings_date_filter" || state.EditMode == "bookings_client_filter" {
+			return b.handleAdminBookingsFilterInput(c)
+		}
+		if state.EditMode == "booking_note" {
+			return b.handleAdminBookingNoteInput(c)
+		}
+
+		// Client search and client card
+		if state.EditMode == "client_search" {
+			return b.handleAdminClientSearchInput(c)
+		}
+		if state.EditMode == "client_note" ||
+			state.EditMode == "client_message" ||
+			state.EditMode == "client_block_reason" {
+			return b.handleAdminClientInput(c)
+		}
+
+		// Custom report period
+		if state.EditMode == "report_period" {
+			return b.handleAdminReportPeriodInput(c)
+		}
+
+		// Custom export period
+		if state.EditMode == "export_period" {
+			return b.handleAdminExportPeriodInput(c)
+		}
+
+		// API key name
+		if state.EditMode == "api_key_name" {
+			return b.handleAdminAPIKeyNameInput(c)
+		}
+
+		// Notification template editing
+		if state.EditMode == "template_body" {
+			return b.handleAdminTemplateInput(c)
+		}
+
+		// Client selection for a booking created by admin
+		if state.EditMode == "nb_client_search" ||
+			state.EditMode == "nb_client_name" ||
+			state.EditMode == "nb_client_phone" {
+			return b.handleAdminNewBookingMessage(c)
+		}
+
 		// Service editing
 		if state.EditMode != "" && state.EditServiceID != 0 {
 			return b.handleAdminTextMessage(c)
@@ -54,9 +120,29 @@ func (b *Bot) handleTextInput(c tele.Context) error {
 				state.EditMode == "add_discount_dates" {
 				return b.handleAdminAddDiscountMessage(c)
 			}
+
+			// Certificate issuing
+			if state.EditMode == "add_certificate_amount" {
+				return b.handleAdminAddCertificateMessage(c)
+			}
+
+			// Broadcast composing
+			if state.EditMode == "broadcast_text" {
+				return b.handleAdminBroadcastMessage(c)
+			}
 		}
 	}
 
 	// Default: no special handling
 	return nil
 }
+
+// isMainMenuText reports whether text is the main menu button of any language
+func isMainMenuText(text string) bool {
+	for _, lang := range i18n.Supported() {
+		if text == i18n.T(lang, "menu.main") {
+			return true
+		}
+	}
+	return false
+}
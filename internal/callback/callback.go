@@ -0,0 +1,184 @@
+// Package callback encodes and verifies the payloads of inline buttons
+// A payload is the version, an HMAC signature and the route with its arguments:
+// "1" + signature + "route|arg|arg". The signature stops clients from forging payloads,
+// the version tells buttons of old messages from current ones after a format change
+package callback
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	// Version is the current payload format
+	Version = "1"
+	// MaxLength is the Telegram limit of callback data in bytes
+	MaxLength = 64
+	// separator joins the route and arguments
+	separator = "|"
+	// signatureBytes is the length of the truncated HMAC, 48 bits are plenty against guessing
+	signatureBytes = 6
+)
+
+// signatureLength is the length of the encoded signature in the payload
+var signatureLength = base64.RawURLEncoding.EncodedLen(signatureBytes)
+
+// Payload errors, both mean the button must be refreshed rather than handled
+var (
+	// ErrStale is a payload of another format, e.g. a button sent before a deploy
+	ErrStale = errors.New("stale callback payload")
+	// ErrSignature is a payload whose signature doesn't match, forged or signed with another secret
+	ErrSignature = errors.New("invalid callback signature")
+)
+
+// Data is a decoded payload
+type Data struct {
+	Route string
+	Args  []string
+}
+
+// Arg returns the argument at the index, empty if there is none
+func (d Data) Arg(i int) string {
+	if i < 0 || i >= len(d.Args) {
+		return ""
+	}
+	return d.Args[i]
+}
+
+// Uint parses the argument at the index as an ID
+func (d Data) Uint(i int) (uint, error) {
+	id, err := strconv.ParseUint(d.Arg(i), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s argument %d: %w", d.Route, i, err)
+	}
+	return uint(id), nil
+}
+
+// Int64 parses the argument at the index as a Telegram user ID
+func (d Data) Int64(i int) (int64, error) {
+	id, err := strconv.ParseInt(d.Arg(i), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s argument %d: %w", d.Route, i, err)
+	}
+	return id, nil
+}
+
+// Int parses the argument at the index as a number, e.g. a page offset
+func (d Data) Int(i int) (int, error) {
+	n, err := strconv.Atoi(d.Arg(i))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s argument %d: %w", d.Route, i, err)
+	}
+	return n, nil
+}
+
+// Codec encodes and decodes payloads, signing them when it has a key
+type Codec struct {
+	key []byte
+}
+
+// NewCodec creates a codec signing payloads with a key derived from the secret
+// An empty secret leaves payloads unsigned
+func NewCodec(secret string) *Codec {
+	if secret == "" {
+		return &Codec{}
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("callback"))
+	return &Codec{key: mac.Sum(nil)}
+}
+
+// Encode returns the payload of a route with its arguments
+func (c *Codec) Encode(route string, args ...string) string {
+	body := strings.Join(append([]string{route}, args...), separator)
+	return Version + c.sign(body) + body
+}
+
+// Decode verifies a payload and splits it into the route and arguments
+func (c *Codec) Decode(payload string) (Data, error) {
+	rest, ok := strings.CutPrefix(payload, Version)
+	if !ok {
+		return Data{}, ErrStale
+	}
+	if c.key != nil {
+		if len(rest) < signatureLength {
+			return Data{}, ErrStale
+		}
+		signature, body := rest[:signatureLength], rest[signatureLength:]
+		if !hmac.Equal([]byte(signature), []byte(c.sign(body))) {
+			return Data{}, ErrSignature
+		}
+		rest = body
+	}
+	return split(rest), nil
+}
+
+// Peek returns the route and arguments of a payload without verifying it, for logs only
+func (c *Codec) Peek(payload string) (Data, bool) {
+	rest, ok := strings.CutPrefix(payload, Version)
+	if !ok {
+		return Data{}, false
+	}
+	if c.key != nil {
+		if len(rest) < signatureLength {
+			return Data{}, false
+		}
+		rest = rest[signatureLength:]
+	}
+	return split(rest), true
+}
+
+// sign returns the encoded signature of a payload body, empty without a key
+func (c *Codec) sign(body string) string {
+	if c.key == nil {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(Version + body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureBytes])
+}
+
+// split splits a payload body into the route and arguments
+func split(body string) Data {
+	parts := strings.Split(body, separator)
+	return Data{Route: parts[0], Args: parts[1:]}
+}
+
+// defaultCodec encodes buttons of the bot and its notifications
+var (
+	defaultMu    sync.RWMutex
+	defaultCodec = NewCodec("")
+)
+
+// SetSecret sets the secret signing buttons, call it before any button is built
+func SetSecret(secret string) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultCodec = NewCodec(secret)
+}
+
+// Default returns the codec of the bot
+func Default() *Codec {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultCodec
+}
+
+// Button returns an inline button calling the route with the arguments
+// Unique stays empty, so telebot sends the payload as is instead of prefixing it
+func Button(text, route string, args ...string) tele.Btn {
+	data := Default().Encode(route, args...)
+	if len(data) > MaxLength {
+		slog.Error("Callback payload exceeds the Telegram limit", "route", route, "length", len(data))
+	}
+	return tele.Btn{Text: text, Data: data}
+}
@@ -0,0 +1,150 @@
+package callback
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		route string
+		args  []string
+	}{
+		{name: "no args", route: "confirm"},
+		{name: "one arg", route: "admin_booking", args: []string{"42"}},
+		{name: "several args", route: "admin_template_action", args: []string{"preview", "booking_rescheduled", "ru"}},
+		{name: "empty arg", route: "admin_bookings_set", args: []string{"date", ""}},
+	}
+
+	for _, secret := range []string{"", "secret"} {
+		codec := NewCodec(secret)
+		for _, tt := range tests {
+			t.Run(tt.name+"/secret="+secret, func(t *testing.T) {
+				data, err := codec.Decode(codec.Encode(tt.route, tt.args...))
+				if err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				if data.Route != tt.route {
+					t.Errorf("Route = %q, want %q", data.Route, tt.route)
+				}
+				if !slices.Equal(data.Args, tt.args) {
+					t.Errorf("Args = %q, want %q", data.Args, tt.args)
+				}
+			})
+		}
+	}
+}
+
+func TestCodecDecodeErrors(t *testing.T) {
+	codec := NewCodec("secret")
+	payload := codec.Encode("admin_booking", "42")
+
+	tests := []struct {
+		name    string
+		payload string
+		want    error
+	}{
+		{name: "tampered argument", payload: payload[:len(payload)-2] + "43", want: ErrSignature},
+		{name: "tampered signature", payload: Version + "AAAAAAAA" + payload[1+signatureLength:], want: ErrSignature},
+		{name: "signed with another secret", payload: NewCodec("other").Encode("admin_booking", "42"), want: ErrSignature},
+		{name: "unsigned", payload: NewCodec("").Encode("admin_booking", "42"), want: ErrSignature},
+		{name: "no version", payload: payload[1:], want: ErrStale},
+		{name: "old format", payload: "admin_booking:42", want: ErrStale},
+		{name: "shorter than signature", payload: Version + "abc", want: ErrStale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := codec.Decode(tt.payload); !errors.Is(err, tt.want) {
+				t.Errorf("Decode(%q) error = %v, want %v", tt.payload, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodecUnsigned(t *testing.T) {
+	codec := NewCodec("")
+
+	payload := codec.Encode("admin_booking", "42")
+	if want := Version + "admin_booking|42"; payload != want {
+		t.Fatalf("Encode() = %q, want %q", payload, want)
+	}
+
+	data, err := codec.Decode(payload)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if id, err := data.Uint(0); err != nil || id != 42 {
+		t.Errorf("Uint(0) = %d, %v, want 42", id, err)
+	}
+
+	if _, err := codec.Decode("admin_booking|42"); !errors.Is(err, ErrStale) {
+		t.Errorf("Decode() without version error = %v, want %v", err, ErrStale)
+	}
+}
+
+func TestCodecPeek(t *testing.T) {
+	codec := NewCodec("secret")
+
+	forged := NewCodec("other").Encode("admin_booking", "42")
+	data, ok := codec.Peek(forged)
+	if !ok || data.Route != "admin_booking" || data.Arg(0) != "42" {
+		t.Errorf("Peek() = %+v, %v, want admin_booking 42", data, ok)
+	}
+
+	if _, ok := codec.Peek("admin_booking:42"); ok {
+		t.Error("Peek() of a payload without version succeeded")
+	}
+}
+
+func TestDataArgs(t *testing.T) {
+	data := Data{Route: "review_rate", Args: []string{"42", "-5", "x"}}
+
+	if got := data.Arg(3); got != "" {
+		t.Errorf("Arg(3) = %q, want empty", got)
+	}
+	if got, err := data.Uint(0); err != nil || got != 42 {
+		t.Errorf("Uint(0) = %d, %v, want 42", got, err)
+	}
+	if got, err := data.Int(1); err != nil || got != -5 {
+		t.Errorf("Int(1) = %d, %v, want -5", got, err)
+	}
+	if got, err := data.Int64(1); err != nil || got != -5 {
+		t.Errorf("Int64(1) = %d, %v, want -5", got, err)
+	}
+	if _, err := data.Uint(1); err == nil {
+		t.Error("Uint(1) of a negative number succeeded")
+	}
+	if _, err := data.Int(2); err == nil {
+		t.Error("Int(2) of a word succeeded")
+	}
+	if _, err := data.Uint(3); err == nil {
+		t.Error("Uint(3) of a missing argument succeeded")
+	}
+}
+
+// TestLongestPayloads checks the longest buttons of the bot fit the Telegram limit
+func TestLongestPayloads(t *testing.T) {
+	codec := NewCodec("secret")
+
+	tests := []struct {
+		route string
+		args  []string
+	}{
+		{"admin_booking_resched_time", []string{"4294967295", "2026-10-18", "14:30"}},
+		{"admin_template_action", []string{"preview", "booking_rescheduled", "ru"}},
+		{"admin_client_action", []string{"block_hidden", "-9223372036854775808"}},
+		{"admin_export", []string{"bookings", "20261001-20261101", "xlsx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			payload := codec.Encode(tt.route, tt.args...)
+			if len(payload) > MaxLength {
+				t.Errorf("payload %q is %d bytes, limit %d", payload, len(payload), MaxLength)
+			}
+		})
+	}
+}
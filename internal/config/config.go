@@ -3,6 +3,7 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"strconv"
 	"strings"
@@ -14,10 +15,52 @@ import (
 type Config struct {
 	BotToken     string
 	AdminUserIDs []int64
+	OwnerUserID  int64 // Admin who sees the audit log, the first admin by default
+	ErrorChatID  int64 // Chat receiving reports of unexpected errors, the owner by default
 	DBPath       string
 	Timezone     string
 	Debug        bool
-	ChannelID    string // Optional: Telegram channel ID for promotions (format: @channelname or -1001234567890)
+	LogLevel     slog.Level // Debug by default with BOT_DEBUG, info otherwise
+	LogFormat    string     // "json" for production, "text" by default with BOT_DEBUG
+	ChannelID    string     // Optional: Telegram channel ID for promotions (format: @channelname or -1001234567890)
+
+	// PaymentProviderToken enables buying gift certificates via Telegram Payments (optional)
+	PaymentProviderToken string
+	PaymentCurrency      string
+
+	// ReviewRequestDelayHours is how long after a visit the client is asked for a review
+	ReviewRequestDelayHours int
+
+	// Booking limits per client, 0 disables the limit
+	MaxActiveBookings         int // Pending and confirmed upcoming bookings
+	MaxBookingsPerDay         int // Bookings created within 24 hours
+	MinBookingIntervalSeconds int // Pause between booking attempts
+
+	// RateLimitPerMinute is how many updates a user can send per minute, 0 disables the limiter
+	RateLimitPerMinute int
+	RateLimitBurst     int
+
+	// HTTPAddr is the listen address of the HTTP server, e.g. ":8080" (optional)
+	HTTPAddr string
+	// PublicURL is the external address of the HTTP server used in links, e.g. https://bot.example.com
+	PublicURL string
+	// CalendarFeedToken protects the admin iCal feed, empty disables the feed
+	CalendarFeedToken string
+	// MetricsToken is the bearer token required by /metrics, empty leaves the endpoint open
+	MetricsToken string
+	// CallbackSecret signs inline button payloads, derived from BOT_TOKEN by default
+	CallbackSecret string
+
+	// CalDAV calendar collection synced with bookings (optional)
+	CalDAVURL             string
+	CalDAVUsername        string
+	CalDAVPassword        string
+	CalDAVSyncIntervalMin int
+
+	// Webhook URLs receiving booking, discount and user events, empty disables webhooks
+	WebhookURLs []string
+	// WebhookSecret signs webhook payloads with HMAC-SHA256
+	WebhookSecret string
 }
 
 // Load reads configuration from environment variables
@@ -32,6 +75,21 @@ func Load() (*Config, error) {
 		Timezone:  os.Getenv("TIMEZONE"),
 		Debug:     os.Getenv("BOT_DEBUG") == "true",
 		ChannelID: os.Getenv("CHANNEL_ID"), // Optional channel for promotions
+
+		PaymentProviderToken: os.Getenv("PAYMENT_PROVIDER_TOKEN"),
+		PaymentCurrency:      os.Getenv("PAYMENT_CURRENCY"),
+
+		HTTPAddr:          os.Getenv("HTTP_ADDR"),
+		PublicURL:         strings.TrimRight(os.Getenv("PUBLIC_URL"), "/"),
+		CalendarFeedToken: os.Getenv("CALENDAR_FEED_TOKEN"),
+		MetricsToken:      os.Getenv("METRICS_TOKEN"),
+		CallbackSecret:    os.Getenv("CALLBACK_SECRET"),
+
+		CalDAVURL:      os.Getenv("CALDAV_URL"),
+		CalDAVUsername: os.Getenv("CALDAV_USERNAME"),
+		CalDAVPassword: os.Getenv("CALDAV_PASSWORD"),
+
+		WebhookSecret: os.Getenv("WEBHOOK_SECRET"),
 	}
 
 	// Validate required fields
@@ -39,6 +97,11 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("BOT_TOKEN is required")
 	}
 
+	// Buttons of old messages stay valid as long as the secret doesn't change
+	if cfg.CallbackSecret == "" {
+		cfg.CallbackSecret = cfg.BotToken
+	}
+
 	if cfg.DBPath == "" {
 		cfg.DBPath = "./bot.db" // Default value
 	}
@@ -47,6 +110,93 @@ func Load() (*Config, error) {
 		cfg.Timezone = "UTC" // Default value
 	}
 
+	cfg.LogLevel = slog.LevelInfo
+	cfg.LogFormat = "json"
+	if cfg.Debug {
+		cfg.LogLevel = slog.LevelDebug
+		cfg.LogFormat = "text"
+	}
+	if levelStr := os.Getenv("LOG_LEVEL"); levelStr != "" {
+		if err := cfg.LogLevel.UnmarshalText([]byte(levelStr)); err != nil {
+			return nil, fmt.Errorf("invalid LOG_LEVEL: %s", levelStr)
+		}
+	}
+	if format := os.Getenv("LOG_FORMAT"); format != "" {
+		if format != "json" && format != "text" {
+			return nil, fmt.Errorf("invalid LOG_FORMAT: %s", format)
+		}
+		cfg.LogFormat = format
+	}
+
+	if cfg.PaymentCurrency == "" {
+		cfg.PaymentCurrency = "RUB" // Default value
+	}
+
+	// The token is the only protection of the feed, so it must be hard to guess
+	if cfg.CalendarFeedToken != "" && len(cfg.CalendarFeedToken) < 16 {
+		return nil, fmt.Errorf("CALENDAR_FEED_TOKEN must be at least 16 characters long")
+	}
+
+	cfg.ReviewRequestDelayHours = 3 // Default value
+	if delayStr := os.Getenv("REVIEW_REQUEST_DELAY_HOURS"); delayStr != "" {
+		delay, err := strconv.Atoi(delayStr)
+		if err != nil || delay < 0 {
+			return nil, fmt.Errorf("invalid REVIEW_REQUEST_DELAY_HOURS: %s", delayStr)
+		}
+		cfg.ReviewRequestDelayHours = delay
+	}
+
+	// Anti-abuse limits with default values
+	limits := []struct {
+		name  string
+		value *int
+		def   int
+	}{
+		{"BOOKING_MAX_ACTIVE", &cfg.MaxActiveBookings, 3},
+		{"BOOKING_MAX_PER_DAY", &cfg.MaxBookingsPerDay, 3},
+		{"BOOKING_MIN_INTERVAL_SECONDS", &cfg.MinBookingIntervalSeconds, 30},
+		{"RATE_LIMIT_PER_MINUTE", &cfg.RateLimitPerMinute, 60},
+		{"RATE_LIMIT_BURST", &cfg.RateLimitBurst, 10},
+	}
+	for _, limit := range limits {
+		*limit.value = limit.def
+		valueStr := os.Getenv(limit.name)
+		if valueStr == "" {
+			continue
+		}
+		value, err := strconv.Atoi(valueStr)
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("invalid %s: %s", limit.name, valueStr)
+		}
+		*limit.value = value
+	}
+
+	cfg.CalDAVSyncIntervalMin = 5 // Default value
+	if intervalStr := os.Getenv("CALDAV_SYNC_INTERVAL_MINUTES"); intervalStr != "" {
+		interval, err := strconv.Atoi(intervalStr)
+		if err != nil || interval < 1 {
+			return nil, fmt.Errorf("invalid CALDAV_SYNC_INTERVAL_MINUTES: %s", intervalStr)
+		}
+		cfg.CalDAVSyncIntervalMin = interval
+	}
+
+	// Parse webhook URLs
+	for _, rawURL := range strings.Split(os.Getenv("WEBHOOK_URLS"), ",") {
+		rawURL = strings.TrimSpace(rawURL)
+		if rawURL == "" {
+			continue
+		}
+		if !strings.HasPrefix(rawURL, "https://") && !strings.HasPrefix(rawURL, "http://") {
+			return nil, fmt.Errorf("invalid webhook URL: %s", rawURL)
+		}
+		cfg.WebhookURLs = append(cfg.WebhookURLs, rawURL)
+	}
+
+	// Receivers can't tell real events from forged ones without the signature
+	if len(cfg.WebhookURLs) > 0 && len(cfg.WebhookSecret) < 16 {
+		return nil, fmt.Errorf("WEBHOOK_SECRET of at least 16 characters is required with WEBHOOK_URLS")
+	}
+
 	// Parse admin user IDs
 	adminIDsStr := os.Getenv("ADMIN_USER_IDS")
 	if adminIDsStr != "" {
@@ -61,9 +211,37 @@ func Load() (*Config, error) {
 		}
 	}
 
+	if ownerStr := os.Getenv("OWNER_USER_ID"); ownerStr != "" {
+		ownerID, err := strconv.ParseInt(strings.TrimSpace(ownerStr), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OWNER_USER_ID: %w", err)
+		}
+		if !cfg.IsAdmin(ownerID) {
+			return nil, fmt.Errorf("OWNER_USER_ID must be one of ADMIN_USER_IDS")
+		}
+		cfg.OwnerUserID = ownerID
+	} else if len(cfg.AdminUserIDs) > 0 {
+		cfg.OwnerUserID = cfg.AdminUserIDs[0]
+	}
+
+	// Group chat IDs are negative, so any non-zero ID is accepted
+	cfg.ErrorChatID = cfg.OwnerUserID
+	if chatStr := os.Getenv("ERROR_CHAT_ID"); chatStr != "" {
+		chatID, err := strconv.ParseInt(strings.TrimSpace(chatStr), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ERROR_CHAT_ID: %w", err)
+		}
+		cfg.ErrorChatID = chatID
+	}
+
 	return cfg, nil
 }
 
+// IsOwner checks if the given user ID is the owner
+func (c *Config) IsOwner(userID int64) bool {
+	return c.OwnerUserID != 0 && c.OwnerUserID == userID
+}
+
 // IsAdmin checks if the given user ID is an admin
 func (c *Config) IsAdmin(userID int64) bool {
 	for _, adminID := range c.AdminUserIDs {
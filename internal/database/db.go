@@ -3,10 +3,9 @@ package database
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 
 	// Use pure-Go SQLite driver
 	sqlite "github.com/glebarez/sqlite"
@@ -16,31 +15,28 @@ import (
 var DB *gorm.DB
 
 // Initialize sets up the database connection and runs migrations
-func Initialize(dbPath string, debug bool) error {
+// Queries are logged at debug level of the default slog logger
+func Initialize(dbPath string) error {
 	var err error
 
-	// Configure GORM logger
-	var gormLogger logger.Interface
-	if debug {
-		gormLogger = logger.Default.LogMode(logger.Info)
-	} else {
-		gormLogger = logger.Default.LogMode(logger.Silent)
-	}
-
 	// Open database connection
 	DB, err = gorm.Open(sqlite.Open(dbPath), &gorm.Config{
-		Logger: gormLogger,
+		Logger: newSlogLogger(),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	if err := registerMetrics(DB); err != nil {
+		return err
+	}
+
 	// Run auto migrations
 	if err := runMigrations(); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	log.Println("Database initialized successfully")
+	slog.Info("Database initialized", "path", dbPath)
 	return nil
 }
 
@@ -50,10 +46,24 @@ func runMigrations() error {
 		&User{},
 		&Service{},
 		&Booking{},
+		&BookingStatusHistory{},
 		&TimeSlot{},
 		&Discount{},
 		&WorkSchedule{},
 		&BlockedDate{},
+		&GiftCertificate{},
+		&GiftCertificateRedemption{},
+		&Review{},
+		&Broadcast{},
+		&OutboxMessage{},
+		&MessageTemplate{},
+		&CalendarEvent{},
+		&CalendarBusyPeriod{},
+		&APIKey{},
+		&AdminLoginToken{},
+		&AdminSession{},
+		&WebhookDelivery{},
+		&AuditLog{},
 	)
 }
 
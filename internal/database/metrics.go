@@ -0,0 +1,62 @@
+// Package database measures query durations for metrics
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gobot/internal/metrics"
+
+	"gorm.io/gorm"
+)
+
+// queryDuration observes durations of GORM operations by operation and table
+var queryDuration = metrics.NewHistogram("gobot_db_query_duration_seconds",
+	"Database query durations by operation and table", metrics.DefaultBuckets, "operation", "table")
+
+// queryStartKey stores the start time of an operation in the statement
+const queryStartKey = "metrics:start"
+
+// registerMetrics times create, query, update, delete, row and raw operations
+func registerMetrics(db *gorm.DB) error {
+	callbacks := db.Callback()
+	err := errors.Join(
+		callbacks.Create().Before("*").Register("metrics:before_create", startQuery),
+		callbacks.Create().After("*").Register("metrics:after_create", observeQuery("create")),
+		callbacks.Query().Before("*").Register("metrics:before_query", startQuery),
+		callbacks.Query().After("*").Register("metrics:after_query", observeQuery("query")),
+		callbacks.Update().Before("*").Register("metrics:before_update", startQuery),
+		callbacks.Update().After("*").Register("metrics:after_update", observeQuery("update")),
+		callbacks.Delete().Before("*").Register("metrics:before_delete", startQuery),
+		callbacks.Delete().After("*").Register("metrics:after_delete", observeQuery("delete")),
+		callbacks.Row().Before("*").Register("metrics:before_row", startQuery),
+		callbacks.Row().After("*").Register("metrics:after_row", observeQuery("row")),
+		callbacks.Raw().Before("*").Register("metrics:before_raw", startQuery),
+		callbacks.Raw().After("*").Register("metrics:after_raw", observeQuery("raw")),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register query metrics: %w", err)
+	}
+	return nil
+}
+
+// startQuery remembers when an operation started
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+// observeQuery returns a callback observing the duration of an operation
+func observeQuery(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+		queryDuration.Observe(time.Since(start).Seconds(), operation, db.Statement.Table)
+	}
+}
@@ -2,6 +2,7 @@
 package database
 
 import (
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
@@ -9,14 +10,28 @@ import (
 
 // User represents a Telegram user in the system
 type User struct {
-	ID        int64  `gorm:"primaryKey"` // Telegram User ID
-	Username  string `gorm:"index"`
-	FirstName string
-	LastName  string
-	IsAdmin   bool `gorm:"default:false"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ID                int64  `gorm:"primaryKey"` // Telegram User ID
+	Username          string `gorm:"index"`
+	FirstName         string
+	LastName          string
+	IsAdmin           bool `gorm:"default:false"`
+	Birthday          *time.Time
+	BlockedBot        bool   `gorm:"default:false;index"` // User blocked the bot, messages can't be delivered
+	Phone             string `gorm:"index"`
+	Allergies         string `gorm:"type:text"` // Allergies and contraindications told by the client
+	Specialist        string // Preferred specialist
+	IsOffline         bool   `gorm:"default:false;index"` // Phone/walk-in client created by admin, has a negative ID
+	InviteToken       string `gorm:"index"`               // Deep link token to claim offline bookings in the bot
+	IsBlocked         bool   `gorm:"default:false;index"` // Blocked by admin, can't book
+	BlockReason       string // Why the client was blocked
+	BlockReasonHidden bool   `gorm:"default:false"` // Don't show the reason to the client
+	BlockedAt         *time.Time
+	Language          string // Language chosen by the user, empty means detected from Telegram
+	LanguageCode      string // Language code reported by Telegram
+	AdminNotes        string `gorm:"type:text"` // Internal notes about the client, visible to admins only
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         gorm.DeletedAt `gorm:"index"`
 
 	// Relations
 	Bookings []Booking `gorm:"foreignKey:UserID"`
@@ -47,29 +62,58 @@ const (
 	BookingStatusConfirmed BookingStatus = "confirmed"
 	BookingStatusCancelled BookingStatus = "cancelled"
 	BookingStatusCompleted BookingStatus = "completed"
+	BookingStatusNoShow    BookingStatus = "no_show"
+	BookingStatusRejected  BookingStatus = "rejected" // Declined by staff before confirmation
 )
 
 // Booking represents a service booking
 type Booking struct {
-	ID                    uint          `gorm:"primaryKey"`
-	UserID                int64         `gorm:"not null;index"`
-	ServiceID             uint          `gorm:"not null;index"`
-	Date                  time.Time     `gorm:"not null;index"`
-	Time                  string        `gorm:"not null"` // Format: "HH:MM"
-	Status                BookingStatus `gorm:"not null;index;default:'pending'"`
-	Notes                 string
-	ReminderSent          bool      `gorm:"default:false"` // Reminder sent 1 day before
-	HourReminderSent      bool      `gorm:"default:false"` // Reminder sent 1 hour before
-	AdminDailyReminderSent bool     `gorm:"default:false"` // Admin daily reminder sent
-	CreatedAt             time.Time
-	UpdatedAt             time.Time
-	DeletedAt             gorm.DeletedAt `gorm:"index"`
+	ID                     uint          `gorm:"primaryKey"`
+	UserID                 int64         `gorm:"not null;index"`
+	ServiceID              uint          `gorm:"not null;index"`
+	Date                   time.Time     `gorm:"not null;index"`
+	Time                   string        `gorm:"not null"` // Format: "HH:MM"
+	Status                 BookingStatus `gorm:"not null;index;default:'pending'"`
+	Notes                  string
+	Price                  int        // Final price at booking time (with discount applied)
+	CertificateAmount      int        // Part of the price paid with a gift certificate
+	ReminderSent           bool       `gorm:"default:false"` // Reminder sent 1 day before
+	HourReminderSent       bool       `gorm:"default:false"` // Reminder sent 1 hour before
+	AdminDailyReminderSent bool       `gorm:"default:false"` // Admin daily reminder sent
+	ReviewRequestSent      bool       `gorm:"default:false"` // Post-visit review request sent
+	CancelledAt            *time.Time // Set when the client cancels the booking
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+	DeletedAt              gorm.DeletedAt `gorm:"index"`
 
 	// Relations
 	User    User    `gorm:"foreignKey:UserID"`
 	Service Service `gorm:"foreignKey:ServiceID"`
 }
 
+// ActorType tells who made a change
+type ActorType string
+
+const (
+	ActorClient ActorType = "client"
+	ActorAdmin  ActorType = "admin"
+	ActorAPI    ActorType = "api"
+	ActorSystem ActorType = "system" // Workers and command line tools
+)
+
+// BookingStatusHistory records a status change of a booking
+type BookingStatusHistory struct {
+	ID         uint          `gorm:"primaryKey"`
+	BookingID  uint          `gorm:"not null;index"`
+	FromStatus BookingStatus `gorm:"not null"`
+	ToStatus   BookingStatus `gorm:"not null"`
+	Actor      ActorType     `gorm:"not null"`
+	ActorID    int64         // Telegram ID of the client or admin, ID of the API key
+	ActorName  string        // Name of the API key
+	Reason     string
+	CreatedAt  time.Time
+}
+
 // TimeSlot represents an available time slot
 type TimeSlot struct {
 	ID          uint      `gorm:"primaryKey"`
@@ -121,3 +165,272 @@ type BlockedDate struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
+
+// GiftCertificateStatus represents the status of a gift certificate
+type GiftCertificateStatus string
+
+const (
+	GiftCertificateStatusActive GiftCertificateStatus = "active"
+	GiftCertificateStatusUsed   GiftCertificateStatus = "used"
+	GiftCertificateStatusVoided GiftCertificateStatus = "voided"
+)
+
+// GiftCertificate represents a prepaid gift certificate
+// Nominal certificates are spent across bookings until the balance is exhausted,
+// service certificates cover one booking of the specified service
+type GiftCertificate struct {
+	ID              uint                  `gorm:"primaryKey"`
+	Code            string                `gorm:"not null;uniqueIndex"`
+	Amount          int                   `gorm:"not null"` // Nominal value in smallest currency unit
+	Balance         int                   `gorm:"not null"` // Remaining value in smallest currency unit
+	ServiceID       *uint                 `gorm:"index"`    // Set for certificates issued for a specific service
+	Status          GiftCertificateStatus `gorm:"not null;index;default:'active'"`
+	ExpiresAt       time.Time             `gorm:"not null;index"`
+	IssuedBy        int64                 // Telegram ID of the admin or buyer who issued the certificate
+	PurchasedBy     *int64                `gorm:"index"` // Set when bought by a client via payment
+	PaymentChargeID string                // Telegram payment charge ID for purchased certificates
+	RecipientName   string
+	VoidedAt        *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+
+	// Relations
+	Service     *Service                    `gorm:"foreignKey:ServiceID"`
+	Redemptions []GiftCertificateRedemption `gorm:"foreignKey:CertificateID"`
+}
+
+// GiftCertificateRedemption records a part of a certificate spent on a booking
+type GiftCertificateRedemption struct {
+	ID            uint       `gorm:"primaryKey"`
+	CertificateID uint       `gorm:"not null;index"`
+	BookingID     uint       `gorm:"not null;index"`
+	UserID        int64      `gorm:"not null;index"`
+	Amount        int        `gorm:"not null"` // Amount deducted from the balance
+	RefundedAt    *time.Time // Set when the booking was cancelled and the amount returned to the balance
+	CreatedAt     time.Time
+
+	// Relations
+	Booking Booking `gorm:"foreignKey:BookingID"`
+}
+
+// ReviewStatus represents the moderation status of a review
+type ReviewStatus string
+
+const (
+	ReviewStatusNew      ReviewStatus = "new"
+	ReviewStatusApproved ReviewStatus = "approved"
+	ReviewStatusHidden   ReviewStatus = "hidden"
+)
+
+// Review represents a client's rating of a completed visit
+type Review struct {
+	ID          uint         `gorm:"primaryKey"`
+	BookingID   uint         `gorm:"not null;uniqueIndex"`
+	UserID      int64        `gorm:"not null;index"`
+	ServiceID   uint         `gorm:"not null;index"`
+	Rating      int          `gorm:"not null"` // 1-5 stars
+	Comment     string       `gorm:"type:text"`
+	Status      ReviewStatus `gorm:"not null;index;default:'new'"`
+	PublishedAt *time.Time   // Set when the review was posted to the channel
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// Relations
+	Booking Booking `gorm:"foreignKey:BookingID"`
+	User    User    `gorm:"foreignKey:UserID"`
+	Service Service `gorm:"foreignKey:ServiceID"`
+}
+
+// BroadcastSegment selects which users receive a broadcast
+type BroadcastSegment string
+
+const (
+	BroadcastSegmentAll      BroadcastSegment = "all"      // All users who haven't blocked the bot
+	BroadcastSegmentClients  BroadcastSegment = "clients"  // Users with at least one completed booking
+	BroadcastSegmentInactive BroadcastSegment = "inactive" // Users without bookings in the last N days
+	BroadcastSegmentService  BroadcastSegment = "service"  // Users who booked a specific service
+	BroadcastSegmentBirthday BroadcastSegment = "birthday" // Users with a birthday this month
+)
+
+// BroadcastStatus represents the status of a broadcast
+type BroadcastStatus string
+
+const (
+	BroadcastStatusSending  BroadcastStatus = "sending"
+	BroadcastStatusFinished BroadcastStatus = "finished"
+)
+
+// Broadcast represents a message sent by admins to a segment of users
+type Broadcast struct {
+	ID           uint             `gorm:"primaryKey"`
+	CreatedBy    int64            `gorm:"not null"`
+	Text         string           `gorm:"type:text"`
+	PhotoFileID  string           // Optional Telegram photo file ID
+	ServiceID    *uint            // Optional service for the "book" button
+	Segment      BroadcastSegment `gorm:"not null"`
+	SegmentParam int              // Days for inactive segment, service ID for service segment
+	Status       BroadcastStatus  `gorm:"not null;index"`
+	Total        int
+	Delivered    int
+	Failed       int
+	Blocked      int // Recipients who blocked the bot
+	FinishedAt   *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// OutboxStatus represents the delivery status of an outgoing message
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "pending" // Waiting to be sent or retried
+	OutboxStatusSent    OutboxStatus = "sent"
+	OutboxStatusFailed  OutboxStatus = "failed"  // Gave up after retries or permanent error
+	OutboxStatusBlocked OutboxStatus = "blocked" // Recipient blocked the bot
+)
+
+// OutboxMessage represents a queued outgoing Telegram message
+type OutboxMessage struct {
+	ID            uint   `gorm:"primaryKey"`
+	ChatID        string `gorm:"not null;index"` // User ID, channel ID or @channelname
+	BookingID     uint   `gorm:"index"`          // Related booking, 0 if none
+	Text          string `gorm:"type:text;not null"`
+	ParseMode     string
+	ReplyMarkup   string       `gorm:"type:text"` // JSON encoded inline keyboard
+	Status        OutboxStatus `gorm:"not null;index"`
+	Attempts      int          `gorm:"default:0"`
+	NextAttemptAt time.Time    `gorm:"index"`
+	LastError     string
+	MessageID     int    // Telegram message ID once sent
+	FileName      string // Attached document, Text is sent as its caption
+	FileMIME      string
+	FileData      []byte
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// MessageTemplate is an admin override of a built-in notification template
+type MessageTemplate struct {
+	ID        uint   `gorm:"primaryKey"`
+	Key       string `gorm:"not null;uniqueIndex:idx_message_template"` // Template key, e.g. "booking_confirmed"
+	Language  string `gorm:"not null;uniqueIndex:idx_message_template"`
+	Body      string `gorm:"type:text;not null"` // Go text/template source
+	UpdatedBy int64  // Admin who saved the template
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CalendarEvent tracks a booking exported to calendars
+// Sequence grows each time the exported event changes so calendar apps replace their copy
+type CalendarEvent struct {
+	ID          uint   `gorm:"primaryKey"`
+	BookingID   uint   `gorm:"not null;uniqueIndex"`
+	UID         string `gorm:"not null;uniqueIndex"` // Stable iCalendar UID
+	Sequence    int    `gorm:"default:0"`
+	Fingerprint string // Exported fields of the last published version
+
+	// CalDAV copy of the event
+	RemoteFingerprint string // Fingerprint of the version stored on the server, empty if not pushed
+	RemoteETag        string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CalendarBusyPeriod is a busy event pulled from the staff CalDAV calendar
+// Slots overlapping a busy period are not offered for booking
+type CalendarBusyPeriod struct {
+	ID        uint   `gorm:"primaryKey"`
+	UID       string `gorm:"not null;index"` // UID of the remote event
+	Summary   string
+	StartsAt  time.Time `gorm:"not null;index"`
+	EndsAt    time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// APIKey grants access to the admin HTTP API
+// Only the SHA-256 hash of the key is stored, the key itself is shown once on creation
+type APIKey struct {
+	ID         uint   `gorm:"primaryKey"`
+	Name       string `gorm:"not null"`             // What the key is used for, e.g. "CRM"
+	Prefix     string `gorm:"not null"`             // First characters of the key to tell keys apart
+	KeyHash    string `gorm:"not null;uniqueIndex"` // Hex SHA-256 of the key
+	CreatedBy  int64  // Admin who created the key
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time `gorm:"index"`
+	CreatedAt  time.Time
+}
+
+// AdminLoginToken is a one-time link the bot sends to an admin to open the web dashboard
+type AdminLoginToken struct {
+	ID        uint   `gorm:"primaryKey"`
+	TokenHash string `gorm:"not null;uniqueIndex"` // Hex SHA-256 of the token
+	AdminID   int64  `gorm:"not null"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// AdminSession is a signed in browser of the web dashboard
+type AdminSession struct {
+	ID        uint      `gorm:"primaryKey"`
+	TokenHash string    `gorm:"not null;uniqueIndex"` // Hex SHA-256 of the cookie value
+	AdminID   int64     `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
+
+// WebhookStatus represents the delivery status of a webhook
+type WebhookStatus string
+
+// Webhook delivery statuses
+const (
+	WebhookStatusPending   WebhookStatus = "pending" // Waiting to be sent or retried
+	WebhookStatusDelivered WebhookStatus = "delivered"
+	WebhookStatusFailed    WebhookStatus = "failed" // Gave up after retries
+)
+
+// WebhookDelivery is an event queued for POSTing to a webhook URL, kept as the delivery log
+type WebhookDelivery struct {
+	ID            uint          `gorm:"primaryKey"`
+	EventID       string        `gorm:"not null;index"` // Same for all URLs of an event
+	EventType     string        `gorm:"not null;index"` // E.g. "booking.created"
+	URL           string        `gorm:"not null"`
+	Payload       string        `gorm:"type:text;not null"` // JSON body
+	Status        WebhookStatus `gorm:"not null;index"`
+	Attempts      int           `gorm:"default:0"`
+	NextAttemptAt time.Time     `gorm:"index"`
+	ResponseCode  int           // HTTP status of the last attempt, 0 if there was no response
+	LastError     string
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// ErrAuditLogReadOnly is returned when something tries to change or delete audit records
+var ErrAuditLogReadOnly = errors.New("audit log is append-only")
+
+// AuditLog records an admin change, records are never changed or deleted
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey"`
+	ActorType  ActorType `gorm:"not null;index"`
+	ActorID    int64     `gorm:"index"` // Telegram ID of the admin, ID of the API key
+	ActorName  string    // Name of the API key
+	Action     string    `gorm:"not null;index"` // E.g. "service.update"
+	EntityType string    `gorm:"not null;index"` // E.g. "service"
+	EntityID   uint      `gorm:"index"`
+	Before     string    `gorm:"type:text"` // JSON of changed fields before the change, empty for creation
+	After      string    `gorm:"type:text"` // JSON of changed fields after the change, empty for deletion
+	CreatedAt  time.Time `gorm:"index"`
+}
+
+// BeforeUpdate keeps audit records unchanged
+func (AuditLog) BeforeUpdate(*gorm.DB) error {
+	return ErrAuditLogReadOnly
+}
+
+// BeforeDelete keeps audit records in place
+func (AuditLog) BeforeDelete(*gorm.DB) error {
+	return ErrAuditLogReadOnly
+}
@@ -0,0 +1,334 @@
+package i18n
+
+// en is the English message catalog
+var en = map[string]string{
+	// Formatting
+	"price":     "%d RUB",
+	"weekday.0": "Sun",
+	"weekday.1": "Mon",
+	"weekday.2": "Tue",
+	"weekday.3": "Wed",
+	"weekday.4": "Thu",
+	"weekday.5": "Fri",
+	"weekday.6": "Sat",
+	"month.1":   "Jan",
+	"month.2":   "Feb",
+	"month.3":   "Mar",
+	"month.4":   "Apr",
+	"month.5":   "May",
+	"month.6":   "Jun",
+	"month.7":   "Jul",
+	"month.8":   "Aug",
+	"month.9":   "Sep",
+	"month.10":  "Oct",
+	"month.11":  "Nov",
+	"month.12":  "Dec",
+
+	// Plurals
+	"reviews.one":          "%d review",
+	"reviews.other":        "%d reviews",
+	"bookings_found.one":   "%d booking",
+	"bookings_found.other": "%d bookings",
+
+	// Languages
+	"language.en": "🇬🇧 English",
+
+	// Booking statuses
+	"status.pending":   "Awaiting confirmation",
+	"status.confirmed": "Confirmed",
+	"status.cancelled": "Cancelled",
+	"status.completed": "Completed",
+	"status.no_show":   "Missed",
+	"status.rejected":  "Declined",
+
+	// Main menu
+	"menu.catalog":         "📋 Services",
+	"menu.my_bookings":     "📅 My bookings",
+	"menu.discounts":       "🎉 Offers",
+	"menu.gift":            "🎁 Gift cards",
+	"menu.help":            "❓ Help",
+	"menu.profile":         "👤 Profile",
+	"menu.admin":           "🔧 Admin panel",
+	"menu.admin_discounts": "🎉 Manage offers",
+	"menu.webapp":          "📱 Online booking",
+	"menu.main":            "🏠 Main menu",
+	"menu.title":           "🏠 <b>Main menu</b>\n\nChoose an action:",
+	"menu.returned":        "Back to the main menu",
+
+	// Common buttons
+	"button.book_service":       "📝 Book this service",
+	"button.back_to_catalog":    "⬅️ Back to services",
+	"button.no_time":            "❌ No time available",
+	"button.confirm":            "✅ Confirm",
+	"button.apply_certificate":  "🎁 Apply gift card",
+	"button.remove_certificate": "✖️ Remove gift card",
+	"button.cancel":             "❌ Cancel",
+	"button.back":               "⬅️ Back",
+	"button.skip":               "Skip",
+
+	// Errors
+	"error.generic":             "Something went wrong. Please try again later.",
+	"error.registration":        "Registration failed. Please try again later.",
+	"error.action":              "Could not process the action",
+	"error.unknown_action":      "Unknown action",
+	"error.no_access":           "❌ Access denied",
+	"error.no_admin_access":     "❌ You don't have access to the admin panel.",
+	"error.save":                "❌ Could not save. Please try again later.",
+	"error.select_service":      "Could not select the service",
+	"error.select_date":         "Could not select the date",
+	"error.load_service":        "Could not load the service",
+	"error.load_services":       "Could not load services",
+	"error.load_services_retry": "❌ Could not load services. Please try again later.",
+	"error.load_bookings":       "Could not load bookings. Please try again later.",
+	"error.load_discounts":      "❌ Could not load offers. Please try again later.",
+	"error.create_booking":      "❌ Could not create the booking. Please try again later.",
+	"error.not_found":           "❌ Not found. It may have been deleted.",
+	"error.validation":          "❌ Please check the entered data",
+	"error.booking_changed":     "❌ The booking status has already changed",
+	"error.booking_not_movable": "❌ Only upcoming bookings can be moved",
+	"error.stale_button":        "⌛ This button is out of date. Open the menu again with /start",
+
+	// Start and help
+	"start.welcome": "👋 Hi, %s!\n\n" +
+		"Welcome to the booking bot for massage and hair removal services.\n\n" +
+		"Choose an action:",
+	"help.text": "📋 <b>Help:</b>\n\n" +
+		"<b>📋 Services</b>\n" +
+		"Browse all services with descriptions and book\n\n" +
+		"<b>📅 My bookings</b>\n" +
+		"See all your bookings\n\n" +
+		"<b>🎉 Offers</b>\n" +
+		"Current offers and discounts\n\n" +
+		"<b>👤 Profile</b>\n" +
+		"Phone, birthday, preferences and bot language\n\n",
+	"help.admin": "<b>🔧 Admin panel</b>\n" +
+		"Manage bookings, services and offers\n\n",
+	"help.contact": "Contact the administrator with any questions.",
+
+	// Catalog and services
+	"catalog.title": "📋 <b>SERVICES</b>\n\n" +
+		"👇 <i>Tap a service to see the full description and book</i>\n\n",
+	"catalog.item":           "<b>%d. %s</b>\n💰 %s | ⏱ %d min\n📝 %s\n\n",
+	"catalog.empty":          "Sorry, no services are available right now.",
+	"service.header":         "📋 <b>%s</b>\n\n📝 <b>Description:</b>\n%s\n\n",
+	"service.detailed":       "📖 <b>Details:</b>\n%s\n\n",
+	"service.no_detailed":    "ℹ️ <i>No detailed description</i>\n\n",
+	"service.duration_price": "⏱ Duration: <b>%d minutes</b>\n💰 Price: <b>%s</b>",
+	"service.rating":         "\n⭐ Rating: <b>%.1f</b> (%s)",
+
+	// Booking flow
+	"booking.choose_service":   "📋 Choose a service:",
+	"booking.service_selected": "✅ <b>Service: %s</b>\n\n📝 <b>Description:</b>\n%s\n\n",
+	"booking.choose_date":      "📅 <b>Choose a date:</b>",
+	"booking.choose_time":      "⏰ <b>Choose a time:</b>\n\n📅 Date: %s (%s)",
+	"booking.confirm": "✅ <b>Booking confirmation</b>\n\n" +
+		"📋 Service: <b>%s</b>\n" +
+		"📝 Description: %s\n" +
+		"⏱ Duration: %d minutes\n" +
+		"💰 Price: %s\n\n" +
+		"📆 Date: <b>%s (%s)</b>\n" +
+		"⏰ Time: <b>%s</b>\n\n",
+	"booking.certificate_not_applied": "⚠️ Gift card %s was not applied: %s\n\n",
+	"booking.certificate_applied":     "🎁 Gift card <code>%s</code>: −%s\n💳 To pay: <b>%s</b>\n\n",
+	"booking.certificate_failed":      "⚠️ Gift card was not applied: %s\n",
+	"booking.certificate_paid":        "🎁 Paid with gift card: %s\n💳 To pay: %s\n",
+	"booking.confirm_prompt":          "Please confirm the booking:",
+	"booking.choose_another_time":     "Please choose another time in the services catalog.",
+	"booking.created": "⏳ <b>Booking created and awaiting confirmation</b>\n\n" +
+		"📋 Service: <b>%s</b>\n" +
+		"📆 Date: <b>%s (%s)</b>\n" +
+		"⏰ Time: <b>%s</b>\n" +
+		"💰 Price: %s\n" +
+		"%s\n" +
+		"The administrator will review your request and confirm the booking.\n" +
+		"You will be notified about the decision.\n\n" +
+		"Use /my_bookings to see your bookings",
+	"booking.cancelled_action": "❌ Cancelled.\nUse the services catalog to make a new booking.",
+	"slot.invalid_time":        "❌ Invalid time format",
+	"slot.past":                "❌ This time has already passed",
+	"slot.taken":               "❌ This time is already taken. Please choose another time.",
+	"slot.none":                "No free time on this day, please pick another date",
+
+	// Booking limits
+	"limit.blocked_reason": "❌ Booking through the bot is unavailable.\nReason: %s\n\nPlease contact the administrator.",
+	"limit.blocked":        "❌ Booking through the bot is unavailable. Please contact the administrator.",
+	"limit.active":         "❌ You already have too many active bookings.\nWait for your visit or cancel one of them in /my_bookings.",
+	"limit.per_day":        "❌ Daily booking limit reached. Try again tomorrow or contact the administrator.",
+	"limit.too_frequent":   "⏳ Too many booking attempts. Please wait a little.",
+	"rate_limit.short":     "⏳ Too many requests. Please wait a little.",
+	"rate_limit.long":      "⏳ Too many requests. Please wait a little and try again.",
+
+	// My bookings and cancellation
+	"bookings.title": "📅 <b>Your bookings:</b>\n\n",
+	"bookings.empty": "You have no bookings yet.\nUse the services catalog to make one.",
+	"bookings.item": "%d. %s <b>%s</b>\n" +
+		"   📍 %s\n" +
+		"   📆 %s at %s\n" +
+		"   💰 %s\n" +
+		"   %s %s\n\n",
+	"cancel.empty":  "You have no active bookings to cancel.",
+	"cancel.choose": "❌ Choose a booking to cancel:",
+	"cancel.done":   "✅ Booking cancelled!\n\nUse /book to make a new booking",
+
+	// Discounts
+	"discounts.title": "🎉 <b>Current offers:</b>\n\n",
+	"discounts.empty": "🎉 <b>Offers</b>\n\n" +
+		"There are no active offers right now.\n\n" +
+		"Stay tuned! We run special offers regularly.",
+	"discounts.item": "%d. <b>%s</b>\n" +
+		"   📋 Service: %s\n" +
+		"   💰 Discount: %d%%\n" +
+		"   💵 Price: <s>%s</s> <b>%s</b>\n" +
+		"   📅 Valid until: %s\n\n",
+	"discounts.hint": "💡 <i>To get the discount, choose the service when booking.</i>",
+
+	// Profile
+	"profile.text": "👤 <b>My profile</b>\n\n" +
+		"Name: <b>%s %s</b>\n" +
+		"📱 Phone: %s\n" +
+		"🎂 Birthday: %s\n" +
+		"⚠️ Allergies and contraindications: %s\n" +
+		"💆 Preferred specialist: %s\n" +
+		"🌐 Language: %s",
+	"profile.not_set":                 "<i>not set</i>",
+	"profile.language_auto":           "%s (automatic)",
+	"profile.button.phone":            "📱 Phone",
+	"profile.button.birthday":         "🎂 Birthday",
+	"profile.button.allergies":        "⚠️ Allergies",
+	"profile.button.specialist":       "💆 Specialist",
+	"profile.button.language":         "🌐 Language",
+	"profile.button.language_auto":    "🔄 Automatic",
+	"profile.button.clear_birthday":   "✖️ Birthday",
+	"profile.button.clear_allergies":  "✖️ Allergies",
+	"profile.button.clear_specialist": "✖️ Specialist",
+	"profile.phone_prompt":            "📱 Tap the button below to share your phone number or type it in.",
+	"profile.phone_later":             "OK, you can add the number later.",
+	"profile.phone_invalid":           "❌ Invalid number. Enter the phone as +7 900 123-45-67 or tap the button below.",
+	"profile.birthday_prompt":         "🎂 Enter your birthday as <b>DD.MM.YYYY</b>\nA gift will be waiting for you on your birthday 🎁",
+	"profile.birthday_invalid":        "❌ Invalid date. Use the DD.MM.YYYY format, e.g. 15.03.1990",
+	"profile.allergies_prompt":        "⚠️ Describe allergies and contraindications (e.g. nut oil allergy, varicose veins).\nThe specialist will take them into account.",
+	"profile.specialist_prompt":       "💆 Enter the name of your preferred specialist:",
+	"profile.language_prompt":         "🌐 Choose the bot language.\n\n“Automatic” uses the language of your Telegram settings.",
+
+	// Phone sharing
+	"contact.share":          "📱 Share phone number",
+	"contact.skip":           "Skip",
+	"contact.saved":          "✅ Phone saved",
+	"contact.skipped":        "OK, continuing without a phone number.",
+	"contact.not_own":        "❌ Please share your own number with the button below.",
+	"contact.save_failed":    "❌ Could not save the number. Please try again later.",
+	"contact.invalid":        "❌ Invalid number. Tap the button below, enter the phone as +7 900 123-45-67 or tap “Skip”.",
+	"contact.booking_prompt": "📱 Share your phone number so the administrator can contact you if needed.\n\nTap the button below or “Skip”.",
+
+	// Reviews
+	"review.save_failed": "Could not save the rating",
+	"review.thanks_rating": "🙏 <b>Thank you for the rating!</b>\n\n" +
+		"%s\n" +
+		"📋 %s\n\n" +
+		"Would you like to add a comment? Just send it as a message.",
+	"review.thanks":         "🙏 Thank you for the rating! We look forward to seeing you again 🌟",
+	"review.thanks_comment": "🙏 Thank you for the review! We look forward to seeing you again 🌟",
+	"review.comment_failed": "❌ Could not save the comment. Please try again later.",
+
+	// Gift certificates
+	"gift.menu": "🎁 <b>Gift cards</b>\n\n" +
+		"A gift card can be applied when confirming a booking — " +
+		"its amount is deducted from the price and the rest is kept for future visits.\n\n",
+	"gift.choose_amount":              "Choose an amount to buy a gift card:",
+	"gift.ask_admin":                  "Contact the administrator to buy a gift card.",
+	"gift.button.check_code":          "🔎 Check a code",
+	"gift.button.share":               "📤 Send as a gift",
+	"gift.enter_code":                 "🎁 Send the gift card code (e.g. GIFT-ABCD-EFGH):",
+	"gift.auto_apply":                 "✨ The gift card will be applied automatically when you confirm a booking.",
+	"gift.payment_unavailable":        "Payment is temporarily unavailable",
+	"gift.invoice_title":              "Gift card",
+	"gift.invoice_description":        "Gift card for %s on any service, valid for %d months.",
+	"gift.invoice_label":              "Gift card",
+	"gift.invoice_failed":             "Could not create the invoice",
+	"gift.unknown_order":              "Unknown order",
+	"gift.issue_failed":               "❌ Payment received, but the gift card could not be created. The administrator will contact you.",
+	"gift.thanks":                     "✅ <b>Thank you for your purchase!</b>\n\n",
+	"gift.link":                       "\n\n🔗 Link for the recipient:\n%s",
+	"gift.share_text":                 "🎁 Here is a gift card for you! Open the link to book.",
+	"certificate.header":              "🎁 <b>Gift card</b>\n\n🔑 Code: <code>%s</code>\n",
+	"certificate.service":             "📋 Service: <b>%s</b>\n",
+	"certificate.amount":              "💰 Amount: <b>%s</b>\n💳 Balance: <b>%s</b>\n",
+	"certificate.footer":              "📅 Valid until: %s\nStatus: %s",
+	"certificate.status.active":       "✅ Active",
+	"certificate.status.expired":      "⌛ Expired",
+	"certificate.status.used":         "✔️ Used",
+	"certificate.status.voided":       "🚫 Voided",
+	"certificate.status.unknown":      "❓ Unknown",
+	"certificate.error.not_found":     "Gift card not found. Please check the code.",
+	"certificate.error.expired":       "The gift card has expired.",
+	"certificate.error.inactive":      "The gift card has already been used or voided.",
+	"certificate.error.wrong_service": "The gift card is for a different service.",
+	"certificate.error.empty":         "The gift card has no balance left.",
+	"certificate.error.generic":       "Could not apply the gift card.",
+
+	// Invites for clients booked by admins
+	"invite.invalid": "❌ The link is invalid or has already been used",
+	"invite.claimed": "👋 Hi, %s!\n\n" +
+		"✅ We found your bookings (%s) and added them to the bot.\n" +
+		"You will now get visit reminders. See your bookings in “My bookings”.",
+
+	// Notification templates, Go text/template syntax, see services.TemplateData for fields
+	"template.booking_confirmed": "✅ <b>Your booking is confirmed!</b>\n\n" +
+		"📋 Service: <b>{{.Service}}</b>\n" +
+		"📆 Date: <b>{{.Date}} ({{.Weekday}})</b>\n" +
+		"⏰ Time: <b>{{.Time}}</b>\n" +
+		"💰 Price: {{.Price}}\n\n" +
+		"We look forward to seeing you! 🌟\n" +
+		"We will send a reminder the day before your visit.",
+	"template.booking_cancelled": "❌ <b>Booking cancelled</b>\n\n" +
+		"📋 Service: {{.Service}}\n" +
+		"📆 Date: {{.Date}} at {{.Time}}\n\n" +
+		"You can make a new booking with /book",
+	"template.booking_rejected": "❌ <b>Your booking was cancelled by the administrator</b>\n\n" +
+		"📋 Service: {{.Service}}\n" +
+		"📆 Date: {{.Date}} at {{.Time}}\n\n" +
+		"You can make a new booking in the services catalog",
+	"template.booking_rescheduled": "🔄 <b>Your booking was rescheduled</b>\n\n" +
+		"📋 Service: <b>{{.Service}}</b>\n" +
+		"📆 New date: <b>{{.Date}} ({{.Weekday}})</b>\n" +
+		"⏰ New time: <b>{{.Time}}</b>\n\n" +
+		"If the time doesn't suit you, contact us or cancel the booking in “My bookings”.",
+	"template.reminder_day": "🔔 <b>Booking reminder</b>\n\n" +
+		"Tomorrow, {{.Date}} ({{.Weekday}}), at <b>{{.Time}}</b> you have a booking:\n" +
+		"📋 {{.Service}}\n" +
+		"⏱ Duration: {{.Duration}} min\n" +
+		"💰 Price: {{.Price}}\n\n" +
+		"We look forward to seeing you! 🌟",
+	"template.reminder_hour": "⏰ <b>Reminder: your booking is in an hour!</b>\n\n" +
+		"📋 Service: <b>{{.Service}}</b>\n" +
+		"⏰ Time: <b>{{.Time}}</b>\n" +
+		"💰 Price: {{.Price}}\n\n" +
+		"See you soon! 🌟",
+	"template.review_request": "🌟 <b>How was your visit?</b>\n\n" +
+		"📋 {{.Service}}\n" +
+		"📆 {{.Date}} at {{.Time}}\n\n" +
+		"Please rate the service — it helps us improve:",
+
+	// Mini App
+	"webapp.title":         "Online booking",
+	"webapp.services":      "Choose a service",
+	"webapp.minutes":       "%d min",
+	"webapp.date":          "Choose a date",
+	"webapp.time":          "Choose a time",
+	"webapp.no_time":       "No free time on this day",
+	"webapp.day_off":       "Day off",
+	"webapp.confirm":       "Check your booking",
+	"webapp.service":       "Service",
+	"webapp.when":          "Date and time",
+	"webapp.price":         "Price",
+	"webapp.phone":         "Contact phone (optional)",
+	"webapp.phone_invalid": "Check the phone number, e.g. +1 555 123-4567",
+	"webapp.book":          "Book",
+	"webapp.back":          "Back",
+	"webapp.created":       "Booking created and awaiting confirmation. Details are in the chat with the bot.",
+	"webapp.close":         "Done",
+
+	// Calendar files
+	"calendar.caption":     "📅 Open the file to add the booking to your phone calendar",
+	"calendar.description": "Duration: %d min\nPrice: %s",
+}
@@ -0,0 +1,157 @@
+// Package i18n provides message catalogs and locale-aware formatting
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Supported languages
+const (
+	Russian = "ru"
+	English = "en"
+
+	// Default is used when the user's language is unknown or not supported
+	Default = Russian
+)
+
+// catalogs maps language to message key to translation
+// Plural messages use keys with a plural form suffix: ".one", ".few", ".many", ".other"
+var catalogs = map[string]map[string]string{
+	Russian: ru,
+	English: en,
+}
+
+// Supported returns codes of all supported languages
+func Supported() []string {
+	return []string{Russian, English}
+}
+
+// IsSupported reports whether the language has a message catalog
+func IsSupported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Detect returns a supported language for a Telegram language code such as "en-US"
+func Detect(languageCode string) string {
+	code := strings.ToLower(strings.TrimSpace(languageCode))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+
+	if IsSupported(code) {
+		return code
+	}
+
+	// Russian is the most familiar language for users of neighbouring locales
+	switch code {
+	case "uk", "be", "kk", "uz", "ky", "hy", "az", "ka", "tg":
+		return Russian
+	case "":
+		return Default
+	default:
+		return English
+	}
+}
+
+// Resolve returns the language to talk to a user in
+// override is the language chosen by the user, empty means automatic detection
+func Resolve(override, languageCode string) string {
+	if IsSupported(override) {
+		return override
+	}
+	return Detect(languageCode)
+}
+
+// T returns the translated message for key, formatted with args
+// Falls back to the default language and then to the key itself
+func T(lang, key string, args ...interface{}) string {
+	msg, ok := lookup(lang, key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// N returns the plural form of the message for n, formatted with n followed by args
+func N(lang, key string, n int64, args ...interface{}) string {
+	msg, ok := lookup(lang, key+"."+pluralForm(lang, n))
+	if !ok {
+		msg, ok = lookup(lang, key+".other")
+	}
+	if !ok {
+		return key
+	}
+	return fmt.Sprintf(msg, append([]interface{}{n}, args...)...)
+}
+
+// lookup finds a message in the language catalog or the default one
+func lookup(lang, key string) (string, bool) {
+	if catalog, ok := catalogs[lang]; ok {
+		if msg, ok := catalog[key]; ok {
+			return msg, true
+		}
+	}
+	msg, ok := catalogs[Default][key]
+	return msg, ok
+}
+
+// pluralForm returns the CLDR plural category of n for the language
+func pluralForm(lang string, n int64) string {
+	if n < 0 {
+		n = -n
+	}
+
+	switch lang {
+	case Russian:
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 10 || n%100 >= 20):
+			return "few"
+		default:
+			return "many"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
+
+// Weekday returns the short weekday name of t
+func Weekday(lang string, t time.Time) string {
+	return T(lang, fmt.Sprintf("weekday.%d", int(t.Weekday())))
+}
+
+// Month returns the month name of t
+func Month(lang string, t time.Time) string {
+	return T(lang, fmt.Sprintf("month.%d", int(t.Month())))
+}
+
+// Date formats a date with the year, e.g. "15.03.2025" or "Mar 15, 2025"
+func Date(lang string, t time.Time) string {
+	if lang == English {
+		return fmt.Sprintf("%s %d, %d", Month(lang, t), t.Day(), t.Year())
+	}
+	return t.Format("02.01.2006")
+}
+
+// ShortDate formats a date without the year, e.g. "15.03" or "Mar 15"
+func ShortDate(lang string, t time.Time) string {
+	if lang == English {
+		return fmt.Sprintf("%s %d", Month(lang, t), t.Day())
+	}
+	return t.Format("02.01")
+}
+
+// Price formats an amount in the smallest currency unit, e.g. "2500 руб." or "2500 RUB"
+func Price(lang string, amount int) string {
+	return T(lang, "price", amount/100)
+}
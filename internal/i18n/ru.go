@@ -0,0 +1,350 @@
+package i18n
+
+// ru is the Russian message catalog, it is also the fallback for missing translations
+var ru = map[string]string{
+	// Formatting
+	"price":     "%d руб.",
+	"weekday.0": "Вс",
+	"weekday.1": "Пн",
+	"weekday.2": "Вт",
+	"weekday.3": "Ср",
+	"weekday.4": "Чт",
+	"weekday.5": "Пт",
+	"weekday.6": "Сб",
+	"month.1":   "января",
+	"month.2":   "февраля",
+	"month.3":   "марта",
+	"month.4":   "апреля",
+	"month.5":   "мая",
+	"month.6":   "июня",
+	"month.7":   "июля",
+	"month.8":   "августа",
+	"month.9":   "сентября",
+	"month.10":  "октября",
+	"month.11":  "ноября",
+	"month.12":  "декабря",
+
+	// Plurals
+	"reviews.one":         "%d отзыв",
+	"reviews.few":         "%d отзыва",
+	"reviews.many":        "%d отзывов",
+	"bookings_found.one":  "%d запись",
+	"bookings_found.few":  "%d записи",
+	"bookings_found.many": "%d записей",
+
+	// Languages
+	"language.ru": "🇷🇺 Русский",
+
+	// Booking statuses
+	"status.pending":   "Ожидает подтверждения",
+	"status.confirmed": "Подтверждено",
+	"status.cancelled": "Отменено",
+	"status.completed": "Завершено",
+	"status.no_show":   "Клиент не пришел",
+	"status.rejected":  "Отклонено",
+
+	// Main menu
+	"menu.catalog":         "📋 Каталог услуг",
+	"menu.my_bookings":     "📅 Мои записи",
+	"menu.discounts":       "🎉 Акции",
+	"menu.gift":            "🎁 Сертификаты",
+	"menu.help":            "❓ Помощь",
+	"menu.profile":         "👤 Профиль",
+	"menu.admin":           "🔧 Админ-панель",
+	"menu.admin_discounts": "🎉 Управление акциями",
+	"menu.webapp":          "📱 Онлайн-запись",
+	"menu.main":            "🏠 Главное меню",
+	"menu.title":           "🏠 <b>Главное меню</b>\n\nВыберите действие:",
+	"menu.returned":        "Возврат в главное меню",
+
+	// Common buttons
+	"button.book_service":       "📝 Записаться на эту услугу",
+	"button.back_to_catalog":    "⬅️ Назад к каталогу",
+	"button.no_time":            "❌ Нет доступного времени",
+	"button.confirm":            "✅ Подтвердить",
+	"button.apply_certificate":  "🎁 Применить сертификат",
+	"button.remove_certificate": "✖️ Убрать сертификат",
+	"button.cancel":             "❌ Отмена",
+	"button.back":               "⬅️ Назад",
+	"button.skip":               "Пропустить",
+
+	// Errors
+	"error.generic":             "Произошла ошибка. Попробуйте позже.",
+	"error.registration":        "Произошла ошибка при регистрации. Попробуйте позже.",
+	"error.action":              "Ошибка обработки действия",
+	"error.unknown_action":      "Неизвестное действие",
+	"error.no_access":           "❌ Нет доступа",
+	"error.no_admin_access":     "❌ У вас нет доступа к админ-панели.",
+	"error.save":                "❌ Не удалось сохранить. Попробуйте позже.",
+	"error.select_service":      "Ошибка выбора услуги",
+	"error.select_date":         "Ошибка выбора даты",
+	"error.load_service":        "Ошибка загрузки услуги",
+	"error.load_services":       "Ошибка при загрузке услуг",
+	"error.load_services_retry": "❌ Ошибка при загрузке услуг. Попробуйте позже.",
+	"error.load_bookings":       "Ошибка при загрузке записей. Попробуйте позже.",
+	"error.load_discounts":      "❌ Ошибка при загрузке акций. Попробуйте позже.",
+	"error.create_booking":      "❌ Ошибка при создании записи. Попробуйте позже.",
+	"error.not_found":           "❌ Не найдено. Возможно, это уже удалили.",
+	"error.validation":          "❌ Проверьте введённые данные",
+	"error.booking_changed":     "❌ Статус записи уже изменился",
+	"error.booking_not_movable": "❌ Перенести можно только предстоящую запись",
+	"error.stale_button":        "⌛ Эта кнопка устарела. Откройте меню заново: /start",
+
+	// Start and help
+	"start.welcome": "👋 Привет, %s!\n\n" +
+		"Добро пожаловать в систему записи на услуги массажа и депиляции.\n\n" +
+		"Выберите действие:",
+	"help.text": "📋 <b>Справка:</b>\n\n" +
+		"<b>📋 Каталог услуг</b>\n" +
+		"Просмотр всех услуг с описаниями и запись\n\n" +
+		"<b>📅 Мои записи</b>\n" +
+		"Просмотр всех ваших записей\n\n" +
+		"<b>🎉 Акции</b>\n" +
+		"Просмотр текущих акций и скидок\n\n" +
+		"<b>👤 Профиль</b>\n" +
+		"Телефон, день рождения, пожелания и язык бота\n\n",
+	"help.admin": "<b>🔧 Админ-панель</b>\n" +
+		"Управление записями, услугами и акциями\n\n",
+	"help.contact": "По всем вопросам обращайтесь к администратору.",
+
+	// Catalog and services
+	"catalog.title": "📋 <b>КАТАЛОГ УСЛУГ</b>\n\n" +
+		"👇 <i>Нажмите на услугу, чтобы увидеть полное описание и записаться</i>\n\n",
+	"catalog.item":           "<b>%d. %s</b>\n💰 %s | ⏱ %d мин\n📝 %s\n\n",
+	"catalog.empty":          "К сожалению, сейчас нет доступных услуг.",
+	"service.header":         "📋 <b>%s</b>\n\n📝 <b>Описание:</b>\n%s\n\n",
+	"service.detailed":       "📖 <b>Подробное описание:</b>\n%s\n\n",
+	"service.no_detailed":    "ℹ️ <i>Подробное описание отсутствует</i>\n\n",
+	"service.duration_price": "⏱ Длительность: <b>%d минут</b>\n💰 Стоимость: <b>%s</b>",
+	"service.rating":         "\n⭐ Рейтинг: <b>%.1f</b> (%s)",
+
+	// Booking flow
+	"booking.choose_service":   "📋 Выберите услугу:",
+	"booking.service_selected": "✅ <b>Выбрана услуга: %s</b>\n\n📝 <b>Описание:</b>\n%s\n\n",
+	"booking.choose_date":      "📅 <b>Выберите дату:</b>",
+	"booking.choose_time":      "⏰ <b>Выберите время:</b>\n\n📅 Дата: %s (%s)",
+	"booking.confirm": "✅ <b>Подтверждение записи</b>\n\n" +
+		"📋 Услуга: <b>%s</b>\n" +
+		"📝 Описание: %s\n" +
+		"⏱ Длительность: %d минут\n" +
+		"💰 Стоимость: %s\n\n" +
+		"📆 Дата: <b>%s (%s)</b>\n" +
+		"⏰ Время: <b>%s</b>\n\n",
+	"booking.certificate_not_applied": "⚠️ Сертификат %s не применен: %s\n\n",
+	"booking.certificate_applied":     "🎁 Сертификат <code>%s</code>: −%s\n💳 К оплате: <b>%s</b>\n\n",
+	"booking.certificate_failed":      "⚠️ Сертификат не применен: %s\n",
+	"booking.certificate_paid":        "🎁 Оплачено сертификатом: %s\n💳 К оплате: %s\n",
+	"booking.confirm_prompt":          "Подтвердите запись:",
+	"booking.choose_another_time":     "Пожалуйста, выберите другое время в каталоге услуг.",
+	"booking.created": "⏳ <b>Запись создана и ожидает подтверждения</b>\n\n" +
+		"📋 Услуга: <b>%s</b>\n" +
+		"📆 Дата: <b>%s (%s)</b>\n" +
+		"⏰ Время: <b>%s</b>\n" +
+		"💰 Стоимость: %s\n" +
+		"%s\n" +
+		"Администратор рассмотрит вашу заявку и подтвердит запись.\n" +
+		"Вы получите уведомление о решении.\n\n" +
+		"Для просмотра записей используйте /my_bookings",
+	"booking.cancelled_action": "❌ Действие отменено.\nИспользуйте каталог услуг для новой записи.",
+	"slot.invalid_time":        "❌ Неверный формат времени",
+	"slot.past":                "❌ Нельзя записаться на прошедшее время",
+	"slot.taken":               "❌ Это время уже занято. Выберите другое время.",
+	"slot.none":                "На этот день свободного времени нет, выберите другую дату",
+
+	// Booking limits
+	"limit.blocked_reason": "❌ Запись через бота недоступна.\nПричина: %s\n\nПо вопросам обращайтесь к администратору.",
+	"limit.blocked":        "❌ Запись через бота недоступна. Пожалуйста, свяжитесь с администратором.",
+	"limit.active":         "❌ У вас уже много активных записей.\nДождитесь визита или отмените одну из записей в /my_bookings.",
+	"limit.per_day":        "❌ Достигнут лимит новых записей за сутки. Попробуйте завтра или свяжитесь с администратором.",
+	"limit.too_frequent":   "⏳ Слишком частые попытки записи. Подождите немного.",
+	"rate_limit.short":     "⏳ Слишком много запросов. Подождите немного.",
+	"rate_limit.long":      "⏳ Слишком много запросов. Подождите немного и попробуйте снова.",
+
+	// My bookings and cancellation
+	"bookings.title": "📅 <b>Ваши записи:</b>\n\n",
+	"bookings.empty": "У вас пока нет записей.\nИспользуйте каталог услуг для создания записи.",
+	"bookings.item": "%d. %s <b>%s</b>\n" +
+		"   📍 %s\n" +
+		"   📆 %s в %s\n" +
+		"   💰 %s\n" +
+		"   %s %s\n\n",
+	"cancel.empty":  "У вас нет активных записей для отмены.",
+	"cancel.choose": "❌ Выберите запись для отмены:",
+	"cancel.done":   "✅ Запись успешно отменена!\n\nДля создания новой записи используйте /book",
+
+	// Discounts
+	"discounts.title": "🎉 <b>Актуальные акции:</b>\n\n",
+	"discounts.empty": "🎉 <b>Акции</b>\n\n" +
+		"К сожалению, сейчас нет активных акций.\n\n" +
+		"Следите за обновлениями! Мы регулярно проводим специальные предложения.",
+	"discounts.item": "%d. <b>%s</b>\n" +
+		"   📋 Услуга: %s\n" +
+		"   💰 Скидка: %d%%\n" +
+		"   💵 Цена: <s>%s</s> <b>%s</b>\n" +
+		"   📅 Действует до: %s\n\n",
+	"discounts.hint": "💡 <i>Чтобы записаться на услугу со скидкой, выберите услугу при бронировании.</i>",
+
+	// Profile
+	"profile.text": "👤 <b>Мой профиль</b>\n\n" +
+		"Имя: <b>%s %s</b>\n" +
+		"📱 Телефон: %s\n" +
+		"🎂 День рождения: %s\n" +
+		"⚠️ Аллергии и противопоказания: %s\n" +
+		"💆 Предпочитаемый мастер: %s\n" +
+		"🌐 Язык: %s",
+	"profile.not_set":                 "<i>не указано</i>",
+	"profile.language_auto":           "%s (автоматически)",
+	"profile.button.phone":            "📱 Телефон",
+	"profile.button.birthday":         "🎂 День рождения",
+	"profile.button.allergies":        "⚠️ Аллергии",
+	"profile.button.specialist":       "💆 Мастер",
+	"profile.button.language":         "🌐 Язык",
+	"profile.button.language_auto":    "🔄 Автоматически",
+	"profile.button.clear_birthday":   "✖️ ДР",
+	"profile.button.clear_allergies":  "✖️ Аллергии",
+	"profile.button.clear_specialist": "✖️ Мастер",
+	"profile.phone_prompt":            "📱 Нажмите кнопку ниже, чтобы поделиться номером телефона, или введите его вручную.",
+	"profile.phone_later":             "Хорошо, номер можно добавить позже.",
+	"profile.phone_invalid":           "❌ Неверный номер. Введите телефон в формате +7 900 123-45-67 или нажмите кнопку ниже.",
+	"profile.birthday_prompt":         "🎂 Введите дату рождения в формате <b>ДД.ММ.ГГГГ</b>\nВ день рождения вас будет ждать подарок 🎁",
+	"profile.birthday_invalid":        "❌ Неверная дата. Введите в формате ДД.ММ.ГГГГ, например 15.03.1990",
+	"profile.allergies_prompt":        "⚠️ Опишите аллергии и противопоказания (например: аллергия на масла с орехами, варикоз).\nМастер учтет их во время процедуры.",
+	"profile.specialist_prompt":       "💆 Напишите имя мастера, к которому вы предпочитаете записываться:",
+	"profile.language_prompt":         "🌐 Выберите язык бота.\n\n«Автоматически» — язык из настроек Telegram.",
+
+	// Phone sharing
+	"contact.share":          "📱 Отправить номер телефона",
+	"contact.skip":           "Пропустить",
+	"contact.saved":          "✅ Телефон сохранен",
+	"contact.skipped":        "Хорошо, продолжаем без номера.",
+	"contact.not_own":        "❌ Пожалуйста, отправьте свой номер с помощью кнопки ниже.",
+	"contact.save_failed":    "❌ Не удалось сохранить номер. Попробуйте позже.",
+	"contact.invalid":        "❌ Неверный номер. Нажмите кнопку ниже, введите телефон в формате +7 900 123-45-67 или нажмите «Пропустить».",
+	"contact.booking_prompt": "📱 Поделитесь номером телефона, чтобы администратор мог связаться с вами при необходимости.\n\nНажмите кнопку ниже или «Пропустить».",
+
+	// Reviews
+	"review.save_failed": "Не удалось сохранить оценку",
+	"review.thanks_rating": "🙏 <b>Спасибо за оценку!</b>\n\n" +
+		"%s\n" +
+		"📋 %s\n\n" +
+		"Хотите добавить комментарий? Просто напишите его сообщением.",
+	"review.thanks":         "🙏 Спасибо за оценку! Будем рады видеть вас снова 🌟",
+	"review.thanks_comment": "🙏 Спасибо за отзыв! Будем рады видеть вас снова 🌟",
+	"review.comment_failed": "❌ Не удалось сохранить комментарий. Попробуйте позже.",
+
+	// Gift certificates
+	"gift.menu": "🎁 <b>Подарочные сертификаты</b>\n\n" +
+		"Сертификат можно использовать при подтверждении записи — " +
+		"его сумма спишется со стоимости услуги, а остаток сохранится для следующих визитов.\n\n",
+	"gift.choose_amount":              "Выберите номинал, чтобы купить сертификат в подарок:",
+	"gift.ask_admin":                  "Чтобы приобрести сертификат, обратитесь к администратору.",
+	"gift.button.check_code":          "🔎 Проверить код",
+	"gift.button.share":               "📤 Отправить в подарок",
+	"gift.enter_code":                 "🎁 Отправьте код сертификата (например: GIFT-ABCD-EFGH):",
+	"gift.auto_apply":                 "✨ Сертификат будет применен автоматически при подтверждении записи.",
+	"gift.payment_unavailable":        "Оплата временно недоступна",
+	"gift.invoice_title":              "Подарочный сертификат",
+	"gift.invoice_description":        "Сертификат на %s на любые услуги, действует %d мес.",
+	"gift.invoice_label":              "Сертификат",
+	"gift.invoice_failed":             "Ошибка создания счета",
+	"gift.unknown_order":              "Неизвестный заказ",
+	"gift.issue_failed":               "❌ Оплата получена, но сертификат не удалось создать. Администратор свяжется с вами.",
+	"gift.thanks":                     "✅ <b>Спасибо за покупку!</b>\n\n",
+	"gift.link":                       "\n\n🔗 Ссылка для получателя:\n%s",
+	"gift.share_text":                 "🎁 Дарю вам подарочный сертификат! Откройте ссылку, чтобы записаться.",
+	"certificate.header":              "🎁 <b>Подарочный сертификат</b>\n\n🔑 Код: <code>%s</code>\n",
+	"certificate.service":             "📋 Услуга: <b>%s</b>\n",
+	"certificate.amount":              "💰 Номинал: <b>%s</b>\n💳 Остаток: <b>%s</b>\n",
+	"certificate.footer":              "📅 Действует до: %s\nСтатус: %s",
+	"certificate.status.active":       "✅ Активен",
+	"certificate.status.expired":      "⌛ Истек",
+	"certificate.status.used":         "✔️ Использован",
+	"certificate.status.voided":       "🚫 Аннулирован",
+	"certificate.status.unknown":      "❓ Неизвестно",
+	"certificate.error.not_found":     "Сертификат не найден. Проверьте код.",
+	"certificate.error.expired":       "Срок действия сертификата истек.",
+	"certificate.error.inactive":      "Сертификат уже использован или аннулирован.",
+	"certificate.error.wrong_service": "Сертификат выпущен на другую услугу.",
+	"certificate.error.empty":         "На сертификате не осталось средств.",
+	"certificate.error.generic":       "Не удалось применить сертификат.",
+
+	// Invites for clients booked by admins
+	"invite.invalid": "❌ Ссылка недействительна или уже использована",
+	"invite.claimed": "👋 Привет, %s!\n\n" +
+		"✅ Мы нашли ваши записи (%s) и добавили их в бот.\n" +
+		"Теперь вы будете получать напоминания о визитах. Посмотреть записи можно в разделе «Мои записи».",
+
+	// Notification templates, Go text/template syntax, see services.TemplateData for fields
+	"template.booking_confirmed": "✅ <b>Ваша запись подтверждена!</b>\n\n" +
+		"📋 Услуга: <b>{{.Service}}</b>\n" +
+		"📆 Дата: <b>{{.Date}} ({{.Weekday}})</b>\n" +
+		"⏰ Время: <b>{{.Time}}</b>\n" +
+		"💰 Стоимость: {{.Price}}\n\n" +
+		"Мы ждем вас! 🌟\n" +
+		"За день до визита мы отправим напоминание.",
+	"template.booking_cancelled": "❌ <b>Запись отменена</b>\n\n" +
+		"📋 Услуга: {{.Service}}\n" +
+		"📆 Дата: {{.Date}} в {{.Time}}\n\n" +
+		"Вы можете создать новую запись с помощью команды /book",
+	"template.booking_rejected": "❌ <b>Ваша запись была отменена администратором</b>\n\n" +
+		"📋 Услуга: {{.Service}}\n" +
+		"📆 Дата: {{.Date}} в {{.Time}}\n\n" +
+		"Вы можете создать новую запись через каталог услуг",
+	"template.booking_rescheduled": "🔄 <b>Ваша запись перенесена</b>\n\n" +
+		"📋 Услуга: <b>{{.Service}}</b>\n" +
+		"📆 Новая дата: <b>{{.Date}} ({{.Weekday}})</b>\n" +
+		"⏰ Новое время: <b>{{.Time}}</b>\n\n" +
+		"Если время не подходит, свяжитесь с нами или отмените запись через «Мои записи».",
+	"template.reminder_day": "🔔 <b>Напоминание о записи</b>\n\n" +
+		"Завтра, {{.Date}} ({{.Weekday}}), в <b>{{.Time}}</b> у вас запись:\n" +
+		"📋 {{.Service}}\n" +
+		"⏱ Длительность: {{.Duration}} мин\n" +
+		"💰 Стоимость: {{.Price}}\n\n" +
+		"Будем рады вас видеть! 🌟",
+	"template.reminder_hour": "⏰ <b>Напоминание: запись через час!</b>\n\n" +
+		"📋 Услуга: <b>{{.Service}}</b>\n" +
+		"⏰ Время: <b>{{.Time}}</b>\n" +
+		"💰 Стоимость: {{.Price}}\n\n" +
+		"До встречи! 🌟",
+	"template.review_request": "🌟 <b>Как прошел ваш визит?</b>\n\n" +
+		"📋 {{.Service}}\n" +
+		"📆 {{.Date}} в {{.Time}}\n\n" +
+		"Оцените, пожалуйста, услугу — это поможет нам стать лучше:",
+	"template.admin_new_booking": "🔔 <b>Новая запись!</b>\n\n" +
+		"👤 {{.ClientName}} (@{{.Username}})\n" +
+		"📞 {{if .Phone}}{{.Phone}}{{else}}не указан{{end}}\n" +
+		"📋 {{.Service}}\n" +
+		"📆 {{.Date}} в {{.Time}}\n" +
+		"💰 {{.Price}}\n" +
+		"{{.Certificate}}\n" +
+		"Подтвердите или отмените запись:",
+	"template.promotion": "🎉 <b>{{.Discount}}</b>\n\n" +
+		"📋 Услуга: <b>{{.Service}}</b>\n" +
+		"💰 Скидка: <b>{{.Percentage}}%</b>\n" +
+		"💵 Цена: <s>{{.OldPrice}}</s> <b>{{.NewPrice}}</b>\n" +
+		"📅 Действует: {{.StartDate}} - {{.EndDate}}\n\n" +
+		"Записывайтесь через бота! 👇",
+
+	// Mini App
+	"webapp.title":         "Онлайн-запись",
+	"webapp.services":      "Выберите услугу",
+	"webapp.minutes":       "%d мин",
+	"webapp.date":          "Выберите дату",
+	"webapp.time":          "Выберите время",
+	"webapp.no_time":       "На этот день свободного времени нет",
+	"webapp.day_off":       "Выходной",
+	"webapp.confirm":       "Проверьте запись",
+	"webapp.service":       "Услуга",
+	"webapp.when":          "Дата и время",
+	"webapp.price":         "Стоимость",
+	"webapp.phone":         "Телефон для связи (необязательно)",
+	"webapp.phone_invalid": "Проверьте номер телефона, например +7 900 123-45-67",
+	"webapp.book":          "Записаться",
+	"webapp.back":          "Назад",
+	"webapp.created":       "Запись создана и ожидает подтверждения. Подробности — в чате с ботом.",
+	"webapp.close":         "Готово",
+
+	// Calendar files
+	"calendar.caption":     "📅 Добавьте запись в календарь телефона — откройте файл",
+	"calendar.description": "Длительность: %d мин\nСтоимость: %s",
+}
@@ -0,0 +1,99 @@
+// Package logging sets up structured logging with attributes carried in the context
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"slices"
+)
+
+// Log formats
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Setup makes a logger of the level and format the default one
+// Messages of the log package go to the same logger
+func Setup(w io.Writer, level slog.Level, format string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	if format == FormatText {
+		handler = slog.NewTextHandler(w, options)
+	} else {
+		handler = slog.NewJSONHandler(w, options)
+	}
+
+	logger := slog.New(contextHandler{handler})
+	slog.SetDefault(logger)
+	return logger
+}
+
+// attrsKey is the context key of log attributes
+type attrsKey struct{}
+
+// With returns a context whose log records get the attributes, e.g. With(ctx, "trace_id", id)
+func With(ctx context.Context, args ...any) context.Context {
+	attrs := slices.Clone(contextAttrs(ctx))
+	record := slog.Record{}
+	record.Add(args...)
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+// contextAttrs returns log attributes of the context
+func contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// TraceID returns the trace ID added to the context with With, empty if there is none
+func TraceID(ctx context.Context) string {
+	for _, attr := range contextAttrs(ctx) {
+		if attr.Key == "trace_id" {
+			return attr.Value.String()
+		}
+	}
+	return ""
+}
+
+// NewTraceID returns a random ID to tie together records of one update or request
+func NewTraceID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// contextHandler adds attributes of the context to records
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle adds attributes of the context and passes the record on
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs := contextAttrs(ctx); len(attrs) > 0 {
+		record = record.Clone()
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs keeps adding context attributes to records with the attributes
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps adding context attributes to records of the group
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -4,6 +4,10 @@ package services
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
 	"gobot/internal/database"
 )
@@ -34,6 +38,178 @@ func (s *AdminService) GetAllBookings(ctx context.Context, limit, offset int) ([
 	return bookings, nil
 }
 
+// Booking filter periods
+const (
+	BookingPeriodToday    = "today"
+	BookingPeriodTomorrow = "tomorrow"
+	BookingPeriodWeek     = "week"
+	BookingPeriodCustom   = "custom"
+)
+
+// BookingFilter narrows down the admin bookings list
+// Zero values mean "any"
+type BookingFilter struct {
+	Period    string    // One of BookingPeriod* constants
+	DateFrom  time.Time // Inclusive start for the custom period
+	DateTo    time.Time // Exclusive end for the custom period
+	Status    database.BookingStatus
+	ServiceID uint
+	Client    string // Part of name, @username or Telegram ID
+}
+
+// IsEmpty reports whether the filter has no conditions
+func (f BookingFilter) IsEmpty() bool {
+	return f.Period == "" && f.Status == "" && f.ServiceID == 0 && f.Client == ""
+}
+
+// DateRange returns the date bounds of the filter period
+func (f BookingFilter) DateRange(now time.Time) (from, to time.Time, ok bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch f.Period {
+	case BookingPeriodToday:
+		return today, today.AddDate(0, 0, 1), true
+	case BookingPeriodTomorrow:
+		return today.AddDate(0, 0, 1), today.AddDate(0, 0, 2), true
+	case BookingPeriodWeek:
+		return today, today.AddDate(0, 0, 7), true
+	case BookingPeriodCustom:
+		return f.DateFrom, f.DateTo, true
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+}
+
+// ListBookings retrieves bookings matching the filter with pagination
+// Bookings in a date period are ordered chronologically, otherwise newest first
+func (s *AdminService) ListBookings(ctx context.Context, filter BookingFilter, limit, offset int) ([]database.Booking, int64, error) {
+	query := database.DB.WithContext(ctx).Model(&database.Booking{})
+	order := "date DESC, time DESC"
+
+	if from, to, ok := filter.DateRange(time.Now()); ok {
+		query = query.Where("date >= ? AND date < ?", from, to)
+		order = "date, time"
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if filter.ServiceID != 0 {
+		query = query.Where("service_id = ?", filter.ServiceID)
+	}
+
+	if client := strings.TrimPrefix(strings.TrimSpace(filter.Client), "@"); client != "" {
+		if userID, err := strconv.ParseInt(client, 10, 64); err == nil {
+			query = query.Where("user_id = ?", userID)
+		} else {
+			pattern := "%" + client + "%"
+			query = query.Where("user_id IN (?)", database.DB.
+				Model(&database.User{}).
+				Select("id").
+				Where("first_name LIKE ? OR last_name LIKE ? OR username LIKE ?", pattern, pattern, pattern))
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count bookings: %w", err)
+	}
+
+	var bookings []database.Booking
+	err := query.
+		Preload("Service").
+		Preload("User").
+		Order(order).
+		Limit(limit).
+		Offset(offset).
+		Find(&bookings).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get bookings: %w", err)
+	}
+
+	return bookings, total, nil
+}
+
+// GetBookingByID retrieves a booking with its relations
+func (s *AdminService) GetBookingByID(ctx context.Context, bookingID uint) (*database.Booking, error) {
+	var booking database.Booking
+	err := database.DB.WithContext(ctx).
+		Preload("Service").
+		Preload("User").
+		First(&booking, bookingID).Error
+	if err != nil {
+		return nil, fmt.Errorf("booking not found: %w", err)
+	}
+	return &booking, nil
+}
+
+// UpdateBookingNotes saves the internal admin note of a booking
+func (s *AdminService) UpdateBookingNotes(ctx context.Context, bookingID uint, notes string) error {
+	var booking database.Booking
+	if err := database.DB.WithContext(ctx).First(&booking, bookingID).Error; err != nil {
+		return fmt.Errorf("booking not found: %w", err)
+	}
+
+	result := database.DB.WithContext(ctx).
+		Model(&database.Booking{}).
+		Where("id = ?", bookingID).
+		Update("notes", notes)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update booking notes: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: booking %d", ErrNotFound, bookingID)
+	}
+
+	recordAudit(ctx, AuditBookingNotes, AuditEntityBooking, bookingID,
+		map[string]interface{}{"Notes": booking.Notes},
+		map[string]interface{}{"Notes": notes})
+	return nil
+}
+
+// RescheduleBooking moves an upcoming booking to another date and time
+// Reminder flags are reset so the client is reminded about the new time
+func (s *AdminService) RescheduleBooking(ctx context.Context, bookingID uint, date time.Time, timeSlot string) error {
+	var booking database.Booking
+	if err := database.DB.WithContext(ctx).First(&booking, bookingID).Error; err != nil {
+		return fmt.Errorf("booking not found: %w", err)
+	}
+	if !CanMoveBooking(booking.Status) {
+		return fmt.Errorf("%w: booking %d is %s", ErrBookingNotMovable, bookingID, booking.Status)
+	}
+	before := bookingTimeSnapshot(&booking)
+
+	// The status guard keeps a booking cancelled in the meantime where it is
+	result := database.DB.WithContext(ctx).
+		Model(&database.Booking{}).
+		Where("id = ? AND status IN ?", bookingID, movableStatuses).
+		Updates(map[string]interface{}{
+			"date":                      date,
+			"time":                      timeSlot,
+			"reminder_sent":             false,
+			"hour_reminder_sent":        false,
+			"admin_daily_reminder_sent": false,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to reschedule booking: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: booking %d was changed concurrently", ErrBookingNotMovable, bookingID)
+	}
+
+	booking.Date = date
+	booking.Time = timeSlot
+	recordAudit(ctx, AuditBookingReschedule, AuditEntityBooking, bookingID, before, bookingTimeSnapshot(&booking))
+
+	publishBookingEvent(ctx, EventBookingRescheduled, bookingID)
+	return nil
+}
+
 // CreateService creates a new service
 func (s *AdminService) CreateService(ctx context.Context, name, description string, duration, price int) (*database.Service, error) {
 	service := &database.Service{
@@ -48,11 +224,17 @@ func (s *AdminService) CreateService(ctx context.Context, name, description stri
 		return nil, fmt.Errorf("failed to create service: %w", err)
 	}
 
+	recordAudit(ctx, AuditServiceCreate, AuditEntityService, service.ID, nil, service)
 	return service, nil
 }
 
 // UpdateService updates an existing service
 func (s *AdminService) UpdateService(ctx context.Context, serviceID uint, name, description string, duration, price int) error {
+	before, err := s.GetServiceByID(ctx, serviceID)
+	if err != nil {
+		return err
+	}
+
 	updates := map[string]interface{}{
 		"name":        name,
 		"description": description,
@@ -73,11 +255,17 @@ func (s *AdminService) UpdateService(ctx context.Context, serviceID uint, name,
 		return fmt.Errorf("service not found")
 	}
 
+	s.auditServiceChange(ctx, AuditServiceUpdate, before)
 	return nil
 }
 
 // UpdateServiceField updates a single field of a service
 func (s *AdminService) UpdateServiceField(ctx context.Context, serviceID uint, field string, value interface{}) error {
+	before, err := s.GetServiceByID(ctx, serviceID)
+	if err != nil {
+		return err
+	}
+
 	result := database.DB.WithContext(ctx).
 		Model(&database.Service{}).
 		Where("id = ?", serviceID).
@@ -91,6 +279,7 @@ func (s *AdminService) UpdateServiceField(ctx context.Context, serviceID uint, f
 		return fmt.Errorf("service not found")
 	}
 
+	s.auditServiceChange(ctx, AuditServiceUpdate, before)
 	return nil
 }
 
@@ -101,16 +290,23 @@ func (s *AdminService) ToggleServiceStatus(ctx context.Context, serviceID uint)
 		return fmt.Errorf("service not found: %w", err)
 	}
 
+	before := service
 	service.IsActive = !service.IsActive
 	if err := database.DB.WithContext(ctx).Save(&service).Error; err != nil {
 		return fmt.Errorf("failed to toggle service status: %w", err)
 	}
 
+	recordAudit(ctx, AuditServiceToggle, AuditEntityService, service.ID, &before, &service)
 	return nil
 }
 
 // DeleteService soft deletes a service
 func (s *AdminService) DeleteService(ctx context.Context, serviceID uint) error {
+	before, err := s.GetServiceByID(ctx, serviceID)
+	if err != nil {
+		return err
+	}
+
 	result := database.DB.WithContext(ctx).Delete(&database.Service{}, serviceID)
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete service: %w", result.Error)
@@ -120,9 +316,20 @@ func (s *AdminService) DeleteService(ctx context.Context, serviceID uint) error
 		return fmt.Errorf("service not found")
 	}
 
+	recordAudit(ctx, AuditServiceDelete, AuditEntityService, serviceID, before, nil)
 	return nil
 }
 
+// auditServiceChange records the change of a service loaded before it
+func (s *AdminService) auditServiceChange(ctx context.Context, action string, before *database.Service) {
+	after, err := s.GetServiceByID(ctx, before.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load service for the audit log", "service_id", before.ID, "error", err)
+		return
+	}
+	recordAudit(ctx, action, AuditEntityService, before.ID, before, after)
+}
+
 // GetAllServices retrieves all services including inactive ones
 func (s *AdminService) GetAllServices(ctx context.Context) ([]database.Service, error) {
 	var services []database.Service
@@ -143,59 +350,7 @@ func (s *AdminService) GetServiceByID(ctx context.Context, serviceID uint) (*dat
 	return &service, nil
 }
 
-// UpdateBookingStatus updates the status of a booking
-func (s *AdminService) UpdateBookingStatus(ctx context.Context, bookingID uint, status database.BookingStatus) error {
-	result := database.DB.WithContext(ctx).
-		Model(&database.Booking{}).
-		Where("id = ?", bookingID).
-		Update("status", status)
-
-	if result.Error != nil {
-		return fmt.Errorf("failed to update booking status: %w", result.Error)
-	}
-
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("booking not found")
-	}
-
-	return nil
-}
-
-// GetStats retrieves system statistics
-func (s *AdminService) GetStats(ctx context.Context) (map[string]int64, error) {
-	stats := make(map[string]int64)
-
-	var totalUsers int64
-	var totalBookings int64
-	var activeBookings int64
-	var completedBookings int64
-	var activeServices int64
-
-	// Total users
-	database.DB.Model(&database.User{}).Count(&totalUsers)
-	stats["total_users"] = totalUsers
-
-	// Total bookings
-	database.DB.Model(&database.Booking{}).Count(&totalBookings)
-	stats["total_bookings"] = totalBookings
-
-	// Active bookings
-	database.DB.Model(&database.Booking{}).
-		Where("status IN ?", []database.BookingStatus{
-			database.BookingStatusPending,
-			database.BookingStatusConfirmed,
-		}).Count(&activeBookings)
-	stats["active_bookings"] = activeBookings
-
-	// Completed bookings
-	database.DB.Model(&database.Booking{}).
-		Where("status = ?", database.BookingStatusCompleted).
-		Count(&completedBookings)
-	stats["completed_bookings"] = completedBookings
-
-	// Total services
-	database.DB.Model(&database.Service{}).Where("is_active = ?", true).Count(&activeServices)
-	stats["active_services"] = activeServices
-
-	return stats, nil
+// UpdateBookingStatus moves a booking to a new status through the state machine
+func (s *AdminService) UpdateBookingStatus(ctx context.Context, bookingID uint, status database.BookingStatus, change StatusChange) error {
+	return TransitionBooking(ctx, bookingID, status, change)
 }
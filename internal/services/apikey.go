@@ -0,0 +1,111 @@
+// Package services contains API key management
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gobot/internal/database"
+)
+
+// apiKeyPrefix marks keys of this bot so they are easy to find in configs and leaks
+const apiKeyPrefix = "gbk_"
+
+// ErrInvalidAPIKey is returned for unknown and revoked keys
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyService manages keys of the admin HTTP API
+type APIKeyService struct{}
+
+// NewAPIKeyService creates a new API key service instance
+func NewAPIKeyService() *APIKeyService {
+	return &APIKeyService{}
+}
+
+// CreateKey generates a new key and stores its hash
+// The returned key can't be recovered later
+func (s *APIKeyService) CreateKey(ctx context.Context, name string, createdBy int64) (string, *database.APIKey, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := apiKeyPrefix + hex.EncodeToString(buf)
+
+	apiKey := &database.APIKey{
+		Name:      strings.TrimSpace(name),
+		Prefix:    key[:len(apiKeyPrefix)+6],
+		KeyHash:   hashToken(key),
+		CreatedBy: createdBy,
+	}
+	if err := database.DB.WithContext(ctx).Create(apiKey).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return key, apiKey, nil
+}
+
+// Authenticate returns the active key matching the secret and records its use
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*database.APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	var apiKey database.APIKey
+	err := database.DB.WithContext(ctx).
+		Where("key_hash = ? AND revoked_at IS NULL", hashToken(key)).
+		First(&apiKey).Error
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	database.DB.WithContext(ctx).Model(&apiKey).Update("last_used_at", &now)
+	apiKey.LastUsedAt = &now
+
+	return &apiKey, nil
+}
+
+// ListKeys returns keys that weren't revoked, newest first
+func (s *APIKeyService) ListKeys(ctx context.Context) ([]database.APIKey, error) {
+	var keys []database.APIKey
+	err := database.DB.WithContext(ctx).
+		Where("revoked_at IS NULL").
+		Order("created_at DESC").
+		Find(&keys).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeKey disables a key, requests with it are rejected right away
+func (s *APIKeyService) RevokeKey(ctx context.Context, keyID uint) error {
+	now := time.Now()
+	result := database.DB.WithContext(ctx).
+		Model(&database.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", keyID).
+		Update("revoked_at", &now)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke API key: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("API key not found")
+	}
+
+	return nil
+}
+
+// hashToken returns the hex SHA-256 of a secret token
+// Tokens are random, so a plain hash is enough to make a leaked database useless
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,352 @@
+// Package services contains the audit log of admin changes
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gobot/internal/database"
+)
+
+// Audit actions
+const (
+	AuditServiceCreate     = "service.create"
+	AuditServiceUpdate     = "service.update"
+	AuditServiceToggle     = "service.toggle"
+	AuditServiceDelete     = "service.delete"
+	AuditDiscountCreate    = "discount.create"
+	AuditDiscountUpdate    = "discount.update"
+	AuditDiscountToggle    = "discount.toggle"
+	AuditDiscountDelete    = "discount.delete"
+	AuditBookingStatus     = "booking.status"
+	AuditBookingReschedule = "booking.reschedule"
+	AuditBookingNotes      = "booking.notes"
+	AuditScheduleUpdate    = "schedule.update"
+	AuditBlockedDateCreate = "blocked_date.create"
+	AuditBlockedDateDelete = "blocked_date.delete"
+)
+
+// Audited entity types
+const (
+	AuditEntityService     = "service"
+	AuditEntityDiscount    = "discount"
+	AuditEntityBooking     = "booking"
+	AuditEntitySchedule    = "schedule"
+	AuditEntityBlockedDate = "blocked_date"
+)
+
+// AuditActionTitles names audit actions for admins
+var AuditActionTitles = map[string]string{
+	AuditServiceCreate:     "Создана услуга",
+	AuditServiceUpdate:     "Изменена услуга",
+	AuditServiceToggle:     "Услуга включена/выключена",
+	AuditServiceDelete:     "Удалена услуга",
+	AuditDiscountCreate:    "Создана акция",
+	AuditDiscountUpdate:    "Изменена акция",
+	AuditDiscountToggle:    "Акция включена/выключена",
+	AuditDiscountDelete:    "Удалена акция",
+	AuditBookingStatus:     "Изменен статус записи",
+	AuditBookingReschedule: "Перенесена запись",
+	AuditBookingNotes:      "Изменена заметка к записи",
+	AuditScheduleUpdate:    "Изменено расписание",
+	AuditBlockedDateCreate: "Добавлен выходной",
+	AuditBlockedDateDelete: "Удален выходной",
+}
+
+// AuditEntityTitles names audited entity types for admins
+var AuditEntityTitles = map[string]string{
+	AuditEntityService:     "Услуга",
+	AuditEntityDiscount:    "Акция",
+	AuditEntityBooking:     "Запись",
+	AuditEntitySchedule:    "Расписание",
+	AuditEntityBlockedDate: "Выходной",
+}
+
+// auditIgnoredFields are not compared: the ID is the entity ID of the record, timestamps change with every save
+var auditIgnoredFields = []string{"ID", "CreatedAt", "UpdatedAt", "DeletedAt"}
+
+// Actor is who makes a change
+type Actor struct {
+	Type database.ActorType
+	ID   int64  // Telegram ID of the client or admin, ID of the API key
+	Name string // Name of the API key
+}
+
+// actorKey is the context key of the actor
+type actorKey struct{}
+
+// WithActor returns a context of changes made by the actor
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns who makes changes in the context, the system if nobody was set
+func ActorFromContext(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return actor
+	}
+	return Actor{Type: database.ActorSystem}
+}
+
+// APIKeyActor returns the actor of changes made with an HTTP API key
+func APIKeyActor(key *database.APIKey) Actor {
+	return Actor{Type: database.ActorAPI, ID: int64(key.ID), Name: key.Name}
+}
+
+// ActorText describes the actor for admins
+func ActorText(actor Actor) string {
+	switch actor.Type {
+	case database.ActorClient:
+		return "клиент"
+	case database.ActorAdmin:
+		return "админ " + strconv.FormatInt(actor.ID, 10)
+	case database.ActorAPI:
+		return "API «" + actor.Name + "»"
+	case database.ActorSystem:
+		return "система"
+	default:
+		return string(actor.Type)
+	}
+}
+
+// AuditFilter narrows down the audit log, empty fields match everything
+type AuditFilter struct {
+	EntityType string
+	EntityID   uint
+}
+
+// AuditService reads the audit log
+type AuditService struct{}
+
+// NewAuditService creates a new audit service instance
+func NewAuditService() *AuditService {
+	return &AuditService{}
+}
+
+// ListPeriod returns audit records made in the period, oldest first
+func (s *AuditService) ListPeriod(ctx context.Context, period ReportPeriod) ([]database.AuditLog, error) {
+	var records []database.AuditLog
+	err := database.DB.WithContext(ctx).
+		Where("created_at >= ? AND created_at < ?", period.From, period.To).
+		Order("id").
+		Find(&records).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit records: %w", err)
+	}
+	return records, nil
+}
+
+// List returns audit records matching the filter, newest first, and their total count
+func (s *AuditService) List(ctx context.Context, filter AuditFilter, limit, offset int) ([]database.AuditLog, int64, error) {
+	query := database.DB.WithContext(ctx).Model(&database.AuditLog{})
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit records: %w", err)
+	}
+
+	var records []database.AuditLog
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&records).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get audit records: %w", err)
+	}
+	return records, total, nil
+}
+
+// AuditActor returns who made the recorded change
+func AuditActor(record *database.AuditLog) Actor {
+	return Actor{Type: record.ActorType, ID: record.ActorID, Name: record.ActorName}
+}
+
+// AuditChanges returns changed fields of a record as "field: before → after" lines
+func AuditChanges(record *database.AuditLog) []string {
+	before := decodeAuditSnapshot(record.Before)
+	after := decodeAuditSnapshot(record.After)
+
+	var lines []string
+	for _, key := range sortedKeys(before, after) {
+		oldValue, hadOld := before[key]
+		newValue, hasNew := after[key]
+		switch {
+		case !hadOld:
+			lines = append(lines, fmt.Sprintf("%s: %s", key, auditValueText(newValue)))
+		case !hasNew:
+			lines = append(lines, fmt.Sprintf("%s: %s → ∅", key, auditValueText(oldValue)))
+		default:
+			lines = append(lines, fmt.Sprintf("%s: %s → %s", key, auditValueText(oldValue), auditValueText(newValue)))
+		}
+	}
+	return lines
+}
+
+// auditValueText formats a saved field value, timestamps as local date and time
+func auditValueText(value interface{}) string {
+	if text, ok := value.(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, text); err == nil {
+			return t.In(time.Local).Format("02.01.2006 15:04")
+		}
+		return text
+	}
+	return fmt.Sprint(value)
+}
+
+// recordAudit appends a change made by the actor of the context to the audit log
+// before is nil for creation, after is nil for deletion. Nothing is recorded if nothing changed
+func recordAudit(ctx context.Context, action, entityType string, entityID uint, before, after interface{}) {
+	recordAuditAs(ctx, ActorFromContext(ctx), action, entityType, entityID, before, after)
+}
+
+// recordAuditAs appends a change made by the actor to the audit log
+// Failures are logged and don't fail the change, which is saved already
+func recordAuditAs(ctx context.Context, actor Actor, action, entityType string, entityID uint, before, after interface{}) {
+	oldFields, newFields := auditDiff(auditSnapshot(before), auditSnapshot(after))
+	if len(oldFields) == 0 && len(newFields) == 0 {
+		return
+	}
+
+	record := &database.AuditLog{
+		ActorType:  actor.Type,
+		ActorID:    actor.ID,
+		ActorName:  actor.Name,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     encodeAuditSnapshot(oldFields),
+		After:      encodeAuditSnapshot(newFields),
+	}
+	if err := database.DB.WithContext(context.WithoutCancel(ctx)).Create(record).Error; err != nil {
+		slog.ErrorContext(ctx, "Failed to save audit record", "action", action, "entity_type", entityType, "entity_id", entityID, "error", err)
+	}
+}
+
+// auditSnapshot converts a model or a map to fields, nested objects and lists (relations) are left out
+func auditSnapshot(value interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Pointer && reflect.ValueOf(value).IsNil()) {
+		return fields
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fields
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fields
+	}
+
+	for key, field := range fields {
+		switch field.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			delete(fields, key)
+		}
+	}
+	for _, key := range auditIgnoredFields {
+		delete(fields, key)
+	}
+	return fields
+}
+
+// auditDiff keeps only fields that differ between the snapshots
+func auditDiff(before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	oldFields := make(map[string]interface{})
+	newFields := make(map[string]interface{})
+	for _, key := range sortedKeys(before, after) {
+		oldValue, hadOld := before[key]
+		newValue, hasNew := after[key]
+		if hadOld && hasNew && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if hadOld {
+			oldFields[key] = oldValue
+		}
+		if hasNew {
+			newFields[key] = newValue
+		}
+	}
+	return oldFields, newFields
+}
+
+// encodeAuditSnapshot returns JSON of the fields, empty for no fields
+func encodeAuditSnapshot(fields map[string]interface{}) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// decodeAuditSnapshot parses fields saved by encodeAuditSnapshot
+// Numbers are kept as written, prices in kopecks would turn into 1.5e+06 as floats
+func decodeAuditSnapshot(data string) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if data != "" {
+		decoder := json.NewDecoder(strings.NewReader(data))
+		decoder.UseNumber()
+		_ = decoder.Decode(&fields)
+	}
+	return fields
+}
+
+// sortedKeys returns keys of both maps in order
+func sortedKeys(a, b map[string]interface{}) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	keys := make([]string, 0, len(a)+len(b))
+	for _, m := range []map[string]interface{}{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// bookingTimeSnapshot returns the fields of a booking changed by rescheduling
+func bookingTimeSnapshot(booking *database.Booking) map[string]interface{} {
+	return map[string]interface{}{
+		"Date": booking.Date.Format("2006-01-02"),
+		"Time": booking.Time,
+	}
+}
+
+// scheduleSnapshot returns working intervals per weekday, e.g. {"1": "09:00-13:00, 14:00-18:00"}
+func scheduleSnapshot(schedule []database.WorkSchedule) map[string]interface{} {
+	sorted := slices.Clone(schedule)
+	slices.SortFunc(sorted, func(a, b database.WorkSchedule) int {
+		if a.DayOfWeek != b.DayOfWeek {
+			return a.DayOfWeek - b.DayOfWeek
+		}
+		return strings.Compare(a.StartTime, b.StartTime)
+	})
+
+	fields := make(map[string]interface{})
+	for _, day := range sorted {
+		if !day.IsActive {
+			continue
+		}
+		key := strconv.Itoa(day.DayOfWeek)
+		interval := day.StartTime + "-" + day.EndTime
+		if existing, ok := fields[key]; ok {
+			interval = existing.(string) + ", " + interval
+		}
+		fields[key] = interval
+	}
+	return fields
+}
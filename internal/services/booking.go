@@ -4,11 +4,21 @@ package services
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"gobot/internal/database"
 )
 
+// DefaultTimeSlots are the start times offered for booking every day
+// (можно расширить на основе TimeSlots из БД)
+// Исключены: 17:00, 18:00, 20:00 - не работаем в это время
+var DefaultTimeSlots = []string{
+	"09:00", "10:00", "11:00", "12:00",
+	"13:00", "14:00", "15:00", "16:00",
+	"19:00",
+}
+
 // BookingService handles booking-related operations
 type BookingService struct{}
 
@@ -19,12 +29,19 @@ func NewBookingService() *BookingService {
 
 // CreateBooking creates a new booking
 func (s *BookingService) CreateBooking(ctx context.Context, userID int64, serviceID uint, date time.Time, timeSlot string) (*database.Booking, error) {
+	// Fix the price at booking time so later price changes don't affect it
+	_, price, err := NewDiscountService().GetServiceWithDiscount(ctx, serviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service price: %w", err)
+	}
+
 	booking := &database.Booking{
 		UserID:    userID,
 		ServiceID: serviceID,
 		Date:      date,
 		Time:      timeSlot,
 		Status:    database.BookingStatusPending,
+		Price:     price,
 	}
 
 	if err := database.DB.WithContext(ctx).Create(booking).Error; err != nil {
@@ -36,6 +53,7 @@ func (s *BookingService) CreateBooking(ctx context.Context, userID int64, servic
 		return nil, fmt.Errorf("failed to load booking relations: %w", err)
 	}
 
+	Events.Publish(ctx, EventBookingCreated, bookingEventData(booking))
 	return booking, nil
 }
 
@@ -44,7 +62,7 @@ func (s *BookingService) GetUserBookings(ctx context.Context, userID int64) ([]d
 	var bookings []database.Booking
 	err := database.DB.WithContext(ctx).
 		Preload("Service").
-		Where("user_id = ? AND status != ?", userID, database.BookingStatusCancelled).
+		Where("user_id = ? AND status NOT IN ?", userID, []database.BookingStatus{database.BookingStatusCancelled, database.BookingStatusRejected}).
 		Order("date DESC, time DESC").
 		Find(&bookings).Error
 
@@ -63,15 +81,10 @@ func (s *BookingService) CancelBooking(ctx context.Context, bookingID uint, user
 	}
 
 	if booking.UserID != userID {
-		return fmt.Errorf("unauthorized: booking belongs to another user")
+		return fmt.Errorf("%w: booking %d belongs to another user", ErrForbidden, bookingID)
 	}
 
-	booking.Status = database.BookingStatusCancelled
-	if err := database.DB.WithContext(ctx).Save(&booking).Error; err != nil {
-		return fmt.Errorf("failed to cancel booking: %w", err)
-	}
-
-	return nil
+	return TransitionBooking(ctx, booking.ID, database.BookingStatusCancelled, ByClient(userID))
 }
 
 // GetAvailableServices retrieves all active services
@@ -87,3 +100,110 @@ func (s *BookingService) GetAvailableServices(ctx context.Context) ([]database.S
 
 	return services, nil
 }
+
+// AvailableTimeSlots returns free start times of a date, skipping past times,
+// days off and times outside the working hours
+// excludeBookingID ignores a booking when checking overlaps, e.g. one being rescheduled
+func AvailableTimeSlots(ctx context.Context, date time.Time, serviceDuration int, excludeBookingID uint) []string {
+	now := time.Now()
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, now.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	slots := make([]string, 0)
+	hours, err := workingHours(ctx, day)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get working hours", "date", day.Format("2006-01-02"), "error", err)
+		return slots
+	}
+	if hours.closed() {
+		return slots
+	}
+
+	for _, timeSlot := range DefaultTimeSlots {
+		slotTime, err := time.Parse("15:04", timeSlot)
+		if err != nil {
+			continue
+		}
+
+		// Keep a minute of margin so the slot is still ahead when the client confirms
+		slotStart := time.Date(day.Year(), day.Month(), day.Day(), slotTime.Hour(), slotTime.Minute(), 0, 0, day.Location())
+		if day.Equal(today) && slotStart.Before(now.Add(time.Minute)) {
+			continue
+		}
+
+		slotEnd := slotStart.Add(time.Duration(serviceDuration) * time.Minute)
+		if !hours.contains(slotStart, slotEnd) || isSlotBooked(ctx, slotStart, slotEnd, excludeBookingID) {
+			continue
+		}
+
+		slots = append(slots, timeSlot)
+	}
+
+	return slots
+}
+
+// IsTimeSlotTaken checks if a time slot falls on a day off or outside the working hours,
+// or conflicts with pending or confirmed bookings or busy time in the staff calendar,
+// taking service duration into account
+func IsTimeSlotTaken(ctx context.Context, timeSlot string, date time.Time, serviceDuration int, excludeBookingID uint) bool {
+	slotTime, err := time.Parse("15:04", timeSlot)
+	if err != nil {
+		return true // If we can't parse, consider it booked to be safe
+	}
+
+	slotStart := time.Date(
+		date.Year(), date.Month(), date.Day(),
+		slotTime.Hour(), slotTime.Minute(), 0, 0, date.Location(),
+	)
+	slotEnd := slotStart.Add(time.Duration(serviceDuration) * time.Minute)
+
+	hours, err := workingHours(ctx, date)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get working hours", "date", date.Format("2006-01-02"), "error", err)
+		return true
+	}
+	if !hours.contains(slotStart, slotEnd) {
+		return true
+	}
+
+	return isSlotBooked(ctx, slotStart, slotEnd, excludeBookingID)
+}
+
+// isSlotBooked checks if the time overlaps pending or confirmed bookings of its day
+// or busy time in the staff calendar
+func isSlotBooked(ctx context.Context, slotStart, slotEnd time.Time, excludeBookingID uint) bool {
+	startOfDay := time.Date(slotStart.Year(), slotStart.Month(), slotStart.Day(), 0, 0, 0, 0, slotStart.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour)
+
+	var bookings []database.Booking
+	database.DB.WithContext(ctx).
+		Preload("Service").
+		Where("date >= ? AND date < ?", startOfDay, endOfDay).
+		Where("status IN ?", []database.BookingStatus{
+			database.BookingStatusPending,
+			database.BookingStatusConfirmed,
+		}).
+		Where("id != ?", excludeBookingID).
+		Find(&bookings)
+
+	for _, booking := range bookings {
+		bookedTime, err := time.Parse("15:04", booking.Time)
+		if err != nil {
+			continue
+		}
+
+		bookedStart := time.Date(
+			slotStart.Year(), slotStart.Month(), slotStart.Day(),
+			bookedTime.Hour(), bookedTime.Minute(), 0, 0, slotStart.Location(),
+		)
+		bookedEnd := bookedStart.Add(time.Duration(booking.Service.Duration) * time.Minute)
+
+		// Check for overlap
+		if slotStart.Before(bookedEnd) && slotEnd.After(bookedStart) {
+			return true
+		}
+	}
+
+	// Personal time blocked in the staff calendar
+	return IsBusyPeriod(ctx, slotStart, slotEnd)
+}
@@ -0,0 +1,160 @@
+// Package services contains the booking state machine
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gobot/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// ErrBookingTransition is returned for status changes the state machine doesn't allow
+var ErrBookingTransition = errors.New("booking status change is not allowed")
+
+// ErrBookingNotMovable is returned for moves of bookings that already took place or were cancelled
+var ErrBookingNotMovable = errors.New("only upcoming bookings can be moved")
+
+// movableStatuses are statuses of bookings that can still be moved to another time
+var movableStatuses = []database.BookingStatus{
+	database.BookingStatusPending,
+	database.BookingStatusConfirmed,
+}
+
+// bookingTransitions lists statuses a booking can move to
+// Cancelled, rejected, completed and no-show bookings are final
+var bookingTransitions = map[database.BookingStatus][]database.BookingStatus{
+	database.BookingStatusPending: {
+		database.BookingStatusConfirmed,
+		database.BookingStatusRejected,
+		database.BookingStatusCancelled,
+	},
+	database.BookingStatusConfirmed: {
+		database.BookingStatusCompleted,
+		database.BookingStatusNoShow,
+		database.BookingStatusCancelled,
+	},
+}
+
+// StatusChange tells who changes a booking status and why
+type StatusChange struct {
+	Actor
+	Reason string
+}
+
+// ByClient returns a change made by the client of the booking
+func ByClient(userID int64) StatusChange {
+	return StatusChange{Actor: Actor{Type: database.ActorClient, ID: userID}}
+}
+
+// ByAdmin returns a change made by an admin in the bot or the dashboard
+func ByAdmin(adminID int64) StatusChange {
+	return StatusChange{Actor: Actor{Type: database.ActorAdmin, ID: adminID}}
+}
+
+// ByAPIKey returns a change made with an HTTP API key
+func ByAPIKey(key *database.APIKey) StatusChange {
+	return StatusChange{Actor: APIKeyActor(key)}
+}
+
+// CanTransitionBooking reports whether the actor may move a booking from one status to another
+// Staff reject pending bookings, only the client withdraws one
+func CanTransitionBooking(from, to database.BookingStatus, actor database.ActorType) bool {
+	if from == database.BookingStatusPending && to == database.BookingStatusCancelled {
+		return actor == database.ActorClient
+	}
+	return slices.Contains(bookingTransitions[from], to)
+}
+
+// CanMoveBooking reports whether a booking with the status can be moved to another time
+func CanMoveBooking(status database.BookingStatus) bool {
+	return slices.Contains(movableStatuses, status)
+}
+
+// TransitionBooking moves a booking to a new status and records the change in its history
+func TransitionBooking(ctx context.Context, bookingID uint, to database.BookingStatus, change StatusChange) error {
+	var from database.BookingStatus
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var booking database.Booking
+		if err := tx.First(&booking, bookingID).Error; err != nil {
+			return fmt.Errorf("booking not found: %w", err)
+		}
+
+		from = booking.Status
+		if !CanTransitionBooking(from, to, change.Type) {
+			return fmt.Errorf("%w: from %s to %s", ErrBookingTransition, from, to)
+		}
+
+		updates := map[string]interface{}{"status": to}
+		if to == database.BookingStatusCancelled && change.Type == database.ActorClient {
+			updates["cancelled_at"] = time.Now()
+		}
+
+		// The status condition loses a race with a concurrent change instead of overwriting it
+		result := tx.Model(&database.Booking{}).
+			Where("id = ? AND status = ?", booking.ID, from).
+			Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update booking status: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: booking %d was changed concurrently", ErrBookingTransition, booking.ID)
+		}
+
+		history := &database.BookingStatusHistory{
+			BookingID:  booking.ID,
+			FromStatus: from,
+			ToStatus:   to,
+			Actor:      change.Type,
+			ActorID:    change.ID,
+			ActorName:  change.Name,
+			Reason:     change.Reason,
+		}
+		if err := tx.Create(history).Error; err != nil {
+			return fmt.Errorf("failed to save booking history: %w", err)
+		}
+
+		// The client keeps what was paid with a certificate for a visit that won't happen
+		if to == database.BookingStatusCancelled || to == database.BookingStatusRejected {
+			if err := refundCertificates(tx, booking.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Clients' own cancellations are in the booking history, the audit log is for staff
+	if change.Type != database.ActorClient {
+		before := map[string]interface{}{"Status": from}
+		after := map[string]interface{}{"Status": to}
+		if change.Reason != "" {
+			after["Reason"] = change.Reason
+		}
+		recordAuditAs(ctx, change.Actor, AuditBookingStatus, AuditEntityBooking, bookingID, before, after)
+	}
+
+	if event, ok := bookingStatusEvents[to]; ok {
+		publishBookingEvent(ctx, event, bookingID)
+	}
+	return nil
+}
+
+// GetBookingHistory returns status changes of a booking, oldest first
+func (s *AdminService) GetBookingHistory(ctx context.Context, bookingID uint) ([]database.BookingStatusHistory, error) {
+	var history []database.BookingStatusHistory
+	err := database.DB.WithContext(ctx).
+		Where("booking_id = ?", bookingID).
+		Order("id").
+		Find(&history).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking history: %w", err)
+	}
+	return history, nil
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"gobot/internal/database"
+
+	"gorm.io/gorm"
+)
+
+func TestCancelBooking(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+	booking := createTestBooking(t, 1001, 250000)
+	s := NewBookingService()
+
+	if err := s.CancelBooking(ctx, booking.ID, 1002); !errors.Is(err, ErrForbidden) {
+		t.Errorf("CancelBooking() by another user error = %v, want ErrForbidden", err)
+	}
+	if err := s.CancelBooking(ctx, booking.ID+1, 1001); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("CancelBooking() of a missing booking error = %v, want not found", err)
+	}
+	if err := s.CancelBooking(ctx, booking.ID, 1001); err != nil {
+		t.Fatalf("CancelBooking() error = %v", err)
+	}
+	if err := s.CancelBooking(ctx, booking.ID, 1001); !errors.Is(err, ErrBookingTransition) {
+		t.Errorf("CancelBooking() twice error = %v, want ErrBookingTransition", err)
+	}
+
+	var cancelled database.Booking
+	if err := database.DB.First(&cancelled, booking.ID).Error; err != nil {
+		t.Fatalf("failed to get booking: %v", err)
+	}
+	if cancelled.Status != database.BookingStatusCancelled {
+		t.Errorf("status = %s, want cancelled", cancelled.Status)
+	}
+}
+
+func TestRescheduleBookingRequiresUpcomingBooking(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+	booking := createTestBooking(t, 1001, 250000)
+	admin := NewAdminService()
+	date := booking.Date.AddDate(0, 0, 1)
+
+	if err := admin.RescheduleBooking(ctx, booking.ID, date, "14:00"); err != nil {
+		t.Fatalf("RescheduleBooking() error = %v", err)
+	}
+	if err := NewBookingService().CancelBooking(ctx, booking.ID, 1001); err != nil {
+		t.Fatalf("CancelBooking() error = %v", err)
+	}
+	if err := admin.RescheduleBooking(ctx, booking.ID, date, "16:00"); !errors.Is(err, ErrBookingNotMovable) {
+		t.Errorf("RescheduleBooking() of a cancelled booking error = %v, want ErrBookingNotMovable", err)
+	}
+
+	var moved database.Booking
+	if err := database.DB.First(&moved, booking.ID).Error; err != nil {
+		t.Fatalf("failed to get booking: %v", err)
+	}
+	if moved.Time != "14:00" {
+		t.Errorf("time = %s, want 14:00", moved.Time)
+	}
+}
+
+func TestTimeSlotsFollowSchedule(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+	now := time.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 7)
+
+	if slots := AvailableTimeSlots(ctx, day, 60, 0); len(slots) != len(DefaultTimeSlots) {
+		t.Fatalf("AvailableTimeSlots() without schedule = %v, want all default slots", slots)
+	}
+
+	schedule := NewScheduleService()
+	err := schedule.SetSchedule(ctx, []database.WorkSchedule{
+		{DayOfWeek: int(day.Weekday()), StartTime: "10:00", EndTime: "13:00", IsActive: true},
+		{DayOfWeek: int(day.Weekday()), StartTime: "19:00", EndTime: "20:00", IsActive: true},
+	})
+	if err != nil {
+		t.Fatalf("SetSchedule() error = %v", err)
+	}
+
+	want := []string{"10:00", "11:00", "12:00", "19:00"}
+	if slots := AvailableTimeSlots(ctx, day, 60, 0); !slices.Equal(slots, want) {
+		t.Errorf("AvailableTimeSlots() = %v, want %v", slots, want)
+	}
+	if slots := AvailableTimeSlots(ctx, day.AddDate(0, 0, 1), 60, 0); len(slots) != 0 {
+		t.Errorf("AvailableTimeSlots() of a weekday without hours = %v, want none", slots)
+	}
+
+	tests := []struct {
+		name     string
+		timeSlot string
+		duration int
+		want     bool
+	}{
+		{name: "inside hours", timeSlot: "10:30", duration: 60, want: false},
+		{name: "before opening", timeSlot: "09:00", duration: 60, want: true},
+		{name: "ends after closing", timeSlot: "12:30", duration: 60, want: true},
+		{name: "during the break", timeSlot: "15:00", duration: 30, want: true},
+		{name: "evening interval", timeSlot: "19:00", duration: 60, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTimeSlotTaken(ctx, tt.timeSlot, day, tt.duration, 0); got != tt.want {
+				t.Errorf("IsTimeSlotTaken(%s) = %v, want %v", tt.timeSlot, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := schedule.AddBlockedDate(ctx, day, "Holiday"); err != nil {
+		t.Fatalf("AddBlockedDate() error = %v", err)
+	}
+	if slots := AvailableTimeSlots(ctx, day, 60, 0); len(slots) != 0 {
+		t.Errorf("AvailableTimeSlots() of a day off = %v, want none", slots)
+	}
+	if !IsTimeSlotTaken(ctx, "10:00", day, 60, 0) {
+		t.Error("IsTimeSlotTaken() on a day off = false, want true")
+	}
+}
@@ -0,0 +1,182 @@
+// Package services contains broadcast messaging logic
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gobot/internal/database"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// BroadcastService handles admin broadcasts to user segments
+type BroadcastService struct {
+	bot    *tele.Bot
+	outbox *OutboxService
+}
+
+// NewBroadcastService creates a new broadcast service
+// Messages go through the outbox rate limiter shared with notifications
+func NewBroadcastService(bot *tele.Bot, outbox *OutboxService) *BroadcastService {
+	return &BroadcastService{bot: bot, outbox: outbox}
+}
+
+// ResolveRecipients returns IDs of users in the segment who haven't blocked the bot
+func (s *BroadcastService) ResolveRecipients(ctx context.Context, segment database.BroadcastSegment, param int) ([]int64, error) {
+	query := database.DB.WithContext(ctx).
+		Model(&database.User{}).
+		Where("blocked_bot = ? AND is_offline = ?", false, false)
+
+	switch segment {
+	case database.BroadcastSegmentAll:
+		// No additional filter
+
+	case database.BroadcastSegmentClients:
+		query = query.Where("id IN (?)", database.DB.
+			Model(&database.Booking{}).
+			Select("user_id").
+			Where("status = ?", database.BookingStatusCompleted))
+
+	case database.BroadcastSegmentInactive:
+		cutoff := time.Now().AddDate(0, 0, -param)
+		query = query.Where("id IN (?)", database.DB.
+			Model(&database.Booking{}).
+			Select("user_id").
+			Group("user_id").
+			Having("MAX(date) < ?", cutoff))
+
+	case database.BroadcastSegmentService:
+		query = query.Where("id IN (?)", database.DB.
+			Model(&database.Booking{}).
+			Select("user_id").
+			Where("service_id = ? AND status NOT IN ?", param, []database.BookingStatus{database.BookingStatusCancelled, database.BookingStatusRejected}))
+
+	case database.BroadcastSegmentBirthday:
+		var users []database.User
+		if err := query.Where("birthday IS NOT NULL").Find(&users).Error; err != nil {
+			return nil, fmt.Errorf("failed to get users: %w", err)
+		}
+		month := time.Now().Month()
+		ids := make([]int64, 0)
+		for _, user := range users {
+			if user.Birthday.Month() == month {
+				ids = append(ids, user.ID)
+			}
+		}
+		return ids, nil
+
+	default:
+		return nil, fmt.Errorf("unknown segment: %s", segment)
+	}
+
+	var ids []int64
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to get recipients: %w", err)
+	}
+
+	return ids, nil
+}
+
+// SendPreview sends the broadcast message to a single chat for review
+func (s *BroadcastService) SendPreview(ctx context.Context, broadcast *database.Broadcast, chatID int64) error {
+	return s.send(ctx, &tele.User{ID: chatID}, broadcast)
+}
+
+// Start saves the broadcast and delivers it in background
+// onDone is called with final counters once all messages are processed
+func (s *BroadcastService) Start(ctx context.Context, broadcast *database.Broadcast, onDone func(*database.Broadcast)) error {
+	recipients, err := s.ResolveRecipients(ctx, broadcast.Segment, broadcast.SegmentParam)
+	if err != nil {
+		return err
+	}
+
+	broadcast.Status = database.BroadcastStatusSending
+	broadcast.Total = len(recipients)
+	if err := database.DB.WithContext(ctx).Create(broadcast).Error; err != nil {
+		return fmt.Errorf("failed to save broadcast: %w", err)
+	}
+
+	go s.deliver(context.Background(), broadcast, recipients, onDone)
+	return nil
+}
+
+// deliver sends the broadcast to recipients respecting the rate limit
+func (s *BroadcastService) deliver(ctx context.Context, broadcast *database.Broadcast, recipients []int64, onDone func(*database.Broadcast)) {
+	slog.InfoContext(ctx, "Broadcast started", "broadcast_id", broadcast.ID, "recipients", len(recipients))
+
+	for _, userID := range recipients {
+		if ctx.Err() != nil {
+			return
+		}
+
+		err := s.send(ctx, &tele.User{ID: userID}, broadcast)
+
+		switch {
+		case err == nil:
+			broadcast.Delivered++
+		case IsBlockedError(err):
+			broadcast.Blocked++
+			if markErr := MarkUserBlockedBot(ctx, userID); markErr != nil {
+				slog.ErrorContext(ctx, "Failed to mark user as blocked", "user_id", userID, "error", markErr)
+			}
+		default:
+			broadcast.Failed++
+			slog.WarnContext(ctx, "Failed to send broadcast", "broadcast_id", broadcast.ID, "user_id", userID, "error", err)
+		}
+	}
+
+	now := time.Now()
+	broadcast.Status = database.BroadcastStatusFinished
+	broadcast.FinishedAt = &now
+	if err := database.DB.WithContext(ctx).Save(broadcast).Error; err != nil {
+		slog.ErrorContext(ctx, "Failed to save broadcast results", "broadcast_id", broadcast.ID, "error", err)
+	}
+
+	slog.InfoContext(ctx, "Broadcast finished", "broadcast_id", broadcast.ID,
+		"delivered", broadcast.Delivered, "failed", broadcast.Failed, "blocked", broadcast.Blocked)
+
+	if onDone != nil {
+		onDone(broadcast)
+	}
+}
+
+// send delivers the broadcast content to a recipient
+func (s *BroadcastService) send(ctx context.Context, recipient tele.Recipient, broadcast *database.Broadcast) error {
+	opts := &tele.SendOptions{ParseMode: tele.ModeHTML}
+
+	if broadcast.ServiceID != nil {
+		markup := &tele.ReplyMarkup{}
+		link := fmt.Sprintf("https://t.me/%s?start=service_%d", s.bot.Me.Username, *broadcast.ServiceID)
+		markup.Inline(markup.Row(markup.URL("📝 Записаться", link)))
+		opts.ReplyMarkup = markup
+	}
+
+	var what interface{} = broadcast.Text
+	if broadcast.PhotoFileID != "" {
+		what = &tele.Photo{
+			File:    tele.File{FileID: broadcast.PhotoFileID},
+			Caption: broadcast.Text,
+		}
+	}
+
+	_, err := s.outbox.Send(ctx, recipient, what, opts)
+	return err
+}
+
+// IsBlockedError reports whether a send error means the chat is unreachable for good
+func IsBlockedError(err error) bool {
+	return err == tele.ErrBlockedByUser ||
+		err == tele.ErrUserIsDeactivated ||
+		err == tele.ErrNotStartedByUser
+}
+
+// MarkUserBlockedBot flags a user as unreachable so they are skipped in broadcasts
+func MarkUserBlockedBot(ctx context.Context, userID int64) error {
+	return database.DB.WithContext(ctx).
+		Model(&database.User{}).
+		Where("id = ?", userID).
+		Update("blocked_bot", true).Error
+}
@@ -0,0 +1,248 @@
+// Package services contains two-way sync with the staff CalDAV calendar
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"gobot/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// caldavPullDays is how far ahead busy events are pulled
+const caldavPullDays = 90
+
+// CalDAVStatus describes the last sync run
+type CalDAVStatus struct {
+	LastSync    time.Time
+	LastError   error
+	Pushed      int // Events created or updated on the server
+	Deleted     int // Events removed from the server
+	BusyPeriods int // Busy events pulled from the server
+}
+
+// CalDAVService pushes bookings to a CalDAV calendar and pulls busy time from it
+type CalDAVService struct {
+	client   *CalDAVClient
+	calendar *CalendarService
+	interval time.Duration
+	wake     chan struct{}
+
+	mu     sync.Mutex
+	status CalDAVStatus
+}
+
+// NewCalDAVService creates a new CalDAV sync service
+func NewCalDAVService(client *CalDAVClient, calendar *CalendarService, interval time.Duration) *CalDAVService {
+	return &CalDAVService{
+		client:   client,
+		calendar: calendar,
+		interval: interval,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// StartWorker syncs periodically until the context is cancelled
+func (s *CalDAVService) StartWorker(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	slog.InfoContext(ctx, "CalDAV sync worker started")
+
+	for {
+		s.Sync(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "CalDAV sync worker stopped")
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// SyncNow asks the worker to sync without waiting for the next tick
+func (s *CalDAVService) SyncNow() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Status returns the result of the last sync
+func (s *CalDAVService) Status() CalDAVStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Sync pushes changed bookings and pulls busy periods
+func (s *CalDAVService) Sync(ctx context.Context) {
+	status := CalDAVStatus{LastSync: time.Now()}
+
+	pushed, deleted, err := s.push(ctx)
+	status.Pushed, status.Deleted = pushed, deleted
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to push bookings to CalDAV", "error", err)
+		status.LastError = err
+	}
+
+	busy, err := s.pull(ctx)
+	status.BusyPeriods = busy
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to pull busy time from CalDAV", "error", err)
+		if status.LastError == nil {
+			status.LastError = err
+		}
+	}
+
+	s.mu.Lock()
+	s.status = status
+	s.mu.Unlock()
+}
+
+// push uploads confirmed bookings changed since the last push and removes cancelled ones
+func (s *CalDAVService) push(ctx context.Context) (int, int, error) {
+	var bookings []database.Booking
+	err := database.DB.WithContext(ctx).
+		Preload("Service").
+		Preload("User").
+		Where("date >= ?", time.Now().AddDate(0, 0, -calendarFeedPastDays)).
+		Where("status IN ?", []database.BookingStatus{
+			database.BookingStatusConfirmed,
+			database.BookingStatusCompleted,
+			database.BookingStatusNoShow,
+		}).
+		Find(&bookings).Error
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get bookings: %w", err)
+	}
+
+	events, err := s.calendar.trackEvents(ctx, bookings)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pushed := 0
+	for i := range bookings {
+		booking := &bookings[i]
+		event := events[booking.ID]
+		if event.RemoteFingerprint == event.Fingerprint {
+			continue
+		}
+
+		var w icsWriter
+		w.begin("", "")
+		w.adminEvent(booking, event)
+		w.end()
+
+		etag, err := s.client.Put(ctx, event.UID, w.bytes())
+		if err != nil {
+			return pushed, 0, fmt.Errorf("failed to push booking %d: %w", booking.ID, err)
+		}
+
+		if err := database.DB.WithContext(ctx).Model(event).Updates(map[string]interface{}{
+			"RemoteFingerprint": event.Fingerprint,
+			"RemoteETag":        etag,
+		}).Error; err != nil {
+			return pushed, 0, fmt.Errorf("failed to save calendar event: %w", err)
+		}
+		pushed++
+	}
+
+	// Pushed bookings that were cancelled, returned to pending or deleted since
+	var stale []database.CalendarEvent
+	err = database.DB.WithContext(ctx).
+		Joins("LEFT JOIN bookings ON bookings.id = calendar_events.booking_id").
+		Where("calendar_events.remote_fingerprint != ''").
+		Where("bookings.id IS NULL OR bookings.deleted_at IS NOT NULL OR bookings.status IN ?", []database.BookingStatus{
+			database.BookingStatusCancelled,
+			database.BookingStatusPending,
+		}).
+		Find(&stale).Error
+	if err != nil {
+		return pushed, 0, fmt.Errorf("failed to get removed bookings: %w", err)
+	}
+
+	deleted := 0
+	for i := range stale {
+		event := &stale[i]
+		if err := s.client.Delete(ctx, event.UID); err != nil {
+			return pushed, deleted, fmt.Errorf("failed to delete booking %d: %w", event.BookingID, err)
+		}
+
+		if err := database.DB.WithContext(ctx).Model(event).Updates(map[string]interface{}{
+			"RemoteFingerprint": "",
+			"RemoteETag":        "",
+		}).Error; err != nil {
+			return pushed, deleted, fmt.Errorf("failed to save calendar event: %w", err)
+		}
+		deleted++
+	}
+
+	return pushed, deleted, nil
+}
+
+// pull replaces stored busy periods with busy events of the calendar
+// Events pushed by the bot itself are skipped, they are bookings already
+func (s *CalDAVService) pull(ctx context.Context) (int, error) {
+	from := time.Now().AddDate(0, 0, -1)
+	to := time.Now().AddDate(0, 0, caldavPullDays)
+
+	events, err := s.client.Events(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	periods := make([]database.CalendarBusyPeriod, 0, len(events))
+	for _, event := range events {
+		if event.Transparent || event.Cancelled || !event.End.After(event.Start) {
+			continue
+		}
+		if strings.HasSuffix(event.UID, "@"+calendarUIDDomain) {
+			continue
+		}
+		// Stored in UTC so SQLite compares times as text correctly
+		periods = append(periods, database.CalendarBusyPeriod{
+			UID:      event.UID,
+			Summary:  event.Summary,
+			StartsAt: event.Start.UTC(),
+			EndsAt:   event.End.UTC(),
+		})
+	}
+
+	err = database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&database.CalendarBusyPeriod{}).Error; err != nil {
+			return err
+		}
+		if len(periods) == 0 {
+			return nil
+		}
+		return tx.Create(&periods).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to save busy periods: %w", err)
+	}
+
+	return len(periods), nil
+}
+
+// IsBusyPeriod reports whether the staff calendar has a busy event overlapping the range
+func IsBusyPeriod(ctx context.Context, start, end time.Time) bool {
+	var count int64
+	err := database.DB.WithContext(ctx).
+		Model(&database.CalendarBusyPeriod{}).
+		Where("starts_at < ? AND ends_at > ?", end.UTC(), start.UTC()).
+		Count(&count).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to check busy periods", "error", err)
+		return false
+	}
+	return count > 0
+}
@@ -0,0 +1,161 @@
+// Package services contains a minimal CalDAV client
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// caldavQuery asks for events overlapping a time range, recurring events expanded by the server
+const caldavQuery = `<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <D:getetag/>
+    <C:calendar-data>
+      <C:expand start="%[1]s" end="%[2]s"/>
+    </C:calendar-data>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT">
+        <C:time-range start="%[1]s" end="%[2]s"/>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+
+// CalDAVClient talks to a single CalDAV calendar collection
+type CalDAVClient struct {
+	collection string // Collection URL ending with a slash
+	username   string
+	password   string
+	HTTPClient *http.Client
+}
+
+// NewCalDAVClient creates a client for the collection at collectionURL
+func NewCalDAVClient(collectionURL, username, password string) *CalDAVClient {
+	if !strings.HasSuffix(collectionURL, "/") {
+		collectionURL += "/"
+	}
+	return &CalDAVClient{
+		collection: collectionURL,
+		username:   username,
+		password:   password,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// caldavMultistatus is a WebDAV multistatus response
+type caldavMultistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				ETag         string `xml:"DAV: getetag"`
+				CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// Put stores a calendar object under the event UID and returns its new ETag
+func (c *CalDAVClient) Put(ctx context.Context, uid string, data []byte) (string, error) {
+	resp, err := c.do(ctx, http.MethodPut, c.objectURL(uid), bytes.NewReader(data), map[string]string{
+		"Content-Type": CalendarMIME,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return "", caldavStatusError(http.MethodPut, resp)
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// Delete removes the calendar object of the event UID, missing objects are ignored
+func (c *CalDAVClient) Delete(ctx context.Context, uid string) error {
+	resp, err := c.do(ctx, http.MethodDelete, c.objectURL(uid), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		return caldavStatusError(http.MethodDelete, resp)
+	}
+	return nil
+}
+
+// Events returns events of the collection overlapping the time range
+func (c *CalDAVClient) Events(ctx context.Context, from, to time.Time) ([]icsEvent, error) {
+	body := fmt.Sprintf(caldavQuery, icsTime(from), icsTime(to))
+	resp, err := c.do(ctx, "REPORT", c.collection, strings.NewReader(body), map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+		"Depth":        "1",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, caldavStatusError("REPORT", resp)
+	}
+
+	var multistatus caldavMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&multistatus); err != nil {
+		return nil, fmt.Errorf("failed to decode CalDAV response: %w", err)
+	}
+
+	var events []icsEvent
+	for _, response := range multistatus.Responses {
+		for _, propstat := range response.Propstat {
+			if propstat.Prop.CalendarData == "" {
+				continue
+			}
+			events = append(events, parseICSEvents(propstat.Prop.CalendarData)...)
+		}
+	}
+	return events, nil
+}
+
+// objectURL returns the URL of the calendar object for the event UID
+func (c *CalDAVClient) objectURL(uid string) string {
+	return c.collection + url.PathEscape(uid) + ".ics"
+}
+
+// do sends an authenticated request
+func (c *CalDAVClient) do(ctx context.Context, method, target string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CalDAV request: %w", err)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send CalDAV %s: %w", method, err)
+	}
+	return resp, nil
+}
+
+// caldavStatusError describes an unexpected CalDAV response
+func caldavStatusError(method string, resp *http.Response) error {
+	text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("CalDAV %s failed: %s: %s", method, resp.Status, strings.TrimSpace(string(text)))
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gobot/internal/database"
+)
+
+// fakeCalDAV is an in-memory CalDAV collection served over httptest
+type fakeCalDAV struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu      sync.Mutex
+	objects map[string]string // Calendar data by object name
+	etags   map[string]int
+	fail    map[string]int // Number of upcoming requests of the method answered with 500
+	puts    int
+}
+
+// newFakeCalDAV starts a fake collection at /calendars/staff/ accepting user:secret
+func newFakeCalDAV(t *testing.T) *fakeCalDAV {
+	t.Helper()
+	f := &fakeCalDAV{
+		t:       t,
+		objects: make(map[string]string),
+		etags:   make(map[string]int),
+		fail:    make(map[string]int),
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// client returns a client of the fake collection
+func (f *fakeCalDAV) client() *CalDAVClient {
+	return NewCalDAVClient(f.server.URL+"/calendars/staff", "user", "secret")
+}
+
+// failNext makes the next n requests of the method fail
+func (f *fakeCalDAV) failNext(method string, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fail[method] = n
+}
+
+// object returns the stored calendar data of the object
+func (f *fakeCalDAV) object(name string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[name]
+	return data, ok
+}
+
+// putCount returns the number of uploads
+func (f *fakeCalDAV) putCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.puts
+}
+
+// store adds an event created by someone else, e.g. in a phone calendar
+func (f *fakeCalDAV) store(name, data string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[name] = data
+}
+
+func (f *fakeCalDAV) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if f.fail[r.Method] > 0 {
+		f.fail[r.Method]--
+		http.Error(w, "temporary failure", http.StatusInternalServerError)
+		return
+	}
+
+	name, ok := strings.CutPrefix(r.URL.Path, "/calendars/staff/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		if ct := r.Header.Get("Content-Type"); ct != CalendarMIME {
+			f.t.Errorf("PUT Content-Type = %q, want %q", ct, CalendarMIME)
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, exists := f.objects[name]
+		f.objects[name] = string(body)
+		f.etags[name]++
+		f.puts++
+		w.Header().Set("ETag", fmt.Sprintf(`"%s-%d"`, name, f.etags[name]))
+		if exists {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case http.MethodDelete:
+		if _, exists := f.objects[name]; !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.objects, name)
+		w.WriteHeader(http.StatusNoContent)
+	case "REPORT":
+		if name != "" || r.Header.Get("Depth") != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><D:multistatus xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">`)
+		for objectName, data := range f.objects {
+			fmt.Fprintf(w, `<D:response><D:href>/calendars/staff/%s</D:href><D:propstat><D:prop><D:getetag>"%d"</D:getetag><C:calendar-data>`, objectName, f.etags[objectName])
+			xml.EscapeText(w, []byte(data))
+			fmt.Fprint(w, `</C:calendar-data></D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat></D:response>`)
+		}
+		fmt.Fprint(w, `</D:multistatus>`)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// externalEvent returns a calendar object with one event
+func externalEvent(uid string, start time.Time, extra string) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\n" +
+		"UID:" + uid + "\r\n" +
+		"SUMMARY:Dentist\r\n" +
+		"DTSTART:" + icsTime(start) + "\r\n" +
+		"DTEND:" + icsTime(start.Add(time.Hour)) + "\r\n" +
+		extra +
+		"END:VEVENT\r\nEND:VCALENDAR\r\n"
+}
+
+// setBookingStatus changes the status bypassing the state machine
+func setBookingStatus(t *testing.T, booking *database.Booking, status database.BookingStatus) {
+	t.Helper()
+	if err := database.DB.Model(booking).Update("status", status).Error; err != nil {
+		t.Fatalf("failed to update booking: %v", err)
+	}
+}
+
+// bookingObject returns the object name the bot pushes the booking under
+func bookingObject(booking *database.Booking) string {
+	return fmt.Sprintf("booking-%d@%s.ics", booking.ID, calendarUIDDomain)
+}
+
+func TestCalDAVClient(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeCalDAV(t)
+	client := fake.client()
+
+	etag, err := client.Put(ctx, "event-1", []byte(externalEvent("event-1", time.Now(), "")))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if etag != `"event-1.ics-1"` {
+		t.Errorf("Put() etag = %q, want %q", etag, `"event-1.ics-1"`)
+	}
+	if etag, err = client.Put(ctx, "event-1", []byte(externalEvent("event-1", time.Now(), ""))); err != nil || etag != `"event-1.ics-2"` {
+		t.Errorf("Put() update = %q, %v, want the second etag", etag, err)
+	}
+
+	events, err := client.Events(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Events() error = %v", err)
+	}
+	if len(events) != 1 || events[0].UID != "event-1" || events[0].Summary != "Dentist" {
+		t.Errorf("Events() = %+v, want event-1", events)
+	}
+
+	if err := client.Delete(ctx, "event-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok := fake.object("event-1.ics"); ok {
+		t.Error("Delete() left the object on the server")
+	}
+	if err := client.Delete(ctx, "event-1"); err != nil {
+		t.Errorf("Delete() of a missing object error = %v, want nil", err)
+	}
+
+	errorTests := []struct {
+		method string
+		call   func() error
+	}{
+		{method: http.MethodPut, call: func() error { _, err := client.Put(ctx, "event-2", nil); return err }},
+		{method: http.MethodDelete, call: func() error { return client.Delete(ctx, "event-2") }},
+		{method: "REPORT", call: func() error { _, err := client.Events(ctx, time.Now(), time.Now()); return err }},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.method+" error", func(t *testing.T) {
+			fake.failNext(tt.method, 1)
+			err := tt.call()
+			if err == nil || !strings.Contains(err.Error(), "500") {
+				t.Errorf("error = %v, want the 500 status", err)
+			}
+		})
+	}
+
+	wrongPassword := NewCalDAVClient(fake.server.URL+"/calendars/staff/", "user", "wrong")
+	if _, err := wrongPassword.Put(ctx, "event-3", nil); err == nil {
+		t.Error("Put() with a wrong password error = nil, want 401")
+	}
+}
+
+func TestCalDAVSyncPushesBookings(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+	fake := newFakeCalDAV(t)
+	service := NewCalDAVService(fake.client(), NewCalendarService(), time.Hour)
+
+	booking := createTestBooking(t, 1001, 250000)
+	name := bookingObject(booking)
+
+	// Pending bookings are not pushed
+	service.Sync(ctx)
+	if status := service.Status(); status.LastError != nil || status.Pushed != 0 {
+		t.Fatalf("Sync() of a pending booking = %+v, want nothing pushed", status)
+	}
+
+	// Create
+	setBookingStatus(t, booking, database.BookingStatusConfirmed)
+	service.Sync(ctx)
+	if status := service.Status(); status.LastError != nil || status.Pushed != 1 {
+		t.Fatalf("Sync() after confirmation = %+v, want one pushed", status)
+	}
+	created, ok := fake.object(name)
+	if !ok {
+		t.Fatalf("object %s was not created", name)
+	}
+	var event database.CalendarEvent
+	if err := database.DB.Where("booking_id = ?", booking.ID).First(&event).Error; err != nil {
+		t.Fatalf("failed to get calendar event: %v", err)
+	}
+	if event.RemoteETag == "" || event.RemoteFingerprint != event.Fingerprint {
+		t.Errorf("calendar event = %+v, want the pushed fingerprint and etag", event)
+	}
+
+	// Unchanged bookings are not pushed again
+	service.Sync(ctx)
+	if status := service.Status(); status.Pushed != 0 || fake.putCount() != 1 {
+		t.Errorf("Sync() without changes = %+v after %d PUTs, want nothing pushed", status, fake.putCount())
+	}
+
+	// Update
+	if err := database.DB.Model(booking).Update("time", "15:00").Error; err != nil {
+		t.Fatalf("failed to update booking: %v", err)
+	}
+	service.Sync(ctx)
+	if status := service.Status(); status.LastError != nil || status.Pushed != 1 {
+		t.Fatalf("Sync() after rescheduling = %+v, want one pushed", status)
+	}
+	if updated, _ := fake.object(name); updated == created {
+		t.Error("rescheduled booking was not updated on the server")
+	}
+
+	// Delete
+	setBookingStatus(t, booking, database.BookingStatusCancelled)
+	service.Sync(ctx)
+	if status := service.Status(); status.LastError != nil || status.Deleted != 1 {
+		t.Fatalf("Sync() after cancellation = %+v, want one deleted", status)
+	}
+	if _, ok := fake.object(name); ok {
+		t.Error("cancelled booking is still on the server")
+	}
+	service.Sync(ctx)
+	if status := service.Status(); status.Deleted != 0 {
+		t.Errorf("Sync() after deletion = %+v, want nothing deleted again", status)
+	}
+}
+
+func TestCalDAVSyncRetriesFailedRequests(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+	fake := newFakeCalDAV(t)
+	service := NewCalDAVService(fake.client(), NewCalendarService(), time.Hour)
+
+	booking := createTestBooking(t, 1001, 250000)
+	setBookingStatus(t, booking, database.BookingStatusConfirmed)
+
+	fake.failNext(http.MethodPut, 1)
+	service.Sync(ctx)
+	if status := service.Status(); status.LastError == nil || status.Pushed != 0 {
+		t.Fatalf("Sync() with a failing PUT = %+v, want an error", status)
+	}
+	var event database.CalendarEvent
+	if err := database.DB.Where("booking_id = ?", booking.ID).First(&event).Error; err != nil {
+		t.Fatalf("failed to get calendar event: %v", err)
+	}
+	if event.RemoteFingerprint != "" {
+		t.Errorf("RemoteFingerprint = %q after a failed PUT, want empty", event.RemoteFingerprint)
+	}
+
+	service.Sync(ctx)
+	if status := service.Status(); status.LastError != nil || status.Pushed != 1 {
+		t.Fatalf("Sync() retry = %+v, want one pushed", status)
+	}
+
+	setBookingStatus(t, booking, database.BookingStatusCancelled)
+	fake.failNext(http.MethodDelete, 1)
+	service.Sync(ctx)
+	if status := service.Status(); status.LastError == nil || status.Deleted != 0 {
+		t.Fatalf("Sync() with a failing DELETE = %+v, want an error", status)
+	}
+	service.Sync(ctx)
+	if status := service.Status(); status.LastError != nil || status.Deleted != 1 {
+		t.Fatalf("Sync() retry = %+v, want one deleted", status)
+	}
+	if _, ok := fake.object(bookingObject(booking)); ok {
+		t.Error("cancelled booking is still on the server")
+	}
+}
+
+func TestCalDAVSyncPullsBusyPeriods(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+	fake := newFakeCalDAV(t)
+	service := NewCalDAVService(fake.client(), NewCalendarService(), time.Hour)
+
+	start := time.Now().AddDate(0, 0, 2).Truncate(time.Hour)
+	fake.store("dentist.ics", externalEvent("dentist", start, ""))
+	fake.store("lunch.ics", externalEvent("lunch", start.Add(3*time.Hour), "TRANSP:TRANSPARENT\r\n"))
+	fake.store("trip.ics", externalEvent("trip", start.Add(6*time.Hour), "STATUS:CANCELLED\r\n"))
+
+	// The bot's own events are bookings already
+	booking := createTestBooking(t, 1001, 250000)
+	setBookingStatus(t, booking, database.BookingStatusConfirmed)
+
+	service.Sync(ctx)
+	status := service.Status()
+	if status.LastError != nil || status.Pushed != 1 || status.BusyPeriods != 1 {
+		t.Fatalf("Sync() = %+v, want one pushed and one busy period", status)
+	}
+	if !IsBusyPeriod(ctx, start.Add(30*time.Minute), start.Add(90*time.Minute)) {
+		t.Error("IsBusyPeriod() = false during the external event")
+	}
+	if IsBusyPeriod(ctx, start.Add(3*time.Hour), start.Add(4*time.Hour)) {
+		t.Error("IsBusyPeriod() = true during a transparent event")
+	}
+
+	// A failed pull keeps the periods of the last successful one
+	fake.failNext("REPORT", 1)
+	service.Sync(ctx)
+	if status := service.Status(); status.LastError == nil {
+		t.Fatal("Sync() with a failing REPORT error = nil")
+	}
+	if !IsBusyPeriod(ctx, start, start.Add(time.Hour)) {
+		t.Error("busy periods were dropped after a failed pull")
+	}
+}
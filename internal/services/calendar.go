@@ -0,0 +1,459 @@
+// Package services contains iCalendar export of bookings
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gobot/internal/database"
+	"gobot/internal/i18n"
+
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// calendarProdID identifies the bot as the producer of exported calendars
+	calendarProdID = "-//gobot//Massage booking bot//RU"
+	// calendarUIDDomain makes event UIDs globally unique, must never change
+	calendarUIDDomain = "gobot.booking"
+	// calendarFeedPastDays is how far back the admin feed lists bookings
+	calendarFeedPastDays = 30
+	// calendarFeedName is shown by calendar apps for the admin feed
+	calendarFeedName = "Записи клиентов"
+	// CalendarMIME is the content type of exported calendars
+	CalendarMIME = "text/calendar; charset=utf-8"
+)
+
+// CalendarService exports bookings in iCalendar format
+type CalendarService struct{}
+
+// NewCalendarService creates a new calendar service instance
+func NewCalendarService() *CalendarService {
+	return &CalendarService{}
+}
+
+// BookingICS returns a calendar file with a single booking for its client
+func (s *CalendarService) BookingICS(ctx context.Context, lang string, booking *database.Booking) ([]byte, error) {
+	if booking.Service.ID == 0 {
+		if err := database.DB.WithContext(ctx).First(&booking.Service, booking.ServiceID).Error; err != nil {
+			return nil, fmt.Errorf("failed to load service: %w", err)
+		}
+	}
+
+	events, err := s.trackEvents(ctx, []database.Booking{*booking})
+	if err != nil {
+		return nil, err
+	}
+
+	data := BookingTemplateData(lang, booking)
+	description := i18n.T(lang, "calendar.description", booking.Service.Duration, data.Price)
+	if booking.Service.Description != "" {
+		description = booking.Service.Description + "\n" + description
+	}
+
+	var w icsWriter
+	w.begin("", "PUBLISH")
+	w.event(booking, events[booking.ID], booking.Service.Name, description)
+	w.end()
+	return w.bytes(), nil
+}
+
+// FeedICS returns the admin calendar with confirmed bookings
+// Cancelled bookings stay in the feed marked as cancelled once they were published,
+// so subscribed calendars remove them instead of keeping a stale copy
+func (s *CalendarService) FeedICS(ctx context.Context) ([]byte, error) {
+	var bookings []database.Booking
+	err := database.DB.WithContext(ctx).
+		Preload("Service").
+		Preload("User").
+		Where("date >= ?", time.Now().AddDate(0, 0, -calendarFeedPastDays)).
+		Where("status IN ? OR (status = ? AND id IN (?))",
+			[]database.BookingStatus{database.BookingStatusConfirmed, database.BookingStatusCompleted, database.BookingStatusNoShow},
+			database.BookingStatusCancelled,
+			database.DB.Model(&database.CalendarEvent{}).Select("booking_id"),
+		).
+		Order("date, time").
+		Find(&bookings).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookings: %w", err)
+	}
+
+	events, err := s.trackEvents(ctx, bookings)
+	if err != nil {
+		return nil, err
+	}
+
+	var w icsWriter
+	w.begin(calendarFeedName, "PUBLISH")
+	for i := range bookings {
+		w.adminEvent(&bookings[i], events[bookings[i].ID])
+	}
+	w.end()
+	return w.bytes(), nil
+}
+
+// trackEvents returns calendar events of the bookings, creating missing ones
+// The sequence is increased for bookings changed since they were last exported
+func (s *CalendarService) trackEvents(ctx context.Context, bookings []database.Booking) (map[uint]*database.CalendarEvent, error) {
+	ids := make([]uint, 0, len(bookings))
+	for _, booking := range bookings {
+		ids = append(ids, booking.ID)
+	}
+
+	var existing []database.CalendarEvent
+	if len(ids) > 0 {
+		if err := database.DB.WithContext(ctx).Where("booking_id IN ?", ids).Find(&existing).Error; err != nil {
+			return nil, fmt.Errorf("failed to get calendar events: %w", err)
+		}
+	}
+
+	events := make(map[uint]*database.CalendarEvent, len(bookings))
+	for i := range existing {
+		events[existing[i].BookingID] = &existing[i]
+	}
+
+	for i := range bookings {
+		booking := &bookings[i]
+		fingerprint := eventFingerprint(booking)
+
+		event, ok := events[booking.ID]
+		if !ok {
+			event = &database.CalendarEvent{
+				BookingID:   booking.ID,
+				UID:         fmt.Sprintf("booking-%d@%s", booking.ID, calendarUIDDomain),
+				Fingerprint: fingerprint,
+			}
+			// Another request may have exported the booking at the same time
+			result := database.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+			if result.Error != nil {
+				return nil, fmt.Errorf("failed to create calendar event: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				if err := database.DB.WithContext(ctx).Where("booking_id = ?", booking.ID).First(event).Error; err != nil {
+					return nil, fmt.Errorf("failed to get calendar event: %w", err)
+				}
+			}
+			events[booking.ID] = event
+		}
+
+		if event.Fingerprint != fingerprint {
+			event.Sequence++
+			event.Fingerprint = fingerprint
+			if err := database.DB.WithContext(ctx).Save(event).Error; err != nil {
+				return nil, fmt.Errorf("failed to update calendar event: %w", err)
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// eventFingerprint returns the booking fields calendar apps show
+func eventFingerprint(booking *database.Booking) string {
+	return fmt.Sprintf("%s|%s|%s|%d|%d|%s",
+		booking.Date.Format("2006-01-02"),
+		booking.Time,
+		eventStatus(booking.Status),
+		booking.ServiceID,
+		booking.Service.Duration,
+		booking.Notes,
+	)
+}
+
+// eventStatus maps a booking status to an iCalendar event status
+func eventStatus(status database.BookingStatus) string {
+	switch status {
+	case database.BookingStatusCancelled, database.BookingStatusRejected:
+		return "CANCELLED"
+	case database.BookingStatusPending:
+		return "TENTATIVE"
+	default:
+		return "CONFIRMED"
+	}
+}
+
+// adminEventSummary returns the event title shown to staff
+func adminEventSummary(booking *database.Booking) string {
+	summary := booking.Service.Name
+	if name := strings.TrimSpace(booking.User.FirstName + " " + booking.User.LastName); name != "" {
+		summary += " — " + name
+	}
+	return summary
+}
+
+// adminEventDescription returns client details shown in the admin feed
+func adminEventDescription(booking *database.Booking) string {
+	lines := make([]string, 0, 5)
+	if booking.User.Username != "" {
+		lines = append(lines, "Telegram: @"+booking.User.Username)
+	}
+	if booking.User.Phone != "" {
+		lines = append(lines, "Телефон: "+booking.User.Phone)
+	}
+
+	lines = append(lines, "Стоимость: "+i18n.Price(i18n.Default, PaidPrice(booking)))
+
+	if booking.Notes != "" {
+		lines = append(lines, "Заметка: "+booking.Notes)
+	}
+	lines = append(lines, fmt.Sprintf("Запись #%d", booking.ID))
+
+	return strings.Join(lines, "\n")
+}
+
+// icsWriter builds an iCalendar document
+type icsWriter struct {
+	sb strings.Builder
+}
+
+// begin opens the calendar, name and method are optional
+// CalDAV objects must be written without a method
+func (w *icsWriter) begin(name, method string) {
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", calendarProdID)
+	w.line("CALSCALE", "GREGORIAN")
+	if method != "" {
+		w.line("METHOD", method)
+	}
+	if name != "" {
+		w.line("X-WR-CALNAME", escapeICSText(name))
+	}
+}
+
+// event writes a booking as VEVENT
+func (w *icsWriter) event(booking *database.Booking, event *database.CalendarEvent, summary, description string) {
+	start := bookingStart(booking)
+	end := start.Add(time.Duration(booking.Service.Duration) * time.Minute)
+
+	w.line("BEGIN", "VEVENT")
+	w.line("UID", event.UID)
+	w.line("SEQUENCE", fmt.Sprintf("%d", event.Sequence))
+	w.line("DTSTAMP", icsTime(event.UpdatedAt))
+	w.line("CREATED", icsTime(booking.CreatedAt))
+	w.line("LAST-MODIFIED", icsTime(event.UpdatedAt))
+	w.line("DTSTART", icsTime(start))
+	w.line("DTEND", icsTime(end))
+	w.line("SUMMARY", escapeICSText(summary))
+	w.line("DESCRIPTION", escapeICSText(description))
+	w.line("STATUS", eventStatus(booking.Status))
+	w.line("END", "VEVENT")
+}
+
+// adminEvent writes a booking as VEVENT with client details for staff
+func (w *icsWriter) adminEvent(booking *database.Booking, event *database.CalendarEvent) {
+	w.event(booking, event, adminEventSummary(booking), adminEventDescription(booking))
+}
+
+// end closes the calendar
+func (w *icsWriter) end() {
+	w.line("END", "VCALENDAR")
+}
+
+// bytes returns the document
+func (w *icsWriter) bytes() []byte {
+	return []byte(w.sb.String())
+}
+
+// line writes a content line folded to 75 octets as required by RFC 5545
+func (w *icsWriter) line(name, value string) {
+	line := name + ":" + value
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			w.sb.WriteString("\r\n ")
+			width = 1
+		}
+		w.sb.WriteRune(r)
+		width += size
+	}
+	w.sb.WriteString("\r\n")
+}
+
+// icsTime formats time in UTC so no timezone definitions are needed
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeICSText escapes a TEXT property value
+func escapeICSText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// icsEvent is a VEVENT read from a remote calendar
+type icsEvent struct {
+	UID         string
+	Summary     string
+	Start       time.Time
+	End         time.Time
+	Transparent bool // TRANSP:TRANSPARENT, the event doesn't block time
+	Cancelled   bool
+}
+
+// parseICSEvents reads events of an iCalendar document
+// Events without a start time are skipped
+func parseICSEvents(data string) []icsEvent {
+	// Unfold continuation lines first
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\n ", "")
+	data = strings.ReplaceAll(data, "\n\t", "")
+
+	var events []icsEvent
+	var current *icsEvent
+	var duration time.Duration
+	allDay := false
+	depth := 0 // Nested components such as VALARM
+
+	for _, line := range strings.Split(data, "\n") {
+		name, params, value := parseICSLine(line)
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			current = &icsEvent{}
+			duration = 0
+			allDay = false
+			depth = 0
+			continue
+		case current == nil:
+			continue
+		case name == "BEGIN":
+			depth++
+			continue
+		case name == "END" && value != "VEVENT":
+			depth--
+			continue
+		case depth > 0:
+			continue
+		}
+
+		switch name {
+		case "END":
+			if !current.Start.IsZero() {
+				if current.End.IsZero() {
+					switch {
+					case duration > 0:
+						current.End = current.Start.Add(duration)
+					case allDay:
+						current.End = current.Start.AddDate(0, 0, 1)
+					default:
+						current.End = current.Start
+					}
+				}
+				events = append(events, *current)
+			}
+			current = nil
+		case "UID":
+			current.UID = value
+		case "SUMMARY":
+			current.Summary = unescapeICSText(value)
+		case "DTSTART":
+			current.Start, allDay = parseICSTime(params, value)
+		case "DTEND":
+			current.End, _ = parseICSTime(params, value)
+		case "DURATION":
+			duration = parseICSDuration(value)
+		case "TRANSP":
+			current.Transparent = value == "TRANSPARENT"
+		case "STATUS":
+			current.Cancelled = value == "CANCELLED"
+		}
+	}
+
+	return events
+}
+
+// parseICSLine splits a content line into name, parameters and value
+func parseICSLine(line string) (string, map[string]string, string) {
+	line = strings.TrimRight(line, "\r")
+	head, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", nil, ""
+	}
+
+	parts := strings.Split(head, ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		if key, val, ok := strings.Cut(param, "="); ok {
+			params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, value
+}
+
+// parseICSTime parses DATE and DATE-TIME values
+// Floating times and unknown timezones are treated as local time
+func parseICSTime(params map[string]string, value string) (time.Time, bool) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t, true
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t, false
+	}
+
+	loc := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, false
+}
+
+// parseICSDuration parses DURATION values like PT1H30M or P1D
+func parseICSDuration(value string) time.Duration {
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "+"), "P")
+	var d time.Duration
+	number := 0
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			number = number*10 + int(r-'0')
+			continue
+		case r == 'W':
+			d += time.Duration(number) * 7 * 24 * time.Hour
+		case r == 'D':
+			d += time.Duration(number) * 24 * time.Hour
+		case r == 'H':
+			d += time.Duration(number) * time.Hour
+		case r == 'M':
+			d += time.Duration(number) * time.Minute
+		case r == 'S':
+			d += time.Duration(number) * time.Second
+		}
+		number = 0
+	}
+	return d
+}
+
+// unescapeICSText reverses escapeICSText
+func unescapeICSText(s string) string {
+	return strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	).Replace(s)
+}
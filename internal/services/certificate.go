@@ -0,0 +1,313 @@
+// Package services contains gift certificate logic
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"gobot/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// Certificate validation errors
+var (
+	ErrCertificateNotFound     = errors.New("certificate not found")
+	ErrCertificateExpired      = errors.New("certificate expired")
+	ErrCertificateInactive     = errors.New("certificate is not active")
+	ErrCertificateWrongService = errors.New("certificate is issued for another service")
+	ErrCertificateEmpty        = errors.New("certificate balance is empty")
+)
+
+// certificateCodeAlphabet excludes characters that are easy to confuse (0/O, 1/I/L)
+const certificateCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// CertificateService handles gift certificate operations
+type CertificateService struct{}
+
+// NewCertificateService creates a new certificate service instance
+func NewCertificateService() *CertificateService {
+	return &CertificateService{}
+}
+
+// IssueCertificate creates a new gift certificate
+// For service certificates the amount is taken from the current service price
+func (s *CertificateService) IssueCertificate(ctx context.Context, issuedBy int64, amount int, serviceID *uint, expiresAt time.Time, recipientName string) (*database.GiftCertificate, error) {
+	if serviceID != nil {
+		var service database.Service
+		if err := database.DB.WithContext(ctx).First(&service, *serviceID).Error; err != nil {
+			return nil, fmt.Errorf("service not found: %w", err)
+		}
+		amount = service.Price
+	}
+
+	if amount <= 0 {
+		return nil, fmt.Errorf("certificate amount must be positive")
+	}
+
+	code, err := generateCertificateCode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate certificate code: %w", err)
+	}
+
+	certificate := &database.GiftCertificate{
+		Code:          code,
+		Amount:        amount,
+		Balance:       amount,
+		ServiceID:     serviceID,
+		Status:        database.GiftCertificateStatusActive,
+		ExpiresAt:     expiresAt,
+		IssuedBy:      issuedBy,
+		RecipientName: recipientName,
+	}
+
+	if err := database.DB.WithContext(ctx).Create(certificate).Error; err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	return s.GetCertificateByID(ctx, certificate.ID)
+}
+
+// IssuePurchasedCertificate creates a nominal certificate paid by a client
+func (s *CertificateService) IssuePurchasedCertificate(ctx context.Context, buyerID int64, amount int, expiresAt time.Time, chargeID string) (*database.GiftCertificate, error) {
+	certificate, err := s.IssueCertificate(ctx, buyerID, amount, nil, expiresAt, "")
+	if err != nil {
+		return nil, err
+	}
+
+	certificate.PurchasedBy = &buyerID
+	certificate.PaymentChargeID = chargeID
+	if err := database.DB.WithContext(ctx).Save(certificate).Error; err != nil {
+		return nil, fmt.Errorf("failed to save payment info: %w", err)
+	}
+
+	return certificate, nil
+}
+
+// GetCertificateByID retrieves a certificate with its service and redemptions
+func (s *CertificateService) GetCertificateByID(ctx context.Context, certificateID uint) (*database.GiftCertificate, error) {
+	var certificate database.GiftCertificate
+	err := database.DB.WithContext(ctx).
+		Preload("Service").
+		Preload("Redemptions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Redemptions.Booking").
+		First(&certificate, certificateID).Error
+	if err != nil {
+		return nil, ErrCertificateNotFound
+	}
+	return &certificate, nil
+}
+
+// GetCertificateByCode retrieves a certificate by its code
+func (s *CertificateService) GetCertificateByCode(ctx context.Context, code string) (*database.GiftCertificate, error) {
+	var certificate database.GiftCertificate
+	err := database.DB.WithContext(ctx).
+		Preload("Service").
+		Where("code = ?", NormalizeCertificateCode(code)).
+		First(&certificate).Error
+	if err != nil {
+		return nil, ErrCertificateNotFound
+	}
+	return &certificate, nil
+}
+
+// ListCertificates retrieves the most recently issued certificates
+func (s *CertificateService) ListCertificates(ctx context.Context, limit int) ([]database.GiftCertificate, error) {
+	var certificates []database.GiftCertificate
+	err := database.DB.WithContext(ctx).
+		Preload("Service").
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&certificates).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get certificates: %w", err)
+	}
+	return certificates, nil
+}
+
+// VoidCertificate makes a certificate unusable
+func (s *CertificateService) VoidCertificate(ctx context.Context, certificateID uint) error {
+	now := time.Now()
+	result := database.DB.WithContext(ctx).
+		Model(&database.GiftCertificate{}).
+		Where("id = ? AND status = ?", certificateID, database.GiftCertificateStatusActive).
+		Updates(map[string]interface{}{
+			"status":    database.GiftCertificateStatusVoided,
+			"voided_at": now,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to void certificate: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrCertificateInactive
+	}
+
+	return nil
+}
+
+// ValidateForService checks that a certificate can be applied to a booking of the service
+func (s *CertificateService) ValidateForService(ctx context.Context, code string, serviceID uint) (*database.GiftCertificate, error) {
+	certificate, err := s.GetCertificateByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkCertificateUsable(certificate, serviceID); err != nil {
+		return certificate, err
+	}
+
+	return certificate, nil
+}
+
+// CertificateDeduction calculates how much of the price a certificate covers
+func CertificateDeduction(certificate *database.GiftCertificate, price int) int {
+	if certificate.ServiceID != nil {
+		return price
+	}
+	if certificate.Balance < price {
+		return certificate.Balance
+	}
+	return price
+}
+
+// RedeemForBooking spends the certificate on a booking and records the redemption
+func (s *CertificateService) RedeemForBooking(ctx context.Context, code string, booking *database.Booking) (int, error) {
+	var deducted int
+
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var certificate database.GiftCertificate
+		if err := tx.Where("code = ?", NormalizeCertificateCode(code)).First(&certificate).Error; err != nil {
+			return ErrCertificateNotFound
+		}
+
+		if err := checkCertificateUsable(&certificate, booking.ServiceID); err != nil {
+			return err
+		}
+
+		deducted = CertificateDeduction(&certificate, booking.Price)
+		if certificate.ServiceID != nil {
+			certificate.Balance = 0
+		} else {
+			certificate.Balance -= deducted
+		}
+		if certificate.Balance == 0 {
+			certificate.Status = database.GiftCertificateStatusUsed
+		}
+
+		if err := tx.Save(&certificate).Error; err != nil {
+			return fmt.Errorf("failed to update certificate: %w", err)
+		}
+
+		redemption := &database.GiftCertificateRedemption{
+			CertificateID: certificate.ID,
+			BookingID:     booking.ID,
+			UserID:        booking.UserID,
+			Amount:        deducted,
+		}
+		if err := tx.Create(redemption).Error; err != nil {
+			return fmt.Errorf("failed to record redemption: %w", err)
+		}
+
+		if err := tx.Model(&database.Booking{}).
+			Where("id = ?", booking.ID).
+			Update("certificate_amount", deducted).Error; err != nil {
+			return fmt.Errorf("failed to update booking: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	booking.CertificateAmount = deducted
+	return deducted, nil
+}
+
+// refundCertificates returns certificate amounts spent on a booking to their balances
+// Runs in the transaction cancelling or rejecting the booking; each redemption is refunded once
+func refundCertificates(tx *gorm.DB, bookingID uint) error {
+	var redemptions []database.GiftCertificateRedemption
+	if err := tx.Where("booking_id = ? AND refunded_at IS NULL", bookingID).Find(&redemptions).Error; err != nil {
+		return fmt.Errorf("failed to get certificate redemptions: %w", err)
+	}
+
+	now := time.Now()
+	for _, redemption := range redemptions {
+		var certificate database.GiftCertificate
+		if err := tx.First(&certificate, redemption.CertificateID).Error; err != nil {
+			return fmt.Errorf("failed to get certificate %d: %w", redemption.CertificateID, err)
+		}
+
+		// Service certificates cover the whole visit, so they get their full value back
+		if certificate.ServiceID != nil {
+			certificate.Balance = certificate.Amount
+		} else {
+			certificate.Balance = min(certificate.Balance+redemption.Amount, certificate.Amount)
+		}
+		// Voided certificates stay voided
+		if certificate.Status == database.GiftCertificateStatusUsed {
+			certificate.Status = database.GiftCertificateStatusActive
+		}
+		if err := tx.Save(&certificate).Error; err != nil {
+			return fmt.Errorf("failed to refund certificate %d: %w", certificate.ID, err)
+		}
+
+		if err := tx.Model(&redemption).Update("refunded_at", now).Error; err != nil {
+			return fmt.Errorf("failed to record certificate refund: %w", err)
+		}
+	}
+	return nil
+}
+
+// NormalizeCertificateCode converts user input to the stored code format
+func NormalizeCertificateCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// checkCertificateUsable validates certificate status, expiry and service restriction
+func checkCertificateUsable(certificate *database.GiftCertificate, serviceID uint) error {
+	if certificate.Status != database.GiftCertificateStatusActive {
+		return ErrCertificateInactive
+	}
+	if time.Now().After(certificate.ExpiresAt) {
+		return ErrCertificateExpired
+	}
+	if certificate.ServiceID != nil && *certificate.ServiceID != serviceID {
+		return ErrCertificateWrongService
+	}
+	if certificate.Balance <= 0 {
+		return ErrCertificateEmpty
+	}
+	return nil
+}
+
+// generateCertificateCode generates a random code like GIFT-ABCD-EFGH
+func generateCertificateCode() (string, error) {
+	const groups, groupLen = 2, 4
+	max := big.NewInt(int64(len(certificateCodeAlphabet)))
+
+	parts := []string{"GIFT"}
+	for g := 0; g < groups; g++ {
+		group := make([]byte, groupLen)
+		for i := range group {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return "", err
+			}
+			group[i] = certificateCodeAlphabet[n.Int64()]
+		}
+		parts = append(parts, string(group))
+	}
+
+	return strings.Join(parts, "-"), nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gobot/internal/database"
+)
+
+func TestCancelledBookingRefundsCertificate(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      int
+		price       int
+		forService  bool
+		to          database.BookingStatus
+		change      StatusChange
+		wantBalance int
+	}{
+		{name: "client cancels, partial amount", amount: 500000, price: 300000, to: database.BookingStatusCancelled, change: ByClient(1), wantBalance: 500000},
+		{name: "client cancels, whole balance", amount: 200000, price: 300000, to: database.BookingStatusCancelled, change: ByClient(1), wantBalance: 200000},
+		{name: "admin rejects", amount: 300000, price: 300000, to: database.BookingStatusRejected, change: ByAdmin(2), wantBalance: 300000},
+		{name: "service certificate", price: 300000, forService: true, to: database.BookingStatusCancelled, change: ByClient(1), wantBalance: 300000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			ctx := context.Background()
+			certificates := NewCertificateService()
+
+			booking := createTestBooking(t, 1, tt.price)
+			var serviceID *uint
+			if tt.forService {
+				serviceID = &booking.ServiceID
+			}
+			certificate, err := certificates.IssueCertificate(ctx, 2, tt.amount, serviceID, time.Now().AddDate(1, 0, 0), "")
+			if err != nil {
+				t.Fatalf("IssueCertificate() error = %v", err)
+			}
+			if _, err := certificates.RedeemForBooking(ctx, certificate.Code, booking); err != nil {
+				t.Fatalf("RedeemForBooking() error = %v", err)
+			}
+
+			if err := TransitionBooking(ctx, booking.ID, tt.to, tt.change); err != nil {
+				t.Fatalf("TransitionBooking() error = %v", err)
+			}
+
+			got, err := certificates.GetCertificateByID(ctx, certificate.ID)
+			if err != nil {
+				t.Fatalf("GetCertificateByID() error = %v", err)
+			}
+			if got.Balance != tt.wantBalance {
+				t.Errorf("Balance = %d, want %d", got.Balance, tt.wantBalance)
+			}
+			if got.Status != database.GiftCertificateStatusActive {
+				t.Errorf("Status = %s, want %s", got.Status, database.GiftCertificateStatusActive)
+			}
+
+			var redemption database.GiftCertificateRedemption
+			if err := database.DB.Where("booking_id = ?", booking.ID).First(&redemption).Error; err != nil {
+				t.Fatalf("failed to get redemption: %v", err)
+			}
+			if redemption.RefundedAt == nil {
+				t.Error("RefundedAt is not set")
+			}
+		})
+	}
+}
+
+func TestCompletedBookingKeepsCertificateSpent(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+	certificates := NewCertificateService()
+
+	booking := createTestBooking(t, 1, 300000)
+	certificate, err := certificates.IssueCertificate(ctx, 2, 300000, nil, time.Now().AddDate(1, 0, 0), "")
+	if err != nil {
+		t.Fatalf("IssueCertificate() error = %v", err)
+	}
+	if _, err := certificates.RedeemForBooking(ctx, certificate.Code, booking); err != nil {
+		t.Fatalf("RedeemForBooking() error = %v", err)
+	}
+	for _, to := range []database.BookingStatus{database.BookingStatusConfirmed, database.BookingStatusCompleted} {
+		if err := TransitionBooking(ctx, booking.ID, to, ByAdmin(2)); err != nil {
+			t.Fatalf("TransitionBooking(%s) error = %v", to, err)
+		}
+	}
+
+	got, err := certificates.GetCertificateByID(ctx, certificate.ID)
+	if err != nil {
+		t.Fatalf("GetCertificateByID() error = %v", err)
+	}
+	if got.Balance != 0 || got.Status != database.GiftCertificateStatusUsed {
+		t.Errorf("certificate = balance %d, status %s, want 0, %s", got.Balance, got.Status, database.GiftCertificateStatusUsed)
+	}
+}
+
+func TestVoidedCertificateStaysVoidedOnRefund(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+	certificates := NewCertificateService()
+
+	booking := createTestBooking(t, 1, 100000)
+	certificate, err := certificates.IssueCertificate(ctx, 2, 300000, nil, time.Now().AddDate(1, 0, 0), "")
+	if err != nil {
+		t.Fatalf("IssueCertificate() error = %v", err)
+	}
+	if _, err := certificates.RedeemForBooking(ctx, certificate.Code, booking); err != nil {
+		t.Fatalf("RedeemForBooking() error = %v", err)
+	}
+	if err := certificates.VoidCertificate(ctx, certificate.ID); err != nil {
+		t.Fatalf("VoidCertificate() error = %v", err)
+	}
+	if err := TransitionBooking(ctx, booking.ID, database.BookingStatusCancelled, ByClient(1)); err != nil {
+		t.Fatalf("TransitionBooking() error = %v", err)
+	}
+
+	got, err := certificates.GetCertificateByID(ctx, certificate.ID)
+	if err != nil {
+		t.Fatalf("GetCertificateByID() error = %v", err)
+	}
+	if got.Status != database.GiftCertificateStatusVoided {
+		t.Errorf("Status = %s, want %s", got.Status, database.GiftCertificateStatusVoided)
+	}
+}
@@ -0,0 +1,197 @@
+// Package services contains web dashboard sign in
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gobot/internal/database"
+)
+
+// Lifetimes of dashboard credentials
+const (
+	LoginLinkTTL    = 10 * time.Minute
+	AdminSessionTTL = 7 * 24 * time.Hour
+
+	// telegramLoginMaxAge rejects replayed Login Widget data
+	telegramLoginMaxAge = 24 * time.Hour
+	// telegramAuthDateSkew tolerates clocks of Telegram and the server drifting apart
+	telegramAuthDateSkew = 5 * time.Minute
+)
+
+// Dashboard sign in errors
+var (
+	ErrInvalidLoginToken    = errors.New("invalid or expired login link")
+	ErrInvalidSession       = errors.New("invalid or expired session")
+	ErrInvalidTelegramLogin = errors.New("invalid Telegram login data")
+)
+
+// DashboardAuthService signs admins in to the web dashboard
+type DashboardAuthService struct{}
+
+// NewDashboardAuthService creates a new dashboard auth service instance
+func NewDashboardAuthService() *DashboardAuthService {
+	return &DashboardAuthService{}
+}
+
+// CreateLoginToken issues a one-time login link token for an admin
+func (s *DashboardAuthService) CreateLoginToken(ctx context.Context, adminID int64) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	loginToken := &database.AdminLoginToken{
+		TokenHash: hashToken(token),
+		AdminID:   adminID,
+		ExpiresAt: time.Now().Add(LoginLinkTTL),
+	}
+	if err := database.DB.WithContext(ctx).Create(loginToken).Error; err != nil {
+		return "", fmt.Errorf("failed to create login token: %w", err)
+	}
+
+	return token, nil
+}
+
+// ConsumeLoginToken marks a login link as used and returns its admin
+// A link works only once, so a leaked browser history can't be replayed
+func (s *DashboardAuthService) ConsumeLoginToken(ctx context.Context, token string) (int64, error) {
+	now := time.Now()
+	hash := hashToken(token)
+
+	result := database.DB.WithContext(ctx).
+		Model(&database.AdminLoginToken{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hash, now).
+		Update("used_at", &now)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to use login token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrInvalidLoginToken
+	}
+
+	var loginToken database.AdminLoginToken
+	if err := database.DB.WithContext(ctx).Where("token_hash = ?", hash).First(&loginToken).Error; err != nil {
+		return 0, fmt.Errorf("failed to get login token: %w", err)
+	}
+
+	return loginToken.AdminID, nil
+}
+
+// CreateSession starts a dashboard session and returns its cookie value
+func (s *DashboardAuthService) CreateSession(ctx context.Context, adminID int64) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	session := &database.AdminSession{
+		TokenHash: hashToken(token),
+		AdminID:   adminID,
+		ExpiresAt: time.Now().Add(AdminSessionTTL),
+	}
+	if err := database.DB.WithContext(ctx).Create(session).Error; err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+
+	// Old sessions and links are cleaned up on sign in, there are only a few admins
+	now := time.Now()
+	database.DB.WithContext(ctx).Where("expires_at < ?", now).Delete(&database.AdminSession{})
+	database.DB.WithContext(ctx).Where("expires_at < ?", now).Delete(&database.AdminLoginToken{})
+
+	return token, nil
+}
+
+// GetSession returns the active session with the cookie value
+func (s *DashboardAuthService) GetSession(ctx context.Context, token string) (*database.AdminSession, error) {
+	var session database.AdminSession
+	err := database.DB.WithContext(ctx).
+		Where("token_hash = ? AND expires_at > ?", hashToken(token), time.Now()).
+		First(&session).Error
+	if err != nil {
+		return nil, ErrInvalidSession
+	}
+	return &session, nil
+}
+
+// DeleteSession signs the browser out
+func (s *DashboardAuthService) DeleteSession(ctx context.Context, token string) error {
+	err := database.DB.WithContext(ctx).
+		Where("token_hash = ?", hashToken(token)).
+		Delete(&database.AdminSession{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// VerifyTelegramLogin checks data of the Telegram Login Widget and returns the user ID
+// See https://core.telegram.org/widgets/login#checking-authorization
+func VerifyTelegramLogin(botToken string, values url.Values, now time.Time) (int64, error) {
+	hash := values.Get("hash")
+	if hash == "" {
+		return 0, ErrInvalidTelegramLogin
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, key+"="+values.Get(key))
+	}
+
+	secret := sha256.Sum256([]byte(botToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(hash)) {
+		return 0, ErrInvalidTelegramLogin
+	}
+
+	if !validAuthDate(values.Get("auth_date"), now, telegramLoginMaxAge) {
+		return 0, ErrInvalidTelegramLogin
+	}
+
+	userID, err := strconv.ParseInt(values.Get("id"), 10, 64)
+	if err != nil {
+		return 0, ErrInvalidTelegramLogin
+	}
+
+	return userID, nil
+}
+
+// validAuthDate reports whether a signed auth_date is neither older than maxAge nor in the future
+// A date from the future would keep the data valid for longer than maxAge
+func validAuthDate(value string, now time.Time, maxAge time.Duration) bool {
+	authDate, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+	age := now.Sub(time.Unix(authDate, 0))
+	return age <= maxAge && age >= -telegramAuthDateSkew
+}
+
+// randomToken returns a random hex token for links and cookies
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// testBotToken signs the fixed Telegram payloads of the tests
+const testBotToken = "123456:TEST-token"
+
+// testAuthDate is auth_date of the fixed payloads
+var testAuthDate = time.Unix(1760000000, 0)
+
+func TestVerifyTelegramLogin(t *testing.T) {
+	valid := func() url.Values {
+		return url.Values{
+			"id":         {"1001"},
+			"first_name": {"Anna"},
+			"username":   {"anna"},
+			"auth_date":  {"1760000000"},
+			"hash":       {"0ac4357b8c077b10d61269199fb231e21a6273394962977c2e2ca1c7100dbb1b"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(url.Values)
+		now    time.Time
+		wantID int64
+	}{
+		{name: "valid", now: testAuthDate.Add(time.Hour), wantID: 1001},
+		{name: "clock slightly behind", now: testAuthDate.Add(-time.Minute), wantID: 1001},
+		{name: "tampered name", modify: func(v url.Values) { v.Set("first_name", "Admin") }, now: testAuthDate},
+		{name: "tampered id", modify: func(v url.Values) { v.Set("id", "1") }, now: testAuthDate},
+		{name: "extra field", modify: func(v url.Values) { v.Set("photo_url", "https://example.com/a.jpg") }, now: testAuthDate},
+		{name: "missing hash", modify: func(v url.Values) { v.Del("hash") }, now: testAuthDate},
+		{name: "wrong token", modify: func(v url.Values) { v.Set("hash", "00"+v.Get("hash")[2:]) }, now: testAuthDate},
+		{name: "expired", now: testAuthDate.Add(telegramLoginMaxAge + time.Second)},
+		{name: "future", now: testAuthDate.Add(-telegramAuthDateSkew - time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := valid()
+			if tt.modify != nil {
+				tt.modify(values)
+			}
+			id, err := VerifyTelegramLogin(testBotToken, values, tt.now)
+			if tt.wantID == 0 {
+				if err != ErrInvalidTelegramLogin {
+					t.Errorf("VerifyTelegramLogin() = %d, %v, want ErrInvalidTelegramLogin", id, err)
+				}
+				return
+			}
+			if err != nil || id != tt.wantID {
+				t.Errorf("VerifyTelegramLogin() = %d, %v, want %d", id, err, tt.wantID)
+			}
+		})
+	}
+}
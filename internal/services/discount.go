@@ -37,6 +37,8 @@ func (s *DiscountService) CreateDiscount(ctx context.Context, serviceID uint, na
 		return nil, fmt.Errorf("failed to load discount relations: %w", err)
 	}
 
+	recordAudit(ctx, AuditDiscountCreate, AuditEntityDiscount, discount.ID, nil, discount)
+	Events.Publish(ctx, EventDiscountCreated, discountEventData(discount))
 	return discount, nil
 }
 
@@ -96,6 +98,39 @@ func (s *DiscountService) GetServiceWithDiscount(ctx context.Context, serviceID
 	return &service, service.Price, nil
 }
 
+// UpdateDiscount updates name, percentage, period and status of a discount
+func (s *DiscountService) UpdateDiscount(ctx context.Context, discount *database.Discount) error {
+	var before database.Discount
+	if err := database.DB.WithContext(ctx).First(&before, discount.ID).Error; err != nil {
+		return fmt.Errorf("discount not found: %w", err)
+	}
+
+	result := database.DB.WithContext(ctx).
+		Model(&database.Discount{}).
+		Where("id = ?", discount.ID).
+		Updates(map[string]interface{}{
+			"name":       discount.Name,
+			"percentage": discount.Percentage,
+			"start_date": discount.StartDate,
+			"end_date":   discount.EndDate,
+			"is_active":  discount.IsActive,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update discount: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("discount not found")
+	}
+
+	var after database.Discount
+	if err := database.DB.WithContext(ctx).First(&after, discount.ID).Error; err == nil {
+		recordAudit(ctx, AuditDiscountUpdate, AuditEntityDiscount, discount.ID, &before, &after)
+	}
+	return nil
+}
+
 // ToggleDiscountStatus activates or deactivates a discount
 func (s *DiscountService) ToggleDiscountStatus(ctx context.Context, discountID uint) error {
 	var discount database.Discount
@@ -103,16 +138,23 @@ func (s *DiscountService) ToggleDiscountStatus(ctx context.Context, discountID u
 		return fmt.Errorf("discount not found: %w", err)
 	}
 
+	before := discount
 	discount.IsActive = !discount.IsActive
 	if err := database.DB.WithContext(ctx).Save(&discount).Error; err != nil {
 		return fmt.Errorf("failed to toggle discount status: %w", err)
 	}
 
+	recordAudit(ctx, AuditDiscountToggle, AuditEntityDiscount, discount.ID, &before, &discount)
 	return nil
 }
 
 // DeleteDiscount deletes a discount
 func (s *DiscountService) DeleteDiscount(ctx context.Context, discountID uint) error {
+	var before database.Discount
+	if err := database.DB.WithContext(ctx).First(&before, discountID).Error; err != nil {
+		return fmt.Errorf("discount not found: %w", err)
+	}
+
 	result := database.DB.WithContext(ctx).Delete(&database.Discount{}, discountID)
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete discount: %w", result.Error)
@@ -122,6 +164,7 @@ func (s *DiscountService) DeleteDiscount(ctx context.Context, discountID uint) e
 		return fmt.Errorf("discount not found")
 	}
 
+	recordAudit(ctx, AuditDiscountDelete, AuditEntityDiscount, discountID, &before, nil)
 	return nil
 }
 
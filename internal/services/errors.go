@@ -0,0 +1,69 @@
+// Package services contains domain errors shown to users
+package services
+
+import (
+	"errors"
+
+	"gobot/internal/i18n"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of domain errors, services wrap them so front ends can tell the user what went wrong
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrForbidden  = errors.New("forbidden")
+	ErrSlotTaken  = errors.New("time slot is taken")
+	ErrValidation = errors.New("invalid input")
+)
+
+// ValidationError is invalid input with a message explaining it to the user
+type ValidationError struct {
+	Key  string // i18n message key
+	Args []interface{}
+}
+
+// Invalid returns a validation error shown to the user as the translated message
+func Invalid(key string, args ...interface{}) error {
+	return &ValidationError{Key: key, Args: args}
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return "invalid input: " + e.Key
+}
+
+// Is makes errors.Is(err, ErrValidation) match validation errors
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// UserErrorText returns a message explaining a domain error to the user
+// Returns false for unexpected errors, which users only see as a generic failure
+func UserErrorText(lang string, err error) (string, bool) {
+	var validationErr *ValidationError
+	var blockedErr *BlockedError
+	switch {
+	case errors.As(err, &validationErr):
+		return i18n.T(lang, validationErr.Key, validationErr.Args...), true
+	case errors.As(err, &blockedErr),
+		errors.Is(err, ErrTooManyActiveBookings),
+		errors.Is(err, ErrTooManyBookingsPerDay),
+		errors.Is(err, ErrBookingTooFrequent):
+		return LimitErrorText(lang, err), true
+	case errors.Is(err, ErrValidation):
+		return i18n.T(lang, "error.validation"), true
+	case errors.Is(err, ErrSlotTaken):
+		return i18n.T(lang, "slot.taken"), true
+	case errors.Is(err, ErrBookingTransition):
+		return i18n.T(lang, "error.booking_changed"), true
+	case errors.Is(err, ErrBookingNotMovable):
+		return i18n.T(lang, "error.booking_not_movable"), true
+	case errors.Is(err, ErrForbidden):
+		return i18n.T(lang, "error.no_access"), true
+	case errors.Is(err, ErrNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		return i18n.T(lang, "error.not_found"), true
+	default:
+		return "", false
+	}
+}
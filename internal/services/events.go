@@ -0,0 +1,199 @@
+// Package services contains the domain event bus
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"gobot/internal/database"
+)
+
+// Event types published on the event bus
+const (
+	EventBookingCreated     = "booking.created"
+	EventBookingConfirmed   = "booking.confirmed"
+	EventBookingCancelled   = "booking.cancelled"
+	EventBookingRejected    = "booking.rejected"
+	EventBookingCompleted   = "booking.completed"
+	EventBookingNoShow      = "booking.no_show"
+	EventBookingRescheduled = "booking.rescheduled"
+	EventDiscountCreated    = "discount.created"
+	EventUserRegistered     = "user.registered"
+)
+
+// bookingStatusEvents maps booking statuses to the events published when a booking gets them
+var bookingStatusEvents = map[database.BookingStatus]string{
+	database.BookingStatusConfirmed: EventBookingConfirmed,
+	database.BookingStatusCancelled: EventBookingCancelled,
+	database.BookingStatusRejected:  EventBookingRejected,
+	database.BookingStatusCompleted: EventBookingCompleted,
+	database.BookingStatusNoShow:    EventBookingNoShow,
+}
+
+// Event is something that happened in the domain, e.g. a booking was confirmed
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// BookingEventData is the data of booking events
+type BookingEventData struct {
+	ID          uint                   `json:"id"`
+	Status      database.BookingStatus `json:"status"`
+	Date        string                 `json:"date"` // YYYY-MM-DD
+	Time        string                 `json:"time"` // HH:MM
+	Price       int                    `json:"price"`
+	ServiceID   uint                   `json:"service_id"`
+	ServiceName string                 `json:"service_name"`
+	Duration    int                    `json:"duration"`
+	UserID      int64                  `json:"user_id"`
+	ClientName  string                 `json:"client_name"`
+	Username    string                 `json:"username,omitempty"`
+	Phone       string                 `json:"phone,omitempty"`
+}
+
+// DiscountEventData is the data of discount events
+type DiscountEventData struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Percentage  int    `json:"percentage"`
+	ServiceID   uint   `json:"service_id"`
+	ServiceName string `json:"service_name"`
+	StartDate   string `json:"start_date"`
+	EndDate     string `json:"end_date"`
+}
+
+// UserEventData is the data of user events
+type UserEventData struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+	Offline   bool   `json:"offline"` // Client added by an admin without Telegram
+}
+
+// EventHandler receives published events
+// Handlers run synchronously in the publisher, so slow work must be queued
+type EventHandler func(ctx context.Context, event Event)
+
+// EventBus delivers domain events to subscribers
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers []EventHandler
+}
+
+// Events is the event bus of the application, services publish to it after saving changes
+var Events = NewEventBus()
+
+// NewEventBus creates a new event bus
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe adds a handler called for every event
+func (b *EventBus) Subscribe(handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// HasSubscribers reports whether anyone listens, so publishers can skip building event data
+func (b *EventBus) HasSubscribers() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.handlers) > 0
+}
+
+// Publish sends an event to all subscribers
+func (b *EventBus) Publish(ctx context.Context, eventType string, data interface{}) {
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+	if len(handlers) == 0 {
+		return
+	}
+
+	event := Event{
+		ID:         newEventID(),
+		Type:       eventType,
+		OccurredAt: time.Now(),
+		Data:       data,
+	}
+	for _, handler := range handlers {
+		handler(ctx, event)
+	}
+}
+
+// publishBookingEvent loads a booking with its client and service and publishes an event about it
+func publishBookingEvent(ctx context.Context, eventType string, bookingID uint) {
+	if !Events.HasSubscribers() {
+		return
+	}
+
+	var booking database.Booking
+	if err := database.DB.WithContext(ctx).Preload("Service").Preload("User").First(&booking, bookingID).Error; err != nil {
+		slog.ErrorContext(ctx, "Failed to load booking for event", "booking_id", bookingID, "event", eventType, "error", err)
+		return
+	}
+	Events.Publish(ctx, eventType, bookingEventData(&booking))
+}
+
+// bookingEventData returns event data of a booking loaded with its client and service
+func bookingEventData(booking *database.Booking) BookingEventData {
+	return BookingEventData{
+		ID:          booking.ID,
+		Status:      booking.Status,
+		Date:        booking.Date.Format("2006-01-02"),
+		Time:        booking.Time,
+		Price:       booking.Price,
+		ServiceID:   booking.ServiceID,
+		ServiceName: booking.Service.Name,
+		Duration:    booking.Service.Duration,
+		UserID:      booking.UserID,
+		ClientName:  strings.TrimSpace(booking.User.FirstName + " " + booking.User.LastName),
+		Username:    booking.User.Username,
+		Phone:       booking.User.Phone,
+	}
+}
+
+// discountEventData returns event data of a discount loaded with its service
+func discountEventData(discount *database.Discount) DiscountEventData {
+	return DiscountEventData{
+		ID:          discount.ID,
+		Name:        discount.Name,
+		Percentage:  discount.Percentage,
+		ServiceID:   discount.ServiceID,
+		ServiceName: discount.Service.Name,
+		StartDate:   discount.StartDate.Format("2006-01-02"),
+		EndDate:     discount.EndDate.Format("2006-01-02"),
+	}
+}
+
+// userEventData returns event data of a user
+func userEventData(user *database.User) UserEventData {
+	return UserEventData{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Username:  user.Username,
+		Phone:     user.Phone,
+		Offline:   user.IsOffline,
+	}
+}
+
+// newEventID returns a random event ID receivers can deduplicate retries by
+func newEventID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
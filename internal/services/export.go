@@ -0,0 +1,402 @@
+// Package services contains CSV and XLSX exports for accounting
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gobot/internal/database"
+	"gobot/internal/i18n"
+)
+
+// Export kinds
+const (
+	ExportBookings = "bookings"
+	ExportClients  = "clients"
+	ExportRevenue  = "revenue"
+	ExportAudit    = "audit"
+)
+
+// Export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+// exportMoney is an amount in kopecks written as rubles
+type exportMoney int
+
+// ExportTable is a table of exported values
+// Cells are strings, int, int64, exportMoney or float64
+type ExportTable struct {
+	Name    string
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// ExportFile is a generated export ready to be sent or saved
+type ExportFile struct {
+	Name string
+	MIME string
+	Data []byte
+}
+
+// ExportService generates exports of bookings, clients and revenue
+type ExportService struct{}
+
+// NewExportService creates a new export service instance
+func NewExportService() *ExportService {
+	return &ExportService{}
+}
+
+// Export builds the export of the kind in the format
+// The period is ignored for the client list
+func (s *ExportService) Export(ctx context.Context, kind, format string, period ReportPeriod) (*ExportFile, error) {
+	var table *ExportTable
+	var err error
+
+	switch kind {
+	case ExportBookings:
+		table, err = s.BookingsTable(ctx, period)
+	case ExportClients:
+		table, err = s.ClientsTable(ctx)
+	case ExportRevenue:
+		table, err = s.RevenueTable(ctx, period)
+	case ExportAudit:
+		table, err = s.AuditTable(ctx, period)
+	default:
+		return nil, fmt.Errorf("unknown export: %s", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	name := kind
+	if kind != ExportClients {
+		name += "_" + period.From.Format("2006-01-02") + "_" + period.To.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+
+	var buf bytes.Buffer
+	file := &ExportFile{}
+	switch format {
+	case ExportFormatCSV:
+		err = WriteCSV(&buf, table)
+		file.Name = name + ".csv"
+		file.MIME = "text/csv"
+	case ExportFormatXLSX:
+		err = WriteXLSX(&buf, table)
+		file.Name = name + ".xlsx"
+		file.MIME = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return nil, fmt.Errorf("unknown export format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file.Data = buf.Bytes()
+	return file, nil
+}
+
+// BookingsTable returns bookings with a visit in the period
+func (s *ExportService) BookingsTable(ctx context.Context, period ReportPeriod) (*ExportTable, error) {
+	var bookings []database.Booking
+	err := database.DB.WithContext(ctx).
+		Preload("Service").
+		Preload("User").
+		Where("date >= ? AND date < ?", period.From, period.To).
+		Order("date, time").
+		Find(&bookings).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookings: %w", err)
+	}
+
+	table := &ExportTable{
+		Name: "Записи",
+		Columns: []string{
+			"ID", "Дата", "Время", "Услуга", "Длительность, мин", "Клиент", "Username", "Телефон",
+			"Статус", "Цена", "Оплачено сертификатом", "Заметка", "Создана",
+		},
+	}
+	for _, booking := range bookings {
+		table.Rows = append(table.Rows, []interface{}{
+			int(booking.ID),
+			booking.Date.Format("02.01.2006"),
+			booking.Time,
+			booking.Service.Name,
+			booking.Service.Duration,
+			strings.TrimSpace(booking.User.FirstName + " " + booking.User.LastName),
+			booking.User.Username,
+			booking.User.Phone,
+			i18n.T(i18n.Default, "status."+string(booking.Status)),
+			exportMoney(PaidPrice(&booking)),
+			exportMoney(booking.CertificateAmount),
+			booking.Notes,
+			booking.CreatedAt.Format("02.01.2006 15:04"),
+		})
+	}
+
+	return table, nil
+}
+
+// ClientsTable returns all clients with their visit totals
+func (s *ExportService) ClientsTable(ctx context.Context) (*ExportTable, error) {
+	var users []database.User
+	if err := database.DB.WithContext(ctx).Order("created_at").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to get clients: %w", err)
+	}
+
+	var bookings []database.Booking
+	if err := database.DB.WithContext(ctx).Preload("Service").Find(&bookings).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bookings: %w", err)
+	}
+
+	type clientTotals struct {
+		bookings  int
+		completed int
+		spent     int
+		lastVisit string
+	}
+	totals := make(map[int64]*clientTotals)
+	for i := range bookings {
+		booking := &bookings[i]
+		t, ok := totals[booking.UserID]
+		if !ok {
+			t = &clientTotals{}
+			totals[booking.UserID] = t
+		}
+		t.bookings++
+		if booking.Status == database.BookingStatusCompleted {
+			t.completed++
+			t.spent += PaidPrice(booking)
+			if visit := booking.Date.Format("2006-01-02"); visit > t.lastVisit {
+				t.lastVisit = visit
+			}
+		}
+	}
+
+	table := &ExportTable{
+		Name: "Клиенты",
+		Columns: []string{
+			"ID", "Имя", "Фамилия", "Username", "Телефон", "День рождения", "Язык",
+			"Записей", "Визитов", "Потрачено", "Последний визит", "Без Telegram", "Заблокирован", "Создан",
+		},
+	}
+	for _, user := range users {
+		t := totals[user.ID]
+		if t == nil {
+			t = &clientTotals{}
+		}
+
+		birthday := ""
+		if user.Birthday != nil {
+			birthday = user.Birthday.Format("02.01.2006")
+		}
+		lastVisit := ""
+		if t.lastVisit != "" {
+			lastVisit = t.lastVisit[8:10] + "." + t.lastVisit[5:7] + "." + t.lastVisit[0:4]
+		}
+
+		table.Rows = append(table.Rows, []interface{}{
+			strconv.FormatInt(user.ID, 10),
+			user.FirstName,
+			user.LastName,
+			user.Username,
+			user.Phone,
+			birthday,
+			UserLanguage(&user),
+			t.bookings,
+			t.completed,
+			exportMoney(t.spent),
+			lastVisit,
+			exportYesNo(user.IsOffline),
+			exportYesNo(user.IsBlocked),
+			user.CreatedAt.Format("02.01.2006"),
+		})
+	}
+
+	return table, nil
+}
+
+// AuditTable returns admin changes made in the period
+func (s *ExportService) AuditTable(ctx context.Context, period ReportPeriod) (*ExportTable, error) {
+	records, err := NewAuditService().ListPeriod(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &ExportTable{
+		Name: "Журнал",
+		Columns: []string{
+			"ID", "Время", "Кто", "Действие", "Объект", "ID объекта", "Изменения", "До", "После",
+		},
+	}
+	for i := range records {
+		record := &records[i]
+		action := AuditActionTitles[record.Action]
+		if action == "" {
+			action = record.Action
+		}
+		entity := AuditEntityTitles[record.EntityType]
+		if entity == "" {
+			entity = record.EntityType
+		}
+
+		table.Rows = append(table.Rows, []interface{}{
+			int(record.ID),
+			record.CreatedAt.Format("02.01.2006 15:04:05"),
+			ActorText(AuditActor(record)),
+			action,
+			entity,
+			int(record.EntityID),
+			strings.Join(AuditChanges(record), "\n"),
+			record.Before,
+			record.After,
+		})
+	}
+
+	return table, nil
+}
+
+// RevenueTable returns revenue per day of the period with a total row
+func (s *ExportService) RevenueTable(ctx context.Context, period ReportPeriod) (*ExportTable, error) {
+	var bookings []database.Booking
+	err := database.DB.WithContext(ctx).
+		Preload("Service").
+		Where("date >= ? AND date < ?", period.From, period.To).
+		Find(&bookings).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookings: %w", err)
+	}
+
+	type dayTotals struct {
+		bookings, completed, cancelled, noShow int
+		revenue, certificates                  int
+	}
+	days := make(map[string]*dayTotals)
+	for i := range bookings {
+		booking := &bookings[i]
+		key := booking.Date.Format("2006-01-02")
+		t, ok := days[key]
+		if !ok {
+			t = &dayTotals{}
+			days[key] = t
+		}
+		t.bookings++
+		switch booking.Status {
+		case database.BookingStatusCompleted:
+			t.completed++
+			t.revenue += PaidPrice(booking)
+			t.certificates += booking.CertificateAmount
+		case database.BookingStatusCancelled, database.BookingStatusRejected:
+			t.cancelled++
+		case database.BookingStatusNoShow:
+			t.noShow++
+		}
+	}
+
+	table := &ExportTable{
+		Name: "Выручка",
+		Columns: []string{
+			"Дата", "Записей", "Завершено", "Отмены", "Неявки", "Выручка", "В т.ч. сертификатами", "Средний чек",
+		},
+	}
+
+	total := &dayTotals{}
+	addRow := func(title string, t *dayTotals) {
+		average := 0
+		if t.completed > 0 {
+			average = t.revenue / t.completed
+		}
+		table.Rows = append(table.Rows, []interface{}{
+			title, t.bookings, t.completed, t.cancelled, t.noShow,
+			exportMoney(t.revenue), exportMoney(t.certificates), exportMoney(average),
+		})
+	}
+
+	for day := period.From; day.Before(period.To); day = day.AddDate(0, 0, 1) {
+		t := days[day.Format("2006-01-02")]
+		if t == nil {
+			t = &dayTotals{}
+		}
+		addRow(day.Format("02.01.2006"), t)
+
+		total.bookings += t.bookings
+		total.completed += t.completed
+		total.cancelled += t.cancelled
+		total.noShow += t.noShow
+		total.revenue += t.revenue
+		total.certificates += t.certificates
+	}
+	addRow("Итого", total)
+
+	return table, nil
+}
+
+// WriteCSV writes the table as CSV for Excel with Russian regional settings:
+// UTF-8 with BOM, semicolon separator and decimal comma
+func WriteCSV(w io.Writer, table *ExportTable) error {
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = ';'
+	cw.UseCRLF = true
+
+	if err := cw.Write(table.Columns); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	record := make([]string, len(table.Columns))
+	for _, row := range table.Rows {
+		for i, value := range row {
+			record[i] = csvValue(value)
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// csvValue formats a cell for CSV
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case exportMoney:
+		return fmt.Sprintf("%d,%02d", int(v)/100, int(v)%100)
+	case float64:
+		return strings.Replace(strconv.FormatFloat(v, 'f', 1, 64), ".", ",", 1)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// PaidPrice returns the price the client pays for a booking
+// Bookings made before prices were stored fall back to the service price, the service must be preloaded
+func PaidPrice(booking *database.Booking) int {
+	if booking.Price != 0 {
+		return booking.Price
+	}
+	return booking.Service.Price
+}
+
+// exportYesNo formats a flag
+func exportYesNo(value bool) string {
+	if value {
+		return "да"
+	}
+	return "нет"
+}
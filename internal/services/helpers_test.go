@@ -0,0 +1,47 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gobot/internal/database"
+)
+
+// setupTestDB points the global database at a fresh SQLite file for one test
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	if err := database.Initialize(filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := database.Close(); err != nil {
+			t.Errorf("failed to close database: %v", err)
+		}
+	})
+}
+
+// createTestBooking creates a client, a service and a pending booking of the client tomorrow
+func createTestBooking(t *testing.T, userID int64, price int) *database.Booking {
+	t.Helper()
+	user := &database.User{ID: userID, FirstName: "Anna", Username: "anna"}
+	if err := database.DB.FirstOrCreate(user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	service := &database.Service{Name: "Massage", Duration: 60, Price: price}
+	if err := database.DB.Create(service).Error; err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	booking := &database.Booking{
+		UserID:    userID,
+		ServiceID: service.ID,
+		Date:      time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour),
+		Time:      "12:00",
+		Status:    database.BookingStatusPending,
+		Price:     price,
+	}
+	if err := database.DB.Create(booking).Error; err != nil {
+		t.Fatalf("failed to create booking: %v", err)
+	}
+	return booking
+}
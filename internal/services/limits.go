@@ -0,0 +1,151 @@
+// Package services contains business logic for the application
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gobot/internal/database"
+	"gobot/internal/i18n"
+)
+
+// Booking limit errors
+var (
+	ErrBookingTooFrequent    = errors.New("booking attempts too frequent")
+	ErrTooManyActiveBookings = errors.New("too many active bookings")
+	ErrTooManyBookingsPerDay = errors.New("too many bookings per day")
+)
+
+// BlockedError is returned when an admin blocked the client from booking
+type BlockedError struct {
+	Reason string // Empty when the reason is hidden from the client
+}
+
+// Error implements the error interface
+func (e *BlockedError) Error() string {
+	if e.Reason == "" {
+		return "user is blocked"
+	}
+	return "user is blocked: " + e.Reason
+}
+
+// BookingLimits configures anti-abuse limits, zero value disables a limit
+type BookingLimits struct {
+	MaxActive   int
+	MaxPerDay   int
+	MinInterval time.Duration
+}
+
+// BookingLimiter checks whether a client is allowed to create a booking
+type BookingLimiter struct {
+	limits BookingLimits
+
+	mu          sync.Mutex
+	lastAttempt map[int64]time.Time
+}
+
+// NewBookingLimiter creates a new booking limiter instance
+func NewBookingLimiter(limits BookingLimits) *BookingLimiter {
+	return &BookingLimiter{
+		limits:      limits,
+		lastAttempt: make(map[int64]time.Time),
+	}
+}
+
+// Limits returns configured limits
+func (l *BookingLimiter) Limits() BookingLimits {
+	return l.limits
+}
+
+// Check verifies block status and booking counts of a client
+func (l *BookingLimiter) Check(ctx context.Context, userID int64) error {
+	var user database.User
+	if err := database.DB.WithContext(ctx).First(&user, userID).Error; err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.IsBlocked {
+		blockedErr := &BlockedError{}
+		if !user.BlockReasonHidden {
+			blockedErr.Reason = user.BlockReason
+		}
+		return blockedErr
+	}
+
+	if l.limits.MaxActive > 0 {
+		var active int64
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if err := database.DB.WithContext(ctx).
+			Model(&database.Booking{}).
+			Where("user_id = ? AND status IN ? AND date >= ?", userID,
+				[]database.BookingStatus{database.BookingStatusPending, database.BookingStatusConfirmed}, today).
+			Count(&active).Error; err != nil {
+			return fmt.Errorf("failed to count active bookings: %w", err)
+		}
+		if active >= int64(l.limits.MaxActive) {
+			return ErrTooManyActiveBookings
+		}
+	}
+
+	if l.limits.MaxPerDay > 0 {
+		var created int64
+		if err := database.DB.WithContext(ctx).
+			Model(&database.Booking{}).
+			Where("user_id = ? AND created_at >= ?", userID, time.Now().Add(-24*time.Hour)).
+			Count(&created).Error; err != nil {
+			return fmt.Errorf("failed to count recent bookings: %w", err)
+		}
+		if created >= int64(l.limits.MaxPerDay) {
+			return ErrTooManyBookingsPerDay
+		}
+	}
+
+	return nil
+}
+
+// Attempt registers a booking attempt and checks all limits
+func (l *BookingLimiter) Attempt(ctx context.Context, userID int64) error {
+	if l.limits.MinInterval > 0 {
+		l.mu.Lock()
+		now := time.Now()
+		if last, ok := l.lastAttempt[userID]; ok && now.Sub(last) < l.limits.MinInterval {
+			l.mu.Unlock()
+			return ErrBookingTooFrequent
+		}
+
+		// Forget stale attempts so the map doesn't grow forever
+		for id, last := range l.lastAttempt {
+			if now.Sub(last) >= l.limits.MinInterval {
+				delete(l.lastAttempt, id)
+			}
+		}
+		l.lastAttempt[userID] = now
+		l.mu.Unlock()
+	}
+
+	return l.Check(ctx, userID)
+}
+
+// LimitErrorText returns user-facing text for booking limiter errors
+func LimitErrorText(lang string, err error) string {
+	var blockedErr *BlockedError
+	switch {
+	case errors.As(err, &blockedErr):
+		if blockedErr.Reason != "" {
+			return i18n.T(lang, "limit.blocked_reason", blockedErr.Reason)
+		}
+		return i18n.T(lang, "limit.blocked")
+	case errors.Is(err, ErrTooManyActiveBookings):
+		return i18n.T(lang, "limit.active")
+	case errors.Is(err, ErrTooManyBookingsPerDay):
+		return i18n.T(lang, "limit.per_day")
+	case errors.Is(err, ErrBookingTooFrequent):
+		return i18n.T(lang, "limit.too_frequent")
+	default:
+		return i18n.T(lang, "error.generic")
+	}
+}
@@ -0,0 +1,98 @@
+// Package services contains metrics of bookings, notifications and background workers
+package services
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gobot/internal/database"
+	"gobot/internal/metrics"
+)
+
+// Notification kinds that aren't template keys
+const (
+	NotificationBookingCreated = "booking_created"
+	NotificationCalendarFile   = "calendar_file"
+	NotificationAdmin          = "admin"
+	NotificationAdminBooking   = "admin_booking"
+	NotificationAdminReminder  = "admin_reminder"
+	NotificationPromotion      = "promotion"
+	NotificationReview         = "review"
+)
+
+var (
+	bookingsTotal = metrics.NewCounter("gobot_bookings_total",
+		"Booking events by event and service", "event", "service")
+	notificationsTotal = metrics.NewCounter("gobot_notifications_total",
+		"Notifications queued or failed to queue by kind", "kind", "result")
+	outboxDeliveriesTotal = metrics.NewCounter("gobot_outbox_deliveries_total",
+		"Outbox delivery attempts by result: sent, retry, failed, blocked", "result")
+
+	// reminderLastRun is the Unix time in nanoseconds of the last reminder pass
+	reminderLastRun atomic.Int64
+)
+
+func init() {
+	metrics.NewGaugeFunc("gobot_reminder_worker_lag_seconds",
+		"Seconds since the last reminder pass, above the hourly interval the worker is behind", reminderLag)
+	metrics.NewGaugeFunc("gobot_outbox_queue_depth",
+		"Outgoing messages waiting to be sent", func() float64 {
+			return countPending(&database.OutboxMessage{}, database.OutboxStatusPending)
+		})
+	metrics.NewGaugeFunc("gobot_webhook_queue_depth",
+		"Webhook deliveries waiting to be sent", func() float64 {
+			return countPending(&database.WebhookDelivery{}, database.WebhookStatusPending)
+		})
+}
+
+// CountBookingEvent counts booking events by service, subscribe it to the event bus
+func CountBookingEvent(ctx context.Context, event Event) {
+	data, ok := event.Data.(BookingEventData)
+	if !ok {
+		return
+	}
+	service := data.ServiceName
+	if service == "" {
+		service = strconv.FormatUint(uint64(data.ServiceID), 10)
+	}
+	bookingsTotal.Inc(strings.TrimPrefix(event.Type, "booking."), service)
+}
+
+// countNotification counts a notification by whether it was queued
+func countNotification(kind string, err error) {
+	result := "queued"
+	if err != nil {
+		result = "failed"
+	}
+	notificationsTotal.Inc(kind, result)
+}
+
+// markReminderRun records the time of a reminder pass
+func markReminderRun() {
+	reminderLastRun.Store(time.Now().UnixNano())
+}
+
+// reminderLag returns seconds since the last reminder pass, 0 before the worker starts
+func reminderLag() float64 {
+	last := reminderLastRun.Load()
+	if last == 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, last)).Seconds()
+}
+
+// countPending counts rows of a queue model in the status, NaN when the database can't be read
+func countPending(model interface{}, status interface{}) float64 {
+	if database.DB == nil {
+		return 0
+	}
+	var count int64
+	if err := database.DB.Model(model).Where("status = ?", status).Count(&count).Error; err != nil {
+		return math.NaN()
+	}
+	return float64(count)
+}
@@ -4,94 +4,127 @@ package services
 import (
 	"context"
 	"fmt"
-	"log"
+	"html"
+	"log/slog"
+	"strings"
 	"time"
 
+	"gobot/internal/callback"
 	"gobot/internal/database"
+	"gobot/internal/i18n"
 
 	tele "gopkg.in/telebot.v3"
 )
 
 // NotificationService handles notifications and reminders
 type NotificationService struct {
-	bot       *tele.Bot
-	adminIDs  []int64
-	channelID string
+	outbox             *OutboxService
+	templates          *TemplateService
+	calendar           *CalendarService
+	adminIDs           []int64
+	channelID          string
+	reviewRequestDelay time.Duration
 }
 
 // NewNotificationService creates a new notification service
-func NewNotificationService(bot *tele.Bot, adminIDs []int64, channelID string) *NotificationService {
+func NewNotificationService(outbox *OutboxService, templates *TemplateService, calendar *CalendarService, adminIDs []int64, channelID string, reviewRequestDelay time.Duration) *NotificationService {
 	return &NotificationService{
-		bot:       bot,
-		adminIDs:  adminIDs,
-		channelID: channelID,
+		outbox:             outbox,
+		templates:          templates,
+		calendar:           calendar,
+		adminIDs:           adminIDs,
+		channelID:          channelID,
+		reviewRequestDelay: reviewRequestDelay,
 	}
 }
 
 // SendBookingConfirmation sends confirmation message to user
 func (s *NotificationService) SendBookingConfirmation(ctx context.Context, booking *database.Booking) error {
-	msg := fmt.Sprintf(
-		"✅ <b>Ваша запись подтверждена!</b>\n\n"+
-			"📋 Услуга: <b>%s</b>\n"+
-			"📆 Дата: <b>%s</b>\n"+
-			"⏰ Время: <b>%s</b>\n"+
-			"💰 Стоимость: %d руб.\n\n"+
-			"Мы ждем вас! 🌟\n"+
-			"За день до визита мы отправим напоминание.",
-		booking.Service.Name,
-		booking.Date.Format("02.01.2006"),
-		booking.Time,
-		booking.Service.Price/100,
-	)
-
-	recipient := &tele.User{ID: booking.UserID}
-	_, err := s.bot.Send(recipient, msg, &tele.SendOptions{ParseMode: tele.ModeHTML})
+	msg, err := s.renderBooking(ctx, TemplateBookingConfirmed, booking)
 	if err != nil {
+		return err
+	}
+
+	if err := s.sendToClient(ctx, TemplateBookingConfirmed, booking, msg, nil); err != nil {
 		return fmt.Errorf("failed to send confirmation: %w", err)
 	}
 
+	s.sendCalendarFile(ctx, booking)
 	return nil
 }
 
-// SendBookingCancellation sends cancellation message to user
-func (s *NotificationService) SendBookingCancellation(ctx context.Context, booking *database.Booking) error {
-	msg := fmt.Sprintf(
-		"❌ <b>Запись отменена</b>\n\n"+
-			"📋 Услуга: %s\n"+
-			"📆 Дата: %s в %s\n\n"+
-			"Вы можете создать новую запись с помощью команды /book",
+// SendBookingCreated tells the client the booking is waiting for confirmation
+// Used when the booking was made outside the chat, e.g. in the Mini App
+func (s *NotificationService) SendBookingCreated(ctx context.Context, booking *database.Booking) error {
+	lang := s.clientLanguage(ctx, booking)
+	msg := i18n.T(lang, "booking.created",
 		booking.Service.Name,
-		booking.Date.Format("02.01.2006"),
+		i18n.Date(lang, booking.Date),
+		i18n.Weekday(lang, booking.Date),
 		booking.Time,
+		i18n.Price(lang, booking.Price),
+		"",
 	)
 
-	recipient := &tele.User{ID: booking.UserID}
-	_, err := s.bot.Send(recipient, msg, &tele.SendOptions{ParseMode: tele.ModeHTML})
+	if err := s.sendToClient(ctx, NotificationBookingCreated, booking, msg, nil); err != nil {
+		return fmt.Errorf("failed to send booking notice: %w", err)
+	}
+
+	return nil
+}
+
+// SendBookingCancellation sends cancellation message to user
+func (s *NotificationService) SendBookingCancellation(ctx context.Context, booking *database.Booking) error {
+	msg, err := s.renderBooking(ctx, TemplateBookingCancelled, booking)
 	if err != nil {
+		return err
+	}
+
+	if err := s.sendToClient(ctx, TemplateBookingCancelled, booking, msg, nil); err != nil {
 		return fmt.Errorf("failed to send cancellation: %w", err)
 	}
 
 	return nil
 }
 
+// SendBookingRejection notifies user that admin rejected the booking
+func (s *NotificationService) SendBookingRejection(ctx context.Context, booking *database.Booking) error {
+	msg, err := s.renderBooking(ctx, TemplateBookingRejected, booking)
+	if err != nil {
+		return err
+	}
+
+	if err := s.sendToClient(ctx, TemplateBookingRejected, booking, msg, nil); err != nil {
+		return fmt.Errorf("failed to send rejection: %w", err)
+	}
+
+	return nil
+}
+
+// SendBookingRescheduled notifies user that admin moved the booking to another time
+func (s *NotificationService) SendBookingRescheduled(ctx context.Context, booking *database.Booking) error {
+	msg, err := s.renderBooking(ctx, TemplateBookingRescheduled, booking)
+	if err != nil {
+		return err
+	}
+
+	if err := s.sendToClient(ctx, TemplateBookingRescheduled, booking, msg, nil); err != nil {
+		return fmt.Errorf("failed to send reschedule notice: %w", err)
+	}
+
+	// Same event UID with a higher sequence replaces the appointment in the client's calendar
+	s.sendCalendarFile(ctx, booking)
+	return nil
+}
+
 // SendReminder sends reminder to user about upcoming booking
 func (s *NotificationService) SendReminder(ctx context.Context, booking *database.Booking) error {
-	msg := fmt.Sprintf(
-		"🔔 <b>Напоминание о записи</b>\n\n"+
-			"Завтра в <b>%s</b> у вас запись:\n"+
-			"📋 %s\n"+
-			"⏱ Длительность: %d мин\n"+
-			"💰 Стоимость: %d руб.\n\n"+
-			"Будем рады вас видеть! 🌟",
-		booking.Time,
-		booking.Service.Name,
-		booking.Service.Duration,
-		booking.Service.Price/100,
-	)
-
-	recipient := &tele.User{ID: booking.UserID}
-	_, err := s.bot.Send(recipient, msg, &tele.SendOptions{ParseMode: tele.ModeHTML})
+	msg, err := s.renderBooking(ctx, TemplateReminderDay, booking)
 	if err != nil {
+		return err
+	}
+
+	if err := s.sendToClient(ctx, TemplateReminderDay, booking, msg, nil); err != nil {
 		return fmt.Errorf("failed to send reminder: %w", err)
 	}
 
@@ -103,12 +136,13 @@ func (s *NotificationService) StartReminderWorker(ctx context.Context) {
 	ticker := time.NewTicker(1 * time.Hour) // Check every hour
 	defer ticker.Stop()
 
-	log.Println("Reminder worker started")
+	markReminderRun()
+	slog.InfoContext(ctx, "Reminder worker started")
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("Reminder worker stopped")
+			slog.InfoContext(ctx, "Reminder worker stopped")
 			return
 		case <-ticker.C:
 			s.checkAndSendReminders(ctx)
@@ -118,6 +152,7 @@ func (s *NotificationService) StartReminderWorker(ctx context.Context) {
 
 // checkAndSendReminders checks for bookings that need reminders
 func (s *NotificationService) checkAndSendReminders(ctx context.Context) {
+	defer markReminderRun()
 	now := time.Now()
 
 	// 1. Check for daily admin reminders (send at 8:00 AM)
@@ -130,6 +165,9 @@ func (s *NotificationService) checkAndSendReminders(ctx context.Context) {
 
 	// 3. Check for 1-hour reminders (today's bookings)
 	s.checkHourBeforeReminders(ctx)
+
+	// 4. Ask clients to rate their past visits
+	s.checkReviewRequests(ctx)
 }
 
 // sendDailyAdminReminder sends daily reminder to admins about today's bookings
@@ -151,7 +189,7 @@ func (s *NotificationService) sendDailyAdminReminder(ctx context.Context) {
 		Find(&bookings).Error
 
 	if err != nil {
-		log.Printf("Error fetching today's bookings: %v", err)
+		slog.ErrorContext(ctx, "Failed to fetch today's bookings", "error", err)
 		return
 	}
 
@@ -167,13 +205,13 @@ func (s *NotificationService) sendDailyAdminReminder(ctx context.Context) {
 			"%d. <b>%s</b>\n"+
 				"   👤 %s %s\n"+
 				"   📋 %s\n"+
-				"   💰 %d руб.\n\n",
+				"   💰 %s\n\n",
 			i+1,
 			booking.Time,
-			booking.User.FirstName,
-			booking.User.LastName,
-			booking.Service.Name,
-			booking.Service.Price/100,
+			html.EscapeString(booking.User.FirstName),
+			html.EscapeString(booking.User.LastName),
+			html.EscapeString(booking.Service.Name),
+			i18n.Price(i18n.Default, PaidPrice(&booking)),
 		)
 	}
 
@@ -181,20 +219,17 @@ func (s *NotificationService) sendDailyAdminReminder(ctx context.Context) {
 
 	// Send to all admins
 	for _, adminID := range s.adminIDs {
-		recipient := &tele.User{ID: adminID}
-		if _, err := s.bot.Send(recipient, msg, &tele.SendOptions{ParseMode: tele.ModeHTML}); err != nil {
-			log.Printf("Error sending daily reminder to admin %d: %v", adminID, err)
+		if err := s.NotifyAdmin(ctx, adminID, msg); err != nil {
+			slog.ErrorContext(ctx, "Failed to send daily reminder to admin", "admin_id", adminID, "error", err)
 		}
-		time.Sleep(100 * time.Millisecond)
 	}
 
 	// Mark as sent
 	for _, booking := range bookings {
-		booking.AdminDailyReminderSent = true
-		database.DB.WithContext(ctx).Save(&booking)
+		markBookingNotified(ctx, booking.ID, "admin_daily_reminder_sent")
 	}
 
-	log.Printf("Daily admin reminder sent for %d bookings", len(bookings))
+	slog.InfoContext(ctx, "Daily admin reminder sent", "bookings", len(bookings))
 }
 
 // checkDayBeforeReminders checks for bookings tomorrow and sends reminders
@@ -216,19 +251,17 @@ func (s *NotificationService) checkDayBeforeReminders(ctx context.Context) {
 		Find(&bookings).Error
 
 	if err != nil {
-		log.Printf("Error fetching bookings for reminders: %v", err)
+		slog.ErrorContext(ctx, "Failed to fetch bookings for reminders", "error", err)
 		return
 	}
 
 	for _, booking := range bookings {
 		if err := s.SendReminder(ctx, &booking); err != nil {
-			log.Printf("Error sending reminder for booking %d: %v", booking.ID, err)
+			slog.ErrorContext(ctx, "Failed to send reminder", "booking_id", booking.ID, "error", err)
 		} else {
-			booking.ReminderSent = true
-			database.DB.WithContext(ctx).Save(&booking)
-			log.Printf("Reminder sent for booking %d to user %d", booking.ID, booking.UserID)
+			markBookingNotified(ctx, booking.ID, "reminder_sent")
+			slog.InfoContext(ctx, "Reminder sent", "booking_id", booking.ID, "user_id", booking.UserID)
 		}
-		time.Sleep(100 * time.Millisecond)
 	}
 }
 
@@ -251,7 +284,7 @@ func (s *NotificationService) checkHourBeforeReminders(ctx context.Context) {
 		Find(&bookings).Error
 
 	if err != nil {
-		log.Printf("Error fetching bookings for hour reminders: %v", err)
+		slog.ErrorContext(ctx, "Failed to fetch bookings for hour reminders", "error", err)
 		return
 	}
 
@@ -272,37 +305,26 @@ func (s *NotificationService) checkHourBeforeReminders(ctx context.Context) {
 		if diff >= 55*time.Minute && diff <= 65*time.Minute {
 			// Send reminder to user
 			if err := s.SendHourReminder(ctx, &booking); err != nil {
-				log.Printf("Error sending hour reminder for booking %d: %v", booking.ID, err)
+				slog.ErrorContext(ctx, "Failed to send hour reminder", "booking_id", booking.ID, "error", err)
 			} else {
-				booking.HourReminderSent = true
-				database.DB.WithContext(ctx).Save(&booking)
-				log.Printf("Hour reminder sent for booking %d to user %d", booking.ID, booking.UserID)
+				markBookingNotified(ctx, booking.ID, "hour_reminder_sent")
+				slog.InfoContext(ctx, "Hour reminder sent", "booking_id", booking.ID, "user_id", booking.UserID)
 			}
 
 			// Send reminder to admins
 			s.sendHourReminderToAdmins(ctx, &booking)
-
-			time.Sleep(100 * time.Millisecond)
 		}
 	}
 }
 
 // SendHourReminder sends reminder to user 1 hour before booking
 func (s *NotificationService) SendHourReminder(ctx context.Context, booking *database.Booking) error {
-	msg := fmt.Sprintf(
-		"⏰ <b>Напоминание: запись через час!</b>\n\n"+
-			"📋 Услуга: <b>%s</b>\n"+
-			"⏰ Время: <b>%s</b>\n"+
-			"💰 Стоимость: %d руб.\n\n"+
-			"До встречи! 🌟",
-		booking.Service.Name,
-		booking.Time,
-		booking.Service.Price/100,
-	)
-
-	recipient := &tele.User{ID: booking.UserID}
-	_, err := s.bot.Send(recipient, msg, &tele.SendOptions{ParseMode: tele.ModeHTML})
+	msg, err := s.renderBooking(ctx, TemplateReminderHour, booking)
 	if err != nil {
+		return err
+	}
+
+	if err := s.sendToClient(ctx, TemplateReminderHour, booking, msg, nil); err != nil {
 		return fmt.Errorf("failed to send hour reminder: %w", err)
 	}
 
@@ -316,109 +338,338 @@ func (s *NotificationService) sendHourReminderToAdmins(ctx context.Context, book
 			"👤 Клиент: %s %s\n"+
 			"📋 Услуга: <b>%s</b>\n"+
 			"⏰ Время: <b>%s</b>\n"+
-			"💰 Стоимость: %d руб.",
-		booking.User.FirstName,
-		booking.User.LastName,
-		booking.Service.Name,
+			"💰 Стоимость: %s",
+		html.EscapeString(booking.User.FirstName),
+		html.EscapeString(booking.User.LastName),
+		html.EscapeString(booking.Service.Name),
 		booking.Time,
-		booking.Service.Price/100,
+		i18n.Price(i18n.Default, PaidPrice(booking)),
 	)
 
 	for _, adminID := range s.adminIDs {
 		recipient := &tele.User{ID: adminID}
-		if _, err := s.bot.Send(recipient, msg, &tele.SendOptions{ParseMode: tele.ModeHTML}); err != nil {
-			log.Printf("Error sending hour reminder to admin %d: %v", adminID, err)
+		_, err := s.outbox.Enqueue(ctx, recipient, msg, nil, booking.ID)
+		countNotification(NotificationAdminReminder, err)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to send hour reminder to admin", "admin_id", adminID, "error", err)
+		}
+	}
+}
+
+// checkReviewRequests asks clients for a rating after their visit was marked completed
+func (s *NotificationService) checkReviewRequests(ctx context.Context) {
+	now := time.Now()
+	// Only look at recent visits so enabling the feature doesn't spam old clients
+	since := now.AddDate(0, 0, -7)
+
+	var bookings []database.Booking
+	err := database.DB.WithContext(ctx).
+		Preload("Service").
+		Preload("User").
+		Where("date >= ? AND date <= ?", since, now).
+		Where("status = ?", database.BookingStatusCompleted).
+		Where("review_request_sent = ?", false).
+		Find(&bookings).Error
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to fetch bookings for review requests", "error", err)
+		return
+	}
+
+	for _, booking := range bookings {
+		bookingTime, err := time.Parse("15:04", booking.Time)
+		if err != nil {
+			continue
+		}
+
+		visitEnd := time.Date(
+			booking.Date.Year(), booking.Date.Month(), booking.Date.Day(),
+			bookingTime.Hour(), bookingTime.Minute(), 0, 0, now.Location(),
+		).Add(time.Duration(booking.Service.Duration) * time.Minute)
+
+		if now.Before(visitEnd.Add(s.reviewRequestDelay)) {
+			continue
+		}
+
+		if err := s.SendReviewRequest(ctx, &booking); err != nil {
+			slog.ErrorContext(ctx, "Failed to send review request", "booking_id", booking.ID, "error", err)
+		} else {
+			slog.InfoContext(ctx, "Review request sent", "booking_id", booking.ID, "user_id", booking.UserID)
+		}
+
+		// Mark as sent even on failure to avoid retrying forever, delivery is retried by the outbox
+		markBookingNotified(ctx, booking.ID, "review_request_sent")
+	}
+}
+
+// markBookingNotified sets a notification flag of a booking
+// Only the flag column is written, so a status changed while the notification was sent is kept
+func markBookingNotified(ctx context.Context, bookingID uint, column string) {
+	err := database.DB.WithContext(ctx).
+		Model(&database.Booking{}).
+		Where("id = ?", bookingID).
+		Update(column, true).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to mark booking notified", "booking_id", bookingID, "flag", column, "error", err)
+	}
+}
+
+// SendReviewRequest asks the client to rate a visit with 1-5 stars
+func (s *NotificationService) SendReviewRequest(ctx context.Context, booking *database.Booking) error {
+	msg, err := s.renderBooking(ctx, TemplateReviewRequest, booking)
+	if err != nil {
+		return err
+	}
+
+	markup := &tele.ReplyMarkup{}
+	row := tele.Row{}
+	for rating := 1; rating <= 5; rating++ {
+		row = append(row, callback.Button(
+			fmt.Sprintf("%d⭐", rating),
+			"review_rate",
+			fmt.Sprintf("%d", booking.ID),
+			fmt.Sprintf("%d", rating),
+		))
+	}
+	markup.Inline(row)
+
+	if err := s.sendToClient(ctx, TemplateReviewRequest, booking, msg, markup); err != nil {
+		return fmt.Errorf("failed to send review request: %w", err)
+	}
+
+	return nil
+}
+
+// NotifyAdminsLowRating alerts admins about a low rating
+func (s *NotificationService) NotifyAdminsLowRating(ctx context.Context, review *database.Review) {
+	msg := fmt.Sprintf(
+		"⚠️ <b>Низкая оценка: %s</b>\n\n"+
+			"👤 %s %s (@%s)\n"+
+			"📋 %s\n"+
+			"📆 %s в %s\n",
+		FormatStars(review.Rating),
+		html.EscapeString(review.User.FirstName),
+		html.EscapeString(review.User.LastName),
+		html.EscapeString(review.User.Username),
+		html.EscapeString(review.Service.Name),
+		review.Booking.Date.Format("02.01.2006"),
+		review.Booking.Time,
+	)
+
+	if review.Comment != "" {
+		msg += fmt.Sprintf("\n💬 %s", html.EscapeString(review.Comment))
+	}
+
+	for _, adminID := range s.adminIDs {
+		if err := s.NotifyAdmin(ctx, adminID, msg); err != nil {
+			slog.ErrorContext(ctx, "Failed to send low rating alert to admin", "admin_id", adminID, "error", err)
+		}
+	}
+}
+
+// SendReviewToChannel publishes a review to the configured channel
+func (s *NotificationService) SendReviewToChannel(ctx context.Context, review *database.Review) error {
+	if s.channelID == "" {
+		return fmt.Errorf("channel ID not configured")
+	}
+
+	msg := fmt.Sprintf(
+		"💬 <b>Отзыв клиента</b>\n\n"+
+			"%s\n"+
+			"📋 %s\n",
+		FormatStars(review.Rating),
+		html.EscapeString(review.Service.Name),
+	)
+
+	if review.Comment != "" {
+		msg += fmt.Sprintf("\n«%s»\n", html.EscapeString(review.Comment))
+	}
+
+	msg += fmt.Sprintf("\n— %s\n\nЗаписывайтесь через бота! 👇", html.EscapeString(review.User.FirstName))
+
+	recipient, err := s.channelRecipient()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.outbox.Enqueue(ctx, recipient, msg, nil, 0)
+	countNotification(NotificationReview, err)
+	if err != nil {
+		return fmt.Errorf("failed to send review to channel: %w", err)
+	}
+
+	return nil
+}
+
+// FormatStars renders a rating as a string of stars
+func FormatStars(rating int) string {
+	stars := ""
+	for i := 1; i <= 5; i++ {
+		if i <= rating {
+			stars += "⭐"
+		} else {
+			stars += "☆"
 		}
-		time.Sleep(100 * time.Millisecond)
+	}
+	return stars
+}
+
+// renderBooking renders a booking template in the language of the booking's client
+func (s *NotificationService) renderBooking(ctx context.Context, key string, booking *database.Booking) (string, error) {
+	lang := s.clientLanguage(ctx, booking)
+	return s.templates.Render(ctx, key, lang, BookingTemplateData(lang, booking))
+}
+
+// clientLanguage returns the language of the booking's client
+// Loads the user when the booking was fetched without it
+func (s *NotificationService) clientLanguage(ctx context.Context, booking *database.Booking) string {
+	if booking.User.ID == 0 {
+		if err := database.DB.WithContext(ctx).First(&booking.User, booking.UserID).Error; err != nil {
+			return i18n.Default
+		}
+	}
+	return UserLanguage(&booking.User)
+}
+
+// sendToClient queues a message about a booking to its client, kind labels it in metrics
+// Offline clients have no Telegram chat, so nothing is sent to them
+func (s *NotificationService) sendToClient(ctx context.Context, kind string, booking *database.Booking, msg string, markup *tele.ReplyMarkup) error {
+	if IsOfflineUserID(booking.UserID) {
+		return nil
+	}
+
+	_, err := s.outbox.Enqueue(ctx, &tele.User{ID: booking.UserID}, msg, markup, booking.ID)
+	countNotification(kind, err)
+	return err
+}
+
+// sendCalendarFile queues an .ics file of the booking to its client
+// The booking notice is already sent, so errors are only logged
+func (s *NotificationService) sendCalendarFile(ctx context.Context, booking *database.Booking) {
+	if IsOfflineUserID(booking.UserID) {
+		return
+	}
+
+	lang := s.clientLanguage(ctx, booking)
+	data, err := s.calendar.BookingICS(ctx, lang, booking)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to build calendar file", "booking_id", booking.ID, "error", err)
+		return
+	}
+
+	fileName := fmt.Sprintf("booking-%d.ics", booking.ID)
+	_, err = s.outbox.EnqueueDocument(ctx, &tele.User{ID: booking.UserID}, i18n.T(lang, "calendar.caption"), fileName, CalendarMIME, data, booking.ID)
+	countNotification(NotificationCalendarFile, err)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to send calendar file", "booking_id", booking.ID, "error", err)
 	}
 }
 
 // NotifyAdmin sends notification to admin
 func (s *NotificationService) NotifyAdmin(ctx context.Context, adminID int64, message string) error {
 	recipient := &tele.User{ID: adminID}
-	_, err := s.bot.Send(recipient, message, &tele.SendOptions{ParseMode: tele.ModeHTML})
+	_, err := s.outbox.Enqueue(ctx, recipient, message, nil, 0)
+	countNotification(NotificationAdmin, err)
 	if err != nil {
 		return fmt.Errorf("failed to notify admin: %w", err)
 	}
 	return nil
 }
 
+// NotifyAdmins sends a notification to every admin
+// Failures are only logged, the change the admins are told about is already saved
+func (s *NotificationService) NotifyAdmins(ctx context.Context, message string) {
+	for _, adminID := range s.adminIDs {
+		if err := s.NotifyAdmin(ctx, adminID, message); err != nil {
+			slog.ErrorContext(ctx, "Failed to notify admin", "admin_id", adminID, "error", err)
+		}
+	}
+}
+
 // NotifyAdminWithActions sends notification to admin with approve/reject buttons
-func (s *NotificationService) NotifyAdminWithActions(ctx context.Context, adminID int64, message string, bookingID uint) error {
+func (s *NotificationService) NotifyAdminWithActions(ctx context.Context, adminID int64, message string, bookingID uint, userID int64) error {
 	recipient := &tele.User{ID: adminID}
 
 	// Create keyboard with approve/reject buttons
 	markup := &tele.ReplyMarkup{}
-	btnApprove := markup.Data("✅ Подтвердить", "admin_approve_booking", fmt.Sprintf("%d", bookingID))
-	btnReject := markup.Data("❌ Отменить", "admin_reject_booking", fmt.Sprintf("%d", bookingID))
+	btnApprove := callback.Button("✅ Подтвердить", "admin_approve_booking", fmt.Sprintf("%d", bookingID))
+	btnReject := callback.Button("❌ Отменить", "admin_reject_booking", fmt.Sprintf("%d", bookingID))
+	btnClient := callback.Button("👤 Карточка клиента", "admin_client", fmt.Sprintf("%d", userID))
 	markup.Inline(
 		markup.Row(btnApprove, btnReject),
+		markup.Row(btnClient),
 	)
 
-	_, err := s.bot.Send(recipient, message, &tele.SendOptions{
-		ParseMode:   tele.ModeHTML,
-		ReplyMarkup: markup,
-	})
+	_, err := s.outbox.Enqueue(ctx, recipient, message, markup, bookingID)
+	countNotification(NotificationAdminBooking, err)
 	if err != nil {
 		return fmt.Errorf("failed to notify admin with actions: %w", err)
 	}
 	return nil
 }
 
+// NotifyAdminsNewBooking sends a new booking to every admin with approve/reject buttons
+// certificateLine tells admins how much of the price a gift certificate paid
+func (s *NotificationService) NotifyAdminsNewBooking(ctx context.Context, booking *database.Booking, certificateLine string) {
+	data := BookingTemplateData(i18n.Default, booking)
+	data.Certificate = certificateLine
+	msg, err := s.templates.Render(ctx, TemplateAdminNewBooking, i18n.Default, data)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to render new booking notification", "booking_id", booking.ID, "error", err)
+		return
+	}
+
+	for _, adminID := range s.adminIDs {
+		if err := s.NotifyAdminWithActions(ctx, adminID, msg, booking.ID, booking.UserID); err != nil {
+			slog.ErrorContext(ctx, "Failed to notify admin about booking", "admin_id", adminID, "booking_id", booking.ID, "error", err)
+		}
+	}
+}
+
 // SendPromotionToChannel sends promotion message to configured channel
 // This function can be called when creating a discount to automatically post to channel
 // Usage: notificationService.SendPromotionToChannel(ctx, discount)
 // Note: Requires CHANNEL_ID in .env file (format: @channelname or -1001234567890)
 func (s *NotificationService) SendPromotionToChannel(ctx context.Context, discount *database.Discount) error {
 	if s.channelID == "" {
-		log.Println("Channel ID not configured, skipping promotion")
+		slog.InfoContext(ctx, "Channel ID not configured, skipping promotion")
 		return nil
 	}
 
-	originalPrice := discount.Service.Price / 100
-	discountAmount := (discount.Service.Price * discount.Percentage) / 10000
-	newPrice := originalPrice - discountAmount
-
-	msg := fmt.Sprintf(
-		"🎉 <b>%s</b>\n\n"+
-			"📋 Услуга: <b>%s</b>\n"+
-			"💰 Скидка: <b>%d%%</b>\n"+
-			"💵 Цена: <s>%d руб.</s> <b>%d руб.</b>\n"+
-			"📅 Действует: %s - %s\n\n"+
-			"Записывайтесь через бота! 👇",
-		discount.Name,
-		discount.Service.Name,
-		discount.Percentage,
-		originalPrice,
-		newPrice,
-		discount.StartDate.Format("02.01.2006"),
-		discount.EndDate.Format("02.01.2006"),
-	)
+	msg, err := s.templates.Render(ctx, TemplatePromotion, i18n.Default, PromotionTemplateData(i18n.Default, discount))
+	if err != nil {
+		return err
+	}
 
-	// Try to parse channel ID (can be @channelname or -1001234567890)
-	var recipient tele.Recipient
-	if len(s.channelID) > 0 && s.channelID[0] == '@' {
-		recipient = &tele.Chat{Username: s.channelID[1:]}
-	} else {
-		// For numeric channel IDs, use ChatID directly
-		// Note: This requires the bot to be added to the channel as admin
-		// Format: -1001234567890 (negative number for channels)
-		chatID, err := parseChannelID(s.channelID)
-		if err != nil {
-			return fmt.Errorf("invalid channel ID format: %w", err)
-		}
-		recipient = &tele.Chat{ID: chatID}
+	recipient, err := s.channelRecipient()
+	if err != nil {
+		return err
 	}
 
-	_, err := s.bot.Send(recipient, msg, &tele.SendOptions{ParseMode: tele.ModeHTML})
+	_, err = s.outbox.Enqueue(ctx, recipient, msg, nil, 0)
+	countNotification(NotificationPromotion, err)
 	if err != nil {
 		return fmt.Errorf("failed to send promotion to channel: %w", err)
 	}
 
-	log.Printf("Promotion sent to channel: %s", s.channelID)
+	slog.InfoContext(ctx, "Promotion sent to channel", "channel_id", s.channelID)
 	return nil
 }
 
+// channelRecipient resolves the configured channel ID to a recipient
+// The outbox stores it as is, Telegram accepts both @channelname and -1001234567890 as a chat ID
+func (s *NotificationService) channelRecipient() (tele.Recipient, error) {
+	if strings.HasPrefix(s.channelID, "@") {
+		return chatRecipient(s.channelID), nil
+	}
+
+	// Numeric IDs require the bot to be added to the channel as admin
+	if _, err := parseChannelID(s.channelID); err != nil {
+		return nil, fmt.Errorf("invalid channel ID format: %w", err)
+	}
+	return chatRecipient(s.channelID), nil
+}
+
 // parseChannelID parses channel ID string to int64
 func parseChannelID(channelID string) (int64, error) {
 	// Remove @ if present
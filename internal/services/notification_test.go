@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"gobot/internal/database"
+)
+
+func TestChannelRecipient(t *testing.T) {
+	tests := []struct {
+		channelID string
+		want      string
+		wantErr   bool
+	}{
+		{channelID: "@salon_news", want: "@salon_news"},
+		{channelID: "-1001234567890", want: "-1001234567890"},
+		{channelID: "salon_news", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.channelID, func(t *testing.T) {
+			s := &NotificationService{channelID: tt.channelID}
+			recipient, err := s.channelRecipient()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("channelRecipient() = %q, want error", recipient.Recipient())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("channelRecipient() error = %v", err)
+			}
+			if got := recipient.Recipient(); got != tt.want {
+				t.Errorf("Recipient() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkBookingNotifiedKeepsStatus(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+	booking := createTestBooking(t, 1001, 250000)
+
+	// The client cancels while the reminder worker holds the pending booking
+	if err := TransitionBooking(ctx, booking.ID, database.BookingStatusCancelled, ByClient(1001)); err != nil {
+		t.Fatalf("TransitionBooking() error = %v", err)
+	}
+	markBookingNotified(ctx, booking.ID, "reminder_sent")
+
+	var got database.Booking
+	if err := database.DB.First(&got, booking.ID).Error; err != nil {
+		t.Fatalf("failed to get booking: %v", err)
+	}
+	if got.Status != database.BookingStatusCancelled {
+		t.Errorf("status = %s, want cancelled", got.Status)
+	}
+	if !got.ReminderSent {
+		t.Error("reminder_sent is not set")
+	}
+}
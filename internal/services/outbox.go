@@ -0,0 +1,404 @@
+// Package services contains the outgoing message queue
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gobot/internal/database"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	// outboxRate keeps sending below Telegram's limit of ~30 messages per second
+	outboxRate = 25
+	// outboxChatInterval is the minimal pause between messages to the same chat
+	outboxChatInterval = time.Second
+	// outboxMaxAttempts is the number of tries before a message is marked as failed
+	outboxMaxAttempts = 5
+	// outboxBaseBackoff is the delay before the first retry, doubled on each attempt
+	outboxBaseBackoff = 10 * time.Second
+	// outboxPollInterval is how often the worker checks for due messages
+	outboxPollInterval = 5 * time.Second
+	// outboxBatchSize is the number of messages loaded per worker pass
+	outboxBatchSize = 50
+)
+
+// OutboxStats holds the number of queued messages per status
+type OutboxStats struct {
+	Pending int64
+	Sent    int64
+	Failed  int64
+	Blocked int64
+}
+
+// OutboxService is the central sender for outgoing messages
+// Messages are persisted first and delivered by a background worker,
+// so they survive restarts and temporary Telegram failures
+type OutboxService struct {
+	bot  *tele.Bot
+	wake chan struct{}
+
+	mu          sync.Mutex
+	nextSend    time.Time
+	chatNext    map[string]time.Time
+	pausedUntil time.Time
+}
+
+// NewOutboxService creates a new outbox service
+func NewOutboxService(bot *tele.Bot) *OutboxService {
+	return &OutboxService{
+		bot:      bot,
+		wake:     make(chan struct{}, 1),
+		chatNext: make(map[string]time.Time),
+	}
+}
+
+// chatRecipient addresses a chat by its stored ID or @username
+type chatRecipient string
+
+// Recipient implements tele.Recipient
+func (r chatRecipient) Recipient() string {
+	return string(r)
+}
+
+// Enqueue stores an HTML message for delivery and wakes up the worker
+// bookingID links the message to a booking, pass 0 if there is none
+func (s *OutboxService) Enqueue(ctx context.Context, to tele.Recipient, text string, markup *tele.ReplyMarkup, bookingID uint) (*database.OutboxMessage, error) {
+	msg := &database.OutboxMessage{
+		ChatID:        to.Recipient(),
+		BookingID:     bookingID,
+		Text:          text,
+		ParseMode:     string(tele.ModeHTML),
+		Status:        database.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+
+	if markup != nil {
+		data, err := json.Marshal(markup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode markup: %w", err)
+		}
+		msg.ReplyMarkup = string(data)
+	}
+
+	if err := database.DB.WithContext(ctx).Create(msg).Error; err != nil {
+		return nil, fmt.Errorf("failed to enqueue message: %w", err)
+	}
+
+	s.notify()
+	return msg, nil
+}
+
+// EnqueueDocument stores a document with an HTML caption for delivery
+func (s *OutboxService) EnqueueDocument(ctx context.Context, to tele.Recipient, caption, fileName, mime string, data []byte, bookingID uint) (*database.OutboxMessage, error) {
+	msg := &database.OutboxMessage{
+		ChatID:        to.Recipient(),
+		BookingID:     bookingID,
+		Text:          caption,
+		ParseMode:     string(tele.ModeHTML),
+		FileName:      fileName,
+		FileMIME:      mime,
+		FileData:      data,
+		Status:        database.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+
+	if err := database.DB.WithContext(ctx).Create(msg).Error; err != nil {
+		return nil, fmt.Errorf("failed to enqueue document: %w", err)
+	}
+
+	s.notify()
+	return msg, nil
+}
+
+// GetMessage returns a queued message with its delivery status
+func (s *OutboxService) GetMessage(ctx context.Context, id uint) (*database.OutboxMessage, error) {
+	var msg database.OutboxMessage
+	if err := database.DB.WithContext(ctx).First(&msg, id).Error; err != nil {
+		return nil, fmt.Errorf("message not found: %w", err)
+	}
+	return &msg, nil
+}
+
+// ListByBooking returns messages sent about a booking, newest first
+func (s *OutboxService) ListByBooking(ctx context.Context, bookingID uint) ([]database.OutboxMessage, error) {
+	var messages []database.OutboxMessage
+	err := database.DB.WithContext(ctx).
+		Where("booking_id = ?", bookingID).
+		Order("created_at DESC").
+		Find(&messages).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+	return messages, nil
+}
+
+// GetStats counts queued messages by delivery status
+func (s *OutboxService) GetStats(ctx context.Context) (*OutboxStats, error) {
+	var rows []struct {
+		Status database.OutboxStatus
+		Count  int64
+	}
+	err := database.DB.WithContext(ctx).
+		Model(&database.OutboxMessage{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbox stats: %w", err)
+	}
+
+	stats := &OutboxStats{}
+	for _, row := range rows {
+		switch row.Status {
+		case database.OutboxStatusPending:
+			stats.Pending = row.Count
+		case database.OutboxStatusSent:
+			stats.Sent = row.Count
+		case database.OutboxStatusFailed:
+			stats.Failed = row.Count
+		case database.OutboxStatusBlocked:
+			stats.Blocked = row.Count
+		}
+	}
+	return stats, nil
+}
+
+// StartWorker delivers queued messages until the context is cancelled
+func (s *OutboxService) StartWorker(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	slog.InfoContext(ctx, "Outbox worker started")
+
+	for {
+		s.processPending(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "Outbox worker stopped")
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// notify wakes up the worker without blocking
+func (s *OutboxService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// processPending sends all messages that are due
+func (s *OutboxService) processPending(ctx context.Context) {
+	var messages []database.OutboxMessage
+	err := database.DB.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", database.OutboxStatusPending, time.Now()).
+		Order("next_attempt_at, id").
+		Limit(outboxBatchSize).
+		Find(&messages).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to fetch outbox messages", "error", err)
+		return
+	}
+
+	deferred := false
+	for i := range messages {
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Leave the message for the next pass instead of stalling the whole queue
+		if !s.chatReady(messages[i].ChatID) {
+			deferred = true
+			continue
+		}
+
+		s.deliver(ctx, &messages[i])
+	}
+
+	// Come back soon for deferred messages and for the rest of a full batch
+	if deferred || len(messages) == outboxBatchSize {
+		time.AfterFunc(outboxChatInterval, s.notify)
+	}
+}
+
+// deliver sends a single queued message and records the outcome
+func (s *OutboxService) deliver(ctx context.Context, msg *database.OutboxMessage) {
+	opts := &tele.SendOptions{ParseMode: tele.ParseMode(msg.ParseMode)}
+	if msg.ReplyMarkup != "" {
+		markup := &tele.ReplyMarkup{}
+		if err := json.Unmarshal([]byte(msg.ReplyMarkup), markup); err != nil {
+			slog.ErrorContext(ctx, "Failed to decode markup of outbox message", "message_id", msg.ID, "error", err)
+		} else {
+			opts.ReplyMarkup = markup
+		}
+	}
+
+	var what interface{} = msg.Text
+	if msg.FileName != "" {
+		what = &tele.Document{
+			File:     tele.FromReader(bytes.NewReader(msg.FileData)),
+			FileName: msg.FileName,
+			MIME:     msg.FileMIME,
+			Caption:  msg.Text,
+		}
+	}
+
+	sent, err := s.Send(ctx, chatRecipient(msg.ChatID), what, opts)
+	if err != nil && ctx.Err() != nil {
+		// Shutting down, the message stays pending
+		return
+	}
+	now := time.Now()
+
+	var floodErr tele.FloodError
+	switch {
+	case err == nil:
+		msg.Status = database.OutboxStatusSent
+		msg.Attempts++
+		msg.SentAt = &now
+		msg.MessageID = sent.ID
+		msg.LastError = ""
+		outboxDeliveriesTotal.Inc("sent")
+
+	case errors.As(err, &floodErr):
+		// Rate limit is not the message's fault, retry without spending an attempt
+		msg.NextAttemptAt = now.Add(time.Duration(floodErr.RetryAfter) * time.Second)
+		msg.LastError = err.Error()
+		outboxDeliveriesTotal.Inc("retry")
+
+	case IsBlockedError(err):
+		msg.Status = database.OutboxStatusBlocked
+		msg.Attempts++
+		msg.LastError = err.Error()
+		outboxDeliveriesTotal.Inc("blocked")
+		if userID, parseErr := strconv.ParseInt(msg.ChatID, 10, 64); parseErr == nil && userID > 0 {
+			if markErr := MarkUserBlockedBot(ctx, userID); markErr != nil {
+				slog.ErrorContext(ctx, "Failed to mark user as blocked", "user_id", userID, "error", markErr)
+			}
+		}
+
+	default:
+		msg.Attempts++
+		msg.LastError = err.Error()
+		if isPermanentSendError(err) || msg.Attempts >= outboxMaxAttempts {
+			msg.Status = database.OutboxStatusFailed
+			outboxDeliveriesTotal.Inc("failed")
+			slog.WarnContext(ctx, "Outbox message failed", "message_id", msg.ID, "chat_id", msg.ChatID, "attempts", msg.Attempts, "error", err)
+		} else {
+			msg.NextAttemptAt = now.Add(outboxBaseBackoff << (msg.Attempts - 1))
+			outboxDeliveriesTotal.Inc("retry")
+		}
+	}
+
+	if err := database.DB.WithContext(ctx).Save(msg).Error; err != nil {
+		slog.ErrorContext(ctx, "Failed to save outbox message", "message_id", msg.ID, "error", err)
+	}
+}
+
+// Send delivers a message immediately while respecting the shared rate limits
+// A 429 response pauses all sending for retry_after and the message is retried once
+func (s *OutboxService) Send(ctx context.Context, to tele.Recipient, what interface{}, opts *tele.SendOptions) (*tele.Message, error) {
+	if err := s.waitTurn(ctx, to.Recipient()); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.bot.Send(to, what, opts)
+
+	var floodErr tele.FloodError
+	if errors.As(err, &floodErr) {
+		s.pause(time.Duration(floodErr.RetryAfter) * time.Second)
+		if err := s.waitTurn(ctx, to.Recipient()); err != nil {
+			return nil, err
+		}
+		msg, err = s.bot.Send(to, what, opts)
+	}
+
+	return msg, err
+}
+
+// chatReady reports whether a message to the chat can be sent right now
+func (s *OutboxService) chatReady(chatID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !time.Now().Before(s.chatNext[chatID])
+}
+
+// waitTurn blocks until both the global and the per-chat limits allow sending
+func (s *OutboxService) waitTurn(ctx context.Context, chatID string) error {
+	s.mu.Lock()
+	now := time.Now()
+	slot := now
+	if s.nextSend.After(slot) {
+		slot = s.nextSend
+	}
+	if s.pausedUntil.After(slot) {
+		slot = s.pausedUntil
+	}
+	if next := s.chatNext[chatID]; next.After(slot) {
+		slot = next
+	}
+	s.nextSend = slot.Add(time.Second / outboxRate)
+	s.chatNext[chatID] = slot.Add(outboxChatInterval)
+	s.cleanupChats(now)
+	s.mu.Unlock()
+
+	if wait := time.Until(slot); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
+
+// pause stops all sending for the given duration
+func (s *OutboxService) pause(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if until := time.Now().Add(d); until.After(s.pausedUntil) {
+		s.pausedUntil = until
+	}
+	slog.Warn("Telegram rate limit hit, pausing sending", "pause", d.String())
+}
+
+// cleanupChats forgets chats whose per-chat limit has expired
+// Must be called with the mutex held
+func (s *OutboxService) cleanupChats(now time.Time) {
+	if len(s.chatNext) < 1000 {
+		return
+	}
+	for chatID, next := range s.chatNext {
+		if next.Before(now) {
+			delete(s.chatNext, chatID)
+		}
+	}
+}
+
+// isPermanentSendError reports whether retrying the send can't help,
+// e.g. a malformed message or a chat that doesn't exist
+func isPermanentSendError(err error) bool {
+	var apiErr *tele.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == 400 || apiErr.Code == 403
+	}
+	// Unknown API errors are returned as plain errors with the code in the text
+	return strings.Contains(err.Error(), "(400)") || strings.Contains(err.Error(), "(403)")
+}
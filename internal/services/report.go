@@ -0,0 +1,261 @@
+// Package services contains business reports for admins
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gobot/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// Report period kinds
+const (
+	ReportPeriodDay    = "day"
+	ReportPeriodWeek   = "week"
+	ReportPeriodMonth  = "month"
+	ReportPeriodCustom = "custom"
+)
+
+// ReportPeriod is a date range [From, To) of a report
+type ReportPeriod struct {
+	Kind string
+	From time.Time
+	To   time.Time
+}
+
+// NewReportPeriod returns the current day, week or month containing now
+func NewReportPeriod(kind string, now time.Time) ReportPeriod {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch kind {
+	case ReportPeriodWeek:
+		// Weeks start on Monday
+		offset := (int(today.Weekday()) + 6) % 7
+		from := today.AddDate(0, 0, -offset)
+		return ReportPeriod{Kind: kind, From: from, To: from.AddDate(0, 0, 7)}
+	case ReportPeriodMonth:
+		from := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+		return ReportPeriod{Kind: kind, From: from, To: from.AddDate(0, 1, 0)}
+	default:
+		return ReportPeriod{Kind: ReportPeriodDay, From: today, To: today.AddDate(0, 0, 1)}
+	}
+}
+
+// Previous returns the period of the same kind and length right before this one
+func (p ReportPeriod) Previous() ReportPeriod {
+	if p.Kind == ReportPeriodMonth {
+		return ReportPeriod{Kind: p.Kind, From: p.From.AddDate(0, -1, 0), To: p.From}
+	}
+	days := p.Days()
+	return ReportPeriod{Kind: p.Kind, From: p.From.AddDate(0, 0, -days), To: p.From}
+}
+
+// Days returns the number of days in the period
+func (p ReportPeriod) Days() int {
+	days := 0
+	for day := p.From; day.Before(p.To); day = day.AddDate(0, 0, 1) {
+		days++
+	}
+	return days
+}
+
+// ServiceReport holds numbers of a single service
+type ServiceReport struct {
+	ServiceID uint
+	Name      string
+	Bookings  int64 // Bookings that weren't cancelled
+	Completed int64
+	Revenue   int // Kopecks
+}
+
+// Report holds business numbers for a period
+type Report struct {
+	Period ReportPeriod
+
+	Bookings  int64 // All bookings with a visit in the period
+	Completed int64
+	Cancelled int64 // Cancelled and rejected
+	NoShow    int64
+	Upcoming  int64 // Pending and confirmed
+
+	Revenue      int // Kopecks paid for completed visits
+	AverageCheck int // Kopecks per completed visit
+
+	CancellationRate float64 // Percent of all bookings
+	NoShowRate       float64 // Percent of visits that should have happened
+
+	BookedMinutes  int
+	WorkingMinutes int
+	Utilization    float64 // Percent of working time taken by bookings
+
+	NewClients       int64 // First visit in the period
+	ReturningClients int64 // Visited before the period
+
+	Services []ServiceReport // Sorted by number of bookings
+}
+
+// ReportService builds business reports
+type ReportService struct{}
+
+// NewReportService creates a new report service instance
+func NewReportService() *ReportService {
+	return &ReportService{}
+}
+
+// bookingPrice is the SQL expression of the price paid for a booking
+// Bookings created before prices were stored fall back to the service price
+const bookingPrice = "COALESCE(NULLIF(bookings.price, 0), services.price)"
+
+// Build calculates the report for the period
+func (s *ReportService) Build(ctx context.Context, period ReportPeriod) (*Report, error) {
+	report := &Report{Period: period}
+	db := database.DB.WithContext(ctx)
+
+	inPeriod := func() *gorm.DB {
+		return db.Model(&database.Booking{}).
+			Joins("JOIN services ON services.id = bookings.service_id").
+			Where("bookings.date >= ? AND bookings.date < ?", period.From, period.To)
+	}
+
+	// Bookings by status
+	var statusRows []struct {
+		Status  database.BookingStatus
+		Count   int64
+		Revenue int
+		Minutes int
+	}
+	err := inPeriod().
+		Select("bookings.status AS status, COUNT(*) AS count, SUM(" + bookingPrice + ") AS revenue, SUM(services.duration) AS minutes").
+		Group("bookings.status").
+		Scan(&statusRows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count bookings: %w", err)
+	}
+
+	for _, row := range statusRows {
+		report.Bookings += row.Count
+		switch row.Status {
+		case database.BookingStatusCompleted:
+			report.Completed = row.Count
+			report.Revenue = row.Revenue
+			report.BookedMinutes += row.Minutes
+		case database.BookingStatusCancelled, database.BookingStatusRejected:
+			report.Cancelled += row.Count
+		case database.BookingStatusNoShow:
+			report.NoShow = row.Count
+			report.BookedMinutes += row.Minutes
+		case database.BookingStatusPending, database.BookingStatusConfirmed:
+			report.Upcoming += row.Count
+			report.BookedMinutes += row.Minutes
+		}
+	}
+
+	if report.Completed > 0 {
+		report.AverageCheck = report.Revenue / int(report.Completed)
+	}
+	report.CancellationRate = percent(report.Cancelled, report.Bookings)
+	report.NoShowRate = percent(report.NoShow, report.Completed+report.NoShow)
+
+	// Bookings per service
+	err = inPeriod().
+		Select("services.id AS service_id, services.name AS name, "+
+			"SUM(CASE WHEN bookings.status NOT IN ? THEN 1 ELSE 0 END) AS bookings, "+
+			"SUM(CASE WHEN bookings.status = ? THEN 1 ELSE 0 END) AS completed, "+
+			"SUM(CASE WHEN bookings.status = ? THEN "+bookingPrice+" ELSE 0 END) AS revenue",
+			[]database.BookingStatus{database.BookingStatusCancelled, database.BookingStatusRejected},
+			database.BookingStatusCompleted, database.BookingStatusCompleted).
+		Group("services.id, services.name").
+		Having("bookings > 0").
+		Order("bookings DESC, revenue DESC").
+		Scan(&report.Services).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count bookings per service: %w", err)
+	}
+
+	// Clients who came in the period, returning ones had a visit before it
+	visited := []database.BookingStatus{database.BookingStatusConfirmed, database.BookingStatusCompleted}
+	var clients int64
+	err = inPeriod().
+		Where("bookings.status IN ?", visited).
+		Distinct("bookings.user_id").
+		Count(&clients).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clients: %w", err)
+	}
+	err = inPeriod().
+		Where("bookings.status IN ?", visited).
+		Where("bookings.user_id IN (?)", db.Model(&database.Booking{}).
+			Select("user_id").
+			Where("date < ? AND status = ?", period.From, database.BookingStatusCompleted)).
+		Distinct("bookings.user_id").
+		Count(&report.ReturningClients).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count returning clients: %w", err)
+	}
+	report.NewClients = clients - report.ReturningClients
+
+	report.WorkingMinutes, err = workingMinutes(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+	report.Utilization = percent(int64(report.BookedMinutes), int64(report.WorkingMinutes))
+
+	return report, nil
+}
+
+// workingMinutes returns the working time in the period
+// Uses the work schedule when it's configured, otherwise the default booking slots every day
+func workingMinutes(ctx context.Context, period ReportPeriod) (int, error) {
+	var schedule []database.WorkSchedule
+	if err := database.DB.WithContext(ctx).Where("is_active = ?", true).Find(&schedule).Error; err != nil {
+		return 0, fmt.Errorf("failed to get work schedule: %w", err)
+	}
+
+	var blocked []database.BlockedDate
+	err := database.DB.WithContext(ctx).
+		Where("date >= ? AND date < ?", period.From, period.To).
+		Find(&blocked).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get blocked dates: %w", err)
+	}
+	blockedDays := make(map[string]bool, len(blocked))
+	for _, date := range blocked {
+		blockedDays[date.Date.Format("2006-01-02")] = true
+	}
+
+	// Minutes per weekday
+	var perWeekday [7]int
+	if len(schedule) == 0 {
+		for i := range perWeekday {
+			perWeekday[i] = len(DefaultTimeSlots) * 60
+		}
+	}
+	for _, day := range schedule {
+		start, err1 := time.Parse("15:04", day.StartTime)
+		end, err2 := time.Parse("15:04", day.EndTime)
+		if err1 != nil || err2 != nil || !end.After(start) || day.DayOfWeek < 0 || day.DayOfWeek > 6 {
+			continue
+		}
+		perWeekday[day.DayOfWeek] += int(end.Sub(start).Minutes())
+	}
+
+	minutes := 0
+	for day := period.From; day.Before(period.To); day = day.AddDate(0, 0, 1) {
+		if blockedDays[day.Format("2006-01-02")] {
+			continue
+		}
+		minutes += perWeekday[day.Weekday()]
+	}
+	return minutes, nil
+}
+
+// percent returns part of total in percent, 0 when total is 0
+func percent(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
@@ -0,0 +1,172 @@
+// Package services contains review and rating logic
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gobot/internal/database"
+)
+
+// LowRatingThreshold is the highest rating that triggers an admin alert
+const LowRatingThreshold = 3
+
+// Review errors
+var (
+	ErrReviewNotAllowed  = errors.New("booking cannot be reviewed by this user")
+	ErrReviewInvalidRate = errors.New("rating must be between 1 and 5")
+)
+
+// ServiceRating holds aggregated rating of a service
+type ServiceRating struct {
+	Average float64
+	Count   int64
+}
+
+// ReviewService handles reviews and ratings
+type ReviewService struct{}
+
+// NewReviewService creates a new review service instance
+func NewReviewService() *ReviewService {
+	return &ReviewService{}
+}
+
+// RateBooking creates or updates the review for a booking
+func (s *ReviewService) RateBooking(ctx context.Context, bookingID uint, userID int64, rating int) (*database.Review, error) {
+	if rating < 1 || rating > 5 {
+		return nil, ErrReviewInvalidRate
+	}
+
+	var booking database.Booking
+	if err := database.DB.WithContext(ctx).First(&booking, bookingID).Error; err != nil {
+		return nil, fmt.Errorf("booking not found: %w", err)
+	}
+
+	if booking.UserID != userID {
+		return nil, ErrReviewNotAllowed
+	}
+
+	var review database.Review
+	err := database.DB.WithContext(ctx).Where("booking_id = ?", bookingID).First(&review).Error
+	if err != nil {
+		review = database.Review{
+			BookingID: bookingID,
+			UserID:    userID,
+			ServiceID: booking.ServiceID,
+			Status:    database.ReviewStatusNew,
+		}
+	}
+
+	review.Rating = rating
+	if err := database.DB.WithContext(ctx).Save(&review).Error; err != nil {
+		return nil, fmt.Errorf("failed to save review: %w", err)
+	}
+
+	return s.GetReviewByID(ctx, review.ID)
+}
+
+// SetComment saves the optional text comment of a review
+func (s *ReviewService) SetComment(ctx context.Context, reviewID uint, userID int64, comment string) (*database.Review, error) {
+	result := database.DB.WithContext(ctx).
+		Model(&database.Review{}).
+		Where("id = ? AND user_id = ?", reviewID, userID).
+		Update("comment", comment)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to save comment: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, ErrReviewNotAllowed
+	}
+
+	return s.GetReviewByID(ctx, reviewID)
+}
+
+// GetReviewByID retrieves a review with its relations
+func (s *ReviewService) GetReviewByID(ctx context.Context, reviewID uint) (*database.Review, error) {
+	var review database.Review
+	err := database.DB.WithContext(ctx).
+		Preload("Booking").
+		Preload("User").
+		Preload("Service").
+		First(&review, reviewID).Error
+	if err != nil {
+		return nil, fmt.Errorf("review not found: %w", err)
+	}
+	return &review, nil
+}
+
+// ListReviews retrieves reviews ordered from newest with pagination
+// An empty status returns reviews in any status
+func (s *ReviewService) ListReviews(ctx context.Context, status database.ReviewStatus, limit, offset int) ([]database.Review, int64, error) {
+	query := database.DB.WithContext(ctx).Model(&database.Review{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count reviews: %w", err)
+	}
+
+	var reviews []database.Review
+	err := query.
+		Preload("User").
+		Preload("Service").
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&reviews).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get reviews: %w", err)
+	}
+
+	return reviews, total, nil
+}
+
+// SetReviewStatus changes the moderation status of a review
+func (s *ReviewService) SetReviewStatus(ctx context.Context, reviewID uint, status database.ReviewStatus) error {
+	result := database.DB.WithContext(ctx).
+		Model(&database.Review{}).
+		Where("id = ?", reviewID).
+		Update("status", status)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update review status: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("review not found")
+	}
+
+	return nil
+}
+
+// MarkPublished records that a review was posted to the channel
+func (s *ReviewService) MarkPublished(ctx context.Context, reviewID uint) error {
+	now := time.Now()
+	return database.DB.WithContext(ctx).
+		Model(&database.Review{}).
+		Where("id = ?", reviewID).
+		Updates(map[string]interface{}{
+			"status":       database.ReviewStatusApproved,
+			"published_at": now,
+		}).Error
+}
+
+// GetServiceRating calculates the average rating of a service, ignoring hidden reviews
+func (s *ReviewService) GetServiceRating(ctx context.Context, serviceID uint) (*ServiceRating, error) {
+	var rating ServiceRating
+	err := database.DB.WithContext(ctx).
+		Model(&database.Review{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+		Where("service_id = ? AND status != ?", serviceID, database.ReviewStatusHidden).
+		Scan(&rating).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service rating: %w", err)
+	}
+	return &rating, nil
+}
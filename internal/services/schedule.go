@@ -0,0 +1,215 @@
+// Package services contains working schedule management
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gobot/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidSchedule is returned for working hours that make no sense
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// ScheduleService manages working hours and days off
+type ScheduleService struct{}
+
+// NewScheduleService creates a new schedule service instance
+func NewScheduleService() *ScheduleService {
+	return &ScheduleService{}
+}
+
+// GetSchedule returns working hours ordered by weekday
+func (s *ScheduleService) GetSchedule(ctx context.Context) ([]database.WorkSchedule, error) {
+	var schedule []database.WorkSchedule
+	if err := database.DB.WithContext(ctx).Order("day_of_week, start_time").Find(&schedule).Error; err != nil {
+		return nil, fmt.Errorf("failed to get work schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// SetSchedule replaces working hours with the given intervals
+// A weekday may have several intervals, e.g. before and after a break
+func (s *ScheduleService) SetSchedule(ctx context.Context, schedule []database.WorkSchedule) error {
+	for _, day := range schedule {
+		if err := validateWorkInterval(day); err != nil {
+			return err
+		}
+	}
+
+	before, err := s.GetSchedule(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&database.WorkSchedule{}).Error; err != nil {
+			return err
+		}
+		if len(schedule) == 0 {
+			return nil
+		}
+		for i := range schedule {
+			active := schedule[i].IsActive
+			schedule[i].ID = 0
+			if err := tx.Create(&schedule[i]).Error; err != nil {
+				return err
+			}
+			// gorm saves false as the column default
+			if !active {
+				if err := tx.Model(&schedule[i]).Update("is_active", false).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save work schedule: %w", err)
+	}
+
+	recordAudit(ctx, AuditScheduleUpdate, AuditEntitySchedule, 0, scheduleSnapshot(before), scheduleSnapshot(schedule))
+	return nil
+}
+
+// validateWorkInterval checks the weekday and that the interval ends after it starts
+func validateWorkInterval(day database.WorkSchedule) error {
+	if day.DayOfWeek < 0 || day.DayOfWeek > 6 {
+		return fmt.Errorf("%w: invalid day of week %d", ErrInvalidSchedule, day.DayOfWeek)
+	}
+	start, err := time.Parse("15:04", day.StartTime)
+	if err != nil {
+		return fmt.Errorf("%w: invalid start time %q", ErrInvalidSchedule, day.StartTime)
+	}
+	end, err := time.Parse("15:04", day.EndTime)
+	if err != nil {
+		return fmt.Errorf("%w: invalid end time %q", ErrInvalidSchedule, day.EndTime)
+	}
+	if !end.After(start) {
+		return fmt.Errorf("%w: end time %s is not after start time %s", ErrInvalidSchedule, day.EndTime, day.StartTime)
+	}
+	return nil
+}
+
+// GetBlockedDates returns days off in [from, to), zero bounds mean unbounded
+func (s *ScheduleService) GetBlockedDates(ctx context.Context, from, to time.Time) ([]database.BlockedDate, error) {
+	query := database.DB.WithContext(ctx)
+	if !from.IsZero() {
+		query = query.Where("date >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("date < ?", to)
+	}
+
+	var dates []database.BlockedDate
+	if err := query.Order("date").Find(&dates).Error; err != nil {
+		return nil, fmt.Errorf("failed to get blocked dates: %w", err)
+	}
+	return dates, nil
+}
+
+// AddBlockedDate marks a day as a day off
+func (s *ScheduleService) AddBlockedDate(ctx context.Context, date time.Time, reason string) (*database.BlockedDate, error) {
+	blocked := &database.BlockedDate{
+		Date:   time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location()),
+		Reason: reason,
+	}
+	if err := database.DB.WithContext(ctx).Create(blocked).Error; err != nil {
+		return nil, fmt.Errorf("failed to add blocked date: %w", err)
+	}
+
+	recordAudit(ctx, AuditBlockedDateCreate, AuditEntityBlockedDate, blocked.ID, nil, blocked)
+	return blocked, nil
+}
+
+// DeleteBlockedDate removes a day off
+func (s *ScheduleService) DeleteBlockedDate(ctx context.Context, id uint) error {
+	var before database.BlockedDate
+	if err := database.DB.WithContext(ctx).First(&before, id).Error; err != nil {
+		return fmt.Errorf("blocked date not found: %w", err)
+	}
+
+	result := database.DB.WithContext(ctx).Delete(&database.BlockedDate{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete blocked date: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("blocked date not found")
+	}
+
+	recordAudit(ctx, AuditBlockedDateDelete, AuditEntityBlockedDate, id, &before, nil)
+	return nil
+}
+
+// dayHours are the working intervals of a particular day
+type dayHours struct {
+	// limited is false when no work schedule is configured, then every time is working time
+	limited   bool
+	intervals [][2]time.Time
+}
+
+// closed reports whether nothing can be booked on the day
+func (h dayHours) closed() bool {
+	return h.limited && len(h.intervals) == 0
+}
+
+// contains reports whether the time from start to end fits one working interval
+func (h dayHours) contains(start, end time.Time) bool {
+	if !h.limited {
+		return true
+	}
+	for _, interval := range h.intervals {
+		if !start.Before(interval[0]) && !end.After(interval[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// workingHours returns the working intervals of the date's weekday, none on a day off
+func workingHours(ctx context.Context, date time.Time) (dayHours, error) {
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	var blocked int64
+	err := database.DB.WithContext(ctx).
+		Model(&database.BlockedDate{}).
+		Where("date >= ? AND date < ?", startOfDay, startOfDay.AddDate(0, 0, 1)).
+		Count(&blocked).Error
+	if err != nil {
+		return dayHours{}, fmt.Errorf("failed to get blocked dates: %w", err)
+	}
+	if blocked > 0 {
+		return dayHours{limited: true}, nil
+	}
+
+	var schedule []database.WorkSchedule
+	if err := database.DB.WithContext(ctx).Where("is_active = ?", true).Find(&schedule).Error; err != nil {
+		return dayHours{}, fmt.Errorf("failed to get work schedule: %w", err)
+	}
+	// Without a schedule the default time slots are the working hours, as in reports
+	if len(schedule) == 0 {
+		return dayHours{}, nil
+	}
+
+	hours := dayHours{limited: true}
+	for _, day := range schedule {
+		if day.DayOfWeek != int(date.Weekday()) {
+			continue
+		}
+		start, err1 := time.Parse("15:04", day.StartTime)
+		end, err2 := time.Parse("15:04", day.EndTime)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		hours.intervals = append(hours.intervals, [2]time.Time{
+			time.Date(date.Year(), date.Month(), date.Day(), start.Hour(), start.Minute(), 0, 0, date.Location()),
+			time.Date(date.Year(), date.Month(), date.Day(), end.Hour(), end.Minute(), 0, 0, date.Location()),
+		})
+	}
+	return hours, nil
+}
@@ -0,0 +1,306 @@
+// Package services contains editable notification templates
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+
+	"gobot/internal/database"
+	"gobot/internal/i18n"
+
+	"gorm.io/gorm"
+)
+
+// Notification template keys
+const (
+	TemplateBookingConfirmed   = "booking_confirmed"
+	TemplateBookingCancelled   = "booking_cancelled"
+	TemplateBookingRejected    = "booking_rejected"
+	TemplateBookingRescheduled = "booking_rescheduled"
+	TemplateReminderDay        = "reminder_day"
+	TemplateReminderHour       = "reminder_hour"
+	TemplateReviewRequest      = "review_request"
+	TemplateAdminNewBooking    = "admin_new_booking"
+	TemplatePromotion          = "promotion"
+)
+
+// maxTemplateLength keeps rendered messages within Telegram's 4096 characters limit
+const maxTemplateLength = 3500
+
+// TemplateInfo describes an editable template
+type TemplateInfo struct {
+	Key       string
+	Title     string
+	Fields    []string // TemplateData fields the template is expected to use
+	AdminOnly bool     // Sent to admins or the channel, exists only in the default language
+}
+
+// bookingTemplateFields lists fields filled for every booking template
+var bookingTemplateFields = []string{"ClientName", "Username", "Phone", "Service", "Description", "Duration", "Price", "Date", "Weekday", "Time"}
+
+// Templates lists all editable templates in display order
+var Templates = []TemplateInfo{
+	{Key: TemplateBookingConfirmed, Title: "Подтверждение записи", Fields: bookingTemplateFields},
+	{Key: TemplateBookingCancelled, Title: "Отмена записи", Fields: bookingTemplateFields},
+	{Key: TemplateBookingRejected, Title: "Отклонение записи", Fields: bookingTemplateFields},
+	{Key: TemplateBookingRescheduled, Title: "Перенос записи", Fields: bookingTemplateFields},
+	{Key: TemplateReminderDay, Title: "Напоминание за день", Fields: bookingTemplateFields},
+	{Key: TemplateReminderHour, Title: "Напоминание за час", Fields: bookingTemplateFields},
+	{Key: TemplateReviewRequest, Title: "Просьба оценить визит", Fields: bookingTemplateFields},
+	{Key: TemplateAdminNewBooking, Title: "Новая запись (админам)", Fields: append(bookingTemplateFields, "Certificate"), AdminOnly: true},
+	{Key: TemplatePromotion, Title: "Акция в канале", Fields: []string{"Discount", "Service", "Percentage", "OldPrice", "NewPrice", "StartDate", "EndDate"}, AdminOnly: true},
+}
+
+// ErrUnknownTemplate is returned for keys missing from Templates
+var ErrUnknownTemplate = errors.New("unknown template")
+
+// TemplateData is passed to notification templates
+// Dates and prices are already formatted for the recipient's language
+type TemplateData struct {
+	ClientName  string
+	Username    string
+	Phone       string
+	Service     string
+	Description string
+	Duration    int
+	Price       string
+	Date        string
+	Weekday     string
+	Time        string
+	Certificate string // Gift certificate line of a new booking
+
+	// Promotion fields
+	Discount   string
+	Percentage int
+	OldPrice   string
+	NewPrice   string
+	StartDate  string
+	EndDate    string
+}
+
+// BookingTemplateData builds template data for a booking with preloaded service and user
+func BookingTemplateData(lang string, booking *database.Booking) TemplateData {
+	return TemplateData{
+		ClientName:  strings.TrimSpace(booking.User.FirstName + " " + booking.User.LastName),
+		Username:    booking.User.Username,
+		Phone:       booking.User.Phone,
+		Service:     booking.Service.Name,
+		Description: booking.Service.Description,
+		Duration:    booking.Service.Duration,
+		Price:       i18n.Price(lang, PaidPrice(booking)),
+		Date:        i18n.Date(lang, booking.Date),
+		Weekday:     i18n.Weekday(lang, booking.Date),
+		Time:        booking.Time,
+	}
+}
+
+// PromotionTemplateData builds template data for a discount with preloaded service
+func PromotionTemplateData(lang string, discount *database.Discount) TemplateData {
+	discountAmount := discount.Service.Price * discount.Percentage / 100
+
+	return TemplateData{
+		Discount:   discount.Name,
+		Service:    discount.Service.Name,
+		Percentage: discount.Percentage,
+		OldPrice:   i18n.Price(lang, discount.Service.Price),
+		NewPrice:   i18n.Price(lang, discount.Service.Price-discountAmount),
+		StartDate:  i18n.Date(lang, discount.StartDate),
+		EndDate:    i18n.Date(lang, discount.EndDate),
+	}
+}
+
+// SampleTemplateData returns data of a sample booking used for previews and validation
+func SampleTemplateData(lang string) TemplateData {
+	date := time.Now().AddDate(0, 0, 1)
+
+	return TemplateData{
+		ClientName:  "Анна Иванова",
+		Username:    "anna_ivanova",
+		Phone:       "+79001234567",
+		Service:     "Классический массаж",
+		Description: "Расслабляющий массаж всего тела",
+		Duration:    60,
+		Price:       i18n.Price(lang, 250000),
+		Date:        i18n.Date(lang, date),
+		Weekday:     i18n.Weekday(lang, date),
+		Time:        "14:00",
+		Certificate: "🎁 Оплачено сертификатом: " + i18n.Price(lang, 100000) + "\n",
+		Discount:    "Весенняя акция",
+		Percentage:  20,
+		OldPrice:    i18n.Price(lang, 250000),
+		NewPrice:    i18n.Price(lang, 200000),
+		StartDate:   i18n.Date(lang, date),
+		EndDate:     i18n.Date(lang, date.AddDate(0, 0, 14)),
+	}
+}
+
+// TemplateService handles notification templates
+type TemplateService struct{}
+
+// NewTemplateService creates a new template service instance
+func NewTemplateService() *TemplateService {
+	return &TemplateService{}
+}
+
+// GetTemplateInfo returns description of the template with the key
+func GetTemplateInfo(key string) (*TemplateInfo, error) {
+	for i := range Templates {
+		if Templates[i].Key == key {
+			return &Templates[i], nil
+		}
+	}
+	return nil, ErrUnknownTemplate
+}
+
+// TemplateLanguages returns languages the template can be edited in
+func TemplateLanguages(info *TemplateInfo) []string {
+	if info.AdminOnly {
+		return []string{i18n.Default}
+	}
+	return i18n.Supported()
+}
+
+// DefaultTemplate returns the built-in template source
+func DefaultTemplate(key, lang string) string {
+	return i18n.T(lang, "template."+key)
+}
+
+// GetCustom returns the admin override of a template, nil if the default is used
+func (s *TemplateService) GetCustom(ctx context.Context, key, lang string) (*database.MessageTemplate, error) {
+	var tmpl database.MessageTemplate
+	err := database.DB.WithContext(ctx).
+		Where("key = ? AND language = ?", key, lang).
+		First(&tmpl).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	return &tmpl, nil
+}
+
+// GetSource returns the template source in use and whether it was customized
+func (s *TemplateService) GetSource(ctx context.Context, key, lang string) (string, bool) {
+	custom, err := s.GetCustom(ctx, key, lang)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load template", "key", key, "lang", lang, "error", err)
+	}
+	if custom != nil {
+		return custom.Body, true
+	}
+	return DefaultTemplate(key, lang), false
+}
+
+// Render renders the template for the language
+// A broken custom template falls back to the built-in one so the message is still sent
+func (s *TemplateService) Render(ctx context.Context, key, lang string, data TemplateData) (string, error) {
+	source, custom := s.GetSource(ctx, key, lang)
+
+	msg, err := RenderTemplate(key, source, data)
+	if err != nil && custom {
+		slog.WarnContext(ctx, "Failed to render custom template, using default", "key", key, "lang", lang, "error", err)
+		msg, err = RenderTemplate(key, DefaultTemplate(key, lang), data)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return msg, nil
+}
+
+// Preview validates the source and renders it against the sample booking
+func (s *TemplateService) Preview(key, lang, source string) (string, error) {
+	if _, err := GetTemplateInfo(key); err != nil {
+		return "", err
+	}
+	if len([]rune(source)) > maxTemplateLength {
+		return "", fmt.Errorf("template is longer than %d characters", maxTemplateLength)
+	}
+
+	msg, err := RenderTemplate(key, source, SampleTemplateData(lang))
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(msg) == "" {
+		return "", errors.New("template renders an empty message")
+	}
+
+	return msg, nil
+}
+
+// Save validates and stores an admin override of the template
+func (s *TemplateService) Save(ctx context.Context, key, lang, source string, adminID int64) error {
+	if _, err := s.Preview(key, lang, source); err != nil {
+		return err
+	}
+
+	custom, err := s.GetCustom(ctx, key, lang)
+	if err != nil {
+		return err
+	}
+	if custom == nil {
+		custom = &database.MessageTemplate{Key: key, Language: lang}
+	}
+	custom.Body = source
+	custom.UpdatedBy = adminID
+
+	if err := database.DB.WithContext(ctx).Save(custom).Error; err != nil {
+		return fmt.Errorf("failed to save template: %w", err)
+	}
+	return nil
+}
+
+// Reset removes the admin override so the built-in template is used again
+func (s *TemplateService) Reset(ctx context.Context, key, lang string) error {
+	if err := database.DB.WithContext(ctx).
+		Where("key = ? AND language = ?", key, lang).
+		Delete(&database.MessageTemplate{}).Error; err != nil {
+		return fmt.Errorf("failed to reset template: %w", err)
+	}
+	return nil
+}
+
+// ListCustom returns all admin overrides
+func (s *TemplateService) ListCustom(ctx context.Context) ([]database.MessageTemplate, error) {
+	var templates []database.MessageTemplate
+	if err := database.DB.WithContext(ctx).Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	return templates, nil
+}
+
+// escaped returns the data safe to put into an HTML message
+// Certificate is built by the bot and may carry markup, everything else comes from clients or the catalog
+func (d TemplateData) escaped() TemplateData {
+	for _, field := range []*string{
+		&d.ClientName, &d.Username, &d.Phone, &d.Service, &d.Description, &d.Price,
+		&d.Date, &d.Weekday, &d.Time, &d.Discount, &d.OldPrice, &d.NewPrice, &d.StartDate, &d.EndDate,
+	} {
+		*field = html.EscapeString(*field)
+	}
+	return d
+}
+
+// RenderTemplate parses and executes a template source
+// Messages are sent in HTML mode, so data fields are escaped and only the template itself may add markup
+func RenderTemplate(name, source string, data TemplateData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data.escaped()); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+
+	return buf.String(), nil
+}
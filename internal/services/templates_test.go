@@ -0,0 +1,65 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"gobot/internal/i18n"
+)
+
+func TestRenderTemplateEscapesData(t *testing.T) {
+	data := SampleTemplateData(i18n.Default)
+	data.ClientName = "<b"
+	data.Username = "anna&co"
+	data.Service = "Massage <i>deluxe</i>"
+	data.Certificate = "🎁 <b>Certificate</b>\n"
+
+	tests := []struct {
+		name    string
+		source  string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "client name",
+			source:  "<b>{{.ClientName}}</b>",
+			want:    []string{"<b>&lt;b</b>"},
+			notWant: []string{"<b<"},
+		},
+		{
+			name:   "username and service",
+			source: "@{{.Username}}: {{.Service}}",
+			want:   []string{"@anna&amp;co: Massage &lt;i&gt;deluxe&lt;/i&gt;"},
+		},
+		{
+			name:   "certificate keeps markup",
+			source: "{{.Certificate}}",
+			want:   []string{"🎁 <b>Certificate</b>"},
+		},
+		{
+			name:    "admin new booking",
+			source:  DefaultTemplate(TemplateAdminNewBooking, i18n.Default),
+			want:    []string{"<b>Новая запись!</b>", "👤 &lt;b (@anna&amp;co)", "Massage &lt;i&gt;deluxe&lt;/i&gt;"},
+			notWant: []string{"<i>deluxe"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderTemplate("test", tt.source, data)
+			if err != nil {
+				t.Fatalf("RenderTemplate() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("RenderTemplate() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("RenderTemplate() = %q, must not contain %q", got, notWant)
+				}
+			}
+		})
+	}
+}
@@ -3,13 +3,31 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"gobot/internal/database"
+	"gobot/internal/i18n"
+
+	"gorm.io/gorm"
 
 	tele "gopkg.in/telebot.v3"
 )
 
+// ErrInviteNotFound is returned when an offline client invite token is unknown or already used
+var ErrInviteNotFound = errors.New("invite not found")
+
+// IsOfflineUserID reports whether the ID belongs to an offline client created by admin
+// Offline clients get negative IDs so they never collide with Telegram user IDs
+func IsOfflineUserID(userID int64) bool {
+	return userID < 0
+}
+
 // UserService handles user-related operations
 type UserService struct{}
 
@@ -29,6 +47,8 @@ func (s *UserService) GetOrCreateUser(ctx context.Context, tgUser *tele.User) (*
 		user.Username = tgUser.Username
 		user.FirstName = tgUser.FirstName
 		user.LastName = tgUser.LastName
+		user.LanguageCode = tgUser.LanguageCode
+		user.BlockedBot = false // User is talking to the bot again
 		if err := database.DB.WithContext(ctx).Save(&user).Error; err != nil {
 			return nil, fmt.Errorf("failed to update user: %w", err)
 		}
@@ -37,17 +57,19 @@ func (s *UserService) GetOrCreateUser(ctx context.Context, tgUser *tele.User) (*
 
 	// Create new user
 	user = database.User{
-		ID:        tgUser.ID,
-		Username:  tgUser.Username,
-		FirstName: tgUser.FirstName,
-		LastName:  tgUser.LastName,
-		IsAdmin:   false,
+		ID:           tgUser.ID,
+		Username:     tgUser.Username,
+		FirstName:    tgUser.FirstName,
+		LastName:     tgUser.LastName,
+		LanguageCode: tgUser.LanguageCode,
+		IsAdmin:      false,
 	}
 
 	if err := database.DB.WithContext(ctx).Create(&user).Error; err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	Events.Publish(ctx, EventUserRegistered, userEventData(&user))
 	return &user, nil
 }
 
@@ -78,3 +100,388 @@ func (s *UserService) SetAdmin(ctx context.Context, userID int64, isAdmin bool)
 	return nil
 }
 
+// SearchUsers finds users by part of name, @username, phone or exact Telegram ID
+func (s *UserService) SearchUsers(ctx context.Context, query string, limit int) ([]database.User, error) {
+	query = strings.TrimPrefix(strings.TrimSpace(query), "@")
+
+	pattern := "%" + query + "%"
+	db := database.DB.WithContext(ctx).
+		Where("first_name LIKE ? OR last_name LIKE ? OR username LIKE ?", pattern, pattern, pattern)
+
+	if phone := strings.TrimPrefix(NormalizePhone(query), "+"); len(phone) >= 4 {
+		db = db.Or("phone LIKE ?", "%"+phone+"%")
+	}
+
+	if userID, err := strconv.ParseInt(query, 10, 64); err == nil {
+		db = db.Or("id = ?", userID)
+	}
+
+	var users []database.User
+	if err := db.Order("updated_at DESC").Limit(limit).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+
+	return users, nil
+}
+
+// UserFilter narrows down the client list
+// Zero values mean "any"
+type UserFilter struct {
+	Query   string // Part of name, @username, phone or exact Telegram ID
+	Blocked *bool
+	Offline *bool
+}
+
+// ListUsers retrieves clients matching the filter with pagination, newest first
+func (s *UserService) ListUsers(ctx context.Context, filter UserFilter, limit, offset int) ([]database.User, int64, error) {
+	query := database.DB.WithContext(ctx).Model(&database.User{})
+
+	if q := strings.TrimPrefix(strings.TrimSpace(filter.Query), "@"); q != "" {
+		pattern := "%" + q + "%"
+		conditions := database.DB.Where("first_name LIKE ? OR last_name LIKE ? OR username LIKE ?", pattern, pattern, pattern)
+		if phone := strings.TrimPrefix(NormalizePhone(q), "+"); len(phone) >= 4 {
+			conditions = conditions.Or("phone LIKE ?", "%"+phone+"%")
+		}
+		if userID, err := strconv.ParseInt(q, 10, 64); err == nil {
+			conditions = conditions.Or("id = ?", userID)
+		}
+		query = query.Where(conditions)
+	}
+
+	if filter.Blocked != nil {
+		query = query.Where("is_blocked = ?", *filter.Blocked)
+	}
+
+	if filter.Offline != nil {
+		query = query.Where("is_offline = ?", *filter.Offline)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	var users []database.User
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// CreateOfflineClient creates a client record for someone who booked by phone or in person
+func (s *UserService) CreateOfflineClient(ctx context.Context, name, phone string) (*database.User, error) {
+	token, err := generateInviteToken()
+	if err != nil {
+		return nil, err
+	}
+
+	user := database.User{
+		FirstName:   strings.TrimSpace(name),
+		Phone:       NormalizePhone(phone),
+		IsOffline:   true,
+		InviteToken: token,
+	}
+
+	err = database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Offline clients are numbered -1, -2, ... including soft-deleted ones
+		var minID int64
+		if err := tx.Unscoped().
+			Model(&database.User{}).
+			Select("COALESCE(MIN(id), 0)").
+			Where("id < 0").
+			Scan(&minID).Error; err != nil {
+			return err
+		}
+
+		user.ID = minID - 1
+		return tx.Create(&user).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create offline client: %w", err)
+	}
+
+	Events.Publish(ctx, EventUserRegistered, userEventData(&user))
+	return &user, nil
+}
+
+// ClaimOfflineClient moves bookings of an offline client to the Telegram user who opened the invite
+// Returns the number of bookings moved
+func (s *UserService) ClaimOfflineClient(ctx context.Context, token string, userID int64) (int64, error) {
+	var moved int64
+
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var offline database.User
+		if err := tx.Where("invite_token = ? AND is_offline = ?", token, true).First(&offline).Error; err != nil {
+			return ErrInviteNotFound
+		}
+
+		result := tx.Model(&database.Booking{}).
+			Where("user_id = ?", offline.ID).
+			Update("user_id", userID)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved = result.RowsAffected
+
+		if err := tx.Model(&database.GiftCertificateRedemption{}).
+			Where("user_id = ?", offline.ID).
+			Update("user_id", userID).Error; err != nil {
+			return err
+		}
+
+		// Keep the phone number the admin recorded
+		if offline.Phone != "" {
+			if err := tx.Model(&database.User{}).
+				Where("id = ? AND (phone = '' OR phone IS NULL)", userID).
+				Update("phone", offline.Phone).Error; err != nil {
+				return err
+			}
+		}
+
+		// Keep internal notes about the client
+		if offline.AdminNotes != "" {
+			if err := tx.Model(&database.User{}).
+				Where("id = ? AND (admin_notes = '' OR admin_notes IS NULL)", userID).
+				Update("admin_notes", offline.AdminNotes).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&offline).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrInviteNotFound) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("failed to claim offline client: %w", err)
+	}
+
+	return moved, nil
+}
+
+// NormalizePhone keeps digits and the leading plus of a phone number
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		if (r >= '0' && r <= '9') || (r == '+' && i == 0) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// generateInviteToken creates a random token for offline client invite links
+func generateInviteToken() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate invite token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Profile fields a client can edit
+const (
+	ProfileFieldPhone      = "phone"
+	ProfileFieldBirthday   = "birthday"
+	ProfileFieldAllergies  = "allergies"
+	ProfileFieldSpecialist = "specialist"
+	ProfileFieldLanguage   = "language"
+)
+
+// UserLanguage returns the language to talk to the user in
+func UserLanguage(user *database.User) string {
+	return i18n.Resolve(user.Language, user.LanguageCode)
+}
+
+// GetLanguage returns the language chosen by the user, empty if detected automatically
+func (s *UserService) GetLanguage(ctx context.Context, userID int64) string {
+	var user database.User
+	if err := database.DB.WithContext(ctx).Select("language").First(&user, userID).Error; err != nil {
+		return ""
+	}
+	return user.Language
+}
+
+// GetUser retrieves a user by ID
+func (s *UserService) GetUser(ctx context.Context, userID int64) (*database.User, error) {
+	var user database.User
+	if err := database.DB.WithContext(ctx).First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	return &user, nil
+}
+
+// UpdateProfileField updates a single profile field of a user
+// Pass nil value to clear the field
+func (s *UserService) UpdateProfileField(ctx context.Context, userID int64, field string, value interface{}) error {
+	switch field {
+	case ProfileFieldPhone, ProfileFieldAllergies, ProfileFieldSpecialist, ProfileFieldLanguage:
+		if value == nil {
+			value = ""
+		}
+	case ProfileFieldBirthday:
+	default:
+		return fmt.Errorf("unknown profile field: %s", field)
+	}
+
+	result := database.DB.WithContext(ctx).
+		Model(&database.User{}).
+		Where("id = ?", userID).
+		Update(field, value)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update profile: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// LateCancelWindow is how close to the visit a client cancellation counts as late
+const LateCancelWindow = 24 * time.Hour
+
+// ClientStats holds visit history summary of a client
+type ClientStats struct {
+	TotalBookings      int64
+	Completed          int64
+	Cancelled          int64
+	LateCancels        int64
+	NoShows            int64
+	TotalSpent         int // Sum of completed bookings prices in smallest currency unit
+	CertificateBalance int // Remaining balance of active certificates bought or used by the client
+	LastVisit          *time.Time
+}
+
+// GetClientStats calculates visit history summary of a client
+func (s *UserService) GetClientStats(ctx context.Context, userID int64) (*ClientStats, error) {
+	var bookings []database.Booking
+	if err := database.DB.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Find(&bookings).Error; err != nil {
+		return nil, fmt.Errorf("failed to get client bookings: %w", err)
+	}
+
+	stats := &ClientStats{TotalBookings: int64(len(bookings))}
+	for _, booking := range bookings {
+		switch booking.Status {
+		case database.BookingStatusCompleted:
+			stats.Completed++
+			stats.TotalSpent += booking.Price
+			if stats.LastVisit == nil || booking.Date.After(*stats.LastVisit) {
+				date := booking.Date
+				stats.LastVisit = &date
+			}
+		case database.BookingStatusNoShow:
+			stats.NoShows++
+		case database.BookingStatusCancelled:
+			stats.Cancelled++
+			if booking.CancelledAt != nil && bookingStart(&booking).Sub(*booking.CancelledAt) < LateCancelWindow {
+				stats.LateCancels++
+			}
+		}
+	}
+
+	var balance int64
+	if err := database.DB.WithContext(ctx).
+		Model(&database.GiftCertificate{}).
+		Select("COALESCE(SUM(balance), 0)").
+		Where("status = ? AND expires_at > ?", database.GiftCertificateStatusActive, time.Now()).
+		Where("purchased_by = ? OR id IN (?)", userID, database.DB.
+			Model(&database.GiftCertificateRedemption{}).
+			Select("certificate_id").
+			Where("user_id = ?", userID)).
+		Scan(&balance).Error; err != nil {
+		return nil, fmt.Errorf("failed to get certificate balance: %w", err)
+	}
+	stats.CertificateBalance = int(balance)
+
+	return stats, nil
+}
+
+// BlockUser prevents a client from booking
+// Hidden reason is visible to admins only
+func (s *UserService) BlockUser(ctx context.Context, userID int64, reason string, hidden bool) error {
+	now := time.Now()
+	result := database.DB.WithContext(ctx).Model(&database.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"is_blocked":          true,
+			"block_reason":        reason,
+			"block_reason_hidden": hidden,
+			"blocked_at":          &now,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to block user: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// UnblockUser allows a blocked client to book again
+func (s *UserService) UnblockUser(ctx context.Context, userID int64) error {
+	result := database.DB.WithContext(ctx).Model(&database.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"is_blocked":          false,
+			"block_reason":        "",
+			"block_reason_hidden": false,
+			"blocked_at":          nil,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to unblock user: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// GetBlockedUsers retrieves blocked clients, most recently blocked first
+func (s *UserService) GetBlockedUsers(ctx context.Context) ([]database.User, error) {
+	var users []database.User
+	if err := database.DB.WithContext(ctx).
+		Where("is_blocked = ?", true).
+		Order("blocked_at DESC").
+		Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to get blocked users: %w", err)
+	}
+	return users, nil
+}
+
+// UpdateAdminNotes updates internal notes about a client
+func (s *UserService) UpdateAdminNotes(ctx context.Context, userID int64, notes string) error {
+	result := database.DB.WithContext(ctx).Model(&database.User{}).
+		Where("id = ?", userID).
+		Update("admin_notes", notes)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update client notes: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// bookingStart returns the date and time a booking begins
+func bookingStart(booking *database.Booking) time.Time {
+	start := booking.Date
+	if t, err := time.Parse("15:04", booking.Time); err == nil {
+		start = time.Date(start.Year(), start.Month(), start.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
+	}
+	return start
+}
@@ -0,0 +1,69 @@
+// Package services contains Telegram Mini App authentication
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// WebAppInitDataMaxAge rejects replayed Mini App launch data
+const WebAppInitDataMaxAge = 24 * time.Hour
+
+// ErrInvalidWebAppData is returned for Mini App launch data not signed by the bot
+var ErrInvalidWebAppData = errors.New("invalid Mini App init data")
+
+// VerifyWebAppInitData checks Telegram.WebApp.initData and returns the user who opened the Mini App
+// See https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app
+func VerifyWebAppInitData(botToken, initData string, now time.Time) (*tele.User, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, ErrInvalidWebAppData
+	}
+
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, ErrInvalidWebAppData
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, key+"="+values.Get(key))
+	}
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(hash)) {
+		return nil, ErrInvalidWebAppData
+	}
+
+	if !validAuthDate(values.Get("auth_date"), now, WebAppInitDataMaxAge) {
+		return nil, ErrInvalidWebAppData
+	}
+
+	var user tele.User
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return nil, ErrInvalidWebAppData
+	}
+
+	return &user, nil
+}
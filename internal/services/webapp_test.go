@@ -0,0 +1,48 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyWebAppInitData(t *testing.T) {
+	const valid = "query_id=AAH" +
+		"&user=%7B%22id%22%3A1001%2C%22first_name%22%3A%22Anna%22%2C%22username%22%3A%22anna%22%2C%22language_code%22%3A%22ru%22%7D" +
+		"&auth_date=1760000000" +
+		"&hash=53ebd86787b9a647a4bb181d65b0b6405adf646be25e4e0ccf64c684a66a8cce"
+
+	tests := []struct {
+		name     string
+		initData string
+		now      time.Time
+		wantID   int64
+	}{
+		{name: "valid", initData: valid, now: testAuthDate.Add(time.Hour), wantID: 1001},
+		{name: "clock slightly behind", initData: valid, now: testAuthDate.Add(-time.Minute), wantID: 1001},
+		{name: "tampered user", initData: strings.Replace(valid, "%3A1001", "%3A1002", 1), now: testAuthDate},
+		{name: "tampered auth_date", initData: strings.Replace(valid, "1760000000", "1760086400", 1), now: testAuthDate},
+		{name: "missing hash", initData: valid[:strings.Index(valid, "&hash=")], now: testAuthDate},
+		{name: "not a query", initData: "%zz", now: testAuthDate},
+		{name: "expired", initData: valid, now: testAuthDate.Add(WebAppInitDataMaxAge + time.Second)},
+		{name: "future", initData: valid, now: testAuthDate.Add(-telegramAuthDateSkew - time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := VerifyWebAppInitData(testBotToken, tt.initData, tt.now)
+			if tt.wantID == 0 {
+				if err != ErrInvalidWebAppData {
+					t.Errorf("VerifyWebAppInitData() = %+v, %v, want ErrInvalidWebAppData", user, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyWebAppInitData() error = %v", err)
+			}
+			if user.ID != tt.wantID || user.FirstName != "Anna" || user.LanguageCode != "ru" {
+				t.Errorf("VerifyWebAppInitData() = %+v, want user %d", user, tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+// Package services contains outgoing webhooks
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gobot/internal/database"
+)
+
+const (
+	// webhookMaxAttempts is the number of tries before a delivery is marked as failed
+	webhookMaxAttempts = 6
+	// webhookBaseBackoff is the delay before the first retry, doubled on each attempt
+	webhookBaseBackoff = 30 * time.Second
+	// webhookPollInterval is how often the worker checks for due deliveries
+	webhookPollInterval = 10 * time.Second
+	// webhookBatchSize is the number of deliveries loaded per worker pass
+	webhookBatchSize = 20
+	// webhookTimeout limits a single POST
+	webhookTimeout = 10 * time.Second
+	// webhookMaxErrorLength keeps response bodies saved as errors short
+	webhookMaxErrorLength = 500
+)
+
+// Headers of webhook requests
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookStats holds the number of deliveries per status
+type WebhookStats struct {
+	Pending   int64
+	Delivered int64
+	Failed    int64
+}
+
+// WebhookService POSTs domain events to configured URLs
+// Deliveries are persisted first and sent by a background worker with retries
+type WebhookService struct {
+	urls   []string
+	secret string
+	client *http.Client
+	wake   chan struct{}
+}
+
+// NewWebhookService creates a new webhook service, no URLs means webhooks are disabled
+func NewWebhookService(urls []string, secret string) *WebhookService {
+	return &WebhookService{
+		urls:   urls,
+		secret: secret,
+		client: &http.Client{Timeout: webhookTimeout},
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Enabled reports whether any webhook URL is configured
+func (s *WebhookService) Enabled() bool {
+	return len(s.urls) > 0
+}
+
+// URLs returns configured webhook URLs
+func (s *WebhookService) URLs() []string {
+	return s.urls
+}
+
+// HandleEvent queues the event for every webhook URL, it is an EventHandler
+func (s *WebhookService) HandleEvent(ctx context.Context, event Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to encode event", "event", event.Type, "error", err)
+		return
+	}
+
+	for _, url := range s.urls {
+		delivery := &database.WebhookDelivery{
+			EventID:       event.ID,
+			EventType:     event.Type,
+			URL:           url,
+			Payload:       string(payload),
+			Status:        database.WebhookStatusPending,
+			NextAttemptAt: time.Now(),
+		}
+		// The event happened already, so the delivery is stored even if the request is cancelled
+		if err := database.DB.WithContext(context.WithoutCancel(ctx)).Create(delivery).Error; err != nil {
+			slog.ErrorContext(ctx, "Failed to queue webhook", "event", event.Type, "url", url, "error", err)
+		}
+	}
+
+	s.notify()
+}
+
+// StartWorker delivers queued webhooks until the context is cancelled
+func (s *WebhookService) StartWorker(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	slog.InfoContext(ctx, "Webhook worker started")
+
+	for {
+		s.processPending(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "Webhook worker stopped")
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// notify wakes up the worker without blocking
+func (s *WebhookService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// processPending sends all deliveries that are due
+func (s *WebhookService) processPending(ctx context.Context) {
+	var deliveries []database.WebhookDelivery
+	err := database.DB.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", database.WebhookStatusPending, time.Now()).
+		Order("next_attempt_at, id").
+		Limit(webhookBatchSize).
+		Find(&deliveries).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to fetch webhook deliveries", "error", err)
+		return
+	}
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+		s.deliver(ctx, &deliveries[i])
+	}
+
+	// Come back right away for the rest of a full batch
+	if len(deliveries) == webhookBatchSize {
+		s.notify()
+	}
+}
+
+// deliver POSTs a single delivery and records the outcome
+func (s *WebhookService) deliver(ctx context.Context, delivery *database.WebhookDelivery) {
+	code, err := s.post(ctx, delivery)
+	if err != nil && ctx.Err() != nil {
+		// Shutting down, the delivery stays pending
+		return
+	}
+	now := time.Now()
+
+	delivery.Attempts++
+	delivery.ResponseCode = code
+	if err == nil {
+		delivery.Status = database.WebhookStatusDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	} else {
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= webhookMaxAttempts {
+			delivery.Status = database.WebhookStatusFailed
+			slog.WarnContext(ctx, "Webhook delivery failed", "delivery_id", delivery.ID, "event", delivery.EventType, "url", delivery.URL, "attempts", delivery.Attempts, "error", err)
+		} else {
+			delivery.NextAttemptAt = now.Add(webhookBaseBackoff << (delivery.Attempts - 1))
+		}
+	}
+
+	if err := database.DB.WithContext(ctx).Save(delivery).Error; err != nil {
+		slog.ErrorContext(ctx, "Failed to save webhook delivery", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// post sends the payload and returns the response status, any non-2xx status is an error
+func (s *WebhookService) post(ctx context.Context, delivery *database.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gobot-webhooks")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.EventID)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(s.secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxErrorLength))
+		return resp.StatusCode, fmt.Errorf("webhook returned %s: %s", resp.Status, bytes.TrimSpace(text))
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>"
+// Signing the timestamp lets receivers reject replayed requests
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GetStats counts deliveries by status
+func (s *WebhookService) GetStats(ctx context.Context) (*WebhookStats, error) {
+	var rows []struct {
+		Status database.WebhookStatus
+		Count  int64
+	}
+	err := database.DB.WithContext(ctx).
+		Model(&database.WebhookDelivery{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook stats: %w", err)
+	}
+
+	stats := &WebhookStats{}
+	for _, row := range rows {
+		switch row.Status {
+		case database.WebhookStatusPending:
+			stats.Pending = row.Count
+		case database.WebhookStatusDelivered:
+			stats.Delivered = row.Count
+		case database.WebhookStatusFailed:
+			stats.Failed = row.Count
+		}
+	}
+	return stats, nil
+}
+
+// ListFailed returns deliveries that gave up, newest first
+func (s *WebhookService) ListFailed(ctx context.Context, limit int) ([]database.WebhookDelivery, error) {
+	var deliveries []database.WebhookDelivery
+	err := database.DB.WithContext(ctx).
+		Where("status = ?", database.WebhookStatusFailed).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get failed webhooks: %w", err)
+	}
+	return deliveries, nil
+}
+
+// GetDelivery returns a delivery with its log fields
+func (s *WebhookService) GetDelivery(ctx context.Context, id uint) (*database.WebhookDelivery, error) {
+	var delivery database.WebhookDelivery
+	if err := database.DB.WithContext(ctx).First(&delivery, id).Error; err != nil {
+		return nil, fmt.Errorf("webhook delivery not found: %w", err)
+	}
+	return &delivery, nil
+}
+
+// Redeliver queues a failed delivery again with a fresh set of attempts
+func (s *WebhookService) Redeliver(ctx context.Context, id uint) error {
+	result := database.DB.WithContext(ctx).
+		Model(&database.WebhookDelivery{}).
+		Where("id = ? AND status = ?", id, database.WebhookStatusFailed).
+		Updates(map[string]interface{}{
+			"status":          database.WebhookStatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to redeliver webhook: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("failed webhook delivery %d not found", id)
+	}
+
+	s.notify()
+	return nil
+}
+
+// RedeliverFailed queues all failed deliveries again
+// Returns the number of deliveries queued
+func (s *WebhookService) RedeliverFailed(ctx context.Context) (int64, error) {
+	result := database.DB.WithContext(ctx).
+		Model(&database.WebhookDelivery{}).
+		Where("status = ?", database.WebhookStatusFailed).
+		Updates(map[string]interface{}{
+			"status":          database.WebhookStatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to redeliver webhooks: %w", result.Error)
+	}
+
+	s.notify()
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,104 @@
+// Package web contains changes shared by the admin API and the dashboard
+package web
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gobot/internal/database"
+	"gobot/internal/services"
+)
+
+// checkBookingMove reports whether a booking can be moved to the date and time
+func checkBookingMove(ctx context.Context, booking *database.Booking, date time.Time, timeSlot string) error {
+	if !services.CanMoveBooking(booking.Status) {
+		return fmt.Errorf("%w: booking %d is %s", services.ErrBookingNotMovable, booking.ID, booking.Status)
+	}
+	if services.IsTimeSlotTaken(ctx, timeSlot, date, booking.Service.Duration, booking.ID) {
+		return services.ErrSlotTaken
+	}
+	return nil
+}
+
+// checkBookingStatus reports whether the state machine allows the status change
+func checkBookingStatus(booking *database.Booking, status database.BookingStatus, change services.StatusChange) error {
+	if !services.CanTransitionBooking(booking.Status, status, change.Type) {
+		return fmt.Errorf("%w: from %s to %s", services.ErrBookingTransition, booking.Status, status)
+	}
+	return nil
+}
+
+// moveBooking reschedules a booking and tells the client the new time
+func (s *Server) moveBooking(ctx context.Context, booking *database.Booking, date time.Time, timeSlot string) error {
+	if err := checkBookingMove(ctx, booking, date, timeSlot); err != nil {
+		return err
+	}
+
+	if err := s.admin.RescheduleBooking(ctx, booking.ID, date, timeSlot); err != nil {
+		return err
+	}
+	booking.Date = date
+	booking.Time = timeSlot
+
+	s.notifyBooking(ctx, booking, s.notifications.SendBookingRescheduled)
+	return nil
+}
+
+// setBookingStatus changes the status and notifies the client about confirmation, rejection and cancellation
+func (s *Server) setBookingStatus(ctx context.Context, booking *database.Booking, status database.BookingStatus, change services.StatusChange) error {
+	if err := checkBookingStatus(booking, status, change); err != nil {
+		return err
+	}
+
+	if err := s.admin.UpdateBookingStatus(ctx, booking.ID, status, change); err != nil {
+		return err
+	}
+	booking.Status = status
+
+	switch status {
+	case database.BookingStatusConfirmed:
+		s.notifyBooking(ctx, booking, s.notifications.SendBookingConfirmation)
+	case database.BookingStatusCancelled:
+		s.notifyBooking(ctx, booking, s.notifications.SendBookingCancellation)
+	case database.BookingStatusRejected:
+		s.notifyBooking(ctx, booking, s.notifications.SendBookingRejection)
+	}
+	return nil
+}
+
+// notifyBooking sends a client notification, failures don't fail the request
+func (s *Server) notifyBooking(ctx context.Context, booking *database.Booking, send func(context.Context, *database.Booking) error) {
+	if s.notifications == nil {
+		return
+	}
+	if err := send(ctx, booking); err != nil {
+		slog.ErrorContext(ctx, "Failed to notify user about booking", "booking_id", booking.ID, "error", err)
+	}
+}
+
+// createDiscount saves a discount and announces it in the channel unless it's a draft
+func (s *Server) createDiscount(ctx context.Context, discount *database.Discount) (*database.Discount, error) {
+	created, err := s.discounts.CreateDiscount(ctx, discount.ServiceID, discount.Name, discount.Percentage, discount.StartDate, discount.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Created as a draft, nothing to announce yet
+	if !discount.IsActive {
+		created.IsActive = false
+		if err := s.discounts.UpdateDiscount(ctx, created); err != nil {
+			return nil, err
+		}
+		return created, nil
+	}
+
+	if s.notifications != nil {
+		if err := s.notifications.SendPromotionToChannel(ctx, created); err != nil {
+			slog.ErrorContext(ctx, "Failed to send promotion to channel", "discount_id", created.ID, "error", err)
+		}
+	}
+
+	return created, nil
+}
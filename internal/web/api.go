@@ -0,0 +1,247 @@
+// Package web contains the admin JSON API
+package web
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gobot/internal/database"
+	"gobot/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// APIPrefix is the path prefix of the admin API
+const APIPrefix = "/api/v1"
+
+// Pagination limits of list endpoints
+const (
+	apiDefaultLimit = 50
+	apiMaxLimit     = 200
+)
+
+// apiMaxBody limits request bodies
+const apiMaxBody = 1 << 20
+
+// apiDateLayout is the date format of the API
+const apiDateLayout = "2006-01-02"
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// apiKeyContextKey stores the authenticated key in the request context
+type apiKeyContextKey struct{}
+
+// apiList is the response of list endpoints
+type apiList[T any] struct {
+	Items  []T   `json:"items"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit,omitempty"`
+	Offset int   `json:"offset,omitempty"`
+}
+
+// apiError is the response of failed requests
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// registerAPI adds admin API routes to the mux
+func (s *Server) registerAPI(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+APIPrefix+"/openapi.yaml", handleOpenAPISpec)
+
+	routes := map[string]http.HandlerFunc{
+		"GET /services":              s.handleAPIListServices,
+		"POST /services":             s.handleAPICreateService,
+		"GET /services/{id}":         s.handleAPIGetService,
+		"PATCH /services/{id}":       s.handleAPIUpdateService,
+		"DELETE /services/{id}":      s.handleAPIDeleteService,
+		"GET /discounts":             s.handleAPIListDiscounts,
+		"POST /discounts":            s.handleAPICreateDiscount,
+		"GET /discounts/{id}":        s.handleAPIGetDiscount,
+		"PATCH /discounts/{id}":      s.handleAPIUpdateDiscount,
+		"DELETE /discounts/{id}":     s.handleAPIDeleteDiscount,
+		"GET /bookings":              s.handleAPIListBookings,
+		"POST /bookings":             s.handleAPICreateBooking,
+		"GET /bookings/{id}":         s.handleAPIGetBooking,
+		"PATCH /bookings/{id}":       s.handleAPIUpdateBooking,
+		"GET /bookings/{id}/history": s.handleAPIBookingHistory,
+		"GET /users":                 s.handleAPIListUsers,
+		"POST /users":                s.handleAPICreateUser,
+		"GET /users/{id}":            s.handleAPIGetUser,
+		"PATCH /users/{id}":          s.handleAPIUpdateUser,
+		"GET /schedule":              s.handleAPIGetSchedule,
+		"PUT /schedule":              s.handleAPISetSchedule,
+		"GET /blocked-dates":         s.handleAPIListBlockedDates,
+		"POST /blocked-dates":        s.handleAPICreateBlockedDate,
+		"DELETE /blocked-dates/{id}": s.handleAPIDeleteBlockedDate,
+		"GET /stats":                 s.handleAPIStats,
+	}
+	for route, handler := range routes {
+		method, path, _ := strings.Cut(route, " ")
+		mux.Handle(method+" "+APIPrefix+path, s.requireAPIKey(handler))
+	}
+}
+
+// requireAPIKey rejects requests without a valid API key
+// The key is passed as "Authorization: Bearer <key>" or "X-API-Key: <key>"
+func (s *Server) requireAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if auth := r.Header.Get("Authorization"); key == "" && auth != "" {
+			scheme, token, _ := strings.Cut(auth, " ")
+			if strings.EqualFold(scheme, "Bearer") {
+				key = strings.TrimSpace(token)
+			}
+		}
+		if key == "" {
+			writeAPIError(w, http.StatusUnauthorized, "API key is required")
+			return
+		}
+
+		apiKey, err := s.apiKeys.Authenticate(r.Context(), key)
+		if err != nil {
+			writeAPIError(w, http.StatusUnauthorized, "invalid API key")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), apiKeyContextKey{}, apiKey)
+		ctx = services.WithActor(ctx, services.APIKeyActor(apiKey))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requestAPIKey returns the key that authenticated the request
+func requestAPIKey(r *http.Request) *database.APIKey {
+	apiKey, _ := r.Context().Value(apiKeyContextKey{}).(*database.APIKey)
+	return apiKey
+}
+
+// handleOpenAPISpec serves the API description
+func handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(openAPISpec)
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		slog.Error("Failed to write API response", "error", err)
+	}
+}
+
+// writeAPIError writes an error response
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, apiError{Error: message})
+}
+
+// writeAPIServerError logs the error and hides its details from the client
+func writeAPIServerError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "API error", "method", r.Method, "path", r.URL.Path, "error", err)
+	writeAPIError(w, http.StatusInternalServerError, "internal error")
+}
+
+// writeAPILookupError writes 404 for missing records and 500 otherwise
+func writeAPILookupError(w http.ResponseWriter, r *http.Request, err error, what string) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeAPIError(w, http.StatusNotFound, what+" not found")
+		return
+	}
+	writeAPIServerError(w, r, err)
+}
+
+// decodeJSON reads the request body into value, unknown fields are rejected
+func decodeJSON(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// pathID parses the numeric {id} path parameter
+func pathID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid id")
+		return 0, false
+	}
+	return id, true
+}
+
+// pagination parses limit and offset query parameters
+func pagination(w http.ResponseWriter, r *http.Request) (limit, offset int, ok bool) {
+	limit = apiDefaultLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > apiMaxLimit {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", apiMaxLimit))
+			return 0, 0, false
+		}
+		limit = n
+	}
+	if value := r.URL.Query().Get("offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			writeAPIError(w, http.StatusBadRequest, "offset must not be negative")
+			return 0, 0, false
+		}
+		offset = n
+	}
+	return limit, offset, true
+}
+
+// queryBool parses an optional boolean query parameter
+func queryBool(w http.ResponseWriter, r *http.Request, name string) (*bool, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, true
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid "+name)
+		return nil, false
+	}
+	return &b, true
+}
+
+// parseAPIDate parses a YYYY-MM-DD date in the bot time zone
+func parseAPIDate(value string) (time.Time, error) {
+	return time.ParseInLocation(apiDateLayout, value, time.Local)
+}
+
+// queryDateRange parses optional from and to query dates, to is inclusive
+// Returns zero times for missing bounds
+func queryDateRange(w http.ResponseWriter, r *http.Request) (from, to time.Time, ok bool) {
+	var err error
+	if value := r.URL.Query().Get("from"); value != "" {
+		if from, err = parseAPIDate(value); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "from must be YYYY-MM-DD")
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	if value := r.URL.Query().Get("to"); value != "" {
+		if to, err = parseAPIDate(value); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "to must be YYYY-MM-DD")
+			return time.Time{}, time.Time{}, false
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		writeAPIError(w, http.StatusBadRequest, "to is before from")
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
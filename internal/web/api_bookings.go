@@ -0,0 +1,383 @@
+// Package web contains admin API handlers of bookings
+package web
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gobot/internal/database"
+	"gobot/internal/services"
+)
+
+// apiClient is the client of a booking in API responses
+type apiClient struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Username  string `json:"username"`
+	Phone     string `json:"phone"`
+}
+
+// apiBooking is a booking in API responses
+type apiBooking struct {
+	ID                uint                   `json:"id"`
+	Client            apiClient              `json:"client"`
+	ServiceID         uint                   `json:"service_id"`
+	ServiceName       string                 `json:"service_name"`
+	Duration          int                    `json:"duration"` // Minutes
+	Date              string                 `json:"date"`
+	Time              string                 `json:"time"`
+	Status            database.BookingStatus `json:"status"`
+	Price             int                    `json:"price"`              // Kopecks
+	CertificateAmount int                    `json:"certificate_amount"` // Kopecks paid with a gift certificate
+	Notes             string                 `json:"notes"`
+	CancelledAt       *time.Time             `json:"cancelled_at"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+}
+
+// apiBookingInput is the body of a booking create request
+type apiBookingInput struct {
+	UserID    int64  `json:"user_id"`
+	ServiceID uint   `json:"service_id"`
+	Date      string `json:"date"`
+	Time      string `json:"time"`
+	Notes     string `json:"notes"`
+}
+
+// apiBookingUpdate is the body of a booking update request
+// Date and time move the booking and must be set together
+type apiBookingUpdate struct {
+	Status *database.BookingStatus `json:"status"`
+	Reason string                  `json:"reason"` // Saved in the status history
+	Notes  *string                 `json:"notes"`
+	Date   *string                 `json:"date"`
+	Time   *string                 `json:"time"`
+}
+
+// apiStatusChange is a record of the booking status history
+type apiStatusChange struct {
+	From      database.BookingStatus `json:"from"`
+	To        database.BookingStatus `json:"to"`
+	Actor     database.ActorType     `json:"actor"`
+	ActorID   int64                  `json:"actor_id"`
+	ActorName string                 `json:"actor_name,omitempty"`
+	Reason    string                 `json:"reason,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// newAPIBooking converts a booking for API responses
+func newAPIBooking(booking *database.Booking) apiBooking {
+	return apiBooking{
+		ID: booking.ID,
+		Client: apiClient{
+			ID:        booking.User.ID,
+			FirstName: booking.User.FirstName,
+			LastName:  booking.User.LastName,
+			Username:  booking.User.Username,
+			Phone:     booking.User.Phone,
+		},
+		ServiceID:         booking.ServiceID,
+		ServiceName:       booking.Service.Name,
+		Duration:          booking.Service.Duration,
+		Date:              booking.Date.Format(apiDateLayout),
+		Time:              booking.Time,
+		Status:            booking.Status,
+		Price:             booking.Price,
+		CertificateAmount: booking.CertificateAmount,
+		Notes:             booking.Notes,
+		CancelledAt:       booking.CancelledAt,
+		CreatedAt:         booking.CreatedAt,
+		UpdatedAt:         booking.UpdatedAt,
+	}
+}
+
+// handleAPIListBookings lists bookings with filters and pagination
+// Filters: status, service_id, client, period=today|tomorrow|week or from and to dates
+func (s *Server) handleAPIListBookings(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+
+	filter := services.BookingFilter{
+		Status: database.BookingStatus(query.Get("status")),
+		Client: query.Get("client"),
+	}
+	if filter.Status != "" && !isBookingStatus(filter.Status) {
+		writeAPIError(w, http.StatusBadRequest, "invalid status")
+		return
+	}
+	if value := query.Get("service_id"); value != "" {
+		serviceID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid service_id")
+			return
+		}
+		filter.ServiceID = uint(serviceID)
+	}
+
+	switch period := query.Get("period"); period {
+	case "":
+	case services.BookingPeriodToday, services.BookingPeriodTomorrow, services.BookingPeriodWeek:
+		filter.Period = period
+	default:
+		writeAPIError(w, http.StatusBadRequest, "period must be today, tomorrow or week")
+		return
+	}
+
+	from, to, ok := queryDateRange(w, r)
+	if !ok {
+		return
+	}
+	if !from.IsZero() || !to.IsZero() {
+		if from.IsZero() || to.IsZero() {
+			writeAPIError(w, http.StatusBadRequest, "from and to must be set together")
+			return
+		}
+		filter.Period = services.BookingPeriodCustom
+		filter.DateFrom = from
+		filter.DateTo = to
+	}
+
+	bookings, total, err := s.admin.ListBookings(r.Context(), filter, limit, offset)
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	items := make([]apiBooking, 0, len(bookings))
+	for i := range bookings {
+		items = append(items, newAPIBooking(&bookings[i]))
+	}
+
+	writeJSON(w, http.StatusOK, apiList[apiBooking]{Items: items, Total: total, Limit: limit, Offset: offset})
+}
+
+// handleAPICreateBooking creates a confirmed booking like admin does in the bot
+func (s *Server) handleAPICreateBooking(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var in apiBookingInput
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	if in.UserID == 0 || in.ServiceID == 0 || in.Date == "" || in.Time == "" {
+		writeAPIError(w, http.StatusBadRequest, "user_id, service_id, date and time are required")
+		return
+	}
+	date, err := parseAPIDate(in.Date)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "date must be YYYY-MM-DD")
+		return
+	}
+	if _, err := time.Parse("15:04", in.Time); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "time must be HH:MM")
+		return
+	}
+
+	if _, err := s.users.GetUser(ctx, in.UserID); err != nil {
+		writeAPILookupError(w, r, err, "user")
+		return
+	}
+	service, err := s.admin.GetServiceByID(ctx, in.ServiceID)
+	if err != nil {
+		writeAPILookupError(w, r, err, "service")
+		return
+	}
+	if !service.IsActive {
+		writeAPIError(w, http.StatusConflict, "service is not active")
+		return
+	}
+
+	if services.IsTimeSlotTaken(ctx, in.Time, date, service.Duration, 0) {
+		writeAPIError(w, http.StatusConflict, services.ErrSlotTaken.Error())
+		return
+	}
+
+	booking, err := s.bookings.CreateBooking(ctx, in.UserID, in.ServiceID, date, in.Time)
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	// The time is agreed with the client already
+	if err := s.admin.UpdateBookingStatus(ctx, booking.ID, database.BookingStatusConfirmed, services.ByAPIKey(requestAPIKey(r))); err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+	booking.Status = database.BookingStatusConfirmed
+
+	if in.Notes != "" {
+		if err := s.admin.UpdateBookingNotes(ctx, booking.ID, in.Notes); err != nil {
+			writeAPIServerError(w, r, err)
+			return
+		}
+	}
+
+	if !booking.User.IsOffline {
+		s.notifyBooking(ctx, booking, s.notifications.SendBookingConfirmation)
+	}
+	slog.InfoContext(r.Context(), "Booking created with API key", "booking_id", booking.ID, "api_key", requestAPIKey(r).Name)
+
+	s.writeBooking(w, r, booking.ID, http.StatusCreated)
+}
+
+// handleAPIGetBooking returns a booking
+func (s *Server) handleAPIGetBooking(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	s.writeBooking(w, r, uint(id), http.StatusOK)
+}
+
+// handleAPIBookingHistory returns status changes of a booking, oldest first
+func (s *Server) handleAPIBookingHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if _, err := s.admin.GetBookingByID(ctx, uint(id)); err != nil {
+		writeAPILookupError(w, r, err, "booking")
+		return
+	}
+
+	history, err := s.admin.GetBookingHistory(ctx, uint(id))
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	items := make([]apiStatusChange, 0, len(history))
+	for _, h := range history {
+		items = append(items, apiStatusChange{
+			From:      h.FromStatus,
+			To:        h.ToStatus,
+			Actor:     h.Actor,
+			ActorID:   h.ActorID,
+			ActorName: h.ActorName,
+			Reason:    h.Reason,
+			CreatedAt: h.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, apiList[apiStatusChange]{Items: items, Total: int64(len(items))})
+}
+
+// handleAPIUpdateBooking changes notes, time or status of a booking
+// Clients are notified about confirmation, cancellation and new time like in the bot
+func (s *Server) handleAPIUpdateBooking(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var in apiBookingUpdate
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	if (in.Date == nil) != (in.Time == nil) {
+		writeAPIError(w, http.StatusBadRequest, "date and time must be set together")
+		return
+	}
+	if in.Status != nil && !isBookingStatus(*in.Status) {
+		writeAPIError(w, http.StatusBadRequest, "invalid status")
+		return
+	}
+
+	booking, err := s.admin.GetBookingByID(ctx, uint(id))
+	if err != nil {
+		writeAPILookupError(w, r, err, "booking")
+		return
+	}
+
+	change := services.ByAPIKey(requestAPIKey(r))
+	change.Reason = in.Reason
+
+	// Validate everything before changing anything
+	var date time.Time
+	if in.Date != nil {
+		if date, err = parseAPIDate(*in.Date); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "date must be YYYY-MM-DD")
+			return
+		}
+		if _, err := time.Parse("15:04", *in.Time); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "time must be HH:MM")
+			return
+		}
+		if err := checkBookingMove(ctx, booking, date, *in.Time); err != nil {
+			writeAPIError(w, http.StatusConflict, err.Error())
+			return
+		}
+	}
+	if in.Status != nil && *in.Status != booking.Status {
+		if err := checkBookingStatus(booking, *in.Status, change); err != nil {
+			writeAPIError(w, http.StatusConflict, err.Error())
+			return
+		}
+	}
+
+	if in.Notes != nil {
+		if err := s.admin.UpdateBookingNotes(ctx, booking.ID, *in.Notes); err != nil {
+			writeAPIServerError(w, r, err)
+			return
+		}
+	}
+
+	if in.Date != nil {
+		if err := s.moveBooking(ctx, booking, date, *in.Time); err != nil {
+			if errors.Is(err, services.ErrBookingNotMovable) {
+				writeAPIError(w, http.StatusConflict, err.Error())
+				return
+			}
+			writeAPIServerError(w, r, err)
+			return
+		}
+	}
+
+	if in.Status != nil && *in.Status != booking.Status {
+		if err := s.setBookingStatus(ctx, booking, *in.Status, change); err != nil {
+			if errors.Is(err, services.ErrBookingTransition) {
+				writeAPIError(w, http.StatusConflict, err.Error())
+				return
+			}
+			writeAPIServerError(w, r, err)
+			return
+		}
+		slog.InfoContext(r.Context(), "Booking status set with API key", "booking_id", booking.ID, "status", booking.Status, "api_key", requestAPIKey(r).Name)
+	}
+
+	s.writeBooking(w, r, booking.ID, http.StatusOK)
+}
+
+// writeBooking responds with the current state of a booking
+func (s *Server) writeBooking(w http.ResponseWriter, r *http.Request, bookingID uint, status int) {
+	booking, err := s.admin.GetBookingByID(r.Context(), bookingID)
+	if err != nil {
+		writeAPILookupError(w, r, err, "booking")
+		return
+	}
+	writeJSON(w, status, newAPIBooking(booking))
+}
+
+// isBookingStatus reports whether the status is known
+func isBookingStatus(status database.BookingStatus) bool {
+	switch status {
+	case database.BookingStatusPending,
+		database.BookingStatusConfirmed,
+		database.BookingStatusCancelled,
+		database.BookingStatusCompleted,
+		database.BookingStatusNoShow,
+		database.BookingStatusRejected:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,434 @@
+// Package web contains admin API handlers of services and discounts
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gobot/internal/database"
+)
+
+// apiService is a service in API responses
+type apiService struct {
+	ID                  uint      `json:"id"`
+	Name                string    `json:"name"`
+	Description         string    `json:"description"`
+	DetailedDescription string    `json:"detailed_description"`
+	Duration            int       `json:"duration"` // Minutes
+	Price               int       `json:"price"`    // Kopecks
+	IsActive            bool      `json:"is_active"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// apiServiceInput is the body of service create and update requests
+// Missing fields are left unchanged on update
+type apiServiceInput struct {
+	Name                *string `json:"name"`
+	Description         *string `json:"description"`
+	DetailedDescription *string `json:"detailed_description"`
+	Duration            *int    `json:"duration"`
+	Price               *int    `json:"price"`
+	IsActive            *bool   `json:"is_active"`
+}
+
+// apiDiscount is a discount in API responses
+type apiDiscount struct {
+	ID          uint      `json:"id"`
+	ServiceID   uint      `json:"service_id"`
+	ServiceName string    `json:"service_name"`
+	Name        string    `json:"name"`
+	Percentage  int       `json:"percentage"`
+	StartDate   string    `json:"start_date"`
+	EndDate     string    `json:"end_date"` // Inclusive
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// apiDiscountInput is the body of discount create and update requests
+// Missing fields are left unchanged on update
+type apiDiscountInput struct {
+	ServiceID  *uint   `json:"service_id"`
+	Name       *string `json:"name"`
+	Percentage *int    `json:"percentage"`
+	StartDate  *string `json:"start_date"`
+	EndDate    *string `json:"end_date"`
+	IsActive   *bool   `json:"is_active"`
+}
+
+// newAPIService converts a service for API responses
+func newAPIService(service *database.Service) apiService {
+	return apiService{
+		ID:                  service.ID,
+		Name:                service.Name,
+		Description:         service.Description,
+		DetailedDescription: service.DetailedDescription,
+		Duration:            service.Duration,
+		Price:               service.Price,
+		IsActive:            service.IsActive,
+		CreatedAt:           service.CreatedAt,
+		UpdatedAt:           service.UpdatedAt,
+	}
+}
+
+// newAPIDiscount converts a discount for API responses
+func newAPIDiscount(discount *database.Discount) apiDiscount {
+	return apiDiscount{
+		ID:          discount.ID,
+		ServiceID:   discount.ServiceID,
+		ServiceName: discount.Service.Name,
+		Name:        discount.Name,
+		Percentage:  discount.Percentage,
+		StartDate:   discount.StartDate.Format(apiDateLayout),
+		EndDate:     discount.EndDate.Format(apiDateLayout),
+		IsActive:    discount.IsActive,
+		CreatedAt:   discount.CreatedAt,
+	}
+}
+
+// validate checks fields of the service input, required ones must be set on create
+func (in *apiServiceInput) validate(create bool) string {
+	if create && (in.Name == nil || in.Duration == nil || in.Price == nil) {
+		return "name, duration and price are required"
+	}
+	if in.Name != nil && strings.TrimSpace(*in.Name) == "" {
+		return "name must not be empty"
+	}
+	if in.Duration != nil && *in.Duration <= 0 {
+		return "duration must be positive"
+	}
+	if in.Price != nil && *in.Price < 0 {
+		return "price must not be negative"
+	}
+	return ""
+}
+
+// handleAPIListServices lists services, ?active=true|false filters by status
+func (s *Server) handleAPIListServices(w http.ResponseWriter, r *http.Request) {
+	active, ok := queryBool(w, r, "active")
+	if !ok {
+		return
+	}
+
+	services, err := s.admin.GetAllServices(r.Context())
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	items := make([]apiService, 0, len(services))
+	for i := range services {
+		if active != nil && services[i].IsActive != *active {
+			continue
+		}
+		items = append(items, newAPIService(&services[i]))
+	}
+
+	writeJSON(w, http.StatusOK, apiList[apiService]{Items: items, Total: int64(len(items))})
+}
+
+// handleAPICreateService creates an active service
+func (s *Server) handleAPICreateService(w http.ResponseWriter, r *http.Request) {
+	var in apiServiceInput
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	if msg := in.validate(true); msg != "" {
+		writeAPIError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	description := ""
+	if in.Description != nil {
+		description = *in.Description
+	}
+
+	service, err := s.admin.CreateService(r.Context(), strings.TrimSpace(*in.Name), description, *in.Duration, *in.Price)
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	// Optional fields the bot sets after creation too
+	in.Name, in.Description, in.Duration, in.Price = nil, nil, nil, nil
+	if err := s.updateService(r, service.ID, &in); err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	s.writeService(w, r, service.ID, http.StatusCreated)
+}
+
+// handleAPIGetService returns a service
+func (s *Server) handleAPIGetService(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	s.writeService(w, r, uint(id), http.StatusOK)
+}
+
+// handleAPIUpdateService changes fields of a service
+func (s *Server) handleAPIUpdateService(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var in apiServiceInput
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	if msg := in.validate(false); msg != "" {
+		writeAPIError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	if _, err := s.admin.GetServiceByID(r.Context(), uint(id)); err != nil {
+		writeAPILookupError(w, r, err, "service")
+		return
+	}
+
+	if err := s.updateService(r, uint(id), &in); err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	s.writeService(w, r, uint(id), http.StatusOK)
+}
+
+// handleAPIDeleteService deletes a service, its bookings are kept
+func (s *Server) handleAPIDeleteService(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := s.admin.GetServiceByID(r.Context(), uint(id)); err != nil {
+		writeAPILookupError(w, r, err, "service")
+		return
+	}
+
+	if err := s.admin.DeleteService(r.Context(), uint(id)); err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// updateService saves the fields set in the input
+func (s *Server) updateService(r *http.Request, serviceID uint, in *apiServiceInput) error {
+	fields := map[string]interface{}{}
+	if in.Name != nil {
+		fields["name"] = strings.TrimSpace(*in.Name)
+	}
+	if in.Description != nil {
+		fields["description"] = *in.Description
+	}
+	if in.DetailedDescription != nil {
+		fields["detailed_description"] = *in.DetailedDescription
+	}
+	if in.Duration != nil {
+		fields["duration"] = *in.Duration
+	}
+	if in.Price != nil {
+		fields["price"] = *in.Price
+	}
+	if in.IsActive != nil {
+		fields["is_active"] = *in.IsActive
+	}
+
+	for field, value := range fields {
+		if err := s.admin.UpdateServiceField(r.Context(), serviceID, field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeService responds with the current state of a service
+func (s *Server) writeService(w http.ResponseWriter, r *http.Request, serviceID uint, status int) {
+	service, err := s.admin.GetServiceByID(r.Context(), serviceID)
+	if err != nil {
+		writeAPILookupError(w, r, err, "service")
+		return
+	}
+	writeJSON(w, status, newAPIService(service))
+}
+
+// handleAPIListDiscounts lists discounts, filtered by ?service_id= and ?active=true|false
+func (s *Server) handleAPIListDiscounts(w http.ResponseWriter, r *http.Request) {
+	active, ok := queryBool(w, r, "active")
+	if !ok {
+		return
+	}
+	var serviceID uint64
+	if value := r.URL.Query().Get("service_id"); value != "" {
+		var err error
+		if serviceID, err = strconv.ParseUint(value, 10, 32); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid service_id")
+			return
+		}
+	}
+
+	discounts, err := s.discounts.GetAllDiscounts(r.Context())
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	items := make([]apiDiscount, 0, len(discounts))
+	for i := range discounts {
+		if active != nil && discounts[i].IsActive != *active {
+			continue
+		}
+		if serviceID != 0 && discounts[i].ServiceID != uint(serviceID) {
+			continue
+		}
+		items = append(items, newAPIDiscount(&discounts[i]))
+	}
+
+	writeJSON(w, http.StatusOK, apiList[apiDiscount]{Items: items, Total: int64(len(items))})
+}
+
+// handleAPICreateDiscount creates a discount and announces it in the channel like the bot does
+func (s *Server) handleAPICreateDiscount(w http.ResponseWriter, r *http.Request) {
+	var in apiDiscountInput
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	if in.ServiceID == nil || in.Name == nil || in.Percentage == nil || in.StartDate == nil || in.EndDate == nil {
+		writeAPIError(w, http.StatusBadRequest, "service_id, name, percentage, start_date and end_date are required")
+		return
+	}
+
+	discount := &database.Discount{ServiceID: *in.ServiceID, IsActive: true}
+	if msg := applyDiscountInput(discount, &in); msg != "" {
+		writeAPIError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	if _, err := s.admin.GetServiceByID(r.Context(), discount.ServiceID); err != nil {
+		writeAPILookupError(w, r, err, "service")
+		return
+	}
+
+	created, err := s.createDiscount(r.Context(), discount)
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newAPIDiscount(created))
+}
+
+// handleAPIGetDiscount returns a discount
+func (s *Server) handleAPIGetDiscount(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	discount, err := s.discounts.GetDiscountByID(r.Context(), uint(id))
+	if err != nil {
+		writeAPILookupError(w, r, err, "discount")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAPIDiscount(discount))
+}
+
+// handleAPIUpdateDiscount changes fields of a discount, the service can't be changed
+func (s *Server) handleAPIUpdateDiscount(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var in apiDiscountInput
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	if in.ServiceID != nil {
+		writeAPIError(w, http.StatusBadRequest, "service_id can't be changed, create a new discount")
+		return
+	}
+
+	discount, err := s.discounts.GetDiscountByID(r.Context(), uint(id))
+	if err != nil {
+		writeAPILookupError(w, r, err, "discount")
+		return
+	}
+	if msg := applyDiscountInput(discount, &in); msg != "" {
+		writeAPIError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	if err := s.discounts.UpdateDiscount(r.Context(), discount); err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAPIDiscount(discount))
+}
+
+// handleAPIDeleteDiscount deletes a discount
+func (s *Server) handleAPIDeleteDiscount(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := s.discounts.GetDiscountByID(r.Context(), uint(id)); err != nil {
+		writeAPILookupError(w, r, err, "discount")
+		return
+	}
+
+	if err := s.discounts.DeleteDiscount(r.Context(), uint(id)); err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyDiscountInput copies set fields to the discount and validates the result
+// The discount lasts until the end of its last day, as in the bot
+func applyDiscountInput(discount *database.Discount, in *apiDiscountInput) string {
+	if in.Name != nil {
+		discount.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Percentage != nil {
+		discount.Percentage = *in.Percentage
+	}
+	if in.StartDate != nil {
+		date, err := parseAPIDate(*in.StartDate)
+		if err != nil {
+			return "start_date must be YYYY-MM-DD"
+		}
+		discount.StartDate = date
+	}
+	if in.EndDate != nil {
+		date, err := parseAPIDate(*in.EndDate)
+		if err != nil {
+			return "end_date must be YYYY-MM-DD"
+		}
+		discount.EndDate = time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
+	}
+	if in.IsActive != nil {
+		discount.IsActive = *in.IsActive
+	}
+
+	switch {
+	case discount.Name == "":
+		return "name must not be empty"
+	case discount.Percentage < 1 || discount.Percentage > 100:
+		return "percentage must be between 1 and 100"
+	case discount.EndDate.Before(discount.StartDate):
+		return "end_date is before start_date"
+	}
+	return ""
+}
@@ -0,0 +1,247 @@
+// Package web contains admin API handlers of the schedule and statistics
+package web
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gobot/internal/database"
+	"gobot/internal/services"
+)
+
+// apiWorkInterval is working hours of a weekday
+type apiWorkInterval struct {
+	DayOfWeek int    `json:"day_of_week"` // 0 is Sunday
+	StartTime string `json:"start_time"`  // HH:MM
+	EndTime   string `json:"end_time"`    // HH:MM
+	IsActive  bool   `json:"is_active"`
+}
+
+// apiBlockedDate is a day off
+type apiBlockedDate struct {
+	ID     uint   `json:"id"`
+	Date   string `json:"date"`
+	Reason string `json:"reason"`
+}
+
+// apiBlockedDateInput is the body of a day off create request
+type apiBlockedDateInput struct {
+	Date   string `json:"date"`
+	Reason string `json:"reason"`
+}
+
+// apiReport is the business report of a period
+type apiReport struct {
+	From string `json:"from"`
+	To   string `json:"to"` // Inclusive
+
+	Bookings  int64 `json:"bookings"`
+	Completed int64 `json:"completed"`
+	Cancelled int64 `json:"cancelled"`
+	NoShow    int64 `json:"no_show"`
+	Upcoming  int64 `json:"upcoming"`
+
+	Revenue      int `json:"revenue"`       // Kopecks
+	AverageCheck int `json:"average_check"` // Kopecks
+
+	CancellationRate float64 `json:"cancellation_rate"` // Percent
+	NoShowRate       float64 `json:"no_show_rate"`      // Percent
+
+	BookedMinutes  int     `json:"booked_minutes"`
+	WorkingMinutes int     `json:"working_minutes"`
+	Utilization    float64 `json:"utilization"` // Percent
+
+	NewClients       int64 `json:"new_clients"`
+	ReturningClients int64 `json:"returning_clients"`
+
+	Services []apiServiceReport `json:"services"`
+
+	Previous *apiReport `json:"previous,omitempty"`
+}
+
+// apiServiceReport holds numbers of a single service
+type apiServiceReport struct {
+	ServiceID uint   `json:"service_id"`
+	Name      string `json:"name"`
+	Bookings  int64  `json:"bookings"`
+	Completed int64  `json:"completed"`
+	Revenue   int    `json:"revenue"` // Kopecks
+}
+
+// newAPIReport converts a report for API responses
+func newAPIReport(report *services.Report) *apiReport {
+	result := &apiReport{
+		From:             report.Period.From.Format(apiDateLayout),
+		To:               report.Period.To.AddDate(0, 0, -1).Format(apiDateLayout),
+		Bookings:         report.Bookings,
+		Completed:        report.Completed,
+		Cancelled:        report.Cancelled,
+		NoShow:           report.NoShow,
+		Upcoming:         report.Upcoming,
+		Revenue:          report.Revenue,
+		AverageCheck:     report.AverageCheck,
+		CancellationRate: report.CancellationRate,
+		NoShowRate:       report.NoShowRate,
+		BookedMinutes:    report.BookedMinutes,
+		WorkingMinutes:   report.WorkingMinutes,
+		Utilization:      report.Utilization,
+		NewClients:       report.NewClients,
+		ReturningClients: report.ReturningClients,
+		Services:         make([]apiServiceReport, 0, len(report.Services)),
+	}
+	for _, service := range report.Services {
+		result.Services = append(result.Services, apiServiceReport(service))
+	}
+	return result
+}
+
+// handleAPIGetSchedule returns working hours
+func (s *Server) handleAPIGetSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := s.schedule.GetSchedule(r.Context())
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	items := make([]apiWorkInterval, 0, len(schedule))
+	for _, day := range schedule {
+		items = append(items, apiWorkInterval{
+			DayOfWeek: day.DayOfWeek,
+			StartTime: day.StartTime,
+			EndTime:   day.EndTime,
+			IsActive:  day.IsActive,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, apiList[apiWorkInterval]{Items: items, Total: int64(len(items))})
+}
+
+// handleAPISetSchedule replaces working hours with the intervals in the body
+func (s *Server) handleAPISetSchedule(w http.ResponseWriter, r *http.Request) {
+	var in []apiWorkInterval
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+
+	schedule := make([]database.WorkSchedule, 0, len(in))
+	for _, day := range in {
+		schedule = append(schedule, database.WorkSchedule{
+			DayOfWeek: day.DayOfWeek,
+			StartTime: day.StartTime,
+			EndTime:   day.EndTime,
+			IsActive:  day.IsActive,
+		})
+	}
+
+	if err := s.schedule.SetSchedule(r.Context(), schedule); err != nil {
+		if errors.Is(err, services.ErrInvalidSchedule) {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	s.handleAPIGetSchedule(w, r)
+}
+
+// handleAPIListBlockedDates lists days off, optionally within from and to dates
+func (s *Server) handleAPIListBlockedDates(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := queryDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	dates, err := s.schedule.GetBlockedDates(r.Context(), from, to)
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	items := make([]apiBlockedDate, 0, len(dates))
+	for _, date := range dates {
+		items = append(items, apiBlockedDate{ID: date.ID, Date: date.Date.Format(apiDateLayout), Reason: date.Reason})
+	}
+
+	writeJSON(w, http.StatusOK, apiList[apiBlockedDate]{Items: items, Total: int64(len(items))})
+}
+
+// handleAPICreateBlockedDate adds a day off
+func (s *Server) handleAPICreateBlockedDate(w http.ResponseWriter, r *http.Request) {
+	var in apiBlockedDateInput
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	date, err := parseAPIDate(in.Date)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "date must be YYYY-MM-DD")
+		return
+	}
+
+	blocked, err := s.schedule.AddBlockedDate(r.Context(), date, in.Reason)
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, apiBlockedDate{ID: blocked.ID, Date: blocked.Date.Format(apiDateLayout), Reason: blocked.Reason})
+}
+
+// handleAPIDeleteBlockedDate removes a day off
+func (s *Server) handleAPIDeleteBlockedDate(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.schedule.DeleteBlockedDate(r.Context(), uint(id)); err != nil {
+		writeAPIError(w, http.StatusNotFound, "blocked date not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAPIStats returns the report of a period compared with the previous one
+// The period is period=day|week|month (current by default) or from and to dates
+func (s *Server) handleAPIStats(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := queryDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	var period services.ReportPeriod
+	switch kind := r.URL.Query().Get("period"); {
+	case !from.IsZero() || !to.IsZero():
+		if from.IsZero() || to.IsZero() {
+			writeAPIError(w, http.StatusBadRequest, "from and to must be set together")
+			return
+		}
+		period = services.ReportPeriod{Kind: services.ReportPeriodCustom, From: from, To: to}
+	case kind == "" || kind == services.ReportPeriodDay || kind == services.ReportPeriodWeek || kind == services.ReportPeriodMonth:
+		if kind == "" {
+			kind = services.ReportPeriodMonth
+		}
+		period = services.NewReportPeriod(kind, time.Now())
+	default:
+		writeAPIError(w, http.StatusBadRequest, "period must be day, week or month")
+		return
+	}
+
+	report, err := s.reports.Build(r.Context(), period)
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+	previous, err := s.reports.Build(r.Context(), period.Previous())
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	result := newAPIReport(report)
+	result.Previous = newAPIReport(previous)
+
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,394 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gobot/internal/database"
+)
+
+func TestAPIAuth(t *testing.T) {
+	s, key := newTestServer(t)
+
+	revokedKey, revoked, err := s.apiKeys.CreateKey(context.Background(), "revoked", 1)
+	if err != nil {
+		t.Fatalf("failed to create API key: %v", err)
+	}
+	if err := s.apiKeys.RevokeKey(context.Background(), revoked.ID); err != nil {
+		t.Fatalf("failed to revoke API key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{name: "missing key", want: http.StatusUnauthorized},
+		{name: "empty bearer", headers: map[string]string{"Authorization": "Bearer "}, want: http.StatusUnauthorized},
+		{name: "basic auth", headers: map[string]string{"Authorization": "Basic " + key}, want: http.StatusUnauthorized},
+		{name: "invalid key", headers: map[string]string{"Authorization": "Bearer " + key + "x"}, want: http.StatusUnauthorized},
+		{name: "invalid prefix", headers: map[string]string{"X-API-Key": "secret"}, want: http.StatusUnauthorized},
+		{name: "revoked key", headers: map[string]string{"Authorization": "Bearer " + revokedKey}, want: http.StatusUnauthorized},
+		{name: "bearer", headers: map[string]string{"Authorization": "Bearer " + key}, want: http.StatusOK},
+		{name: "lowercase bearer", headers: map[string]string{"Authorization": "bearer " + key}, want: http.StatusOK},
+		{name: "header", headers: map[string]string{"X-API-Key": key}, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, APIPrefix+"/services", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			s.http.Handler.ServeHTTP(rec, req)
+			expectStatus(t, rec, tt.want)
+
+			if tt.want == http.StatusUnauthorized {
+				var body apiError
+				decodeResponse(t, rec, &body)
+				if body.Error == "" {
+					t.Error("error message is empty")
+				}
+			}
+		})
+	}
+
+	// The spec is public
+	rec := apiRequest(t, s, "", http.MethodGet, "/openapi.yaml", "")
+	expectStatus(t, rec, http.StatusOK)
+}
+
+func TestAPIQueryValidation(t *testing.T) {
+	s, key := newTestServer(t)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "/bookings", want: http.StatusOK},
+		{path: "/bookings?limit=1&offset=5", want: http.StatusOK},
+		{path: "/bookings?limit=200", want: http.StatusOK},
+		{path: "/bookings?limit=0", want: http.StatusBadRequest},
+		{path: "/bookings?limit=201", want: http.StatusBadRequest},
+		{path: "/bookings?limit=ten", want: http.StatusBadRequest},
+		{path: "/bookings?offset=-1", want: http.StatusBadRequest},
+		{path: "/bookings?status=confirmed&service_id=1&client=anna", want: http.StatusOK},
+		{path: "/bookings?status=done", want: http.StatusBadRequest},
+		{path: "/bookings?service_id=massage", want: http.StatusBadRequest},
+		{path: "/bookings?period=week", want: http.StatusOK},
+		{path: "/bookings?period=month", want: http.StatusBadRequest},
+		{path: "/bookings?from=2026-01-01&to=2026-01-31", want: http.StatusOK},
+		{path: "/bookings?from=2026-01-01&to=2026-01-01", want: http.StatusOK},
+		{path: "/bookings?from=2026-01-01", want: http.StatusBadRequest},
+		{path: "/bookings?from=01.01.2026&to=2026-01-31", want: http.StatusBadRequest},
+		{path: "/bookings?from=2026-01-31&to=2026-01-01", want: http.StatusBadRequest},
+		{path: "/users?q=anna&blocked=false&offline=true", want: http.StatusOK},
+		{path: "/users?blocked=maybe", want: http.StatusBadRequest},
+		{path: "/users?limit=-5", want: http.StatusBadRequest},
+		{path: "/services?active=yes", want: http.StatusBadRequest},
+		{path: "/discounts?service_id=-1", want: http.StatusBadRequest},
+		{path: "/services/abc", want: http.StatusBadRequest},
+		{path: "/bookings/1.5", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := apiRequest(t, s, key, http.MethodGet, tt.path, "")
+			expectStatus(t, rec, tt.want)
+		})
+	}
+}
+
+func TestAPIPagination(t *testing.T) {
+	s, key := newTestServer(t)
+	for i := 0; i < 3; i++ {
+		rec := apiRequest(t, s, key, http.MethodPost, "/users", fmt.Sprintf(`{"first_name":"Client %d"}`, i))
+		expectStatus(t, rec, http.StatusCreated)
+	}
+
+	tests := []struct {
+		path      string
+		wantItems int
+	}{
+		{path: "/users", wantItems: 3},
+		{path: "/users?limit=2", wantItems: 2},
+		{path: "/users?limit=2&offset=2", wantItems: 1},
+		{path: "/users?offset=3", wantItems: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := apiRequest(t, s, key, http.MethodGet, tt.path, "")
+			expectStatus(t, rec, http.StatusOK)
+
+			var list apiList[apiUser]
+			decodeResponse(t, rec, &list)
+			if len(list.Items) != tt.wantItems || list.Total != 3 {
+				t.Errorf("got %d items of %d, want %d of 3", len(list.Items), list.Total, tt.wantItems)
+			}
+		})
+	}
+}
+
+func TestAPIServiceCRUD(t *testing.T) {
+	s, key := newTestServer(t)
+
+	rec := apiRequest(t, s, key, http.MethodPost, "/services", `{"name":" Massage ","duration":60,"price":250000,"detailed_description":"Full body"}`)
+	expectStatus(t, rec, http.StatusCreated)
+	var created apiService
+	decodeResponse(t, rec, &created)
+	if created.Name != "Massage" || created.DetailedDescription != "Full body" || !created.IsActive {
+		t.Fatalf("created service = %+v", created)
+	}
+	path := fmt.Sprintf("/services/%d", created.ID)
+
+	rec = apiRequest(t, s, key, http.MethodPatch, path, `{"price":300000,"is_active":false}`)
+	expectStatus(t, rec, http.StatusOK)
+	var updated apiService
+	decodeResponse(t, rec, &updated)
+	if updated.Price != 300000 || updated.IsActive || updated.Name != "Massage" {
+		t.Errorf("updated service = %+v", updated)
+	}
+
+	rec = apiRequest(t, s, key, http.MethodGet, "/services?active=false", "")
+	expectStatus(t, rec, http.StatusOK)
+	var list apiList[apiService]
+	decodeResponse(t, rec, &list)
+	if list.Total != 1 || list.Items[0].ID != created.ID {
+		t.Errorf("inactive services = %+v", list)
+	}
+
+	rec = apiRequest(t, s, key, http.MethodDelete, path, "")
+	expectStatus(t, rec, http.StatusNoContent)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{name: "get deleted", method: http.MethodGet, path: path, want: http.StatusNotFound},
+		{name: "update deleted", method: http.MethodPatch, path: path, body: `{"price":1}`, want: http.StatusNotFound},
+		{name: "delete deleted", method: http.MethodDelete, path: path, want: http.StatusNotFound},
+		{name: "create without price", method: http.MethodPost, path: "/services", body: `{"name":"Spa","duration":60}`, want: http.StatusBadRequest},
+		{name: "create with empty name", method: http.MethodPost, path: "/services", body: `{"name":" ","duration":60,"price":1}`, want: http.StatusBadRequest},
+		{name: "unknown field", method: http.MethodPost, path: "/services", body: `{"name":"Spa","duration":60,"price":1,"color":"red"}`, want: http.StatusBadRequest},
+		{name: "invalid JSON", method: http.MethodPost, path: "/services", body: `{"name":`, want: http.StatusBadRequest},
+		{name: "discount of a missing service", method: http.MethodPost, path: "/discounts", body: fmt.Sprintf(`{"service_id":%d,"name":"Spring","percentage":10,"start_date":"2026-03-01","end_date":"2026-03-31"}`, created.ID), want: http.StatusNotFound},
+		{name: "missing discount", method: http.MethodGet, path: "/discounts/999", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiRequest(t, s, key, tt.method, tt.path, tt.body)
+			expectStatus(t, rec, tt.want)
+		})
+	}
+}
+
+func TestAPIDiscountCRUD(t *testing.T) {
+	s, key := newTestServer(t)
+
+	rec := apiRequest(t, s, key, http.MethodPost, "/services", `{"name":"Massage","duration":60,"price":250000}`)
+	expectStatus(t, rec, http.StatusCreated)
+	var service apiService
+	decodeResponse(t, rec, &service)
+
+	rec = apiRequest(t, s, key, http.MethodPost, "/discounts", fmt.Sprintf(`{"service_id":%d,"name":"Spring","percentage":10,"start_date":"2026-03-01","end_date":"2026-03-31"}`, service.ID))
+	expectStatus(t, rec, http.StatusCreated)
+	var created apiDiscount
+	decodeResponse(t, rec, &created)
+	if created.ServiceName != "Massage" || created.EndDate != "2026-03-31" {
+		t.Fatalf("created discount = %+v", created)
+	}
+	path := fmt.Sprintf("/discounts/%d", created.ID)
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		want   int
+	}{
+		{name: "invalid percentage", method: http.MethodPatch, body: `{"percentage":150}`, want: http.StatusBadRequest},
+		{name: "end before start", method: http.MethodPatch, body: `{"end_date":"2026-02-01"}`, want: http.StatusBadRequest},
+		{name: "change service", method: http.MethodPatch, body: `{"service_id":1}`, want: http.StatusBadRequest},
+		{name: "update", method: http.MethodPatch, body: `{"percentage":20}`, want: http.StatusOK},
+		{name: "get", method: http.MethodGet, want: http.StatusOK},
+		{name: "delete", method: http.MethodDelete, want: http.StatusNoContent},
+		{name: "get deleted", method: http.MethodGet, want: http.StatusNotFound},
+		{name: "update deleted", method: http.MethodPatch, body: `{"percentage":30}`, want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiRequest(t, s, key, tt.method, path, tt.body)
+			expectStatus(t, rec, tt.want)
+		})
+	}
+}
+
+func TestAPIBookingCRUD(t *testing.T) {
+	s, key := newTestServer(t)
+	createTestUser(t, 1001)
+
+	rec := apiRequest(t, s, key, http.MethodPost, "/services", `{"name":"Massage","duration":60,"price":250000}`)
+	expectStatus(t, rec, http.StatusCreated)
+	var service apiService
+	decodeResponse(t, rec, &service)
+
+	date := time.Now().AddDate(0, 0, 3).Format(apiDateLayout)
+	body := fmt.Sprintf(`{"user_id":1001,"service_id":%d,"date":"%s","time":"12:00","notes":"first visit"}`, service.ID, date)
+	rec = apiRequest(t, s, key, http.MethodPost, "/bookings", body)
+	expectStatus(t, rec, http.StatusCreated)
+	var created apiBooking
+	decodeResponse(t, rec, &created)
+	if created.Status != database.BookingStatusConfirmed || created.Client.ID != 1001 || created.Price != 250000 || created.Notes != "first visit" {
+		t.Fatalf("created booking = %+v", created)
+	}
+	path := fmt.Sprintf("/bookings/%d", created.ID)
+
+	rec = apiRequest(t, s, key, http.MethodGet, "/bookings?status=confirmed&client=anna", "")
+	expectStatus(t, rec, http.StatusOK)
+	var list apiList[apiBooking]
+	decodeResponse(t, rec, &list)
+	if list.Total != 1 || list.Items[0].ID != created.ID {
+		t.Errorf("confirmed bookings = %+v", list)
+	}
+
+	rec = apiRequest(t, s, key, http.MethodPatch, path, `{"time":"15:00","date":"`+date+`"}`)
+	expectStatus(t, rec, http.StatusOK)
+	var moved apiBooking
+	decodeResponse(t, rec, &moved)
+	if moved.Time != "15:00" {
+		t.Errorf("moved booking time = %q, want 15:00", moved.Time)
+	}
+
+	rec = apiRequest(t, s, key, http.MethodPatch, path, `{"status":"cancelled","reason":"client called"}`)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = apiRequest(t, s, key, http.MethodGet, path+"/history", "")
+	expectStatus(t, rec, http.StatusOK)
+	var history apiList[apiStatusChange]
+	decodeResponse(t, rec, &history)
+	last := history.Items[len(history.Items)-1]
+	if last.To != database.BookingStatusCancelled || last.Actor != database.ActorAPI || last.Reason != "client called" {
+		t.Errorf("last status change = %+v", last)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{name: "get missing", method: http.MethodGet, path: "/bookings/999", want: http.StatusNotFound},
+		{name: "update missing", method: http.MethodPatch, path: "/bookings/999", body: `{"notes":"x"}`, want: http.StatusNotFound},
+		{name: "history of missing", method: http.MethodGet, path: "/bookings/999/history", want: http.StatusNotFound},
+		{name: "create for missing user", method: http.MethodPost, path: "/bookings", body: fmt.Sprintf(`{"user_id":42,"service_id":%d,"date":"%s","time":"12:00"}`, service.ID, date), want: http.StatusNotFound},
+		{name: "create for missing service", method: http.MethodPost, path: "/bookings", body: fmt.Sprintf(`{"user_id":1001,"service_id":999,"date":"%s","time":"12:00"}`, date), want: http.StatusNotFound},
+		{name: "create without time", method: http.MethodPost, path: "/bookings", body: fmt.Sprintf(`{"user_id":1001,"service_id":%d,"date":"%s"}`, service.ID, date), want: http.StatusBadRequest},
+		{name: "create with invalid time", method: http.MethodPost, path: "/bookings", body: fmt.Sprintf(`{"user_id":1001,"service_id":%d,"date":"%s","time":"noon"}`, service.ID, date), want: http.StatusBadRequest},
+		{name: "date without time", method: http.MethodPatch, path: path, body: `{"date":"` + date + `"}`, want: http.StatusBadRequest},
+		{name: "unknown status", method: http.MethodPatch, path: path, body: `{"status":"done"}`, want: http.StatusBadRequest},
+		{name: "confirm cancelled", method: http.MethodPatch, path: path, body: `{"status":"confirmed"}`, want: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiRequest(t, s, key, tt.method, tt.path, tt.body)
+			expectStatus(t, rec, tt.want)
+		})
+	}
+}
+
+func TestAPIUserCRUD(t *testing.T) {
+	s, key := newTestServer(t)
+
+	rec := apiRequest(t, s, key, http.MethodPost, "/users", `{"first_name":"Maria","phone":"+7 900 123-45-67"}`)
+	expectStatus(t, rec, http.StatusCreated)
+	var created apiUser
+	decodeResponse(t, rec, &created)
+	if !created.IsOffline || created.InviteToken == "" || created.ID >= 0 {
+		t.Fatalf("created user = %+v", created)
+	}
+	path := fmt.Sprintf("/users/%d", created.ID)
+
+	rec = apiRequest(t, s, key, http.MethodPatch, path, `{"is_blocked":true,"block_reason":"no-shows","admin_notes":"VIP"}`)
+	expectStatus(t, rec, http.StatusOK)
+	var updated apiUser
+	decodeResponse(t, rec, &updated)
+	if !updated.IsBlocked || updated.BlockReason != "no-shows" || updated.AdminNotes != "VIP" || updated.Stats == nil {
+		t.Errorf("updated user = %+v", updated)
+	}
+
+	rec = apiRequest(t, s, key, http.MethodPatch, path, `{"is_blocked":false}`)
+	expectStatus(t, rec, http.StatusOK)
+	decodeResponse(t, rec, &updated)
+	if updated.IsBlocked {
+		t.Error("user is still blocked")
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{name: "get", method: http.MethodGet, path: path, want: http.StatusOK},
+		{name: "get missing", method: http.MethodGet, path: "/users/42", want: http.StatusNotFound},
+		{name: "update missing", method: http.MethodPatch, path: "/users/42", body: `{"admin_notes":"x"}`, want: http.StatusNotFound},
+		{name: "create without name", method: http.MethodPost, path: "/users", body: `{"phone":"+79001234567"}`, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiRequest(t, s, key, tt.method, tt.path, tt.body)
+			expectStatus(t, rec, tt.want)
+		})
+	}
+}
+
+func TestAPIBookingFollowsSchedule(t *testing.T) {
+	s, key := newTestServer(t)
+	createTestUser(t, 1001)
+
+	rec := apiRequest(t, s, key, http.MethodPost, "/services", `{"name":"Massage","duration":60,"price":250000}`)
+	expectStatus(t, rec, http.StatusCreated)
+	var service apiService
+	decodeResponse(t, rec, &service)
+
+	day := time.Now().AddDate(0, 0, 3)
+	dayOff := day.AddDate(0, 0, 1)
+	rec = apiRequest(t, s, key, http.MethodPut, "/schedule",
+		fmt.Sprintf(`[{"day_of_week":%d,"start_time":"10:00","end_time":"14:00","is_active":true},`+
+			`{"day_of_week":%d,"start_time":"10:00","end_time":"14:00","is_active":true}]`, day.Weekday(), dayOff.Weekday()))
+	expectStatus(t, rec, http.StatusOK)
+	rec = apiRequest(t, s, key, http.MethodPost, "/blocked-dates", `{"date":"`+dayOff.Format(apiDateLayout)+`","reason":"Holiday"}`)
+	expectStatus(t, rec, http.StatusCreated)
+
+	tests := []struct {
+		name string
+		date time.Time
+		time string
+		want int
+	}{
+		{name: "working hours", date: day, time: "12:00", want: http.StatusCreated},
+		{name: "off hours", date: day, time: "16:00", want: http.StatusConflict},
+		{name: "ends after closing", date: day, time: "13:30", want: http.StatusConflict},
+		{name: "day off", date: dayOff, time: "12:00", want: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"user_id":1001,"service_id":%d,"date":"%s","time":"%s"}`, service.ID, tt.date.Format(apiDateLayout), tt.time)
+			expectStatus(t, apiRequest(t, s, key, http.MethodPost, "/bookings", body), tt.want)
+		})
+	}
+}
@@ -0,0 +1,246 @@
+// Package web contains admin API handlers of clients
+package web
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"gobot/internal/database"
+	"gobot/internal/services"
+)
+
+// apiUser is a client in API responses
+type apiUser struct {
+	ID                int64     `json:"id"` // Telegram ID, negative for clients without Telegram
+	FirstName         string    `json:"first_name"`
+	LastName          string    `json:"last_name"`
+	Username          string    `json:"username"`
+	Phone             string    `json:"phone"`
+	Birthday          *string   `json:"birthday"`
+	Allergies         string    `json:"allergies"`
+	Language          string    `json:"language"`
+	IsOffline         bool      `json:"is_offline"`
+	InviteToken       string    `json:"invite_token,omitempty"` // Offline clients claim their bookings with it
+	IsBlocked         bool      `json:"is_blocked"`
+	BlockReason       string    `json:"block_reason"`
+	BlockReasonHidden bool      `json:"block_reason_hidden"`
+	BlockedBot        bool      `json:"blocked_bot"`
+	AdminNotes        string    `json:"admin_notes"`
+	CreatedAt         time.Time `json:"created_at"`
+
+	Stats *apiUserStats `json:"stats,omitempty"`
+}
+
+// apiUserStats is the visit history summary of a client
+type apiUserStats struct {
+	TotalBookings      int64   `json:"total_bookings"`
+	Completed          int64   `json:"completed"`
+	Cancelled          int64   `json:"cancelled"`
+	LateCancels        int64   `json:"late_cancels"`
+	NoShows            int64   `json:"no_shows"`
+	TotalSpent         int     `json:"total_spent"`         // Kopecks
+	CertificateBalance int     `json:"certificate_balance"` // Kopecks
+	LastVisit          *string `json:"last_visit"`
+}
+
+// apiUserInput is the body of a client create request
+type apiUserInput struct {
+	FirstName string `json:"first_name"`
+	Phone     string `json:"phone"`
+}
+
+// apiUserUpdate is the body of a client update request
+// Missing fields are left unchanged
+type apiUserUpdate struct {
+	Phone             *string `json:"phone"`
+	Allergies         *string `json:"allergies"`
+	AdminNotes        *string `json:"admin_notes"`
+	IsBlocked         *bool   `json:"is_blocked"`
+	BlockReason       *string `json:"block_reason"`
+	BlockReasonHidden *bool   `json:"block_reason_hidden"`
+}
+
+// newAPIUser converts a user for API responses
+func newAPIUser(user *database.User) apiUser {
+	result := apiUser{
+		ID:                user.ID,
+		FirstName:         user.FirstName,
+		LastName:          user.LastName,
+		Username:          user.Username,
+		Phone:             user.Phone,
+		Allergies:         user.Allergies,
+		Language:          services.UserLanguage(user),
+		IsOffline:         user.IsOffline,
+		IsBlocked:         user.IsBlocked,
+		BlockReason:       user.BlockReason,
+		BlockReasonHidden: user.BlockReasonHidden,
+		BlockedBot:        user.BlockedBot,
+		AdminNotes:        user.AdminNotes,
+		CreatedAt:         user.CreatedAt,
+	}
+	if user.IsOffline {
+		result.InviteToken = user.InviteToken
+	}
+	if user.Birthday != nil {
+		birthday := user.Birthday.Format(apiDateLayout)
+		result.Birthday = &birthday
+	}
+	return result
+}
+
+// handleAPIListUsers lists clients with pagination
+// Filters: q (name, @username, phone or Telegram ID), blocked and offline flags
+func (s *Server) handleAPIListUsers(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+
+	filter := services.UserFilter{Query: r.URL.Query().Get("q")}
+	if filter.Blocked, ok = queryBool(w, r, "blocked"); !ok {
+		return
+	}
+	if filter.Offline, ok = queryBool(w, r, "offline"); !ok {
+		return
+	}
+
+	users, total, err := s.users.ListUsers(r.Context(), filter, limit, offset)
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	items := make([]apiUser, 0, len(users))
+	for i := range users {
+		items = append(items, newAPIUser(&users[i]))
+	}
+
+	writeJSON(w, http.StatusOK, apiList[apiUser]{Items: items, Total: total, Limit: limit, Offset: offset})
+}
+
+// handleAPICreateUser creates a client without Telegram, e.g. imported from a CRM
+func (s *Server) handleAPICreateUser(w http.ResponseWriter, r *http.Request) {
+	var in apiUserInput
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	if strings.TrimSpace(in.FirstName) == "" {
+		writeAPIError(w, http.StatusBadRequest, "first_name is required")
+		return
+	}
+
+	user, err := s.users.CreateOfflineClient(r.Context(), in.FirstName, in.Phone)
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newAPIUser(user))
+}
+
+// handleAPIGetUser returns a client with visit statistics
+func (s *Server) handleAPIGetUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	s.writeUser(w, r, id)
+}
+
+// handleAPIUpdateUser changes contacts, notes and block status of a client
+func (s *Server) handleAPIUpdateUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var in apiUserUpdate
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+
+	user, err := s.users.GetUser(ctx, id)
+	if err != nil {
+		writeAPILookupError(w, r, err, "user")
+		return
+	}
+
+	if in.Phone != nil {
+		if err := s.users.UpdateProfileField(ctx, id, services.ProfileFieldPhone, services.NormalizePhone(*in.Phone)); err != nil {
+			writeAPIServerError(w, r, err)
+			return
+		}
+	}
+	if in.Allergies != nil {
+		if err := s.users.UpdateProfileField(ctx, id, services.ProfileFieldAllergies, *in.Allergies); err != nil {
+			writeAPIServerError(w, r, err)
+			return
+		}
+	}
+	if in.AdminNotes != nil {
+		if err := s.users.UpdateAdminNotes(ctx, id, *in.AdminNotes); err != nil {
+			writeAPIServerError(w, r, err)
+			return
+		}
+	}
+
+	// Blocking again updates the reason
+	blocked := user.IsBlocked
+	if in.IsBlocked != nil {
+		blocked = *in.IsBlocked
+	}
+	switch {
+	case blocked && (in.IsBlocked != nil || in.BlockReason != nil || in.BlockReasonHidden != nil):
+		reason, hidden := user.BlockReason, user.BlockReasonHidden
+		if in.BlockReason != nil {
+			reason = *in.BlockReason
+		}
+		if in.BlockReasonHidden != nil {
+			hidden = *in.BlockReasonHidden
+		}
+		err = s.users.BlockUser(ctx, id, reason, hidden)
+	case !blocked && user.IsBlocked:
+		err = s.users.UnblockUser(ctx, id)
+	}
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	s.writeUser(w, r, id)
+}
+
+// writeUser responds with the current state of a client
+func (s *Server) writeUser(w http.ResponseWriter, r *http.Request, userID int64) {
+	user, err := s.users.GetUser(r.Context(), userID)
+	if err != nil {
+		writeAPILookupError(w, r, err, "user")
+		return
+	}
+
+	stats, err := s.users.GetClientStats(r.Context(), userID)
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	result := newAPIUser(user)
+	result.Stats = &apiUserStats{
+		TotalBookings:      stats.TotalBookings,
+		Completed:          stats.Completed,
+		Cancelled:          stats.Cancelled,
+		LateCancels:        stats.LateCancels,
+		NoShows:            stats.NoShows,
+		TotalSpent:         stats.TotalSpent,
+		CertificateBalance: stats.CertificateBalance,
+	}
+	if stats.LastVisit != nil {
+		lastVisit := stats.LastVisit.Format(apiDateLayout)
+		result.Stats.LastVisit = &lastVisit
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,50 @@
+// Package web contains the admin calendar feed
+package web
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"gobot/internal/config"
+	"gobot/internal/services"
+)
+
+// CalendarFeedPath is the path of the admin iCal feed
+const CalendarFeedPath = "/calendar/feed.ics"
+
+// CalendarFeedURL returns the subscription link of the admin feed
+// Returns an empty string when the feed or the public URL isn't configured
+func CalendarFeedURL(cfg *config.Config) string {
+	if cfg.HTTPAddr == "" || cfg.PublicURL == "" || cfg.CalendarFeedToken == "" {
+		return ""
+	}
+	return cfg.PublicURL + CalendarFeedPath + "?token=" + url.QueryEscape(cfg.CalendarFeedToken)
+}
+
+// handleCalendarFeed serves confirmed bookings as an iCal feed
+// Calendar apps can't send headers, so the token is passed in the query
+func (s *Server) handleCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	token := s.config.CalendarFeedToken
+	if token == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(token)) != 1 {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	data, err := s.calendar.FeedICS(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to build calendar feed", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", services.CalendarMIME)
+	w.Header().Set("Content-Disposition", `inline; filename="bookings.ics"`)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(data)
+}
@@ -0,0 +1,341 @@
+// Package web contains the admin web dashboard
+package web
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gobot/internal/database"
+	"gobot/internal/i18n"
+	"gobot/internal/services"
+)
+
+// DashboardPath is the path prefix of the web dashboard
+const DashboardPath = "/admin"
+
+// Dashboard cookies
+const (
+	sessionCookie = "gobot_session"
+	flashCookie   = "gobot_flash"
+)
+
+//go:embed templates static
+var dashboardFS embed.FS
+
+// dashboardAdminKey stores the signed in admin in the request context
+type dashboardAdminKey struct{}
+
+// dashboardPage is the data of every dashboard page
+type dashboardPage struct {
+	Title string
+	Nav   string // Active menu item
+	CSRF  string
+	Flash *dashboardFlash
+	Data  interface{}
+}
+
+// dashboardFlash is a one-time message shown after a redirect
+type dashboardFlash struct {
+	Text  string
+	Error bool
+}
+
+// dashboardLoginData is the data of the sign in page
+type dashboardLoginData struct {
+	BotUsername string
+	AuthURL     string // Telegram Login Widget redirect
+	Token       string // One-time link token waiting for confirmation
+	Error       string
+}
+
+// dashboardFuncs are helpers available in templates
+var dashboardFuncs = template.FuncMap{
+	"price": func(amount int) string {
+		return i18n.Price(i18n.Russian, amount)
+	},
+	"rubles": func(amount int) int {
+		return amount / 100
+	},
+	"weekday": func(t time.Time) string {
+		return i18n.Weekday(i18n.Russian, t)
+	},
+	"date": func(t time.Time) string {
+		return t.Format("02.01.2006")
+	},
+	"isoDate": func(t time.Time) string {
+		return t.Format(apiDateLayout)
+	},
+	"statusText": func(status database.BookingStatus) string {
+		return dashboardStatusTitles[status]
+	},
+}
+
+// dashboardStatusTitles names booking statuses like the bot does
+var dashboardStatusTitles = map[database.BookingStatus]string{
+	database.BookingStatusPending:   "Ожидает подтверждения",
+	database.BookingStatusConfirmed: "Подтверждено",
+	database.BookingStatusCancelled: "Отменено",
+	database.BookingStatusCompleted: "Завершено",
+	database.BookingStatusNoShow:    "Клиент не пришел",
+	database.BookingStatusRejected:  "Отклонено",
+}
+
+// parseDashboardTemplates parses every page together with the layout
+func parseDashboardTemplates() map[string]*template.Template {
+	pages, err := fs.Glob(dashboardFS, "templates/*.html")
+	if err != nil {
+		panic(err)
+	}
+
+	templates := make(map[string]*template.Template, len(pages))
+	for _, page := range pages {
+		name := strings.TrimPrefix(page, "templates/")
+		if name == "layout.html" {
+			continue
+		}
+		templates[name] = template.Must(template.New(name).Funcs(dashboardFuncs).
+			ParseFS(dashboardFS, "templates/layout.html", page))
+	}
+	return templates
+}
+
+// registerDashboard adds dashboard routes to the mux
+func (s *Server) registerDashboard(mux *http.ServeMux) {
+	static, err := fs.Sub(dashboardFS, "static")
+	if err != nil {
+		panic(err)
+	}
+	mux.Handle("GET "+DashboardPath+"/static/", http.StripPrefix(DashboardPath+"/static/", http.FileServerFS(static)))
+
+	mux.Handle("GET "+DashboardPath, http.RedirectHandler(DashboardPath+"/", http.StatusMovedPermanently))
+	mux.HandleFunc("GET "+DashboardPath+"/login", s.handleDashboardLoginPage)
+	mux.HandleFunc("POST "+DashboardPath+"/login", s.handleDashboardLinkLogin)
+	mux.HandleFunc("GET "+DashboardPath+"/login/telegram", s.handleDashboardTelegramLogin)
+
+	routes := map[string]http.HandlerFunc{
+		"GET /{$}":                        s.handleDashboardHome,
+		"POST /logout":                    s.handleDashboardLogout,
+		"GET /calendar":                   s.handleDashboardCalendar,
+		"GET /bookings/{id}":              s.handleDashboardBooking,
+		"POST /bookings/{id}/status":      s.handleDashboardBookingStatus,
+		"POST /bookings/{id}/move":        s.handleDashboardBookingMove,
+		"POST /bookings/{id}/notes":       s.handleDashboardBookingNotes,
+		"GET /services":                   s.handleDashboardServices,
+		"POST /services":                  s.handleDashboardCreateService,
+		"GET /services/{id}":              s.handleDashboardService,
+		"POST /services/{id}":             s.handleDashboardUpdateService,
+		"POST /services/{id}/delete":      s.handleDashboardDeleteService,
+		"GET /discounts":                  s.handleDashboardDiscounts,
+		"POST /discounts":                 s.handleDashboardCreateDiscount,
+		"GET /discounts/{id}":             s.handleDashboardDiscount,
+		"POST /discounts/{id}":            s.handleDashboardUpdateDiscount,
+		"POST /discounts/{id}/delete":     s.handleDashboardDeleteDiscount,
+		"GET /schedule":                   s.handleDashboardSchedule,
+		"POST /schedule":                  s.handleDashboardSetSchedule,
+		"POST /blocked-dates":             s.handleDashboardAddBlockedDate,
+		"POST /blocked-dates/{id}/delete": s.handleDashboardDeleteBlockedDate,
+	}
+	for route, handler := range routes {
+		method, path, _ := strings.Cut(route, " ")
+		mux.Handle(method+" "+DashboardPath+path, s.requireAdmin(handler))
+	}
+}
+
+// requireAdmin redirects browsers without an admin session to the sign in page
+// Forms must carry the CSRF token of the session
+func (s *Server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil {
+			http.Redirect(w, r, DashboardPath+"/login", http.StatusSeeOther)
+			return
+		}
+
+		session, err := s.auth.GetSession(r.Context(), cookie.Value)
+		if err != nil || !s.config.IsAdmin(session.AdminID) {
+			s.clearCookie(w, r, sessionCookie)
+			http.Redirect(w, r, DashboardPath+"/login", http.StatusSeeOther)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			token := r.PostFormValue("csrf")
+			if !hmac.Equal([]byte(token), []byte(csrfToken(cookie.Value))) {
+				http.Error(w, "Форма устарела, обновите страницу", http.StatusForbidden)
+				return
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), dashboardAdminKey{}, session.AdminID)
+		ctx = services.WithActor(ctx, services.Actor{Type: database.ActorAdmin, ID: session.AdminID})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// dashboardAdmin returns the Telegram ID of the signed in admin
+func dashboardAdmin(r *http.Request) int64 {
+	adminID, _ := r.Context().Value(dashboardAdminKey{}).(int64)
+	return adminID
+}
+
+// csrfToken derives the form token from the session cookie
+func csrfToken(session string) string {
+	mac := hmac.New(sha256.New, []byte(session))
+	mac.Write([]byte("csrf"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// handleDashboardLoginPage shows the Telegram Login Widget or confirms a one-time link
+func (s *Server) handleDashboardLoginPage(w http.ResponseWriter, r *http.Request) {
+	s.renderLogin(w, r, http.StatusOK, dashboardLoginData{Token: r.URL.Query().Get("token")})
+}
+
+// handleDashboardLinkLogin signs in with a one-time link sent by the bot
+// The link needs a button press, so link previews can't use it up
+func (s *Server) handleDashboardLinkLogin(w http.ResponseWriter, r *http.Request) {
+	adminID, err := s.auth.ConsumeLoginToken(r.Context(), r.PostFormValue("token"))
+	if err != nil {
+		s.renderLogin(w, r, http.StatusUnauthorized, dashboardLoginData{
+			Error: "Ссылка устарела или уже использована. Запросите новую в боте.",
+		})
+		return
+	}
+	s.startSession(w, r, adminID)
+}
+
+// handleDashboardTelegramLogin signs in with data of the Telegram Login Widget
+func (s *Server) handleDashboardTelegramLogin(w http.ResponseWriter, r *http.Request) {
+	userID, err := services.VerifyTelegramLogin(s.config.BotToken, r.URL.Query(), time.Now())
+	if err != nil {
+		s.renderLogin(w, r, http.StatusUnauthorized, dashboardLoginData{Error: "Не удалось проверить вход через Telegram."})
+		return
+	}
+	s.startSession(w, r, userID)
+}
+
+// startSession sets the session cookie of an admin and opens the dashboard
+func (s *Server) startSession(w http.ResponseWriter, r *http.Request, adminID int64) {
+	if !s.config.IsAdmin(adminID) {
+		s.renderLogin(w, r, http.StatusForbidden, dashboardLoginData{Error: "❌ Нет доступа"})
+		return
+	}
+
+	token, err := s.auth.CreateSession(r.Context(), adminID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to start dashboard session", "admin_id", adminID, "error", err)
+		http.Error(w, "Ошибка входа", http.StatusInternalServerError)
+		return
+	}
+	slog.InfoContext(r.Context(), "Admin signed in to the dashboard", "admin_id", adminID)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     DashboardPath,
+		MaxAge:   int(services.AdminSessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   s.secureCookies(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, DashboardPath+"/", http.StatusSeeOther)
+}
+
+// handleDashboardLogout ends the session
+func (s *Server) handleDashboardLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		if err := s.auth.DeleteSession(r.Context(), cookie.Value); err != nil {
+			slog.ErrorContext(r.Context(), "Failed to sign out", "error", err)
+		}
+	}
+	s.clearCookie(w, r, sessionCookie)
+	http.Redirect(w, r, DashboardPath+"/login", http.StatusSeeOther)
+}
+
+// handleDashboardHome opens the calendar
+func (s *Server) handleDashboardHome(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, DashboardPath+"/calendar", http.StatusSeeOther)
+}
+
+// renderLogin shows the sign in page
+func (s *Server) renderLogin(w http.ResponseWriter, r *http.Request, status int, data dashboardLoginData) {
+	data.BotUsername = s.botUsername
+	data.AuthURL = s.config.PublicURL + DashboardPath + "/login/telegram"
+	s.render(w, r, status, "login.html", dashboardPage{Title: "Вход", Data: data})
+}
+
+// render writes a dashboard page, the flash message is shown once
+func (s *Server) render(w http.ResponseWriter, r *http.Request, status int, name string, page dashboardPage) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		page.CSRF = csrfToken(cookie.Value)
+	}
+	if cookie, err := r.Cookie(flashCookie); err == nil {
+		if text, err := url.QueryUnescape(cookie.Value); err == nil && len(text) > 2 {
+			page.Flash = &dashboardFlash{Text: text[2:], Error: strings.HasPrefix(text, "e:")}
+		}
+		s.clearCookie(w, r, flashCookie)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.WriteHeader(status)
+	if err := s.pages[name].ExecuteTemplate(w, "layout", page); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to render page", "page", name, "error", err)
+	}
+}
+
+// redirectWithFlash shows the message on the next page
+func (s *Server) redirectWithFlash(w http.ResponseWriter, r *http.Request, path, text string, isError bool) {
+	kind := "o:"
+	if isError {
+		kind = "e:"
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     flashCookie,
+		Value:    url.QueryEscape(kind + text),
+		Path:     DashboardPath,
+		MaxAge:   60,
+		HttpOnly: true,
+		Secure:   s.secureCookies(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, path, http.StatusSeeOther)
+}
+
+// clearCookie removes a dashboard cookie
+func (s *Server) clearCookie(w http.ResponseWriter, r *http.Request, name string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Path:     DashboardPath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   s.secureCookies(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// secureCookies reports whether the dashboard is served over HTTPS
+func (s *Server) secureCookies(r *http.Request) bool {
+	return r.TLS != nil || strings.HasPrefix(s.config.PublicURL, "https://")
+}
+
+// dashboardNotFound shows a plain 404 page
+func dashboardNotFound(w http.ResponseWriter, message string) {
+	http.Error(w, message, http.StatusNotFound)
+}
+
+// dashboardError logs the error and shows a generic error page
+func (s *Server) dashboardError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "Dashboard error", "method", r.Method, "path", r.URL.Path, "error", err)
+	http.Error(w, "Внутренняя ошибка", http.StatusInternalServerError)
+}
@@ -0,0 +1,235 @@
+// Package web contains dashboard pages of bookings
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gobot/internal/database"
+	"gobot/internal/services"
+)
+
+// dashboardWeekLimit caps bookings loaded for the calendar
+const dashboardWeekLimit = 1000
+
+// calendarDay is a column of the week calendar
+type calendarDay struct {
+	Date     time.Time
+	Today    bool
+	DayOff   string // Reason of the day off, "-" without a reason
+	Bookings []database.Booking
+}
+
+// calendarData is the data of the week calendar
+type calendarData struct {
+	From, To   time.Time // To is inclusive
+	Prev, Next string
+	Days       []calendarDay
+}
+
+// bookingAction is a status button of the booking page
+type bookingAction struct {
+	Status database.BookingStatus
+	Title  string
+	Danger bool
+}
+
+// bookingData is the data of the booking page
+type bookingData struct {
+	Booking *database.Booking
+	Actions []bookingAction
+	Movable bool
+	History []database.BookingStatusHistory
+}
+
+// bookingActions are status changes offered on the booking page, in button order
+var bookingActions = []bookingAction{
+	{Status: database.BookingStatusConfirmed, Title: "✅ Подтвердить"},
+	{Status: database.BookingStatusRejected, Title: "🚫 Отклонить", Danger: true},
+	{Status: database.BookingStatusCompleted, Title: "✔️ Завершена"},
+	{Status: database.BookingStatusNoShow, Title: "🚷 Не пришел"},
+	{Status: database.BookingStatusCancelled, Title: "❌ Отменить", Danger: true},
+}
+
+// handleDashboardCalendar shows bookings of a week, ?week= is any date of the week
+func (s *Server) handleDashboardCalendar(w http.ResponseWriter, r *http.Request) {
+	day := time.Now()
+	if value := r.URL.Query().Get("week"); value != "" {
+		date, err := parseAPIDate(value)
+		if err != nil {
+			http.Error(w, "Неверная дата", http.StatusBadRequest)
+			return
+		}
+		day = date
+	}
+	week := services.NewReportPeriod(services.ReportPeriodWeek, day)
+
+	bookings, _, err := s.admin.ListBookings(r.Context(), services.BookingFilter{
+		Period:   services.BookingPeriodCustom,
+		DateFrom: week.From,
+		DateTo:   week.To,
+	}, dashboardWeekLimit, 0)
+	if err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+
+	blocked, err := s.schedule.GetBlockedDates(r.Context(), week.From, week.To)
+	if err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+
+	data := calendarData{
+		From: week.From,
+		To:   week.To.AddDate(0, 0, -1),
+		Prev: week.From.AddDate(0, 0, -7).Format(apiDateLayout),
+		Next: week.To.Format(apiDateLayout),
+	}
+	today := time.Now().Format(apiDateLayout)
+	for date := week.From; date.Before(week.To); date = date.AddDate(0, 0, 1) {
+		column := calendarDay{Date: date, Today: date.Format(apiDateLayout) == today}
+		for _, day := range blocked {
+			if day.Date.Format(apiDateLayout) == date.Format(apiDateLayout) {
+				column.DayOff = day.Reason
+				if column.DayOff == "" {
+					column.DayOff = "-"
+				}
+			}
+		}
+		for _, booking := range bookings {
+			if booking.Date.Format(apiDateLayout) == date.Format(apiDateLayout) {
+				column.Bookings = append(column.Bookings, booking)
+			}
+		}
+		data.Days = append(data.Days, column)
+	}
+
+	s.render(w, r, http.StatusOK, "calendar.html", dashboardPage{Title: "Календарь", Nav: "calendar", Data: data})
+}
+
+// handleDashboardBooking shows a booking with status buttons and move and note forms
+func (s *Server) handleDashboardBooking(w http.ResponseWriter, r *http.Request) {
+	booking, ok := s.dashboardBooking(w, r)
+	if !ok {
+		return
+	}
+
+	data := bookingData{
+		Booking: booking,
+		Movable: booking.Status == database.BookingStatusPending || booking.Status == database.BookingStatusConfirmed,
+	}
+	history, err := s.admin.GetBookingHistory(r.Context(), booking.ID)
+	if err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+	data.History = history
+
+	for _, action := range bookingActions {
+		if services.CanTransitionBooking(booking.Status, action.Status, database.ActorAdmin) {
+			data.Actions = append(data.Actions, action)
+		}
+	}
+
+	s.render(w, r, http.StatusOK, "booking.html", dashboardPage{
+		Title: fmt.Sprintf("Запись #%d", booking.ID),
+		Nav:   "calendar",
+		Data:  data,
+	})
+}
+
+// handleDashboardBookingStatus changes the status, the client is notified like in the bot
+func (s *Server) handleDashboardBookingStatus(w http.ResponseWriter, r *http.Request) {
+	booking, ok := s.dashboardBooking(w, r)
+	if !ok {
+		return
+	}
+	path := bookingPath(booking.ID)
+
+	status := database.BookingStatus(r.PostFormValue("status"))
+	if err := s.setBookingStatus(r.Context(), booking, status, services.ByAdmin(dashboardAdmin(r))); err != nil {
+		if errors.Is(err, services.ErrBookingTransition) {
+			s.redirectWithFlash(w, r, path, "Запись уже обработана", true)
+			return
+		}
+		s.dashboardError(w, r, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "Booking status set in the dashboard", "booking_id", booking.ID, "status", status, "admin_id", dashboardAdmin(r))
+
+	s.redirectWithFlash(w, r, path, "Статус изменен: "+dashboardStatusTitles[status], false)
+}
+
+// handleDashboardBookingMove moves a booking, the client gets the new time
+func (s *Server) handleDashboardBookingMove(w http.ResponseWriter, r *http.Request) {
+	booking, ok := s.dashboardBooking(w, r)
+	if !ok {
+		return
+	}
+	path := bookingPath(booking.ID)
+
+	date, err := parseAPIDate(r.PostFormValue("date"))
+	if err != nil {
+		s.redirectWithFlash(w, r, path, "Укажите дату", true)
+		return
+	}
+	timeSlot := r.PostFormValue("time")
+	if _, err := time.Parse("15:04", timeSlot); err != nil {
+		s.redirectWithFlash(w, r, path, "Укажите время в формате ЧЧ:ММ", true)
+		return
+	}
+
+	err = s.moveBooking(r.Context(), booking, date, timeSlot)
+	switch {
+	case errors.Is(err, services.ErrSlotTaken):
+		s.redirectWithFlash(w, r, path, "Это время уже занято", true)
+	case errors.Is(err, services.ErrBookingNotMovable):
+		s.redirectWithFlash(w, r, path, "Перенести можно только предстоящую запись", true)
+	case err != nil:
+		s.dashboardError(w, r, err)
+	default:
+		s.redirectWithFlash(w, r, path, "Запись перенесена, клиент получил уведомление", false)
+	}
+}
+
+// handleDashboardBookingNotes saves the internal note of a booking
+func (s *Server) handleDashboardBookingNotes(w http.ResponseWriter, r *http.Request) {
+	booking, ok := s.dashboardBooking(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.admin.UpdateBookingNotes(r.Context(), booking.ID, strings.TrimSpace(r.PostFormValue("notes"))); err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+
+	s.redirectWithFlash(w, r, bookingPath(booking.ID), "Заметка сохранена", false)
+}
+
+// dashboardBooking loads the booking of the {id} path parameter
+func (s *Server) dashboardBooking(w http.ResponseWriter, r *http.Request) (*database.Booking, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		dashboardNotFound(w, "Запись не найдена")
+		return nil, false
+	}
+
+	booking, err := s.admin.GetBookingByID(r.Context(), uint(id))
+	if err != nil {
+		dashboardNotFound(w, "Запись не найдена")
+		return nil, false
+	}
+	return booking, true
+}
+
+// bookingPath returns the dashboard page of a booking
+func bookingPath(bookingID uint) string {
+	return fmt.Sprintf("%s/bookings/%d", DashboardPath, bookingID)
+}
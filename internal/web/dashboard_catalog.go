@@ -0,0 +1,304 @@
+// Package web contains dashboard pages of services and discounts
+package web
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gobot/internal/database"
+)
+
+// discountsData is the data of the discounts page
+type discountsData struct {
+	Discounts []database.Discount
+	Services  []database.Service
+}
+
+// handleDashboardServices lists services with the create form
+func (s *Server) handleDashboardServices(w http.ResponseWriter, r *http.Request) {
+	services, err := s.admin.GetAllServices(r.Context())
+	if err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+
+	s.render(w, r, http.StatusOK, "services.html", dashboardPage{Title: "Услуги", Nav: "services", Data: services})
+}
+
+// handleDashboardCreateService creates an active service
+func (s *Server) handleDashboardCreateService(w http.ResponseWriter, r *http.Request) {
+	in, msg := parseServiceForm(r)
+	if msg != "" {
+		s.redirectWithFlash(w, r, DashboardPath+"/services", msg, true)
+		return
+	}
+
+	service, err := s.admin.CreateService(r.Context(), *in.Name, *in.Description, *in.Duration, *in.Price)
+	if err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+
+	in.Name, in.Description, in.Duration, in.Price = nil, nil, nil, nil
+	if err := s.updateService(r, service.ID, &in); err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "Service created in the dashboard", "service_id", service.ID, "admin_id", dashboardAdmin(r))
+
+	s.redirectWithFlash(w, r, servicePath(service.ID), "Услуга создана", false)
+}
+
+// handleDashboardService shows the edit form of a service
+func (s *Server) handleDashboardService(w http.ResponseWriter, r *http.Request) {
+	service, ok := s.dashboardService(w, r)
+	if !ok {
+		return
+	}
+
+	s.render(w, r, http.StatusOK, "service.html", dashboardPage{Title: service.Name, Nav: "services", Data: service})
+}
+
+// handleDashboardUpdateService saves the edit form of a service
+func (s *Server) handleDashboardUpdateService(w http.ResponseWriter, r *http.Request) {
+	service, ok := s.dashboardService(w, r)
+	if !ok {
+		return
+	}
+
+	in, msg := parseServiceForm(r)
+	if msg != "" {
+		s.redirectWithFlash(w, r, servicePath(service.ID), msg, true)
+		return
+	}
+
+	if err := s.updateService(r, service.ID, &in); err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+
+	s.redirectWithFlash(w, r, servicePath(service.ID), "Изменения сохранены", false)
+}
+
+// handleDashboardDeleteService deletes a service, its bookings are kept
+func (s *Server) handleDashboardDeleteService(w http.ResponseWriter, r *http.Request) {
+	service, ok := s.dashboardService(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.admin.DeleteService(r.Context(), service.ID); err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "Service deleted in the dashboard", "service_id", service.ID, "admin_id", dashboardAdmin(r))
+
+	s.redirectWithFlash(w, r, DashboardPath+"/services", "Услуга «"+service.Name+"» удалена", false)
+}
+
+// handleDashboardDiscounts lists discounts with the create form
+func (s *Server) handleDashboardDiscounts(w http.ResponseWriter, r *http.Request) {
+	discounts, err := s.discounts.GetAllDiscounts(r.Context())
+	if err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+	services, err := s.admin.GetAllServices(r.Context())
+	if err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+
+	s.render(w, r, http.StatusOK, "discounts.html", dashboardPage{
+		Title: "Акции",
+		Nav:   "discounts",
+		Data:  discountsData{Discounts: discounts, Services: services},
+	})
+}
+
+// handleDashboardCreateDiscount creates a discount, active ones are announced in the channel
+func (s *Server) handleDashboardCreateDiscount(w http.ResponseWriter, r *http.Request) {
+	serviceID, err := strconv.ParseUint(r.PostFormValue("service_id"), 10, 32)
+	if err != nil {
+		s.redirectWithFlash(w, r, DashboardPath+"/discounts", "Выберите услугу", true)
+		return
+	}
+	if _, err := s.admin.GetServiceByID(r.Context(), uint(serviceID)); err != nil {
+		s.redirectWithFlash(w, r, DashboardPath+"/discounts", "Услуга не найдена", true)
+		return
+	}
+
+	discount := &database.Discount{ServiceID: uint(serviceID)}
+	if msg := applyDiscountForm(discount, r); msg != "" {
+		s.redirectWithFlash(w, r, DashboardPath+"/discounts", msg, true)
+		return
+	}
+
+	created, err := s.createDiscount(r.Context(), discount)
+	if err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "Discount created in the dashboard", "discount_id", created.ID, "admin_id", dashboardAdmin(r))
+
+	msg := "Акция сохранена как черновик"
+	if created.IsActive {
+		msg = "Акция создана и опубликована в канале"
+	}
+	s.redirectWithFlash(w, r, discountPath(created.ID), msg, false)
+}
+
+// handleDashboardDiscount shows the edit form of a discount
+func (s *Server) handleDashboardDiscount(w http.ResponseWriter, r *http.Request) {
+	discount, ok := s.dashboardDiscount(w, r)
+	if !ok {
+		return
+	}
+
+	s.render(w, r, http.StatusOK, "discount.html", dashboardPage{Title: discount.Name, Nav: "discounts", Data: discount})
+}
+
+// handleDashboardUpdateDiscount saves the edit form of a discount
+func (s *Server) handleDashboardUpdateDiscount(w http.ResponseWriter, r *http.Request) {
+	discount, ok := s.dashboardDiscount(w, r)
+	if !ok {
+		return
+	}
+
+	if msg := applyDiscountForm(discount, r); msg != "" {
+		s.redirectWithFlash(w, r, discountPath(discount.ID), msg, true)
+		return
+	}
+
+	if err := s.discounts.UpdateDiscount(r.Context(), discount); err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+
+	s.redirectWithFlash(w, r, discountPath(discount.ID), "Изменения сохранены", false)
+}
+
+// handleDashboardDeleteDiscount deletes a discount
+func (s *Server) handleDashboardDeleteDiscount(w http.ResponseWriter, r *http.Request) {
+	discount, ok := s.dashboardDiscount(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.discounts.DeleteDiscount(r.Context(), discount.ID); err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+
+	s.redirectWithFlash(w, r, DashboardPath+"/discounts", "Акция «"+discount.Name+"» удалена", false)
+}
+
+// parseServiceForm reads the service form, the price is typed in rubles
+func parseServiceForm(r *http.Request) (apiServiceInput, string) {
+	name := strings.TrimSpace(r.PostFormValue("name"))
+	if name == "" {
+		return apiServiceInput{}, "Введите название услуги"
+	}
+	duration, err := strconv.Atoi(r.PostFormValue("duration"))
+	if err != nil || duration <= 0 {
+		return apiServiceInput{}, "Длительность должна быть положительным числом минут"
+	}
+	rubles, err := strconv.Atoi(r.PostFormValue("price"))
+	if err != nil || rubles < 0 {
+		return apiServiceInput{}, "Цена должна быть числом рублей"
+	}
+
+	description := strings.TrimSpace(r.PostFormValue("description"))
+	detailed := strings.TrimSpace(r.PostFormValue("detailed_description"))
+	price := rubles * 100
+	active := r.PostFormValue("is_active") == "on"
+
+	return apiServiceInput{
+		Name:                &name,
+		Description:         &description,
+		DetailedDescription: &detailed,
+		Duration:            &duration,
+		Price:               &price,
+		IsActive:            &active,
+	}, ""
+}
+
+// applyDiscountForm copies the discount form to the discount and validates it
+func applyDiscountForm(discount *database.Discount, r *http.Request) string {
+	name := strings.TrimSpace(r.PostFormValue("name"))
+	startDate := r.PostFormValue("start_date")
+	endDate := r.PostFormValue("end_date")
+	active := r.PostFormValue("is_active") == "on"
+
+	percentage, err := strconv.Atoi(r.PostFormValue("percentage"))
+	if err != nil || percentage < 1 || percentage > 100 {
+		return "Скидка должна быть от 1 до 100%"
+	}
+	start, err := parseAPIDate(startDate)
+	if err != nil {
+		return "Укажите дату начала"
+	}
+	end, err := parseAPIDate(endDate)
+	if err != nil {
+		return "Укажите дату окончания"
+	}
+	if end.Before(start) {
+		return "Акция заканчивается раньше, чем начинается"
+	}
+
+	if msg := applyDiscountInput(discount, &apiDiscountInput{
+		Name:       &name,
+		Percentage: &percentage,
+		StartDate:  &startDate,
+		EndDate:    &endDate,
+		IsActive:   &active,
+	}); msg != "" {
+		return "Введите название акции"
+	}
+	return ""
+}
+
+// dashboardService loads the service of the {id} path parameter
+func (s *Server) dashboardService(w http.ResponseWriter, r *http.Request) (*database.Service, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		dashboardNotFound(w, "Услуга не найдена")
+		return nil, false
+	}
+
+	service, err := s.admin.GetServiceByID(r.Context(), uint(id))
+	if err != nil {
+		dashboardNotFound(w, "Услуга не найдена")
+		return nil, false
+	}
+	return service, true
+}
+
+// dashboardDiscount loads the discount of the {id} path parameter
+func (s *Server) dashboardDiscount(w http.ResponseWriter, r *http.Request) (*database.Discount, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		dashboardNotFound(w, "Акция не найдена")
+		return nil, false
+	}
+
+	discount, err := s.discounts.GetDiscountByID(r.Context(), uint(id))
+	if err != nil {
+		dashboardNotFound(w, "Акция не найдена")
+		return nil, false
+	}
+	return discount, true
+}
+
+// servicePath returns the dashboard page of a service
+func servicePath(serviceID uint) string {
+	return fmt.Sprintf("%s/services/%d", DashboardPath, serviceID)
+}
+
+// discountPath returns the dashboard page of a discount
+func discountPath(discountID uint) string {
+	return fmt.Sprintf("%s/discounts/%d", DashboardPath, discountID)
+}
@@ -0,0 +1,130 @@
+// Package web contains the dashboard page of the working schedule
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gobot/internal/database"
+	"gobot/internal/i18n"
+	"gobot/internal/services"
+)
+
+// scheduleIntervalsPerDay is how many intervals a weekday has in the form, e.g. before and after a break
+const scheduleIntervalsPerDay = 2
+
+// scheduleDay is a weekday row of the schedule form
+type scheduleDay struct {
+	DayOfWeek int
+	Name      string
+	Active    bool
+	Intervals [scheduleIntervalsPerDay]database.WorkSchedule
+}
+
+// scheduleData is the data of the schedule page
+type scheduleData struct {
+	Days    []scheduleDay
+	Blocked []database.BlockedDate
+}
+
+// handleDashboardSchedule shows working hours and upcoming days off
+func (s *Server) handleDashboardSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := s.schedule.GetSchedule(r.Context())
+	if err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	blocked, err := s.schedule.GetBlockedDates(r.Context(), today, time.Time{})
+	if err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+
+	data := scheduleData{Blocked: blocked}
+	// Weeks start on Monday
+	for _, weekday := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday} {
+		day := scheduleDay{
+			DayOfWeek: int(weekday),
+			Name:      i18n.Weekday(i18n.Russian, today.AddDate(0, 0, int(weekday-today.Weekday()))),
+		}
+		n := 0
+		for _, interval := range schedule {
+			if interval.DayOfWeek != day.DayOfWeek || n == scheduleIntervalsPerDay {
+				continue
+			}
+			day.Intervals[n] = interval
+			day.Active = day.Active || interval.IsActive
+			n++
+		}
+		data.Days = append(data.Days, day)
+	}
+
+	s.render(w, r, http.StatusOK, "schedule.html", dashboardPage{Title: "Расписание", Nav: "schedule", Data: data})
+}
+
+// handleDashboardSetSchedule saves the schedule form
+// Hours of days off are kept inactive so they come back when the day is enabled again
+func (s *Server) handleDashboardSetSchedule(w http.ResponseWriter, r *http.Request) {
+	var schedule []database.WorkSchedule
+	for day := 0; day < 7; day++ {
+		active := r.PostFormValue(fmt.Sprintf("active_%d", day)) == "on"
+		for i := 0; i < scheduleIntervalsPerDay; i++ {
+			start := strings.TrimSpace(r.PostFormValue(fmt.Sprintf("start_%d_%d", day, i)))
+			end := strings.TrimSpace(r.PostFormValue(fmt.Sprintf("end_%d_%d", day, i)))
+			if start == "" && end == "" {
+				continue
+			}
+			schedule = append(schedule, database.WorkSchedule{DayOfWeek: day, StartTime: start, EndTime: end, IsActive: active})
+		}
+	}
+
+	if err := s.schedule.SetSchedule(r.Context(), schedule); err != nil {
+		if errors.Is(err, services.ErrInvalidSchedule) {
+			s.redirectWithFlash(w, r, DashboardPath+"/schedule", "Проверьте время: конец интервала должен быть позже начала", true)
+			return
+		}
+		s.dashboardError(w, r, err)
+		return
+	}
+
+	s.redirectWithFlash(w, r, DashboardPath+"/schedule", "Расписание сохранено", false)
+}
+
+// handleDashboardAddBlockedDate adds a day off
+func (s *Server) handleDashboardAddBlockedDate(w http.ResponseWriter, r *http.Request) {
+	date, err := parseAPIDate(r.PostFormValue("date"))
+	if err != nil {
+		s.redirectWithFlash(w, r, DashboardPath+"/schedule", "Укажите дату", true)
+		return
+	}
+
+	if _, err := s.schedule.AddBlockedDate(r.Context(), date, strings.TrimSpace(r.PostFormValue("reason"))); err != nil {
+		s.dashboardError(w, r, err)
+		return
+	}
+
+	s.redirectWithFlash(w, r, DashboardPath+"/schedule", "Выходной "+date.Format("02.01.2006")+" добавлен", false)
+}
+
+// handleDashboardDeleteBlockedDate removes a day off
+func (s *Server) handleDashboardDeleteBlockedDate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		dashboardNotFound(w, "Выходной не найден")
+		return
+	}
+
+	if err := s.schedule.DeleteBlockedDate(r.Context(), uint(id)); err != nil {
+		dashboardNotFound(w, "Выходной не найден")
+		return
+	}
+
+	s.redirectWithFlash(w, r, DashboardPath+"/schedule", "Выходной удален", false)
+}
@@ -0,0 +1,78 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gobot/internal/config"
+	"gobot/internal/database"
+	"gobot/internal/services"
+)
+
+// setupTestDB points the global database at a fresh SQLite file for one test
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	if err := database.Initialize(filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := database.Close(); err != nil {
+			t.Errorf("failed to close database: %v", err)
+		}
+	})
+}
+
+// newTestServer creates a server without notifications and an API key for it
+func newTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	setupTestDB(t)
+	s := New(&config.Config{}, services.NewCalendarService(), nil, services.NewBookingLimiter(services.BookingLimits{}), "test_bot")
+	key, _, err := s.apiKeys.CreateKey(context.Background(), "tests", 1)
+	if err != nil {
+		t.Fatalf("failed to create API key: %v", err)
+	}
+	return s, key
+}
+
+// apiRequest sends a request with the API key through the server handler
+// An empty key sends the request unauthenticated
+func apiRequest(t *testing.T, s *Server, key, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, APIPrefix+path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	rec := httptest.NewRecorder()
+	s.http.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// decodeResponse reads a JSON response into value
+func decodeResponse(t *testing.T, rec *httptest.ResponseRecorder, value interface{}) {
+	t.Helper()
+	if err := json.NewDecoder(rec.Body).Decode(value); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+}
+
+// expectStatus fails the test if the response has another status
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d, body %s", rec.Code, status, rec.Body.String())
+	}
+}
+
+// createTestUser creates a Telegram client
+func createTestUser(t *testing.T, id int64) *database.User {
+	t.Helper()
+	user := &database.User{ID: id, FirstName: "Anna", Username: "anna"}
+	if err := database.DB.Create(user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return user
+}
@@ -0,0 +1,29 @@
+// Package web serves metrics for Prometheus
+package web
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"gobot/internal/metrics"
+)
+
+// MetricsPath is the path of the Prometheus metrics endpoint
+const MetricsPath = "/metrics"
+
+// handleMetrics serves metrics in the Prometheus text format
+// With METRICS_TOKEN set the scraper must send it as a bearer token
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if token := s.config.MetricsToken; token != "" {
+		given, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	metrics.Default.Handler().ServeHTTP(w, r)
+}
@@ -0,0 +1,353 @@
+// Package web contains the Telegram Mini App for clients
+package web
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"gobot/internal/database"
+	"gobot/internal/i18n"
+	"gobot/internal/services"
+)
+
+// MiniAppPath is the path of the Telegram Mini App
+const MiniAppPath = "/app"
+
+// miniAppDays is how many days ahead the Mini App calendar offers, today included
+const miniAppDays = 30
+
+//go:embed miniapp
+var miniAppFS embed.FS
+
+// miniAppUserKey stores the client who opened the Mini App in the request context
+type miniAppUserKey struct{}
+
+// miniAppTexts are catalog keys the Mini App page is translated with
+var miniAppTexts = []string{
+	"webapp.title", "webapp.services", "webapp.date", "webapp.time", "webapp.no_time", "webapp.day_off",
+	"webapp.confirm", "webapp.service", "webapp.when", "webapp.price", "webapp.phone", "webapp.book",
+	"webapp.back", "webapp.created", "webapp.close", "error.generic",
+}
+
+// miniAppSession is the response of the session endpoint
+type miniAppSession struct {
+	Lang     string            `json:"lang"`
+	Texts    map[string]string `json:"texts"`
+	Services []miniAppService  `json:"services"`
+	HasPhone bool              `json:"has_phone"`
+	From     string            `json:"from"` // First bookable date
+	To       string            `json:"to"`   // Last bookable date
+}
+
+// miniAppService is a catalog item with the price clients pay today
+type miniAppService struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Details      string `json:"details,omitempty"`
+	DurationText string `json:"duration_text"`
+	PriceText    string `json:"price_text"`
+	OldPriceText string `json:"old_price_text,omitempty"` // Set when a discount applies
+}
+
+// miniAppDay is a day of the month calendar
+type miniAppDay struct {
+	Date   string `json:"date"`
+	Free   int    `json:"free"` // Number of free slots
+	DayOff bool   `json:"day_off,omitempty"`
+}
+
+// miniAppBookingInput is the request body of booking creation
+type miniAppBookingInput struct {
+	ServiceID uint   `json:"service_id"`
+	Date      string `json:"date"`
+	Time      string `json:"time"`
+	Phone     string `json:"phone"`
+}
+
+// miniAppBooking is the response of booking creation
+type miniAppBooking struct {
+	ID      uint   `json:"id"`
+	Message string `json:"message"`
+}
+
+// registerMiniApp adds Mini App routes to the mux
+func (s *Server) registerMiniApp(mux *http.ServeMux) {
+	static, err := fs.Sub(miniAppFS, "miniapp")
+	if err != nil {
+		panic(err)
+	}
+	mux.Handle("GET "+MiniAppPath, http.RedirectHandler(MiniAppPath+"/", http.StatusMovedPermanently))
+	mux.Handle("GET "+MiniAppPath+"/", http.StripPrefix(MiniAppPath+"/", http.FileServerFS(static)))
+
+	routes := map[string]http.HandlerFunc{
+		"GET /api/session":             s.handleMiniAppSession,
+		"GET /api/services/{id}/days":  s.handleMiniAppDays,
+		"GET /api/services/{id}/slots": s.handleMiniAppSlots,
+		"POST /api/bookings":           s.handleMiniAppCreateBooking,
+	}
+	for route, handler := range routes {
+		method, path, _ := strings.Cut(route, " ")
+		mux.Handle(method+" "+MiniAppPath+path, s.requireWebApp(handler))
+	}
+}
+
+// requireWebApp rejects requests without init data signed by the bot
+// The page passes Telegram.WebApp.initData as "Authorization: tma <initData>"
+func (s *Server) requireWebApp(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, initData, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !strings.EqualFold(scheme, "tma") || initData == "" {
+			writeAPIError(w, http.StatusUnauthorized, "init data is required")
+			return
+		}
+
+		tgUser, err := services.VerifyWebAppInitData(s.config.BotToken, initData, time.Now())
+		if err != nil {
+			writeAPIError(w, http.StatusUnauthorized, "invalid init data")
+			return
+		}
+
+		user, err := s.users.GetOrCreateUser(r.Context(), tgUser)
+		if err != nil {
+			writeAPIServerError(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), miniAppUserKey{}, user)))
+	})
+}
+
+// miniAppUser returns the client who opened the Mini App
+func miniAppUser(r *http.Request) *database.User {
+	user, _ := r.Context().Value(miniAppUserKey{}).(*database.User)
+	return user
+}
+
+// handleMiniAppSession returns texts, the catalog and the booking window
+func (s *Server) handleMiniAppSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := miniAppUser(r)
+	lang := services.UserLanguage(user)
+
+	catalog, err := s.bookings.GetAvailableServices(ctx)
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	session := miniAppSession{
+		Lang:     lang,
+		Texts:    make(map[string]string, len(miniAppTexts)),
+		Services: make([]miniAppService, 0, len(catalog)),
+		HasPhone: user.Phone != "",
+	}
+	for _, key := range miniAppTexts {
+		session.Texts[key] = i18n.T(lang, key)
+	}
+
+	for _, service := range catalog {
+		_, price, err := s.discounts.GetServiceWithDiscount(ctx, service.ID)
+		if err != nil {
+			writeAPIServerError(w, r, err)
+			return
+		}
+		item := miniAppService{
+			ID:           service.ID,
+			Name:         service.Name,
+			Description:  service.Description,
+			Details:      service.DetailedDescription,
+			DurationText: i18n.T(lang, "webapp.minutes", service.Duration),
+			PriceText:    i18n.Price(lang, price),
+		}
+		if price != service.Price {
+			item.OldPriceText = i18n.Price(lang, service.Price)
+		}
+		session.Services = append(session.Services, item)
+	}
+
+	from, to := miniAppWindow()
+	session.From = from.Format(apiDateLayout)
+	session.To = to.AddDate(0, 0, -1).Format(apiDateLayout)
+
+	writeJSON(w, http.StatusOK, session)
+}
+
+// handleMiniAppDays returns free slot counts of a month, ?month= is YYYY-MM
+// Days outside the booking window are left out
+func (s *Server) handleMiniAppDays(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	service, ok := s.miniAppService(w, r)
+	if !ok {
+		return
+	}
+
+	month, err := time.ParseInLocation("2006-01", r.URL.Query().Get("month"), time.Local)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "month must be YYYY-MM")
+		return
+	}
+
+	from, to := miniAppWindow()
+	if month.After(from) {
+		from = month
+	}
+	if next := month.AddDate(0, 1, 0); next.Before(to) {
+		to = next
+	}
+
+	daysOff, err := s.daysOff(ctx, from, to)
+	if err != nil {
+		writeAPIServerError(w, r, err)
+		return
+	}
+
+	days := make([]miniAppDay, 0)
+	for date := from; date.Before(to); date = date.AddDate(0, 0, 1) {
+		day := miniAppDay{Date: date.Format(apiDateLayout), DayOff: daysOff[date.Format(apiDateLayout)]}
+		if !day.DayOff {
+			day.Free = len(services.AvailableTimeSlots(ctx, date, service.Duration, 0))
+		}
+		days = append(days, day)
+	}
+
+	writeJSON(w, http.StatusOK, days)
+}
+
+// handleMiniAppSlots returns free start times of a date
+func (s *Server) handleMiniAppSlots(w http.ResponseWriter, r *http.Request) {
+	service, ok := s.miniAppService(w, r)
+	if !ok {
+		return
+	}
+
+	date, err := parseAPIDate(r.URL.Query().Get("date"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "date must be YYYY-MM-DD")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.miniAppSlots(r.Context(), date, service.Duration))
+}
+
+// handleMiniAppCreateBooking books a slot with the same checks and notifications as the chat flow
+// Returned errors are user-facing and translated to the client's language
+func (s *Server) handleMiniAppCreateBooking(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := miniAppUser(r)
+	lang := services.UserLanguage(user)
+
+	var in miniAppBookingInput
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	date, err := parseAPIDate(in.Date)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, i18n.T(lang, "error.generic"))
+		return
+	}
+
+	service, err := s.admin.GetServiceByID(ctx, in.ServiceID)
+	if err != nil || !service.IsActive {
+		writeAPIError(w, http.StatusNotFound, i18n.T(lang, "error.load_service"))
+		return
+	}
+
+	phone := ""
+	if in.Phone = strings.TrimSpace(in.Phone); in.Phone != "" && user.Phone == "" {
+		phone = services.NormalizePhone(in.Phone)
+		if digits := strings.TrimPrefix(phone, "+"); len(digits) < 10 || len(digits) > 15 {
+			writeAPIError(w, http.StatusBadRequest, i18n.T(lang, "webapp.phone_invalid"))
+			return
+		}
+	}
+
+	if !slices.Contains(s.miniAppSlots(ctx, date, service.Duration), in.Time) {
+		writeAPIError(w, http.StatusConflict, i18n.T(lang, "slot.taken"))
+		return
+	}
+
+	// Block list and anti-abuse limits are shared with the chat flow
+	if err := s.limiter.Attempt(ctx, user.ID); err != nil {
+		status := http.StatusForbidden
+		if errors.Is(err, services.ErrBookingTooFrequent) {
+			status = http.StatusTooManyRequests
+		}
+		writeAPIError(w, status, services.LimitErrorText(lang, err))
+		return
+	}
+
+	if phone != "" {
+		if err := s.users.UpdateProfileField(ctx, user.ID, services.ProfileFieldPhone, phone); err != nil {
+			slog.ErrorContext(r.Context(), "Failed to save phone", "user_id", user.ID, "error", err)
+		}
+	}
+
+	booking, err := s.bookings.CreateBooking(ctx, user.ID, service.ID, date, in.Time)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to create Mini App booking", "user_id", user.ID, "error", err)
+		writeAPIError(w, http.StatusInternalServerError, i18n.T(lang, "error.create_booking"))
+		return
+	}
+
+	if s.notifications != nil {
+		s.notifications.NotifyAdminsNewBooking(ctx, booking, "")
+		s.notifyBooking(ctx, booking, s.notifications.SendBookingCreated)
+	}
+	slog.InfoContext(r.Context(), "Booking created in the Mini App", "booking_id", booking.ID, "user_id", user.ID)
+
+	writeJSON(w, http.StatusCreated, miniAppBooking{ID: booking.ID, Message: i18n.T(lang, "webapp.created")})
+}
+
+// miniAppService loads the active service of the {id} path parameter
+func (s *Server) miniAppService(w http.ResponseWriter, r *http.Request) (*database.Service, bool) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	service, err := s.admin.GetServiceByID(r.Context(), uint(id))
+	if err != nil || !service.IsActive {
+		writeAPIError(w, http.StatusNotFound, "service not found")
+		return nil, false
+	}
+	return service, true
+}
+
+// miniAppSlots returns free start times of a date inside the booking window
+// Days off and times outside the working hours have no free time
+func (s *Server) miniAppSlots(ctx context.Context, date time.Time, duration int) []string {
+	from, to := miniAppWindow()
+	if date.Before(from) || !date.Before(to) {
+		return []string{}
+	}
+	return services.AvailableTimeSlots(ctx, date, duration, 0)
+}
+
+// daysOff returns blocked dates of [from, to) as a set of YYYY-MM-DD
+func (s *Server) daysOff(ctx context.Context, from, to time.Time) (map[string]bool, error) {
+	blocked, err := s.schedule.GetBlockedDates(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]bool, len(blocked))
+	for _, day := range blocked {
+		days[day.Date.Format(apiDateLayout)] = true
+	}
+	return days, nil
+}
+
+// miniAppWindow returns the dates clients can book in the Mini App, to is exclusive
+func miniAppWindow() (from, to time.Time) {
+	now := time.Now()
+	from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return from, from.AddDate(0, 0, miniAppDays)
+}
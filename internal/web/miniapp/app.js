@@ -0,0 +1,208 @@
+// Booking Mini App: service → date → time → confirmation
+(function () {
+  "use strict";
+
+  var tg = window.Telegram && window.Telegram.WebApp;
+  var app = document.getElementById("app");
+  var session = null;
+  var state = {};
+
+  // t returns a text of the bot catalog in the client's language
+  function t(key) {
+    return (session && session.texts[key]) || key;
+  }
+
+  // el builds an element, strings become text nodes so data is never parsed as HTML
+  function el(tag, attrs) {
+    var node = document.createElement(tag);
+    Object.keys(attrs || {}).forEach(function (name) {
+      if (name === "onclick") {
+        node.onclick = attrs[name];
+      } else if (attrs[name] !== false && attrs[name] != null) {
+        node.setAttribute(name, attrs[name] === true ? "" : attrs[name]);
+      }
+    });
+    for (var i = 2; i < arguments.length; i++) {
+      var child = arguments[i];
+      if (child == null) continue;
+      node.appendChild(typeof child === "string" ? document.createTextNode(child) : child);
+    }
+    return node;
+  }
+
+  function show() {
+    var nodes = Array.prototype.filter.call(arguments, function (node) { return node != null; });
+    app.replaceChildren.apply(app, nodes);
+    window.scrollTo(0, 0);
+  }
+
+  function fail(message) {
+    show(el("p", { class: "error" }, message || t("error.generic")));
+  }
+
+  // api calls the backend with the signed launch data of the Mini App
+  function api(method, path, body) {
+    return fetch("api/" + path, {
+      method: method,
+      headers: { "Authorization": "tma " + tg.initData, "Content-Type": "application/json" },
+      body: body ? JSON.stringify(body) : undefined
+    }).then(function (response) {
+      return response.json().then(function (data) {
+        if (!response.ok) throw new Error(data.error);
+        return data;
+      });
+    });
+  }
+
+  function isoDate(date) {
+    return date.getFullYear() + "-" + String(date.getMonth() + 1).padStart(2, "0") + "-" + String(date.getDate()).padStart(2, "0");
+  }
+
+  function parseDate(value) {
+    var parts = value.split("-");
+    return new Date(+parts[0], parts[1] - 1, +parts[2]);
+  }
+
+  function formatDate(value) {
+    return parseDate(value).toLocaleDateString(session.lang, { weekday: "short", day: "numeric", month: "long" });
+  }
+
+  function backButton(handler) {
+    return el("button", { class: "back", onclick: handler }, "← " + t("webapp.back"));
+  }
+
+  function showServices() {
+    var cards = session.services.map(function (service) {
+      return el("button", { class: "card", onclick: function () {
+        state = { service: service, month: parseDate(session.from) };
+        state.month.setDate(1);
+        showCalendar();
+      } },
+        el("span", { class: "price" },
+          service.old_price_text ? el("span", { class: "old" }, service.old_price_text) : null,
+          service.price_text),
+        el("b", null, service.name),
+        el("span", { class: "hint" }, service.duration_text + " · " + service.description));
+    });
+    show.apply(null, [el("h1", null, t("webapp.services"))].concat(cards));
+  }
+
+  function showCalendar() {
+    var month = state.month;
+    var first = parseDate(session.from);
+    var last = parseDate(session.to);
+    var title = month.toLocaleDateString(session.lang, { month: "long", year: "numeric" });
+    var prev = new Date(month.getFullYear(), month.getMonth() - 1, 1);
+    var next = new Date(month.getFullYear(), month.getMonth() + 1, 1);
+
+    var grid = el("div", { class: "days" });
+    // Weeks start on Monday, 2024-01-01 was one
+    for (var d = 1; d <= 7; d++) {
+      grid.appendChild(el("span", null, new Date(2024, 0, d).toLocaleDateString(session.lang, { weekday: "short" })));
+    }
+    for (var pad = (month.getDay() + 6) % 7; pad > 0; pad--) {
+      grid.appendChild(el("span"));
+    }
+
+    show(
+      backButton(showServices),
+      el("h1", null, state.service.name),
+      el("p", { class: "hint" }, t("webapp.date")),
+      el("div", { class: "month" },
+        el("button", { disabled: prev < new Date(first.getFullYear(), first.getMonth(), 1), onclick: function () { state.month = prev; showCalendar(); } }, "‹"),
+        el("b", null, title),
+        el("button", { disabled: next > last, onclick: function () { state.month = next; showCalendar(); } }, "›")),
+      grid
+    );
+
+    var monthKey = isoDate(month).slice(0, 7);
+    api("GET", "services/" + state.service.id + "/days?month=" + monthKey).then(function (days) {
+      var byDate = {};
+      days.forEach(function (day) { byDate[day.date] = day; });
+      var count = new Date(month.getFullYear(), month.getMonth() + 1, 0).getDate();
+      for (var i = 1; i <= count; i++) {
+        var date = isoDate(new Date(month.getFullYear(), month.getMonth(), i));
+        var day = byDate[date];
+        var free = day && day.free > 0;
+        grid.appendChild(el("button", {
+          class: free ? "free" : false,
+          disabled: !free,
+          title: day && day.day_off ? t("webapp.day_off") : false,
+          onclick: (function (value) { return function () { state.date = value; showTimes(); }; })(date)
+        }, String(i)));
+      }
+    }).catch(function (err) { fail(err.message); });
+  }
+
+  function showTimes() {
+    var list = el("div", { class: "slots" });
+    show(
+      backButton(showCalendar),
+      el("h1", null, formatDate(state.date)),
+      el("p", { class: "hint" }, t("webapp.time")),
+      list
+    );
+
+    api("GET", "services/" + state.service.id + "/slots?date=" + state.date).then(function (slots) {
+      if (slots.length === 0) {
+        list.replaceWith(el("p", { class: "hint" }, t("webapp.no_time")));
+        return;
+      }
+      slots.forEach(function (slot) {
+        list.appendChild(el("button", { onclick: function () { state.time = slot; showConfirm(); } }, slot));
+      });
+    }).catch(function (err) { fail(err.message); });
+  }
+
+  function showConfirm() {
+    var phone = session.has_phone ? null : el("input", { type: "tel", autocomplete: "tel", placeholder: "+7 900 123-45-67" });
+    var error = el("p", { class: "error" });
+    var submit = el("button", { onclick: function () {
+      submit.disabled = true;
+      error.textContent = "";
+      api("POST", "bookings", {
+        service_id: state.service.id,
+        date: state.date,
+        time: state.time,
+        phone: phone ? phone.value : ""
+      }).then(function (booking) {
+        if (phone && phone.value) session.has_phone = true;
+        if (tg.HapticFeedback) tg.HapticFeedback.notificationOccurred("success");
+        show(
+          el("h1", null, "✅"),
+          el("p", null, booking.message),
+          el("button", { onclick: function () { tg.close(); } }, t("webapp.close"))
+        );
+      }).catch(function (err) {
+        submit.disabled = false;
+        error.textContent = err.message;
+      });
+    } }, t("webapp.book"));
+
+    show(
+      backButton(showTimes),
+      el("h1", null, t("webapp.confirm")),
+      el("table", { class: "details" },
+        el("tr", null, el("th", null, t("webapp.service")), el("td", null, state.service.name)),
+        el("tr", null, el("th", null, t("webapp.when")), el("td", null, formatDate(state.date) + ", " + state.time)),
+        el("tr", null, el("th", null, t("webapp.price")), el("td", null, state.service.price_text))),
+      phone ? el("label", null, t("webapp.phone"), phone) : null,
+      error,
+      submit
+    );
+  }
+
+  if (!tg || !tg.initData) {
+    fail("Откройте запись из меню бота в Telegram / Open the booking from the bot menu in Telegram");
+    return;
+  }
+  tg.ready();
+  tg.expand();
+
+  api("GET", "session").then(function (data) {
+    session = data;
+    document.documentElement.lang = data.lang;
+    document.title = t("webapp.title");
+    showServices();
+  }).catch(function (err) { fail(err.message); });
+})();
@@ -0,0 +1,130 @@
+// Package web serves HTTP endpoints of the bot
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"gobot/internal/config"
+	"gobot/internal/logging"
+	"gobot/internal/services"
+)
+
+// Server is the HTTP server of the bot
+type Server struct {
+	config        *config.Config
+	calendar      *services.CalendarService
+	notifications *services.NotificationService
+	admin         *services.AdminService
+	bookings      *services.BookingService
+	discounts     *services.DiscountService
+	users         *services.UserService
+	schedule      *services.ScheduleService
+	reports       *services.ReportService
+	apiKeys       *services.APIKeyService
+	auth          *services.DashboardAuthService
+	limiter       *services.BookingLimiter // Shared with the bot so limits count bookings from both
+	botUsername   string                   // For the Telegram Login Widget
+	pages         map[string]*template.Template
+	http          *http.Server
+}
+
+// New creates a new HTTP server listening on cfg.HTTPAddr
+// Notifications are the same the bot sends, so changes made over HTTP reach clients too
+func New(cfg *config.Config, calendar *services.CalendarService, notifications *services.NotificationService, limiter *services.BookingLimiter, botUsername string) *Server {
+	s := &Server{
+		config:        cfg,
+		calendar:      calendar,
+		notifications: notifications,
+		admin:         services.NewAdminService(),
+		bookings:      services.NewBookingService(),
+		discounts:     services.NewDiscountService(),
+		users:         services.NewUserService(),
+		schedule:      services.NewScheduleService(),
+		reports:       services.NewReportService(),
+		apiKeys:       services.NewAPIKeyService(),
+		auth:          services.NewDashboardAuthService(),
+		limiter:       limiter,
+		botUsername:   botUsername,
+		pages:         parseDashboardTemplates(),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+CalendarFeedPath, s.handleCalendarFeed)
+	mux.HandleFunc("GET "+MetricsPath, s.handleMetrics)
+	s.registerAPI(mux)
+	s.registerDashboard(mux)
+	s.registerMiniApp(mux)
+
+	s.http = &http.Server{
+		Addr:              cfg.HTTPAddr,
+		Handler:           logRequests(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+	return s
+}
+
+// Start serves HTTP requests in background
+func (s *Server) Start() {
+	go func() {
+		slog.Info("HTTP server listening", "addr", s.config.HTTPAddr)
+		if err := s.http.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("HTTP server failed", "error", err)
+		}
+	}()
+}
+
+// Stop gracefully shuts the server down
+func (s *Server) Stop(ctx context.Context) error {
+	if err := s.http.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to stop HTTP server: %w", err)
+	}
+	return nil
+}
+
+// statusRecorder remembers the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests gives every request a trace ID and logs its method, path, status and latency
+// The trace ID is returned in the X-Request-ID header to match client reports with logs
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		traceID := logging.NewTraceID()
+		ctx := logging.With(r.Context(), "trace_id", traceID)
+
+		w.Header().Set("X-Request-ID", traceID)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		level := slog.LevelInfo
+		switch {
+		case recorder.status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case r.URL.Path == MetricsPath:
+			// Scraped every few seconds, would drown other records
+			level = slog.LevelDebug
+		}
+		slog.Log(ctx, level, "HTTP request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"latency_ms", time.Since(start).Milliseconds(),
+		)
+	})
+}